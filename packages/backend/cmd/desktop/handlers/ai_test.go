@@ -59,7 +59,66 @@ func setupTestDBWithAI(t *testing.T) (*sql.DB, func()) {
 			created_at INTEGER NOT NULL,
 			updated_at INTEGER NOT NULL,
 			version INTEGER NOT NULL DEFAULT 1,
-			content_hash TEXT
+			content_hash TEXT,
+			is_pinned INTEGER NOT NULL DEFAULT 0 CHECK(is_pinned IN (0, 1)),
+			is_favorite INTEGER NOT NULL DEFAULT 0 CHECK(is_favorite IN (0, 1)),
+			read_status TEXT NOT NULL DEFAULT 'unread' CHECK(read_status IN ('unread', 'in_progress', 'read')),
+			read_progress REAL NOT NULL DEFAULT 0 CHECK(read_progress >= 0 AND read_progress <= 1),
+			is_archived INTEGER NOT NULL DEFAULT 0 CHECK(is_archived IN (0, 1)),
+			favorite_changed_at INTEGER NOT NULL DEFAULT 0,
+			pinned_changed_at INTEGER NOT NULL DEFAULT 0,
+			read_changed_at INTEGER NOT NULL DEFAULT 0,
+			archived_changed_at INTEGER NOT NULL DEFAULT 0
+		);
+
+		CREATE TABLE IF NOT EXISTS tags (
+			id TEXT PRIMARY KEY,
+			name TEXT NOT NULL UNIQUE,
+			color TEXT DEFAULT '#3B82F6',
+			is_deleted INTEGER NOT NULL DEFAULT 0,
+			created_at INTEGER NOT NULL,
+			updated_at INTEGER NOT NULL,
+			parent_id TEXT
+		);
+
+		CREATE TABLE IF NOT EXISTS content_tags (
+			content_id TEXT NOT NULL,
+			tag_id TEXT NOT NULL,
+			assigned_at INTEGER NOT NULL,
+			PRIMARY KEY (content_id, tag_id)
+		);
+
+		CREATE TABLE IF NOT EXISTS content_revisions (
+			id TEXT PRIMARY KEY,
+			content_id TEXT NOT NULL,
+			version INTEGER NOT NULL,
+			title TEXT NOT NULL,
+			content_text TEXT NOT NULL,
+			source_url TEXT,
+			media_type TEXT NOT NULL,
+			tags TEXT NOT NULL DEFAULT '',
+			summary TEXT,
+			content_hash TEXT,
+			edited_at INTEGER NOT NULL,
+			created_at INTEGER NOT NULL
+		);
+
+		CREATE TABLE IF NOT EXISTS content_links (
+			source_id TEXT NOT NULL,
+			kind TEXT NOT NULL,
+			target TEXT NOT NULL,
+			target_id TEXT,
+			position INTEGER NOT NULL DEFAULT 0,
+			created_at INTEGER NOT NULL,
+			PRIMARY KEY (source_id, kind, target)
+		);
+
+		CREATE TABLE IF NOT EXISTS change_log (
+			id TEXT PRIMARY KEY,
+			item_id TEXT NOT NULL,
+			operation TEXT NOT NULL,
+			version INTEGER NOT NULL,
+			timestamp INTEGER NOT NULL
 		);
 	`)
 	if err != nil {
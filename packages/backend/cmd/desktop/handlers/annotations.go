@@ -0,0 +1,237 @@
+// Package handlers provides REST API handlers for annotations.
+package handlers
+
+import (
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/kimhsiao/memonexus/backend/internal/db"
+	"github.com/kimhsiao/memonexus/backend/internal/models"
+)
+
+// AnnotationHandler handles highlight and note operations on item text.
+type AnnotationHandler struct {
+	repo *db.Repository
+}
+
+// NewAnnotationHandler creates a new AnnotationHandler.
+func NewAnnotationHandler(repo *db.Repository) *AnnotationHandler {
+	return &AnnotationHandler{repo: repo}
+}
+
+// ListAnnotations handles GET /content/{id}/annotations
+// Returns the item's annotations in text order.
+func (h *AnnotationHandler) ListAnnotations(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	annotations, err := h.repo.ListAnnotations(r.PathValue("id"))
+	if err != nil {
+		if err == sql.ErrNoRows {
+			http.Error(w, "Content item not found", http.StatusNotFound)
+			return
+		}
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(annotations)
+}
+
+// CreateAnnotation handles POST /content/{id}/annotations
+// Body: {"start_offset": 10, "end_offset": 24, "quote": "...", "note": "...",
+// "color": "yellow"}. Offsets count characters of the item text; if quote
+// is given, it is looked for near the offsets.
+func (h *AnnotationHandler) CreateAnnotation(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	var request struct {
+		StartOffset int    `json:"start_offset"`
+		EndOffset   int    `json:"end_offset"`
+		Quote       string `json:"quote"`
+		Note        string `json:"note"`
+		Color       string `json:"color"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&request); err != nil {
+		http.Error(w, "Invalid request body", http.StatusBadRequest)
+		return
+	}
+
+	a := &models.Annotation{
+		ContentID:   models.UUID(r.PathValue("id")),
+		StartOffset: request.StartOffset,
+		EndOffset:   request.EndOffset,
+		Quote:       request.Quote,
+		Note:        request.Note,
+		Color:       request.Color,
+	}
+	if err := db.ValidateAnnotation(a); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	if err := h.repo.CreateAnnotation(a); err != nil {
+		if err == sql.ErrNoRows {
+			http.Error(w, "Content item not found", http.StatusNotFound)
+			return
+		}
+		writeAnnotationError(w, err)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusCreated)
+	json.NewEncoder(w).Encode(a)
+}
+
+// GetAnnotation handles GET /annotations/{id}
+func (h *AnnotationHandler) GetAnnotation(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	a, err := h.repo.GetAnnotation(r.PathValue("id"))
+	if err != nil {
+		writeAnnotationError(w, err)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(a)
+}
+
+// UpdateAnnotation handles PUT /annotations/{id}
+// Only the fields present in the request body are changed. New offsets
+// move the highlight, re-anchoring an orphaned annotation.
+func (h *AnnotationHandler) UpdateAnnotation(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPut {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	var request struct {
+		StartOffset *int    `json:"start_offset"`
+		EndOffset   *int    `json:"end_offset"`
+		Quote       *string `json:"quote"`
+		Note        *string `json:"note"`
+		Color       *string `json:"color"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&request); err != nil {
+		http.Error(w, "Invalid request body", http.StatusBadRequest)
+		return
+	}
+	if (request.StartOffset == nil) != (request.EndOffset == nil) {
+		http.Error(w, "start_offset and end_offset must be given together", http.StatusBadRequest)
+		return
+	}
+
+	a, err := h.repo.GetAnnotation(r.PathValue("id"))
+	if err != nil {
+		writeAnnotationError(w, err)
+		return
+	}
+
+	// Update fields
+	if request.StartOffset != nil {
+		a.StartOffset = *request.StartOffset
+		a.EndOffset = *request.EndOffset
+		a.Quote = ""
+		if request.Quote != nil {
+			a.Quote = *request.Quote
+		}
+		a.Orphaned = false
+	}
+	if request.Note != nil {
+		a.Note = *request.Note
+	}
+	if request.Color != nil {
+		a.Color = *request.Color
+	}
+	if err := db.ValidateAnnotation(a); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	if err := h.repo.UpdateAnnotation(a); err != nil {
+		writeAnnotationError(w, err)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(a)
+}
+
+// DeleteAnnotation handles DELETE /annotations/{id}
+func (h *AnnotationHandler) DeleteAnnotation(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodDelete {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	if err := h.repo.DeleteAnnotation(r.PathValue("id")); err != nil {
+		writeAnnotationError(w, err)
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// SearchAnnotations handles GET /annotations/search?q={query}
+// Returns annotations whose notes contain every word of the query.
+func (h *AnnotationHandler) SearchAnnotations(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	query := strings.TrimSpace(r.URL.Query().Get("q"))
+	if query == "" {
+		http.Error(w, "q is required", http.StatusBadRequest)
+		return
+	}
+	page, _ := strconv.Atoi(r.URL.Query().Get("page"))
+	if page < 1 {
+		page = 1
+	}
+	perPage, _ := strconv.Atoi(r.URL.Query().Get("per_page"))
+	if perPage < 1 || perPage > 100 {
+		perPage = 20
+	}
+
+	results, err := h.repo.SearchAnnotations(query, perPage, (page-1)*perPage)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(map[string]interface{}{
+		"results":  results,
+		"page":     page,
+		"per_page": perPage,
+	})
+}
+
+// writeAnnotationError writes the response for a failed annotation
+// operation.
+func writeAnnotationError(w http.ResponseWriter, err error) {
+	switch {
+	case err == sql.ErrNoRows:
+		http.Error(w, "Annotation not found", http.StatusNotFound)
+	case errors.Is(err, db.ErrInvalidAnnotationAnchor):
+		http.Error(w, err.Error(), http.StatusBadRequest)
+	default:
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+	}
+}
@@ -0,0 +1,119 @@
+// Package handlers tests for annotation endpoints.
+package handlers
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/kimhsiao/memonexus/backend/internal/db"
+	"github.com/kimhsiao/memonexus/backend/internal/models"
+)
+
+func TestAnnotationHandler(t *testing.T) {
+	testDB := setupMigratedTestDB(t)
+	defer testDB.Close()
+
+	repo := db.NewRepository(testDB)
+	handler := NewAnnotationHandler(repo)
+
+	item := &models.ContentItem{Title: "Article", ContentText: "Caching is hard.", MediaType: "web"}
+	if err := repo.CreateContentItem(item); err != nil {
+		t.Fatalf("Failed to create test item: %v", err)
+	}
+	itemID := string(item.ID)
+
+	// Create
+	req := httptest.NewRequest(http.MethodPost, "/content/"+itemID+"/annotations",
+		bytes.NewBufferString(`{"start_offset": 0, "end_offset": 7, "note": "Invalidation too"}`))
+	req.SetPathValue("id", itemID)
+	w := httptest.NewRecorder()
+	handler.CreateAnnotation(w, req)
+	if w.Code != http.StatusCreated {
+		t.Fatalf("Create: expected status 201, got %d. Body: %s", w.Code, w.Body.String())
+	}
+	var created models.Annotation
+	json.NewDecoder(w.Body).Decode(&created)
+	if created.Quote != "Caching" || created.Color != "yellow" {
+		t.Errorf("Created = %+v", created)
+	}
+	id := string(created.ID)
+
+	req = httptest.NewRequest(http.MethodPost, "/content/"+itemID+"/annotations",
+		bytes.NewBufferString(`{"start_offset": 5, "end_offset": 50}`))
+	req.SetPathValue("id", itemID)
+	w = httptest.NewRecorder()
+	handler.CreateAnnotation(w, req)
+	if w.Code != http.StatusBadRequest {
+		t.Errorf("Create past the end: expected status 400, got %d", w.Code)
+	}
+
+	req = httptest.NewRequest(http.MethodPost, "/content/"+itemID+"/annotations",
+		bytes.NewBufferString(`{"start_offset": 0, "end_offset": 7, "color": "orange"}`))
+	req.SetPathValue("id", itemID)
+	w = httptest.NewRecorder()
+	handler.CreateAnnotation(w, req)
+	if w.Code != http.StatusBadRequest {
+		t.Errorf("Create with unknown color: expected status 400, got %d", w.Code)
+	}
+
+	// Update moves the highlight
+	req = httptest.NewRequest(http.MethodPut, "/annotations/"+id,
+		bytes.NewBufferString(`{"start_offset": 11, "end_offset": 15, "color": "pink"}`))
+	req.SetPathValue("id", id)
+	w = httptest.NewRecorder()
+	handler.UpdateAnnotation(w, req)
+	var updated models.Annotation
+	json.NewDecoder(w.Body).Decode(&updated)
+	if w.Code != http.StatusOK || updated.Quote != "hard" || updated.Color != "pink" || updated.Note != "Invalidation too" {
+		t.Errorf("Update: got %d %+v", w.Code, updated)
+	}
+
+	// List and search
+	req = httptest.NewRequest(http.MethodGet, "/content/"+itemID+"/annotations", nil)
+	req.SetPathValue("id", itemID)
+	w = httptest.NewRecorder()
+	handler.ListAnnotations(w, req)
+	var list []models.Annotation
+	json.NewDecoder(w.Body).Decode(&list)
+	if w.Code != http.StatusOK || len(list) != 1 {
+		t.Errorf("List: got %d %+v", w.Code, list)
+	}
+
+	req = httptest.NewRequest(http.MethodGet, "/annotations/search?q=invalidation", nil)
+	w = httptest.NewRecorder()
+	handler.SearchAnnotations(w, req)
+	var search struct {
+		Results []db.AnnotationMatch `json:"results"`
+	}
+	json.NewDecoder(w.Body).Decode(&search)
+	if w.Code != http.StatusOK || len(search.Results) != 1 || search.Results[0].ContentTitle != "Article" {
+		t.Errorf("Search: got %d %+v", w.Code, search)
+	}
+
+	req = httptest.NewRequest(http.MethodGet, "/annotations/search", nil)
+	w = httptest.NewRecorder()
+	handler.SearchAnnotations(w, req)
+	if w.Code != http.StatusBadRequest {
+		t.Errorf("Search without q: expected status 400, got %d", w.Code)
+	}
+
+	// Delete
+	req = httptest.NewRequest(http.MethodDelete, "/annotations/"+id, nil)
+	req.SetPathValue("id", id)
+	w = httptest.NewRecorder()
+	handler.DeleteAnnotation(w, req)
+	if w.Code != http.StatusNoContent {
+		t.Errorf("Delete: expected status 204, got %d", w.Code)
+	}
+
+	req = httptest.NewRequest(http.MethodGet, "/annotations/"+id, nil)
+	req.SetPathValue("id", id)
+	w = httptest.NewRecorder()
+	handler.GetAnnotation(w, req)
+	if w.Code != http.StatusNotFound {
+		t.Errorf("Get deleted: expected status 404, got %d", w.Code)
+	}
+}
@@ -0,0 +1,76 @@
+// Package handlers provides the search-as-you-type autocomplete endpoint.
+package handlers
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/kimhsiao/memonexus/backend/internal/db"
+)
+
+// Autocomplete handles GET /search/autocomplete
+// Returns mixed completions (queries from the search history, tags, titles
+// and source domains) for the prefix typed so far.
+func (h *SearchHandler) Autocomplete(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	prefix := r.URL.Query().Get("prefix")
+	if strings.TrimSpace(prefix) == "" {
+		http.Error(w, "prefix is required", http.StatusBadRequest)
+		return
+	}
+	if utf8.RuneCountInString(prefix) > 100 {
+		http.Error(w, "prefix must be 100 characters or less", http.StatusBadRequest)
+		return
+	}
+
+	limit := 10
+	if limitStr := r.URL.Query().Get("limit"); limitStr != "" {
+		l, err := strconv.Atoi(limitStr)
+		if err != nil || l < 1 || l > 20 {
+			http.Error(w, "limit must be between 1 and 20", http.StatusBadRequest)
+			return
+		}
+		limit = l
+	}
+
+	suggestions, err := h.repo.Autocomplete(db.AutocompleteOptions{
+		Prefix: prefix,
+		Limit:  limit,
+	})
+	if err != nil {
+		http.Error(w, fmt.Sprintf("autocomplete failed: %v", err), http.StatusInternalServerError)
+		return
+	}
+
+	result := map[string]interface{}{
+		"prefix":      prefix,
+		"suggestions": toAutocompleteSuggestions(suggestions),
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(result)
+}
+
+// toAutocompleteSuggestions converts db.AutocompleteSuggestion to API response format.
+func toAutocompleteSuggestions(suggestions []*db.AutocompleteSuggestion) []map[string]interface{} {
+	apiSuggestions := make([]map[string]interface{}, len(suggestions))
+	for i, s := range suggestions {
+		apiSuggestions[i] = map[string]interface{}{
+			"kind":  s.Kind,
+			"text":  s.Text,
+			"score": s.Score,
+		}
+		if s.ContentID != "" {
+			apiSuggestions[i]["content_id"] = s.ContentID
+		}
+	}
+	return apiSuggestions
+}
@@ -0,0 +1,74 @@
+// Package handlers tests for the autocomplete endpoint.
+package handlers
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"testing"
+
+	"github.com/kimhsiao/memonexus/backend/internal/db"
+)
+
+func TestSearchHandler_Autocomplete(t *testing.T) {
+	testDB, cleanup := setupTestDBWithHistory(t)
+	defer cleanup()
+
+	handler := NewSearchHandler(db.NewRepository(testDB))
+	insertTestContentItem(t, testDB, "Go Programming Guide", "Learn Go", "web", "golang", 1000)
+
+	// A search is remembered as a recent query
+	w := httptest.NewRecorder()
+	handler.Search(w, httptest.NewRequest(http.MethodGet, "/search?q=golang+guide", nil))
+	if w.Code != http.StatusOK {
+		t.Fatalf("Search: expected status 200, got %d", w.Code)
+	}
+
+	w = httptest.NewRecorder()
+	handler.Autocomplete(w, httptest.NewRequest(http.MethodGet, "/search/autocomplete?prefix=go", nil))
+	if w.Code != http.StatusOK {
+		t.Fatalf("Expected status 200, got %d. Body: %s", w.Code, w.Body.String())
+	}
+
+	var response struct {
+		Suggestions []struct {
+			Kind      string `json:"kind"`
+			Text      string `json:"text"`
+			ContentID string `json:"content_id"`
+		} `json:"suggestions"`
+	}
+	if err := json.NewDecoder(w.Body).Decode(&response); err != nil {
+		t.Fatalf("Failed to decode response: %v", err)
+	}
+
+	var kinds []string
+	for _, s := range response.Suggestions {
+		kinds = append(kinds, s.Kind)
+	}
+	if want := []string{"query", "tag", "title"}; !reflect.DeepEqual(kinds, want) {
+		t.Errorf("Suggestion kinds = %v, want %v", kinds, want)
+	}
+	if len(response.Suggestions) == 3 && response.Suggestions[2].ContentID == "" {
+		t.Error("Title suggestion should include content_id")
+	}
+}
+
+func TestSearchHandler_Autocomplete_InvalidParams(t *testing.T) {
+	testDB, cleanup := setupTestDBWithSearch(t)
+	defer cleanup()
+
+	handler := NewSearchHandler(db.NewRepository(testDB))
+
+	for _, target := range []string{
+		"/search/autocomplete",
+		"/search/autocomplete?prefix=go&limit=0",
+		"/search/autocomplete?prefix=go&limit=abc",
+	} {
+		w := httptest.NewRecorder()
+		handler.Autocomplete(w, httptest.NewRequest(http.MethodGet, target, nil))
+		if w.Code != http.StatusBadRequest {
+			t.Errorf("%s: expected status 400, got %d", target, w.Code)
+		}
+	}
+}
@@ -0,0 +1,163 @@
+// Package handlers provides REST API handlers for database backups.
+package handlers
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"os"
+	"path/filepath"
+
+	"github.com/kimhsiao/memonexus/backend/internal/db"
+	"github.com/kimhsiao/memonexus/backend/internal/logging"
+)
+
+// DefaultBackupRetentionCount is how many backups are kept by default.
+const DefaultBackupRetentionCount = 7
+
+// BackupHandler handles full database backups and restores.
+type BackupHandler struct {
+	database       *db.DB
+	dir            string
+	retentionCount int // Backups kept after each new one; 0 keeps all
+}
+
+// NewBackupHandler creates a new BackupHandler storing backups in dir.
+func NewBackupHandler(database *db.DB, dir string, retentionCount int) *BackupHandler {
+	return &BackupHandler{
+		database:       database,
+		dir:            dir,
+		retentionCount: retentionCount,
+	}
+}
+
+// ListBackups handles GET /backups
+// Returns the backups, newest first.
+func (h *BackupHandler) ListBackups(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	backups, err := db.ListBackups(h.dir)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(map[string]interface{}{"backups": backups})
+}
+
+// CreateBackup handles POST /backups
+// Snapshots the database and applies the retention policy.
+func (h *BackupHandler) CreateBackup(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	backup, err := h.database.Backup(h.dir)
+	if err != nil {
+		http.Error(w, "Backup failed: "+err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	deleted, err := db.ApplyBackupRetention(h.dir, h.retentionCount)
+	if err != nil {
+		logging.Warn("Backup retention failed", map[string]interface{}{"error": err.Error()})
+	}
+	logging.Info("Database backed up", map[string]interface{}{
+		"backup":         backup.ID,
+		"schema_version": backup.SchemaVersion,
+		"deleted_old":    deleted,
+	})
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusCreated)
+	json.NewEncoder(w).Encode(backup)
+}
+
+// DeleteBackup handles DELETE /backups/{id}
+func (h *BackupHandler) DeleteBackup(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodDelete {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	path, ok := h.backupPath(w, r)
+	if !ok {
+		return
+	}
+	if err := db.DeleteBackup(path); err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// RestoreBackup handles POST /backups/{id}/restore
+// Replaces the database with the backup. The current database is backed up
+// first, and that backup is returned as "previous" so the restore can be
+// undone.
+func (h *BackupHandler) RestoreBackup(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	path, ok := h.backupPath(w, r)
+	if !ok {
+		return
+	}
+
+	// Restore checks the backup before replacing anything
+	previous, err := h.database.Backup(h.dir)
+	if err != nil {
+		http.Error(w, "Backup of current database failed: "+err.Error(), http.StatusInternalServerError)
+		return
+	}
+	if err := h.database.Restore(path); err != nil {
+		writeBackupError(w, err)
+		return
+	}
+	logging.Info("Database restored", map[string]interface{}{
+		"backup":   filepath.Base(path),
+		"previous": previous.ID,
+	})
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(map[string]interface{}{
+		"restored": filepath.Base(path),
+		"previous": previous,
+	})
+}
+
+// backupPath returns the file of the backup named by the id path value,
+// writing a 404 response if there is none.
+func (h *BackupHandler) backupPath(w http.ResponseWriter, r *http.Request) (string, bool) {
+	id := r.PathValue("id")
+	path := filepath.Join(h.dir, id)
+	if !db.IsBackupID(id) {
+		http.Error(w, "Backup not found", http.StatusNotFound)
+		return "", false
+	}
+	if _, err := os.Stat(path); err != nil {
+		http.Error(w, "Backup not found", http.StatusNotFound)
+		return "", false
+	}
+	return path, true
+}
+
+// writeBackupError writes the response for a failed backup check or restore.
+func writeBackupError(w http.ResponseWriter, err error) {
+	switch {
+	case errors.Is(err, db.ErrInvalidBackup):
+		http.Error(w, err.Error(), http.StatusBadRequest)
+	case errors.Is(err, db.ErrBackupTooNew):
+		http.Error(w, err.Error(), http.StatusConflict)
+	default:
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+	}
+}
@@ -0,0 +1,102 @@
+// Package handlers tests for database backup endpoints.
+package handlers
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/kimhsiao/memonexus/backend/internal/db"
+	"github.com/kimhsiao/memonexus/backend/internal/models"
+)
+
+func TestBackupHandler(t *testing.T) {
+	database, err := db.Open(t.TempDir())
+	if err != nil {
+		t.Fatalf("Failed to open database: %v", err)
+	}
+	defer database.Close()
+	m := db.NewEmbeddedMigrator(database.DB)
+	if err := m.Initialize(); err != nil {
+		t.Fatalf("Initialize() failed: %v", err)
+	}
+	if err := m.Up(); err != nil {
+		t.Fatalf("Up() failed: %v", err)
+	}
+
+	repo := db.NewRepository(database.DB)
+	item := &models.ContentItem{Title: "Backed up", MediaType: "web", Tags: "go"}
+	if err := repo.CreateContentItem(item); err != nil {
+		t.Fatalf("Failed to create test item: %v", err)
+	}
+
+	dir := filepath.Join(t.TempDir(), "backups")
+	handler := NewBackupHandler(database, dir, 2)
+
+	// Create
+	w := httptest.NewRecorder()
+	handler.CreateBackup(w, httptest.NewRequest(http.MethodPost, "/backups", nil))
+	if w.Code != http.StatusCreated {
+		t.Fatalf("Create: expected status 201, got %d. Body: %s", w.Code, w.Body.String())
+	}
+	var backup db.BackupInfo
+	if err := json.NewDecoder(w.Body).Decode(&backup); err != nil {
+		t.Fatalf("Failed to decode response: %v", err)
+	}
+
+	// Restore undoes the deletion made after the backup
+	if err := repo.DeleteContentItem(string(item.ID)); err != nil {
+		t.Fatalf("Failed to delete test item: %v", err)
+	}
+	req := httptest.NewRequest(http.MethodPost, "/backups/"+backup.ID+"/restore", nil)
+	req.SetPathValue("id", backup.ID)
+	w = httptest.NewRecorder()
+	handler.RestoreBackup(w, req)
+	if w.Code != http.StatusOK {
+		t.Fatalf("Restore: expected status 200, got %d. Body: %s", w.Code, w.Body.String())
+	}
+	if restored, err := repo.GetContentItem(string(item.ID)); err != nil || restored.IsDeleted {
+		t.Errorf("Item after restore = %+v, %v", restored, err)
+	}
+
+	// The pre-restore backup is listed too
+	w = httptest.NewRecorder()
+	handler.ListBackups(w, httptest.NewRequest(http.MethodGet, "/backups", nil))
+	var list struct {
+		Backups []db.BackupInfo `json:"backups"`
+	}
+	if err := json.NewDecoder(w.Body).Decode(&list); err != nil {
+		t.Fatalf("Failed to decode response: %v", err)
+	}
+	if len(list.Backups) != 2 {
+		t.Errorf("List: got %d backups, want 2", len(list.Backups))
+	}
+
+	// Invalid backups are rejected
+	os.WriteFile(filepath.Join(dir, "memonexus-corrupt.db"), []byte("garbage"), 0644)
+	req = httptest.NewRequest(http.MethodPost, "/backups/memonexus-corrupt.db/restore", nil)
+	req.SetPathValue("id", "memonexus-corrupt.db")
+	w = httptest.NewRecorder()
+	handler.RestoreBackup(w, req)
+	if w.Code != http.StatusBadRequest {
+		t.Errorf("Restore corrupt: expected status 400, got %d", w.Code)
+	}
+
+	// Delete
+	for _, id := range []string{backup.ID, "../memonexus.db"} {
+		req = httptest.NewRequest(http.MethodDelete, "/backups/x", nil)
+		req.SetPathValue("id", id)
+		w = httptest.NewRecorder()
+		handler.DeleteBackup(w, req)
+		want := http.StatusNoContent
+		if id != backup.ID {
+			want = http.StatusNotFound
+		}
+		if w.Code != want {
+			t.Errorf("Delete %q: expected status %d, got %d", id, want, w.Code)
+		}
+	}
+}
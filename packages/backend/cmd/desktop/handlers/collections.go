@@ -0,0 +1,329 @@
+// Package handlers provides REST API handlers for collections.
+package handlers
+
+import (
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"strconv"
+
+	"github.com/kimhsiao/memonexus/backend/internal/db"
+	"github.com/kimhsiao/memonexus/backend/internal/models"
+)
+
+// CollectionHandler handles collection (notebook) operations.
+type CollectionHandler struct {
+	repo *db.Repository
+}
+
+// NewCollectionHandler creates a new CollectionHandler.
+func NewCollectionHandler(repo *db.Repository) *CollectionHandler {
+	return &CollectionHandler{repo: repo}
+}
+
+// ListCollections handles GET /collections
+// With tree=true, collections are nested under their parents.
+func (h *CollectionHandler) ListCollections(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	var tree bool
+	if t := r.URL.Query().Get("tree"); t != "" {
+		b, err := strconv.ParseBool(t)
+		if err != nil {
+			http.Error(w, "tree must be true or false", http.StatusBadRequest)
+			return
+		}
+		tree = b
+	}
+
+	var response interface{}
+	var err error
+	if tree {
+		response, err = h.repo.ListCollectionTree()
+	} else {
+		response, err = h.repo.ListCollections()
+	}
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(response)
+}
+
+// CreateCollection handles POST /collections
+func (h *CollectionHandler) CreateCollection(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	var request struct {
+		Name        string      `json:"name"`
+		Description string      `json:"description"`
+		ParentID    models.UUID `json:"parent_id"`
+		Position    int         `json:"position"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&request); err != nil {
+		http.Error(w, "Invalid request body", http.StatusBadRequest)
+		return
+	}
+
+	c := &models.Collection{
+		Name:        request.Name,
+		Description: request.Description,
+		ParentID:    request.ParentID,
+		Position:    request.Position,
+	}
+	if err := db.ValidateCollection(c); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	if err := h.repo.CreateCollection(c); err != nil {
+		writeCollectionError(w, err)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusCreated)
+	json.NewEncoder(w).Encode(c)
+}
+
+// GetCollection handles GET /collections/{id}
+// The response includes the ordered item_ids.
+func (h *CollectionHandler) GetCollection(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	c, err := h.repo.GetCollection(r.PathValue("id"))
+	if err != nil {
+		writeCollectionError(w, err)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(c)
+}
+
+// UpdateCollection handles PUT /collections/{id}
+// Only the fields present in the request body are changed; an empty
+// parent_id moves the collection to the top level.
+func (h *CollectionHandler) UpdateCollection(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPut {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	var request struct {
+		Name        *string      `json:"name"`
+		Description *string      `json:"description"`
+		ParentID    *models.UUID `json:"parent_id"`
+		Position    *int         `json:"position"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&request); err != nil {
+		http.Error(w, "Invalid request body", http.StatusBadRequest)
+		return
+	}
+
+	c, err := h.repo.GetCollection(r.PathValue("id"))
+	if err != nil {
+		writeCollectionError(w, err)
+		return
+	}
+
+	// Update fields
+	if request.Name != nil {
+		c.Name = *request.Name
+	}
+	if request.Description != nil {
+		c.Description = *request.Description
+	}
+	if request.ParentID != nil {
+		c.ParentID = *request.ParentID
+	}
+	if request.Position != nil {
+		c.Position = *request.Position
+	}
+	if err := db.ValidateCollection(c); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	if err := h.repo.UpdateCollection(c); err != nil {
+		writeCollectionError(w, err)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(c)
+}
+
+// DeleteCollection handles DELETE /collections/{id}
+// The collection's items are not deleted.
+func (h *CollectionHandler) DeleteCollection(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodDelete {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	if err := h.repo.DeleteCollection(r.PathValue("id")); err != nil {
+		writeCollectionError(w, err)
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// ReorderCollections handles PUT /collections/order
+// Body: {"parent_id": "...", "collection_ids": [...]} listing every child
+// of parent_id (the top level if empty) in the new order.
+func (h *CollectionHandler) ReorderCollections(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPut {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	var request struct {
+		ParentID      string   `json:"parent_id"`
+		CollectionIDs []string `json:"collection_ids"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&request); err != nil {
+		http.Error(w, "Invalid request body", http.StatusBadRequest)
+		return
+	}
+
+	collections, err := h.repo.ReorderCollections(request.ParentID, request.CollectionIDs)
+	if err != nil {
+		writeCollectionError(w, err)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(collections)
+}
+
+// ListCollectionItems handles GET /collections/{id}/items
+// Returns the collection's items in order.
+func (h *CollectionHandler) ListCollectionItems(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	page, _ := strconv.Atoi(r.URL.Query().Get("page"))
+	if page < 1 {
+		page = 1
+	}
+	perPage, _ := strconv.Atoi(r.URL.Query().Get("per_page"))
+	if perPage < 1 || perPage > 100 {
+		perPage = 20
+	}
+
+	items, err := h.repo.ListCollectionItems(r.PathValue("id"), perPage, (page-1)*perPage)
+	if err != nil {
+		writeCollectionError(w, err)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(map[string]interface{}{
+		"items":    items,
+		"page":     page,
+		"per_page": perPage,
+	})
+}
+
+// AddCollectionItems handles POST /collections/{id}/items
+// Body: {"item_ids": [...]}, appended in order to the end of the collection.
+func (h *CollectionHandler) AddCollectionItems(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	var request struct {
+		ItemIDs []string `json:"item_ids"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&request); err != nil {
+		http.Error(w, "Invalid request body", http.StatusBadRequest)
+		return
+	}
+	if len(request.ItemIDs) == 0 {
+		http.Error(w, "item_ids is required", http.StatusBadRequest)
+		return
+	}
+
+	c, err := h.repo.AddCollectionItems(r.PathValue("id"), request.ItemIDs)
+	if err != nil {
+		writeCollectionError(w, err)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(c)
+}
+
+// ReorderCollectionItems handles PUT /collections/{id}/items/order
+// Body: {"item_ids": [...]} listing every item of the collection in the
+// new order.
+func (h *CollectionHandler) ReorderCollectionItems(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPut {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	var request struct {
+		ItemIDs []string `json:"item_ids"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&request); err != nil {
+		http.Error(w, "Invalid request body", http.StatusBadRequest)
+		return
+	}
+
+	c, err := h.repo.ReorderCollectionItems(r.PathValue("id"), request.ItemIDs)
+	if err != nil {
+		writeCollectionError(w, err)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(c)
+}
+
+// RemoveCollectionItem handles DELETE /collections/{id}/items/{item_id}
+func (h *CollectionHandler) RemoveCollectionItem(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodDelete {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	if err := h.repo.RemoveCollectionItem(r.PathValue("id"), r.PathValue("item_id")); err != nil {
+		writeCollectionError(w, err)
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// writeCollectionError writes the response for a failed collection
+// operation.
+func writeCollectionError(w http.ResponseWriter, err error) {
+	switch {
+	case err == sql.ErrNoRows:
+		http.Error(w, "Collection not found", http.StatusNotFound)
+	case errors.Is(err, db.ErrInvalidCollectionParent),
+		errors.Is(err, db.ErrInvalidCollectionItem),
+		errors.Is(err, db.ErrInvalidCollectionOrder):
+		http.Error(w, err.Error(), http.StatusBadRequest)
+	default:
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+	}
+}
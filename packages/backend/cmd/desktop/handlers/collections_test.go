@@ -0,0 +1,141 @@
+// Package handlers tests for collection endpoints.
+package handlers
+
+import (
+	"bytes"
+	"database/sql"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"testing"
+
+	"github.com/kimhsiao/memonexus/backend/internal/db"
+	"github.com/kimhsiao/memonexus/backend/internal/models"
+)
+
+// setupMigratedTestDB opens a database with the real migrations applied.
+func setupMigratedTestDB(t *testing.T) *sql.DB {
+	t.Helper()
+	testDB, err := sql.Open("sqlite", filepath.Join(t.TempDir(), "test.db"))
+	if err != nil {
+		t.Fatalf("Failed to open test database: %v", err)
+	}
+	m := db.NewEmbeddedMigrator(testDB)
+	if err := m.Initialize(); err != nil {
+		t.Fatalf("Initialize() failed: %v", err)
+	}
+	if err := m.Up(); err != nil {
+		t.Fatalf("Up() failed: %v", err)
+	}
+	return testDB
+}
+
+func TestCollectionHandler(t *testing.T) {
+	testDB := setupMigratedTestDB(t)
+	defer testDB.Close()
+
+	repo := db.NewRepository(testDB)
+	handler := NewCollectionHandler(repo)
+
+	ids := make([]string, 2)
+	for i := range ids {
+		item := &models.ContentItem{Title: "Reading", MediaType: "web"}
+		if err := repo.CreateContentItem(item); err != nil {
+			t.Fatalf("Failed to create test item: %v", err)
+		}
+		ids[i] = string(item.ID)
+	}
+
+	// Create
+	req := httptest.NewRequest(http.MethodPost, "/collections", bytes.NewBufferString(`{"name": " Reading list "}`))
+	w := httptest.NewRecorder()
+	handler.CreateCollection(w, req)
+	if w.Code != http.StatusCreated {
+		t.Fatalf("Create: expected status 201, got %d. Body: %s", w.Code, w.Body.String())
+	}
+	var created models.Collection
+	if err := json.NewDecoder(w.Body).Decode(&created); err != nil {
+		t.Fatalf("Failed to decode response: %v", err)
+	}
+	if created.Name != "Reading list" {
+		t.Errorf("Name = %q, want trimmed name", created.Name)
+	}
+	id := string(created.ID)
+
+	req = httptest.NewRequest(http.MethodPost, "/collections", bytes.NewBufferString(`{"name": "Child", "parent_id": "`+ids[0]+`"}`))
+	w = httptest.NewRecorder()
+	handler.CreateCollection(w, req)
+	if w.Code != http.StatusBadRequest {
+		t.Errorf("Create under missing parent: expected status 400, got %d", w.Code)
+	}
+
+	// Add and reorder items
+	req = httptest.NewRequest(http.MethodPost, "/collections/"+id+"/items",
+		bytes.NewBufferString(`{"item_ids": ["`+ids[0]+`", "`+ids[1]+`"]}`))
+	req.SetPathValue("id", id)
+	w = httptest.NewRecorder()
+	handler.AddCollectionItems(w, req)
+	if w.Code != http.StatusOK {
+		t.Fatalf("Add items: expected status 200, got %d. Body: %s", w.Code, w.Body.String())
+	}
+
+	req = httptest.NewRequest(http.MethodPut, "/collections/"+id+"/items/order",
+		bytes.NewBufferString(`{"item_ids": ["`+ids[1]+`", "`+ids[0]+`"]}`))
+	req.SetPathValue("id", id)
+	w = httptest.NewRecorder()
+	handler.ReorderCollectionItems(w, req)
+	var reordered models.Collection
+	json.NewDecoder(w.Body).Decode(&reordered)
+	if w.Code != http.StatusOK || len(reordered.ItemIDs) != 2 || string(reordered.ItemIDs[0]) != ids[1] {
+		t.Errorf("Reorder: got %d %+v", w.Code, reordered)
+	}
+
+	req = httptest.NewRequest(http.MethodPut, "/collections/"+id+"/items/order",
+		bytes.NewBufferString(`{"item_ids": ["`+ids[1]+`"]}`))
+	req.SetPathValue("id", id)
+	w = httptest.NewRecorder()
+	handler.ReorderCollectionItems(w, req)
+	if w.Code != http.StatusBadRequest {
+		t.Errorf("Partial reorder: expected status 400, got %d", w.Code)
+	}
+
+	// List items in order
+	req = httptest.NewRequest(http.MethodGet, "/collections/"+id+"/items", nil)
+	req.SetPathValue("id", id)
+	w = httptest.NewRecorder()
+	handler.ListCollectionItems(w, req)
+	var list struct {
+		Items []models.ContentItem `json:"items"`
+	}
+	json.NewDecoder(w.Body).Decode(&list)
+	if w.Code != http.StatusOK || len(list.Items) != 2 || string(list.Items[0].ID) != ids[1] {
+		t.Errorf("List items: got %d %+v", w.Code, list)
+	}
+
+	// Remove an item, then delete the collection
+	req = httptest.NewRequest(http.MethodDelete, "/collections/"+id+"/items/"+ids[0], nil)
+	req.SetPathValue("id", id)
+	req.SetPathValue("item_id", ids[0])
+	w = httptest.NewRecorder()
+	handler.RemoveCollectionItem(w, req)
+	if w.Code != http.StatusNoContent {
+		t.Errorf("Remove item: expected status 204, got %d", w.Code)
+	}
+
+	req = httptest.NewRequest(http.MethodDelete, "/collections/"+id, nil)
+	req.SetPathValue("id", id)
+	w = httptest.NewRecorder()
+	handler.DeleteCollection(w, req)
+	if w.Code != http.StatusNoContent {
+		t.Errorf("Delete: expected status 204, got %d", w.Code)
+	}
+
+	req = httptest.NewRequest(http.MethodGet, "/collections/"+id, nil)
+	req.SetPathValue("id", id)
+	w = httptest.NewRecorder()
+	handler.GetCollection(w, req)
+	if w.Code != http.StatusNotFound {
+		t.Errorf("Get deleted: expected status 404, got %d", w.Code)
+	}
+}
@@ -4,6 +4,8 @@ package handlers
 import (
 	"database/sql"
 	"encoding/json"
+	"errors"
+	"log"
 	"net/http"
 	"strconv"
 
@@ -38,15 +40,51 @@ func (h *ContentHandler) ListContentItems(w http.ResponseWriter, r *http.Request
 		perPage = 20
 	}
 	mediaType := r.URL.Query().Get("media_type")
-
-	offset := (page - 1) * perPage
-
-	items, err := h.repo.ListContentItems(perPage, offset, mediaType)
-	if err != nil {
-		http.Error(w, err.Error(), http.StatusInternalServerError)
+	cursor := r.URL.Query().Get("cursor")
+
+	// Filters: media type and triage states. Archived items are hidden
+	// unless archived=true or archived=all; pinned items come first.
+	filters := db.NewFilterBuilder()
+	if mediaType != "" {
+		if _, err := validateMediaType(mediaType); err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		filters.MediaType(mediaType)
+	}
+	if err := addStateFilters(r.URL.Query(), filters, true); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
 		return
 	}
 
+	// Keyset pagination via cursor is preferred; page numbers still work
+	// for older clients but get slower as the page number grows
+	var items []*models.ContentItem
+	var nextCursor string
+	if cursor != "" || page == 1 {
+		result, err := h.repo.ListContentItemsPageFiltered(cursor, perPage, filters)
+		if errors.Is(err, db.ErrInvalidCursor) {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+			return
+		}
+		items, nextCursor = result.Items, result.NextCursor
+	} else {
+		offset := (page - 1) * perPage
+		var err error
+		items, err = h.repo.ListContentItemsFiltered(r.Context(), perPage, offset, filters)
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+			return
+		}
+		if len(items) == perPage {
+			nextCursor = db.ListCursor(items[len(items)-1])
+		}
+	}
+
 	// Get total count (simplified - in production, use separate COUNT query)
 	total := len(items)
 	totalPages := (total + perPage - 1) / perPage
@@ -61,6 +99,9 @@ func (h *ContentHandler) ListContentItems(w http.ResponseWriter, r *http.Request
 		"per_page":    perPage,
 		"total_pages": totalPages,
 	}
+	if nextCursor != "" {
+		response["next_cursor"] = nextCursor
+	}
 
 	w.Header().Set("Content-Type", "application/json")
 	json.NewEncoder(w).Encode(response)
@@ -122,7 +163,11 @@ func (h *ContentHandler) CreateContentItem(w http.ResponseWriter, r *http.Reques
 	// TODO: Trigger async parsing based on type (URL or file)
 	// For now, create with minimal data
 
-	if err := h.repo.CreateContentItem(item); err != nil {
+	if err := h.repo.CreateContentItemContext(r.Context(), item); err != nil {
+		if errors.Is(err, db.ErrInvalidTagName) {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
 		http.Error(w, err.Error(), http.StatusInternalServerError)
 		return
 	}
@@ -145,7 +190,7 @@ func (h *ContentHandler) GetContentItem(w http.ResponseWriter, r *http.Request)
 		id = r.URL.Path[len("/content/"):]
 	}
 
-	item, err := h.repo.GetContentItem(id)
+	item, err := h.repo.GetContentItemContext(r.Context(), id)
 	if err != nil {
 		if err == sql.ErrNoRows {
 			http.Error(w, "Content item not found", http.StatusNotFound)
@@ -155,6 +200,11 @@ func (h *ContentHandler) GetContentItem(w http.ResponseWriter, r *http.Request)
 		return
 	}
 
+	// Open counts feed engagement ranking; they are best effort
+	if err := h.repo.RecordContentOpen(id); err != nil {
+		log.Printf("Failed to record content open: %v", err)
+	}
+
 	w.Header().Set("Content-Type", "application/json")
 	json.NewEncoder(w).Encode(item)
 }
@@ -182,7 +232,7 @@ func (h *ContentHandler) UpdateContentItem(w http.ResponseWriter, r *http.Reques
 	}
 
 	// Get existing item
-	item, err := h.repo.GetContentItem(id)
+	item, err := h.repo.GetContentItemContext(r.Context(), id)
 	if err != nil {
 		if err == sql.ErrNoRows {
 			http.Error(w, "Content item not found", http.StatusNotFound)
@@ -207,7 +257,11 @@ func (h *ContentHandler) UpdateContentItem(w http.ResponseWriter, r *http.Reques
 		item.Tags = tagsStr
 	}
 
-	if err := h.repo.UpdateContentItem(item); err != nil {
+	if err := h.repo.UpdateContentItemContext(r.Context(), item); err != nil {
+		if errors.Is(err, db.ErrInvalidTagName) {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
 		http.Error(w, err.Error(), http.StatusInternalServerError)
 		return
 	}
@@ -228,7 +282,7 @@ func (h *ContentHandler) DeleteContentItem(w http.ResponseWriter, r *http.Reques
 		id = r.URL.Path[len("/content/"):]
 	}
 
-	if err := h.repo.DeleteContentItem(id); err != nil {
+	if err := h.repo.DeleteContentItemContext(r.Context(), id); err != nil {
 		if err == sql.ErrNoRows {
 			http.Error(w, "Content item not found", http.StatusNotFound)
 			return
@@ -0,0 +1,88 @@
+// Package handlers provides REST API handlers for content tag assignment.
+package handlers
+
+import (
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"net/http"
+
+	"github.com/kimhsiao/memonexus/backend/internal/db"
+	"github.com/kimhsiao/memonexus/backend/internal/models"
+)
+
+// ListContentTags handles GET /content/{id}/tags
+// Returns the tags assigned to an item, in assignment order.
+func (h *ContentHandler) ListContentTags(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	tags, err := h.repo.ListContentTags(r.PathValue("id"))
+	if err != nil {
+		if err == sql.ErrNoRows {
+			http.Error(w, "Content item not found", http.StatusNotFound)
+			return
+		}
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(tags)
+}
+
+// AssignContentTags handles POST /content/{id}/tags
+// Adds tags to an item, creating tags that do not exist yet.
+func (h *ContentHandler) AssignContentTags(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	var request struct {
+		Tags []string `json:"tags"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&request); err != nil {
+		http.Error(w, "Invalid request body", http.StatusBadRequest)
+		return
+	}
+	if len(request.Tags) == 0 {
+		http.Error(w, "tags is required", http.StatusBadRequest)
+		return
+	}
+
+	item, err := h.repo.AssignTags(r.PathValue("id"), request.Tags)
+	writeContentTagsResult(w, item, err)
+}
+
+// UnassignContentTag handles DELETE /content/{id}/tags/{name}
+// Removes a tag from an item; the tag itself is kept.
+func (h *ContentHandler) UnassignContentTag(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodDelete {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	item, err := h.repo.UnassignTags(r.PathValue("id"), []string{r.PathValue("name")})
+	writeContentTagsResult(w, item, err)
+}
+
+// writeContentTagsResult writes the item returned by a tag assignment.
+func writeContentTagsResult(w http.ResponseWriter, item *models.ContentItem, err error) {
+	if err != nil {
+		switch {
+		case err == sql.ErrNoRows:
+			http.Error(w, "Content item not found", http.StatusNotFound)
+		case errors.Is(err, db.ErrInvalidTagName):
+			http.Error(w, err.Error(), http.StatusBadRequest)
+		default:
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+		}
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(item)
+}
@@ -0,0 +1,102 @@
+// Package handlers tests for content tag assignment endpoints.
+package handlers
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/kimhsiao/memonexus/backend/internal/db"
+	"github.com/kimhsiao/memonexus/backend/internal/models"
+)
+
+func TestContentHandler_ContentTags(t *testing.T) {
+	testDB, cleanup := setupTestDBWithContent(t)
+	defer cleanup()
+
+	repo := db.NewRepository(testDB)
+	handler := NewContentHandler(repo)
+
+	item := &models.ContentItem{Title: "Tagged", MediaType: "web", Tags: "go"}
+	if err := repo.CreateContentItem(item); err != nil {
+		t.Fatalf("Failed to create test item: %v", err)
+	}
+	id := string(item.ID)
+
+	// Assign
+	req := httptest.NewRequest(http.MethodPost, "/content/"+id+"/tags", strings.NewReader(`{"tags":["research","GO"]}`))
+	req.SetPathValue("id", id)
+	w := httptest.NewRecorder()
+	handler.AssignContentTags(w, req)
+	if w.Code != http.StatusOK {
+		t.Fatalf("Assign: expected status 200, got %d. Body: %s", w.Code, w.Body.String())
+	}
+	var updated models.ContentItem
+	if err := json.NewDecoder(w.Body).Decode(&updated); err != nil {
+		t.Fatalf("Failed to decode response: %v", err)
+	}
+	if updated.Tags != "go,research" {
+		t.Errorf("Expected tags 'go,research', got %q", updated.Tags)
+	}
+
+	// List
+	req = httptest.NewRequest(http.MethodGet, "/content/"+id+"/tags", nil)
+	req.SetPathValue("id", id)
+	w = httptest.NewRecorder()
+	handler.ListContentTags(w, req)
+	var tags []models.Tag
+	if err := json.NewDecoder(w.Body).Decode(&tags); err != nil {
+		t.Fatalf("Failed to decode response: %v", err)
+	}
+	if w.Code != http.StatusOK || len(tags) != 2 || tags[1].Name != "research" {
+		t.Errorf("List: got %d %+v", w.Code, tags)
+	}
+
+	// Unassign
+	req = httptest.NewRequest(http.MethodDelete, "/content/"+id+"/tags/go", nil)
+	req.SetPathValue("id", id)
+	req.SetPathValue("name", "go")
+	w = httptest.NewRecorder()
+	handler.UnassignContentTag(w, req)
+	if w.Code != http.StatusOK {
+		t.Fatalf("Unassign: expected status 200, got %d. Body: %s", w.Code, w.Body.String())
+	}
+	if err := json.NewDecoder(w.Body).Decode(&updated); err != nil || updated.Tags != "research" {
+		t.Errorf("Expected tags 'research' after unassign, got %q (%v)", updated.Tags, err)
+	}
+
+	// Invalid name and unknown item
+	req = httptest.NewRequest(http.MethodPost, "/content/"+id+"/tags",
+		strings.NewReader(`{"tags":["`+strings.Repeat("x", 51)+`"]}`))
+	req.SetPathValue("id", id)
+	w = httptest.NewRecorder()
+	handler.AssignContentTags(w, req)
+	if w.Code != http.StatusBadRequest {
+		t.Errorf("Expected status 400 for long tag name, got %d", w.Code)
+	}
+
+	req = httptest.NewRequest(http.MethodGet, "/content/missing/tags", nil)
+	req.SetPathValue("id", "missing")
+	w = httptest.NewRecorder()
+	handler.ListContentTags(w, req)
+	if w.Code != http.StatusNotFound {
+		t.Errorf("Expected status 404 for unknown item, got %d", w.Code)
+	}
+}
+
+func TestContentHandler_AssignContentTags_EmptyTags(t *testing.T) {
+	testDB, cleanup := setupTestDBWithContent(t)
+	defer cleanup()
+
+	handler := NewContentHandler(db.NewRepository(testDB))
+
+	req := httptest.NewRequest(http.MethodPost, "/content/x/tags", strings.NewReader(`{"tags":[]}`))
+	req.SetPathValue("id", "x")
+	w := httptest.NewRecorder()
+	handler.AssignContentTags(w, req)
+	if w.Code != http.StatusBadRequest {
+		t.Errorf("Expected status 400, got %d", w.Code)
+	}
+}
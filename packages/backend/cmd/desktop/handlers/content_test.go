@@ -36,7 +36,16 @@ func setupTestDBWithContent(t *testing.T) (*sql.DB, func()) {
 			tags TEXT DEFAULT '',
 			summary TEXT,
 			version INTEGER NOT NULL DEFAULT 1,
-			content_hash TEXT
+			content_hash TEXT,
+			is_pinned INTEGER NOT NULL DEFAULT 0 CHECK(is_pinned IN (0, 1)),
+			is_favorite INTEGER NOT NULL DEFAULT 0 CHECK(is_favorite IN (0, 1)),
+			read_status TEXT NOT NULL DEFAULT 'unread' CHECK(read_status IN ('unread', 'in_progress', 'read')),
+			read_progress REAL NOT NULL DEFAULT 0 CHECK(read_progress >= 0 AND read_progress <= 1),
+			is_archived INTEGER NOT NULL DEFAULT 0 CHECK(is_archived IN (0, 1)),
+			favorite_changed_at INTEGER NOT NULL DEFAULT 0,
+			pinned_changed_at INTEGER NOT NULL DEFAULT 0,
+			read_changed_at INTEGER NOT NULL DEFAULT 0,
+			archived_changed_at INTEGER NOT NULL DEFAULT 0
 		);
 
 		CREATE TABLE IF NOT EXISTS tags (
@@ -45,16 +54,104 @@ func setupTestDBWithContent(t *testing.T) (*sql.DB, func()) {
 			color TEXT NOT NULL DEFAULT '#3B82F6',
 			is_deleted INTEGER DEFAULT 0,
 			created_at INTEGER NOT NULL,
-			updated_at INTEGER NOT NULL
+			updated_at INTEGER NOT NULL,
+			parent_id TEXT
 		);
 
 		CREATE TABLE IF NOT EXISTS content_tags (
 			content_id TEXT NOT NULL,
 			tag_id TEXT NOT NULL,
+			assigned_at INTEGER NOT NULL,
 			PRIMARY KEY (content_id, tag_id),
 			FOREIGN KEY (content_id) REFERENCES content_items(id) ON DELETE CASCADE,
 			FOREIGN KEY (tag_id) REFERENCES tags(id) ON DELETE CASCADE
 		);
+
+		CREATE TABLE IF NOT EXISTS content_revisions (
+			id TEXT PRIMARY KEY,
+			content_id TEXT NOT NULL,
+			version INTEGER NOT NULL,
+			title TEXT NOT NULL,
+			content_text TEXT NOT NULL,
+			source_url TEXT,
+			media_type TEXT NOT NULL,
+			tags TEXT NOT NULL DEFAULT '',
+			summary TEXT,
+			content_hash TEXT,
+			edited_at INTEGER NOT NULL,
+			created_at INTEGER NOT NULL
+		);
+
+		CREATE TABLE IF NOT EXISTS content_links (
+			source_id TEXT NOT NULL,
+			kind TEXT NOT NULL,
+			target TEXT NOT NULL,
+			target_id TEXT,
+			position INTEGER NOT NULL DEFAULT 0,
+			created_at INTEGER NOT NULL,
+			PRIMARY KEY (source_id, kind, target)
+		);
+
+		CREATE TABLE IF NOT EXISTS collection_items (
+			collection_id TEXT NOT NULL,
+			content_id TEXT NOT NULL,
+			position INTEGER NOT NULL DEFAULT 0,
+			added_at INTEGER NOT NULL,
+			PRIMARY KEY (collection_id, content_id)
+		);
+
+		CREATE TABLE IF NOT EXISTS annotations (
+			id TEXT PRIMARY KEY,
+			content_id TEXT NOT NULL,
+			start_offset INTEGER NOT NULL,
+			end_offset INTEGER NOT NULL,
+			quote TEXT NOT NULL,
+			prefix TEXT NOT NULL DEFAULT '',
+			suffix TEXT NOT NULL DEFAULT '',
+			note TEXT NOT NULL DEFAULT '',
+			color TEXT NOT NULL DEFAULT 'yellow',
+			is_deleted INTEGER NOT NULL DEFAULT 0,
+			created_at INTEGER NOT NULL,
+			updated_at INTEGER NOT NULL,
+			version INTEGER NOT NULL DEFAULT 1
+		);
+
+		CREATE TABLE IF NOT EXISTS content_properties (
+			content_id TEXT NOT NULL,
+			key TEXT NOT NULL,
+			type TEXT NOT NULL,
+			value TEXT NOT NULL,
+			value_num REAL,
+			source TEXT NOT NULL DEFAULT 'user',
+			is_deleted INTEGER NOT NULL DEFAULT 0,
+			updated_at INTEGER NOT NULL,
+			version INTEGER NOT NULL DEFAULT 1,
+			PRIMARY KEY (content_id, key)
+		);
+
+		CREATE TABLE IF NOT EXISTS content_engagement (
+			content_id TEXT PRIMARY KEY,
+			open_count INTEGER NOT NULL DEFAULT 0,
+			last_opened_at INTEGER,
+			FOREIGN KEY (content_id) REFERENCES content_items(id) ON DELETE CASCADE
+		);
+
+		CREATE TABLE IF NOT EXISTS change_log (
+			id TEXT PRIMARY KEY,
+			item_id TEXT NOT NULL,
+			operation TEXT NOT NULL,
+			version INTEGER NOT NULL,
+			timestamp INTEGER NOT NULL
+		);
+
+		CREATE TABLE IF NOT EXISTS conflict_log (
+			id TEXT PRIMARY KEY,
+			item_id TEXT NOT NULL,
+			local_timestamp INTEGER NOT NULL,
+			remote_timestamp INTEGER NOT NULL,
+			resolution TEXT NOT NULL DEFAULT 'last_write_wins',
+			detected_at INTEGER NOT NULL
+		);
 	`)
 	if err != nil {
 		t.Fatalf("Failed to create tables: %v", err)
@@ -153,6 +250,78 @@ func TestContentHandler_ListContentItems_WithPagination(t *testing.T) {
 	}
 }
 
+func TestContentHandler_ListContentItems_Cursor(t *testing.T) {
+	testDB, cleanup := setupTestDBWithContent(t)
+	defer cleanup()
+
+	repo := db.NewRepository(testDB)
+	handler := NewContentHandler(repo)
+
+	for i := 0; i < 5; i++ {
+		item := &models.ContentItem{Title: "Item", ContentText: "Body", MediaType: "web"}
+		if err := repo.CreateContentItem(item); err != nil {
+			t.Fatalf("Failed to create test item: %v", err)
+		}
+	}
+
+	seen := make(map[string]bool)
+	url := "/content?per_page=2"
+	for pages := 1; ; pages++ {
+		req := httptest.NewRequest(http.MethodGet, url, nil)
+		w := httptest.NewRecorder()
+		handler.ListContentItems(w, req)
+
+		if w.Code != http.StatusOK {
+			t.Fatalf("Expected status 200, got %d", w.Code)
+		}
+
+		var response struct {
+			Items []struct {
+				ID string `json:"id"`
+			} `json:"items"`
+			NextCursor string `json:"next_cursor"`
+		}
+		if err := json.NewDecoder(w.Body).Decode(&response); err != nil {
+			t.Fatalf("Failed to decode response: %v", err)
+		}
+		for _, item := range response.Items {
+			if seen[item.ID] {
+				t.Errorf("Item %s returned twice", item.ID)
+			}
+			seen[item.ID] = true
+		}
+
+		if response.NextCursor == "" {
+			if pages != 3 {
+				t.Errorf("Expected 3 pages, got %d", pages)
+			}
+			break
+		}
+		url = "/content?per_page=2&cursor=" + response.NextCursor
+	}
+
+	if len(seen) != 5 {
+		t.Errorf("Expected 5 distinct items, got %d", len(seen))
+	}
+}
+
+func TestContentHandler_ListContentItems_InvalidCursor(t *testing.T) {
+	testDB, cleanup := setupTestDBWithContent(t)
+	defer cleanup()
+
+	repo := db.NewRepository(testDB)
+	handler := NewContentHandler(repo)
+
+	req := httptest.NewRequest(http.MethodGet, "/content?cursor=bogus", nil)
+	w := httptest.NewRecorder()
+
+	handler.ListContentItems(w, req)
+
+	if w.Code != http.StatusBadRequest {
+		t.Errorf("Expected status 400, got %d", w.Code)
+	}
+}
+
 func TestContentHandler_ListContentItems_MethodNotAllowed(t *testing.T) {
 	testDB, cleanup := setupTestDBWithContent(t)
 	defer cleanup()
@@ -363,6 +532,13 @@ func TestContentHandler_GetContentItem(t *testing.T) {
 	if item.ID != testItem.ID {
 		t.Errorf("Expected ID '%s', got '%s'", testItem.ID, item.ID)
 	}
+
+	// Opening an item counts toward engagement ranking
+	var opens int
+	err := testDB.QueryRow(`SELECT open_count FROM content_engagement WHERE content_id = ?`, testItem.ID).Scan(&opens)
+	if err != nil || opens != 1 {
+		t.Errorf("Expected 1 recorded open, got %d (%v)", opens, err)
+	}
 }
 
 func TestContentHandler_GetContentItem_NotFound(t *testing.T) {
@@ -41,7 +41,8 @@ func setupTestDBWithExport(t *testing.T) (*sql.DB, func()) {
 			color TEXT NOT NULL DEFAULT '#3B82F6',
 			is_deleted INTEGER DEFAULT 0,
 			created_at INTEGER NOT NULL,
-			updated_at INTEGER NOT NULL
+			updated_at INTEGER NOT NULL,
+			parent_id TEXT
 		);
 
 		CREATE TABLE IF NOT EXISTS content_tags (
@@ -0,0 +1,81 @@
+// Package handlers provides REST API handlers for links between content items.
+package handlers
+
+import (
+	"database/sql"
+	"encoding/json"
+	"net/http"
+	"strconv"
+
+	"github.com/kimhsiao/memonexus/backend/internal/models"
+)
+
+// ListLinks handles GET /content/{id}/links
+// Returns the links written in the item, in order.
+func (h *ContentHandler) ListLinks(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	links, err := h.repo.ListOutgoingLinks(r.PathValue("id"))
+	writeLinks(w, links, err)
+}
+
+// ListBacklinks handles GET /content/{id}/backlinks
+// Returns the links from other items to the item.
+func (h *ContentHandler) ListBacklinks(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	links, err := h.repo.ListBacklinks(r.PathValue("id"))
+	writeLinks(w, links, err)
+}
+
+// ListUnresolvedLinks handles GET /links/unresolved
+// Returns links whose target does not exist, grouped by target.
+func (h *ContentHandler) ListUnresolvedLinks(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	page, _ := strconv.Atoi(r.URL.Query().Get("page"))
+	if page < 1 {
+		page = 1
+	}
+	perPage, _ := strconv.Atoi(r.URL.Query().Get("per_page"))
+	if perPage < 1 || perPage > 100 {
+		perPage = 20
+	}
+
+	links, err := h.repo.ListUnresolvedLinks(perPage, (page-1)*perPage)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(map[string]interface{}{
+		"links":    links,
+		"page":     page,
+		"per_page": perPage,
+	})
+}
+
+// writeLinks writes the response for an item's links.
+func writeLinks(w http.ResponseWriter, links []*models.ContentLink, err error) {
+	if err != nil {
+		if err == sql.ErrNoRows {
+			http.Error(w, "Content item not found", http.StatusNotFound)
+			return
+		}
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(links)
+}
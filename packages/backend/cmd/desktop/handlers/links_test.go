@@ -0,0 +1,71 @@
+// Package handlers tests for content link endpoints.
+package handlers
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/kimhsiao/memonexus/backend/internal/db"
+	"github.com/kimhsiao/memonexus/backend/internal/models"
+)
+
+func TestContentHandler_Links(t *testing.T) {
+	testDB, cleanup := setupTestDBWithContent(t)
+	defer cleanup()
+
+	repo := db.NewRepository(testDB)
+	handler := NewContentHandler(repo)
+
+	target := &models.ContentItem{Title: "Go Notes", MediaType: "markdown"}
+	if err := repo.CreateContentItem(target); err != nil {
+		t.Fatalf("Failed to create test item: %v", err)
+	}
+	source := &models.ContentItem{Title: "Index", ContentText: "[[Go Notes]] and [[Rust]]", MediaType: "markdown"}
+	if err := repo.CreateContentItem(source); err != nil {
+		t.Fatalf("Failed to create test item: %v", err)
+	}
+
+	// Outgoing
+	req := httptest.NewRequest(http.MethodGet, "/content/"+string(source.ID)+"/links", nil)
+	req.SetPathValue("id", string(source.ID))
+	w := httptest.NewRecorder()
+	handler.ListLinks(w, req)
+	var links []models.ContentLink
+	json.NewDecoder(w.Body).Decode(&links)
+	if w.Code != http.StatusOK || len(links) != 2 || !links[0].Resolved || links[1].Resolved {
+		t.Errorf("Links: got %d %+v", w.Code, links)
+	}
+
+	// Backlinks
+	req = httptest.NewRequest(http.MethodGet, "/content/"+string(target.ID)+"/backlinks", nil)
+	req.SetPathValue("id", string(target.ID))
+	w = httptest.NewRecorder()
+	handler.ListBacklinks(w, req)
+	links = nil
+	json.NewDecoder(w.Body).Decode(&links)
+	if w.Code != http.StatusOK || len(links) != 1 || links[0].SourceID != source.ID {
+		t.Errorf("Backlinks: got %d %+v", w.Code, links)
+	}
+
+	req = httptest.NewRequest(http.MethodGet, "/content/missing/backlinks", nil)
+	req.SetPathValue("id", "missing")
+	w = httptest.NewRecorder()
+	handler.ListBacklinks(w, req)
+	if w.Code != http.StatusNotFound {
+		t.Errorf("Backlinks of missing item: expected status 404, got %d", w.Code)
+	}
+
+	// Unresolved
+	req = httptest.NewRequest(http.MethodGet, "/links/unresolved", nil)
+	w = httptest.NewRecorder()
+	handler.ListUnresolvedLinks(w, req)
+	var unresolved struct {
+		Links []models.ContentLink `json:"links"`
+	}
+	json.NewDecoder(w.Body).Decode(&unresolved)
+	if w.Code != http.StatusOK || len(unresolved.Links) != 1 || unresolved.Links[0].Target != "Rust" {
+		t.Errorf("Unresolved: got %d %+v", w.Code, unresolved)
+	}
+}
@@ -0,0 +1,99 @@
+// Package handlers provides REST API handlers for content item properties.
+package handlers
+
+import (
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"net/http"
+
+	"github.com/kimhsiao/memonexus/backend/internal/db"
+	"github.com/kimhsiao/memonexus/backend/internal/models"
+)
+
+// ListProperties handles GET /content/{id}/properties
+// Returns the item's typed properties, ordered by key.
+func (h *ContentHandler) ListProperties(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	properties, err := h.repo.ListContentProperties(r.PathValue("id"))
+	if err != nil {
+		if err == sql.ErrNoRows {
+			http.Error(w, "Content item not found", http.StatusNotFound)
+			return
+		}
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(properties)
+}
+
+// SetProperty handles PUT /content/{id}/properties/{key}
+// Body: {"type": "date", "value": "2024-03-01"}. The type defaults to
+// string. A value set here is never overwritten by parsed metadata.
+func (h *ContentHandler) SetProperty(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPut {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	var request struct {
+		Type  string `json:"type"`
+		Value string `json:"value"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&request); err != nil {
+		http.Error(w, "Invalid request body", http.StatusBadRequest)
+		return
+	}
+
+	p := &models.ContentProperty{
+		ContentID: models.UUID(r.PathValue("id")),
+		Key:       r.PathValue("key"),
+		Type:      request.Type,
+		Value:     request.Value,
+		Source:    models.PropertySourceUser,
+	}
+	if err := h.repo.SetContentProperty(p); err != nil {
+		if err == sql.ErrNoRows {
+			http.Error(w, "Content item not found", http.StatusNotFound)
+			return
+		}
+		writePropertyError(w, err)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(p)
+}
+
+// DeleteProperty handles DELETE /content/{id}/properties/{key}
+func (h *ContentHandler) DeleteProperty(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodDelete {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	if err := h.repo.DeleteContentProperty(r.PathValue("id"), r.PathValue("key")); err != nil {
+		writePropertyError(w, err)
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// writePropertyError writes the response for a failed property operation.
+func writePropertyError(w http.ResponseWriter, err error) {
+	switch {
+	case err == sql.ErrNoRows:
+		http.Error(w, "Property not found", http.StatusNotFound)
+	case errors.Is(err, db.ErrInvalidProperty):
+		http.Error(w, err.Error(), http.StatusBadRequest)
+	default:
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+	}
+}
@@ -0,0 +1,83 @@
+// Package handlers tests for content property endpoints.
+package handlers
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/kimhsiao/memonexus/backend/internal/db"
+	"github.com/kimhsiao/memonexus/backend/internal/models"
+)
+
+func TestContentHandler_Properties(t *testing.T) {
+	testDB := setupMigratedTestDB(t)
+	defer testDB.Close()
+
+	repo := db.NewRepository(testDB)
+	handler := NewContentHandler(repo)
+
+	item := &models.ContentItem{Title: "Article", MediaType: "web"}
+	if err := repo.CreateContentItem(item); err != nil {
+		t.Fatalf("Failed to create test item: %v", err)
+	}
+	id := string(item.ID)
+
+	set := func(id, key, body string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(http.MethodPut, "/content/"+id+"/properties/"+key, strings.NewReader(body))
+		req.SetPathValue("id", id)
+		req.SetPathValue("key", key)
+		w := httptest.NewRecorder()
+		handler.SetProperty(w, req)
+		return w
+	}
+
+	w := set(id, "published", `{"type": "date", "value": "2024-03-01"}`)
+	var p models.ContentProperty
+	json.NewDecoder(w.Body).Decode(&p)
+	if w.Code != http.StatusOK || p.Value != "2024-03-01T00:00:00Z" || p.Source != models.PropertySourceUser {
+		t.Errorf("Set: got %d %+v", w.Code, p)
+	}
+	if w := set(id, "author", `{"value": "Jane Doe"}`); w.Code != http.StatusOK {
+		t.Errorf("Set author: expected status 200, got %d", w.Code)
+	}
+
+	// Invalid input
+	if w := set(id, "published", `{"type": "date", "value": "soon"}`); w.Code != http.StatusBadRequest {
+		t.Errorf("Invalid date: expected status 400, got %d", w.Code)
+	}
+	if w := set(id, "9lives", `{"value": "x"}`); w.Code != http.StatusBadRequest {
+		t.Errorf("Invalid key: expected status 400, got %d", w.Code)
+	}
+	if w := set("00000000-0000-0000-0000-000000000000", "author", `{"value": "x"}`); w.Code != http.StatusNotFound {
+		t.Errorf("Missing item: expected status 404, got %d", w.Code)
+	}
+
+	// List
+	req := httptest.NewRequest(http.MethodGet, "/content/"+id+"/properties", nil)
+	req.SetPathValue("id", id)
+	w = httptest.NewRecorder()
+	handler.ListProperties(w, req)
+	var properties []models.ContentProperty
+	json.NewDecoder(w.Body).Decode(&properties)
+	if w.Code != http.StatusOK || len(properties) != 2 || properties[0].Key != "author" {
+		t.Errorf("List: got %d %+v", w.Code, properties)
+	}
+
+	// Delete
+	req = httptest.NewRequest(http.MethodDelete, "/content/"+id+"/properties/author", nil)
+	req.SetPathValue("id", id)
+	req.SetPathValue("key", "author")
+	w = httptest.NewRecorder()
+	handler.DeleteProperty(w, req)
+	if w.Code != http.StatusNoContent {
+		t.Errorf("Delete: expected status 204, got %d", w.Code)
+	}
+	w = httptest.NewRecorder()
+	handler.DeleteProperty(w, req)
+	if w.Code != http.StatusNotFound {
+		t.Errorf("Delete again: expected status 404, got %d", w.Code)
+	}
+}
@@ -0,0 +1,100 @@
+// Package handlers tests for content revision endpoints.
+package handlers
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/kimhsiao/memonexus/backend/internal/db"
+	"github.com/kimhsiao/memonexus/backend/internal/models"
+)
+
+func TestContentHandler_Revisions(t *testing.T) {
+	testDB, cleanup := setupTestDBWithContent(t)
+	defer cleanup()
+
+	repo := db.NewRepository(testDB)
+	handler := NewContentHandler(repo)
+
+	item := &models.ContentItem{Title: "Draft", ContentText: "first", MediaType: "markdown"}
+	if err := repo.CreateContentItem(item); err != nil {
+		t.Fatalf("Failed to create test item: %v", err)
+	}
+	item.ContentText = "second"
+	if err := repo.UpdateContentItem(item); err != nil {
+		t.Fatalf("Failed to update test item: %v", err)
+	}
+	id := string(item.ID)
+
+	// List
+	req := httptest.NewRequest(http.MethodGet, "/content/"+id+"/revisions", nil)
+	req.SetPathValue("id", id)
+	w := httptest.NewRecorder()
+	handler.ListRevisions(w, req)
+	var revisions []models.ContentRevision
+	if err := json.NewDecoder(w.Body).Decode(&revisions); err != nil {
+		t.Fatalf("Failed to decode response: %v", err)
+	}
+	if w.Code != http.StatusOK || len(revisions) != 1 || revisions[0].ContentText != "first" {
+		t.Fatalf("List: got %d %+v", w.Code, revisions)
+	}
+	rev := string(revisions[0].ID)
+
+	// Diff against the current state
+	req = httptest.NewRequest(http.MethodGet, "/content/"+id+"/revisions/diff?from="+rev, nil)
+	req.SetPathValue("id", id)
+	w = httptest.NewRecorder()
+	handler.DiffRevisions(w, req)
+	var diff models.RevisionDiff
+	if err := json.NewDecoder(w.Body).Decode(&diff); err != nil {
+		t.Fatalf("Failed to decode response: %v", err)
+	}
+	if w.Code != http.StatusOK || len(diff.Lines) != 2 || diff.ToVersion != 2 {
+		t.Errorf("Diff: got %d %+v", w.Code, diff)
+	}
+
+	req = httptest.NewRequest(http.MethodGet, "/content/"+id+"/revisions/diff", nil)
+	req.SetPathValue("id", id)
+	w = httptest.NewRecorder()
+	handler.DiffRevisions(w, req)
+	if w.Code != http.StatusBadRequest {
+		t.Errorf("Expected status 400 without from, got %d", w.Code)
+	}
+
+	// Restore
+	req = httptest.NewRequest(http.MethodPost, "/content/"+id+"/revisions/"+rev+"/restore", nil)
+	req.SetPathValue("id", id)
+	req.SetPathValue("rev", rev)
+	w = httptest.NewRecorder()
+	handler.RestoreRevision(w, req)
+	if w.Code != http.StatusOK {
+		t.Fatalf("Restore: expected status 200, got %d. Body: %s", w.Code, w.Body.String())
+	}
+	var restored models.ContentItem
+	if err := json.NewDecoder(w.Body).Decode(&restored); err != nil {
+		t.Fatalf("Failed to decode response: %v", err)
+	}
+	if restored.ContentText != "first" || restored.Version != 3 {
+		t.Errorf("Expected restored text 'first' at version 3, got %+v", restored)
+	}
+
+	// Unknown item and revision
+	req = httptest.NewRequest(http.MethodGet, "/content/missing/revisions", nil)
+	req.SetPathValue("id", "missing")
+	w = httptest.NewRecorder()
+	handler.ListRevisions(w, req)
+	if w.Code != http.StatusNotFound {
+		t.Errorf("Expected status 404 for unknown item, got %d", w.Code)
+	}
+
+	req = httptest.NewRequest(http.MethodPost, "/content/"+id+"/revisions/missing/restore", nil)
+	req.SetPathValue("id", id)
+	req.SetPathValue("rev", "missing")
+	w = httptest.NewRecorder()
+	handler.RestoreRevision(w, req)
+	if w.Code != http.StatusNotFound {
+		t.Errorf("Expected status 404 for unknown revision, got %d", w.Code)
+	}
+}
@@ -0,0 +1,310 @@
+// Package handlers provides REST API handlers for saved searches.
+package handlers
+
+import (
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/kimhsiao/memonexus/backend/internal/db"
+	"github.com/kimhsiao/memonexus/backend/internal/models"
+)
+
+// SavedSearchHandler handles saved search (smart collection) operations.
+type SavedSearchHandler struct {
+	repo *db.Repository
+}
+
+// NewSavedSearchHandler creates a new SavedSearchHandler.
+func NewSavedSearchHandler(repo *db.Repository) *SavedSearchHandler {
+	return &SavedSearchHandler{repo: repo}
+}
+
+// savedSearchResponse is a saved search with its live result count.
+type savedSearchResponse struct {
+	*models.SavedSearch
+	Count int `json:"count"`
+}
+
+// withCount attaches the current result count to a saved search.
+func (h *SavedSearchHandler) withCount(ss *models.SavedSearch) (*savedSearchResponse, error) {
+	count, err := h.repo.CountSavedSearch(ss)
+	if err != nil {
+		return nil, err
+	}
+	return &savedSearchResponse{SavedSearch: ss, Count: count}, nil
+}
+
+// ListSavedSearches handles GET /saved-searches
+// With pinned=true only sidebar smart collections are returned.
+func (h *SavedSearchHandler) ListSavedSearches(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	var pinnedOnly bool
+	if p := r.URL.Query().Get("pinned"); p != "" {
+		b, err := strconv.ParseBool(p)
+		if err != nil {
+			http.Error(w, "pinned must be true or false", http.StatusBadRequest)
+			return
+		}
+		pinnedOnly = b
+	}
+
+	searches, err := h.repo.ListSavedSearches(pinnedOnly)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	response := make([]*savedSearchResponse, 0, len(searches))
+	for _, ss := range searches {
+		item, err := h.withCount(ss)
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+			return
+		}
+		response = append(response, item)
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(response)
+}
+
+// CreateSavedSearch handles POST /saved-searches
+func (h *SavedSearchHandler) CreateSavedSearch(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	var request struct {
+		Name     string               `json:"name"`
+		Query    string               `json:"query"`
+		Filters  models.SearchFilters `json:"filters"`
+		IsPinned bool                 `json:"is_pinned"`
+		Position int                  `json:"position"`
+	}
+
+	if err := json.NewDecoder(r.Body).Decode(&request); err != nil {
+		http.Error(w, "Invalid request body", http.StatusBadRequest)
+		return
+	}
+
+	ss := &models.SavedSearch{
+		Name:     request.Name,
+		Query:    request.Query,
+		Filters:  request.Filters,
+		IsPinned: request.IsPinned,
+		Position: request.Position,
+	}
+	if err := db.ValidateSavedSearch(ss); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	if err := h.repo.CreateSavedSearch(ss); err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	response, err := h.withCount(ss)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusCreated)
+	json.NewEncoder(w).Encode(response)
+}
+
+// GetSavedSearch handles GET /saved-searches/{id}
+func (h *SavedSearchHandler) GetSavedSearch(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	ss, ok := h.lookup(w, r)
+	if !ok {
+		return
+	}
+
+	response, err := h.withCount(ss)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(response)
+}
+
+// UpdateSavedSearch handles PUT /saved-searches/{id}
+// Only the fields present in the request body are changed.
+func (h *SavedSearchHandler) UpdateSavedSearch(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPut {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	var request struct {
+		Name     *string               `json:"name"`
+		Query    *string               `json:"query"`
+		Filters  *models.SearchFilters `json:"filters"`
+		IsPinned *bool                 `json:"is_pinned"`
+		Position *int                  `json:"position"`
+	}
+
+	if err := json.NewDecoder(r.Body).Decode(&request); err != nil {
+		http.Error(w, "Invalid request body", http.StatusBadRequest)
+		return
+	}
+
+	ss, ok := h.lookup(w, r)
+	if !ok {
+		return
+	}
+
+	// Update fields
+	if request.Name != nil {
+		ss.Name = *request.Name
+	}
+	if request.Query != nil {
+		ss.Query = *request.Query
+	}
+	if request.Filters != nil {
+		ss.Filters = *request.Filters
+	}
+	if request.IsPinned != nil {
+		ss.IsPinned = *request.IsPinned
+	}
+	if request.Position != nil {
+		ss.Position = *request.Position
+	}
+	if err := db.ValidateSavedSearch(ss); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	if err := h.repo.UpdateSavedSearch(ss); err != nil {
+		if err == sql.ErrNoRows {
+			http.Error(w, "Saved search not found", http.StatusNotFound)
+			return
+		}
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	response, err := h.withCount(ss)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(response)
+}
+
+// DeleteSavedSearch handles DELETE /saved-searches/{id}
+func (h *SavedSearchHandler) DeleteSavedSearch(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodDelete {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	if err := h.repo.DeleteSavedSearch(r.PathValue("id")); err != nil {
+		if err == sql.ErrNoRows {
+			http.Error(w, "Saved search not found", http.StatusNotFound)
+			return
+		}
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// RunSavedSearch handles GET /saved-searches/{id}/results
+// Accepts the limit, cursor and highlight parameters of GET /search and
+// returns results in the same format.
+func (h *SavedSearchHandler) RunSavedSearch(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	ss, ok := h.lookup(w, r)
+	if !ok {
+		return
+	}
+
+	limit := 20
+	if limitStr := r.URL.Query().Get("limit"); limitStr != "" {
+		l, err := strconv.Atoi(limitStr)
+		if err != nil || l < 1 || l > 100 {
+			http.Error(w, "limit must be between 1 and 100", http.StatusBadRequest)
+			return
+		}
+		limit = l
+	}
+	highlight, err := parseHighlightOptions(r)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	opts, err := db.SavedSearchOptions(ss, time.Now())
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	opts.Limit = limit
+	opts.Cursor = r.URL.Query().Get("cursor")
+	opts.Highlight = highlight
+
+	response, err := h.repo.Search(opts)
+	var queryErr *db.QueryError
+	if errors.Is(err, db.ErrInvalidCursor) || errors.As(err, &queryErr) {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	if err != nil {
+		http.Error(w, fmt.Sprintf("search failed: %v", err), http.StatusInternalServerError)
+		return
+	}
+
+	result := map[string]interface{}{
+		"results": toSearchResults(response.Results),
+		"total":   response.Total,
+		"query":   ss.Query,
+	}
+	if response.NextCursor != "" {
+		result["next_cursor"] = response.NextCursor
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(result)
+}
+
+// lookup loads the saved search named by the {id} path value, writing a
+// 404 or 500 response and returning false if it cannot.
+func (h *SavedSearchHandler) lookup(w http.ResponseWriter, r *http.Request) (*models.SavedSearch, bool) {
+	ss, err := h.repo.GetSavedSearch(r.PathValue("id"))
+	if err != nil {
+		if err == sql.ErrNoRows {
+			http.Error(w, "Saved search not found", http.StatusNotFound)
+			return nil, false
+		}
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return nil, false
+	}
+	return ss, true
+}
@@ -0,0 +1,160 @@
+// Package handlers tests for saved search REST API endpoints.
+package handlers
+
+import (
+	"bytes"
+	"database/sql"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/kimhsiao/memonexus/backend/internal/db"
+)
+
+// setupTestDBWithSavedSearches extends the search test database with the
+// saved_searches table.
+func setupTestDBWithSavedSearches(t *testing.T) (*sql.DB, func()) {
+	testDB, cleanup := setupTestDBWithSearch(t)
+	_, err := testDB.Exec(`
+		CREATE TABLE saved_searches (
+			id TEXT PRIMARY KEY NOT NULL CHECK(length(id) = 36),
+			name TEXT NOT NULL CHECK(length(name) > 0 AND length(name) <= 100),
+			query TEXT NOT NULL DEFAULT '',
+			filters TEXT NOT NULL DEFAULT '{}',
+			is_pinned INTEGER NOT NULL DEFAULT 0,
+			position INTEGER NOT NULL DEFAULT 0,
+			is_deleted INTEGER NOT NULL DEFAULT 0,
+			created_at INTEGER NOT NULL,
+			updated_at INTEGER NOT NULL,
+			version INTEGER NOT NULL DEFAULT 1
+		)
+	`)
+	if err != nil {
+		cleanup()
+		t.Fatalf("Failed to create saved_searches table: %v", err)
+	}
+	return testDB, cleanup
+}
+
+// createTestSavedSearch creates a saved search through the handler and
+// returns the decoded response.
+func createTestSavedSearch(t *testing.T, handler *SavedSearchHandler, body string) map[string]interface{} {
+	t.Helper()
+	req := httptest.NewRequest(http.MethodPost, "/saved-searches", bytes.NewReader([]byte(body)))
+	w := httptest.NewRecorder()
+
+	handler.CreateSavedSearch(w, req)
+
+	if w.Code != http.StatusCreated {
+		t.Fatalf("Expected status 201, got %d. Body: %s", w.Code, w.Body.String())
+	}
+	var response map[string]interface{}
+	if err := json.NewDecoder(w.Body).Decode(&response); err != nil {
+		t.Fatalf("Failed to decode response: %v", err)
+	}
+	return response
+}
+
+func TestSavedSearchHandler_CreateAndList(t *testing.T) {
+	testDB, cleanup := setupTestDBWithSavedSearches(t)
+	defer cleanup()
+
+	handler := NewSavedSearchHandler(db.NewRepository(testDB))
+	insertTestContentItem(t, testDB, "Go Programming Guide", "Learn Go", "web", "golang", 1000)
+	insertTestContentItem(t, testDB, "Go Generics", "Type parameters", "pdf", "golang", 2000)
+
+	created := createTestSavedSearch(t, handler, `{"name":"Go PDFs","query":"tag:golang","filters":{"media_type":"pdf"},"is_pinned":true}`)
+	if created["count"].(float64) != 1 {
+		t.Errorf("Expected count 1, got %v", created["count"])
+	}
+	createTestSavedSearch(t, handler, `{"name":"All Go","query":"go"}`)
+
+	req := httptest.NewRequest(http.MethodGet, "/saved-searches?pinned=true", nil)
+	w := httptest.NewRecorder()
+	handler.ListSavedSearches(w, req)
+
+	if w.Code != http.StatusOK {
+		t.Fatalf("Expected status 200, got %d. Body: %s", w.Code, w.Body.String())
+	}
+	var list []map[string]interface{}
+	if err := json.NewDecoder(w.Body).Decode(&list); err != nil {
+		t.Fatalf("Failed to decode response: %v", err)
+	}
+	if len(list) != 1 || list[0]["name"] != "Go PDFs" {
+		t.Errorf("Expected only the pinned saved search, got %v", list)
+	}
+}
+
+func TestSavedSearchHandler_Create_Invalid(t *testing.T) {
+	testDB, cleanup := setupTestDBWithSavedSearches(t)
+	defer cleanup()
+
+	handler := NewSavedSearchHandler(db.NewRepository(testDB))
+
+	for _, body := range []string{`{"query":"go"}`, `{"name":"Empty"}`, `not json`} {
+		req := httptest.NewRequest(http.MethodPost, "/saved-searches", bytes.NewReader([]byte(body)))
+		w := httptest.NewRecorder()
+		handler.CreateSavedSearch(w, req)
+		if w.Code != http.StatusBadRequest {
+			t.Errorf("Body %s: expected status 400, got %d", body, w.Code)
+		}
+	}
+}
+
+func TestSavedSearchHandler_UpdateRunDelete(t *testing.T) {
+	testDB, cleanup := setupTestDBWithSavedSearches(t)
+	defer cleanup()
+
+	handler := NewSavedSearchHandler(db.NewRepository(testDB))
+	insertTestContentItem(t, testDB, "Go Programming Guide", "Learn Go", "web", "golang", 1000)
+	insertTestContentItem(t, testDB, "Python Tutorial", "Python basics", "web", "python", 2000)
+
+	created := createTestSavedSearch(t, handler, `{"name":"Go","query":"golang"}`)
+	id := created["id"].(string)
+
+	// Update only the query
+	req := httptest.NewRequest(http.MethodPut, "/saved-searches/"+id, bytes.NewReader([]byte(`{"query":"python"}`)))
+	req.SetPathValue("id", id)
+	w := httptest.NewRecorder()
+	handler.UpdateSavedSearch(w, req)
+	if w.Code != http.StatusOK {
+		t.Fatalf("Expected status 200, got %d. Body: %s", w.Code, w.Body.String())
+	}
+	var updated map[string]interface{}
+	json.NewDecoder(w.Body).Decode(&updated)
+	if updated["name"] != "Go" || updated["query"] != "python" || updated["version"].(float64) != 2 {
+		t.Errorf("Unexpected update response: %v", updated)
+	}
+
+	// Run it
+	req = httptest.NewRequest(http.MethodGet, "/saved-searches/"+id+"/results?limit=1", nil)
+	req.SetPathValue("id", id)
+	w = httptest.NewRecorder()
+	handler.RunSavedSearch(w, req)
+	if w.Code != http.StatusOK {
+		t.Fatalf("Expected status 200, got %d. Body: %s", w.Code, w.Body.String())
+	}
+	var results map[string]interface{}
+	json.NewDecoder(w.Body).Decode(&results)
+	if results["total"].(float64) != 1 {
+		t.Errorf("Expected 1 result, got %v", results["total"])
+	}
+
+	// Delete it
+	req = httptest.NewRequest(http.MethodDelete, "/saved-searches/"+id, nil)
+	req.SetPathValue("id", id)
+	w = httptest.NewRecorder()
+	handler.DeleteSavedSearch(w, req)
+	if w.Code != http.StatusNoContent {
+		t.Errorf("Expected status 204, got %d", w.Code)
+	}
+
+	req = httptest.NewRequest(http.MethodGet, "/saved-searches/"+id, nil)
+	req.SetPathValue("id", id)
+	w = httptest.NewRecorder()
+	handler.GetSavedSearch(w, req)
+	if w.Code != http.StatusNotFound {
+		t.Errorf("Expected status 404 after delete, got %d", w.Code)
+	}
+}
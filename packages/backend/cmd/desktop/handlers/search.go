@@ -3,6 +3,7 @@ package handlers
 
 import (
 	"encoding/json"
+	"errors"
 	"fmt"
 	"net/http"
 	"regexp"
@@ -40,6 +41,13 @@ func (h *SearchHandler) Search(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	// Compile the structured query (field operators, phrases, boolean logic)
+	parsed, err := db.ParseQuery(query)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
 	// Parse and validate limit parameter
 	limitStr := r.URL.Query().Get("limit")
 	var limit int
@@ -73,14 +81,73 @@ func (h *SearchHandler) Search(w http.ResponseWriter, r *http.Request) {
 		dateTo = d
 	}
 
+	// Facet counts are opt-in (facets=true)
+	var withFacets bool
+	if f := r.URL.Query().Get("facets"); f != "" {
+		b, err := strconv.ParseBool(f)
+		if err != nil {
+			http.Error(w, "facets must be true or false", http.StatusBadRequest)
+			return
+		}
+		withFacets = b
+	}
+
+	// Optional column restriction (e.g. columns=title,summary)
+	var columns []string
+	if c := r.URL.Query().Get("columns"); c != "" {
+		for _, column := range strings.Split(c, ",") {
+			columns = append(columns, strings.TrimSpace(column))
+		}
+		if err := validateSearchColumns(columns); err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+	}
+
+	// Snippets and match offsets are opt-in (highlight=true)
+	highlight, err := parseHighlightOptions(r)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	// Ranking profile (relevance, recent or balanced)
+	ranking := r.URL.Query().Get("ranking")
+	if _, err := db.LookupRankingProfile(ranking); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
 	// Build search options
 	opts := &db.SearchOptions{
-		Query:     query,
 		Limit:     limit,
 		MediaType: r.URL.Query().Get("media_type"),
 		Tags:      r.URL.Query().Get("tags"),
 		DateFrom:  dateFrom,
 		DateTo:    dateTo,
+		Facets:    withFacets,
+		Columns:   columns,
+		Cursor:    r.URL.Query().Get("cursor"),
+		Highlight: highlight,
+		Ranking:   ranking,
+	}
+	parsed.Apply(opts)
+
+	// Restrict to a collection, including its nested collections
+	if collection := r.URL.Query().Get("collection"); collection != "" {
+		if opts.Filters == nil {
+			opts.Filters = db.NewFilterBuilder()
+		}
+		opts.Filters.Collection(collection)
+	}
+
+	// Triage state filters; archived items are searchable by default
+	if opts.Filters == nil {
+		opts.Filters = db.NewFilterBuilder()
+	}
+	if err := addStateFilters(r.URL.Query(), opts.Filters, false); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
 	}
 
 	// Validate media type if provided
@@ -93,6 +160,11 @@ func (h *SearchHandler) Search(w http.ResponseWriter, r *http.Request) {
 
 	// Execute search using Repository.Search (T115)
 	response, err := h.repo.Search(opts)
+	var queryErr *db.QueryError
+	if errors.Is(err, db.ErrInvalidCursor) || errors.As(err, &queryErr) {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
 	if err != nil {
 		http.Error(w, fmt.Sprintf("search failed: %v", err), http.StatusInternalServerError)
 		return
@@ -102,7 +174,63 @@ func (h *SearchHandler) Search(w http.ResponseWriter, r *http.Request) {
 	result := map[string]interface{}{
 		"results": toSearchResults(response.Results),
 		"total":   response.Total,
-		"query":   response.Query,
+		"query":   query,
+	}
+
+	// Record first-page searches in the history; the client reports the
+	// result the user opens against history_id
+	if opts.Cursor == "" {
+		if entry := h.recordSearch(query, opts, response.Total); entry != nil {
+			result["history_id"] = entry.ID
+		}
+	}
+	if len(response.Suggestions) > 0 {
+		result["suggestions"] = toSuggestions(response.Suggestions)
+	}
+	if response.Facets != nil {
+		result["facets"] = toFacets(response.Facets)
+	}
+	if response.NextCursor != "" {
+		result["next_cursor"] = response.NextCursor
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(result)
+}
+
+// Suggest handles GET /search/suggest
+// Returns "did you mean" corrections for words missing from the search index.
+func (h *SearchHandler) Suggest(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	query := r.URL.Query().Get("q")
+	if err := validateSearchQuery(query); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	limit := 5
+	if limitStr := r.URL.Query().Get("limit"); limitStr != "" {
+		l, err := strconv.Atoi(limitStr)
+		if err != nil || l < 1 || l > 10 {
+			http.Error(w, "limit must be between 1 and 10", http.StatusBadRequest)
+			return
+		}
+		limit = l
+	}
+
+	suggestions, err := h.repo.Suggest(query, limit)
+	if err != nil {
+		http.Error(w, fmt.Sprintf("suggest failed: %v", err), http.StatusInternalServerError)
+		return
+	}
+
+	result := map[string]interface{}{
+		"query":       query,
+		"suggestions": toSuggestions(suggestions),
 	}
 
 	w.Header().Set("Content-Type", "application/json")
@@ -122,36 +250,6 @@ func validateSearchQuery(query string) error {
 		return fmt.Errorf("search query too long (max 500 characters)")
 	}
 
-	// Check for potentially dangerous FTS5 syntax
-	// Allow basic FTS5 operators but limit complex queries
-	dangerousPatterns := []string{
-		"NEAR/", // NEAR with large distance could be slow
-		"^",    // Prefix searches on very short terms
-	}
-
-	for _, pattern := range dangerousPatterns {
-		if strings.Contains(strings.ToUpper(query), pattern) {
-			return fmt.Errorf("search query contains unsupported operator: %s", pattern)
-		}
-	}
-
-	// Check for very short search terms that could cause performance issues
-	terms := strings.Fields(query)
-	for _, term := range terms {
-		// Skip operators
-		upperTerm := strings.ToUpper(term)
-		if upperTerm == "AND" || upperTerm == "OR" || upperTerm == "NOT" {
-			continue
-		}
-		// Remove trailing wildcard
-		term = strings.TrimSuffix(term, "*")
-		term = strings.Trim(term, "\"()")
-		if utf8.RuneCountInString(term) < 2 && !strings.ContainsAny(term, "()*") {
-			// Single character searches (without wildcards) are too broad
-			return fmt.Errorf("search terms must be at least 2 characters")
-		}
-	}
-
 	return nil
 }
 
@@ -171,6 +269,23 @@ func validateMediaType(mediaType string) (bool, error) {
 	return true, nil
 }
 
+// validateSearchColumns validates the columns parameter.
+func validateSearchColumns(columns []string) error {
+	for _, column := range columns {
+		valid := false
+		for _, c := range db.SearchColumns {
+			if column == c {
+				valid = true
+				break
+			}
+		}
+		if !valid {
+			return fmt.Errorf("invalid column: %s (must be one of: %s)", column, strings.Join(db.SearchColumns, ", "))
+		}
+	}
+	return nil
+}
+
 // toSearchResults converts db.SearchResult to API response format.
 func toSearchResults(results []*db.SearchResult) []map[string]interface{} {
 	apiResults := make([]map[string]interface{}, len(results))
@@ -187,6 +302,11 @@ func toSearchResults(results []*db.SearchResult) []map[string]interface{} {
 			"created_at":  item.CreatedAt,
 			"updated_at":  item.UpdatedAt,
 			"version":     item.Version,
+			"is_favorite":   item.IsFavorite,
+			"is_pinned":     item.IsPinned,
+			"read_status":   item.ReadStatus,
+			"read_progress": item.ReadProgress,
+			"is_archived":   item.IsArchived,
 		}
 
 		if item.SourceURL != "" {
@@ -208,13 +328,102 @@ func toSearchResults(results []*db.SearchResult) []map[string]interface{} {
 		apiResults[i] = map[string]interface{}{
 			"item":         apiItem,
 			"relevance":    result.Relevance,
+			"score":        result.Score,
 			"matched_terms": result.MatchedTerms,
 		}
+		if result.Highlights != nil {
+			apiResults[i]["highlights"] = toHighlights(result.Highlights)
+		}
 	}
 
 	return apiResults
 }
 
+// toSuggestions converts db.Suggestion to API response format.
+func toSuggestions(suggestions []*db.Suggestion) []map[string]interface{} {
+	apiSuggestions := make([]map[string]interface{}, len(suggestions))
+	for i, s := range suggestions {
+		apiSuggestions[i] = map[string]interface{}{
+			"query": s.Query,
+			"score": s.Score,
+		}
+	}
+	return apiSuggestions
+}
+
+// parseHighlightOptions reads the highlight, mark_open, mark_close, ellipsis
+// and snippet_tokens parameters. Returns nil if highlighting is not requested.
+func parseHighlightOptions(r *http.Request) (*db.HighlightOptions, error) {
+	q := r.URL.Query()
+	if q.Get("highlight") == "" {
+		return nil, nil
+	}
+	enabled, err := strconv.ParseBool(q.Get("highlight"))
+	if err != nil {
+		return nil, fmt.Errorf("highlight must be true or false")
+	}
+	if !enabled {
+		return nil, nil
+	}
+
+	opts := db.DefaultHighlightOptions()
+	if q.Has("mark_open") || q.Has("mark_close") {
+		opts.TagOpen = q.Get("mark_open")
+		opts.TagClose = q.Get("mark_close")
+	}
+	if e := q.Get("ellipsis"); e != "" {
+		opts.Ellipsis = e
+	}
+	if t := q.Get("snippet_tokens"); t != "" {
+		tokens, err := strconv.Atoi(t)
+		if err != nil || tokens < 1 || tokens > 64 {
+			return nil, fmt.Errorf("snippet_tokens must be between 1 and 64")
+		}
+		opts.ContextTokens = tokens
+	}
+	return opts, nil
+}
+
+// toHighlights converts per-column highlights to API response format.
+func toHighlights(highlights map[string]*db.ColumnHighlight) map[string]interface{} {
+	apiHighlights := make(map[string]interface{}, len(highlights))
+	for column, h := range highlights {
+		spans := make([]map[string]interface{}, len(h.Spans))
+		for i, span := range h.Spans {
+			spans[i] = map[string]interface{}{
+				"start": span.Start,
+				"end":   span.End,
+			}
+		}
+		apiHighlights[column] = map[string]interface{}{
+			"snippet": h.Snippet,
+			"spans":   spans,
+		}
+	}
+	return apiHighlights
+}
+
+// toFacets converts db.SearchFacets to API response format.
+func toFacets(facets *db.SearchFacets) map[string]interface{} {
+	convert := func(counts []db.FacetCount) []map[string]interface{} {
+		apiCounts := make([]map[string]interface{}, len(counts))
+		for i, c := range counts {
+			apiCounts[i] = map[string]interface{}{
+				"value": c.Value,
+				"count": c.Count,
+			}
+		}
+		return apiCounts
+	}
+
+	return map[string]interface{}{
+		"media_type": convert(facets.MediaTypes),
+		"tags":       convert(facets.Tags),
+		"month":      convert(facets.Months),
+		"domain":     convert(facets.Domains),
+	}
+}
+
 // extractMatchedTermsFromResult processes matched terms from search result.
 func extractMatchedTermsFromResult(item *models.ContentItem, matchedTerms []string) []string {
 	// If matched terms are already populated, use them
@@ -0,0 +1,187 @@
+// Package handlers provides REST API handlers for search history.
+package handlers
+
+import (
+	"database/sql"
+	"encoding/json"
+	"log"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/kimhsiao/memonexus/backend/internal/db"
+	"github.com/kimhsiao/memonexus/backend/internal/models"
+)
+
+// recordSearch adds a search to the history. History is best effort: a
+// failure is logged and does not fail the search. Returns nil on failure.
+func (h *SearchHandler) recordSearch(query string, opts *db.SearchOptions, total int) *models.SearchHistoryEntry {
+	entry := &models.SearchHistoryEntry{
+		Query:       query,
+		ResultCount: total,
+		Filters: models.SearchFilters{
+			MediaType: opts.MediaType,
+			DateFrom:  opts.DateFrom,
+			DateTo:    opts.DateTo,
+		},
+	}
+	for _, tag := range strings.Split(opts.Tags, ",") {
+		if tag = strings.TrimSpace(tag); tag != "" {
+			entry.Filters.Tags = append(entry.Filters.Tags, tag)
+		}
+	}
+
+	if err := h.repo.RecordSearch(entry); err != nil {
+		log.Printf("Failed to record search history: %v", err)
+		return nil
+	}
+	return entry
+}
+
+// ListHistory handles GET /search/history
+// Returns recent searches, most recent first.
+func (h *SearchHandler) ListHistory(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	limit := 20
+	if limitStr := r.URL.Query().Get("limit"); limitStr != "" {
+		l, err := strconv.Atoi(limitStr)
+		if err != nil || l < 1 || l > 100 {
+			http.Error(w, "limit must be between 1 and 100", http.StatusBadRequest)
+			return
+		}
+		limit = l
+	}
+
+	entries, err := h.repo.ListSearchHistory(limit)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(entries)
+}
+
+// ClearHistory handles DELETE /search/history
+func (h *SearchHandler) ClearHistory(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodDelete {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	cleared, err := h.repo.ClearSearchHistory()
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(map[string]interface{}{
+		"cleared": cleared,
+	})
+}
+
+// DeleteHistoryEntry handles DELETE /search/history/{id}
+func (h *SearchHandler) DeleteHistoryEntry(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodDelete {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	if err := h.repo.DeleteSearchHistoryEntry(r.PathValue("id")); err != nil {
+		if err == sql.ErrNoRows {
+			http.Error(w, "History entry not found", http.StatusNotFound)
+			return
+		}
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// RecordHistoryClick handles POST /search/history/{id}/click
+// Records the result the user opened from a search.
+func (h *SearchHandler) RecordHistoryClick(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	var request struct {
+		ContentID string `json:"content_id"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&request); err != nil {
+		http.Error(w, "Invalid request body", http.StatusBadRequest)
+		return
+	}
+	if request.ContentID == "" {
+		http.Error(w, "content_id is required", http.StatusBadRequest)
+		return
+	}
+
+	if err := h.repo.RecordSearchClick(r.PathValue("id"), request.ContentID); err != nil {
+		if err == sql.ErrNoRows {
+			http.Error(w, "History entry not found", http.StatusNotFound)
+			return
+		}
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// GetHistorySettings handles GET /search/history/settings
+func (h *SearchHandler) GetHistorySettings(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	enabled, err := h.repo.SearchHistorySyncEnabled()
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(map[string]interface{}{
+		"sync_enabled": enabled,
+	})
+}
+
+// UpdateHistorySettings handles PUT /search/history/settings
+// Search history is only synced after the user opts in here.
+func (h *SearchHandler) UpdateHistorySettings(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPut {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	var request struct {
+		SyncEnabled *bool `json:"sync_enabled"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&request); err != nil {
+		http.Error(w, "Invalid request body", http.StatusBadRequest)
+		return
+	}
+	if request.SyncEnabled == nil {
+		http.Error(w, "sync_enabled is required", http.StatusBadRequest)
+		return
+	}
+
+	if err := h.repo.SetSearchHistorySyncEnabled(*request.SyncEnabled); err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(map[string]interface{}{
+		"sync_enabled": *request.SyncEnabled,
+	})
+}
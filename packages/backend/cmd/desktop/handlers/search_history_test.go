@@ -0,0 +1,160 @@
+// Package handlers tests for search history REST API endpoints.
+package handlers
+
+import (
+	"bytes"
+	"database/sql"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/kimhsiao/memonexus/backend/internal/db"
+)
+
+// setupTestDBWithHistory extends the search test database with the
+// search_history and app_settings tables.
+func setupTestDBWithHistory(t *testing.T) (*sql.DB, func()) {
+	testDB, cleanup := setupTestDBWithSearch(t)
+	_, err := testDB.Exec(`
+		CREATE TABLE search_history (
+			id TEXT PRIMARY KEY NOT NULL,
+			query TEXT NOT NULL,
+			filters TEXT NOT NULL DEFAULT '{}',
+			result_count INTEGER NOT NULL DEFAULT 0,
+			clicked_content_id TEXT,
+			is_deleted INTEGER NOT NULL DEFAULT 0,
+			created_at INTEGER NOT NULL,
+			updated_at INTEGER NOT NULL,
+			version INTEGER NOT NULL DEFAULT 1
+		);
+		CREATE TABLE app_settings (
+			key TEXT PRIMARY KEY NOT NULL,
+			value TEXT NOT NULL,
+			updated_at INTEGER NOT NULL
+		);
+	`)
+	if err != nil {
+		cleanup()
+		t.Fatalf("Failed to create history tables: %v", err)
+	}
+	return testDB, cleanup
+}
+
+func TestSearchHandler_History(t *testing.T) {
+	testDB, cleanup := setupTestDBWithHistory(t)
+	defer cleanup()
+
+	handler := NewSearchHandler(db.NewRepository(testDB))
+	itemID := insertTestContentItem(t, testDB, "Go Programming Guide", "Learn Go", "web", "golang", 1000)
+
+	// Searching records a history entry
+	w := httptest.NewRecorder()
+	handler.Search(w, httptest.NewRequest(http.MethodGet, "/search?q=golang&media_type=web", nil))
+	if w.Code != http.StatusOK {
+		t.Fatalf("Search: expected status 200, got %d", w.Code)
+	}
+	var searchResponse map[string]interface{}
+	json.NewDecoder(w.Body).Decode(&searchResponse)
+	historyID, ok := searchResponse["history_id"].(string)
+	if !ok || historyID == "" {
+		t.Fatalf("Expected history_id in search response, got %v", searchResponse)
+	}
+
+	// Report the opened result
+	req := httptest.NewRequest(http.MethodPost, "/search/history/"+historyID+"/click",
+		bytes.NewReader([]byte(`{"content_id":"`+itemID+`"}`)))
+	req.SetPathValue("id", historyID)
+	w = httptest.NewRecorder()
+	handler.RecordHistoryClick(w, req)
+	if w.Code != http.StatusNoContent {
+		t.Fatalf("RecordHistoryClick: expected status 204, got %d. Body: %s", w.Code, w.Body.String())
+	}
+
+	w = httptest.NewRecorder()
+	handler.ListHistory(w, httptest.NewRequest(http.MethodGet, "/search/history", nil))
+	var entries []map[string]interface{}
+	if err := json.NewDecoder(w.Body).Decode(&entries); err != nil {
+		t.Fatalf("Failed to decode response: %v", err)
+	}
+	if len(entries) != 1 {
+		t.Fatalf("Expected 1 history entry, got %d", len(entries))
+	}
+	e := entries[0]
+	if e["query"] != "golang" || e["result_count"].(float64) != 1 || e["clicked_content_id"] != itemID {
+		t.Errorf("Unexpected history entry: %v", e)
+	}
+	if filters := e["filters"].(map[string]interface{}); filters["media_type"] != "web" {
+		t.Errorf("Expected media_type filter, got %v", filters)
+	}
+
+	// Delete it
+	req = httptest.NewRequest(http.MethodDelete, "/search/history/"+historyID, nil)
+	req.SetPathValue("id", historyID)
+	w = httptest.NewRecorder()
+	handler.DeleteHistoryEntry(w, req)
+	if w.Code != http.StatusNoContent {
+		t.Errorf("DeleteHistoryEntry: expected status 204, got %d", w.Code)
+	}
+	w = httptest.NewRecorder()
+	handler.DeleteHistoryEntry(w, req)
+	if w.Code != http.StatusNotFound {
+		t.Errorf("Second delete: expected status 404, got %d", w.Code)
+	}
+}
+
+func TestSearchHandler_ClearHistory(t *testing.T) {
+	testDB, cleanup := setupTestDBWithHistory(t)
+	defer cleanup()
+
+	handler := NewSearchHandler(db.NewRepository(testDB))
+	for _, q := range []string{"golang", "rust"} {
+		handler.Search(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "/search?q="+q, nil))
+	}
+
+	w := httptest.NewRecorder()
+	handler.ClearHistory(w, httptest.NewRequest(http.MethodDelete, "/search/history", nil))
+	if w.Code != http.StatusOK {
+		t.Fatalf("Expected status 200, got %d", w.Code)
+	}
+	var response map[string]interface{}
+	json.NewDecoder(w.Body).Decode(&response)
+	if response["cleared"].(float64) != 2 {
+		t.Errorf("Expected 2 cleared, got %v", response["cleared"])
+	}
+}
+
+func TestSearchHandler_HistorySettings(t *testing.T) {
+	testDB, cleanup := setupTestDBWithHistory(t)
+	defer cleanup()
+
+	handler := NewSearchHandler(db.NewRepository(testDB))
+
+	get := func() bool {
+		w := httptest.NewRecorder()
+		handler.GetHistorySettings(w, httptest.NewRequest(http.MethodGet, "/search/history/settings", nil))
+		var response map[string]bool
+		json.NewDecoder(w.Body).Decode(&response)
+		return response["sync_enabled"]
+	}
+	if get() {
+		t.Error("History sync should be disabled by default")
+	}
+
+	w := httptest.NewRecorder()
+	handler.UpdateHistorySettings(w, httptest.NewRequest(http.MethodPut, "/search/history/settings",
+		bytes.NewReader([]byte(`{"sync_enabled":true}`))))
+	if w.Code != http.StatusOK {
+		t.Fatalf("Expected status 200, got %d", w.Code)
+	}
+	if !get() {
+		t.Error("History sync should be enabled after opting in")
+	}
+
+	w = httptest.NewRecorder()
+	handler.UpdateHistorySettings(w, httptest.NewRequest(http.MethodPut, "/search/history/settings",
+		bytes.NewReader([]byte(`{}`))))
+	if w.Code != http.StatusBadRequest {
+		t.Errorf("Expected status 400 without sync_enabled, got %d", w.Code)
+	}
+}
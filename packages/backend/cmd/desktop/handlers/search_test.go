@@ -11,6 +11,7 @@ import (
 	"strconv"
 	"strings"
 	"testing"
+	"time"
 
 	"github.com/kimhsiao/memonexus/backend/internal/db"
 	"github.com/kimhsiao/memonexus/backend/internal/models"
@@ -39,11 +40,89 @@ func setupTestDBWithSearch(t *testing.T) (*sql.DB, func()) {
 			created_at INTEGER NOT NULL,
 			updated_at INTEGER NOT NULL CHECK(updated_at > 0 AND updated_at >= created_at),
 			version INTEGER NOT NULL DEFAULT 1 CHECK(version > 0),
-			content_hash TEXT
+			content_hash TEXT,
+			is_pinned INTEGER NOT NULL DEFAULT 0 CHECK(is_pinned IN (0, 1)),
+			is_favorite INTEGER NOT NULL DEFAULT 0 CHECK(is_favorite IN (0, 1)),
+			read_status TEXT NOT NULL DEFAULT 'unread' CHECK(read_status IN ('unread', 'in_progress', 'read')),
+			read_progress REAL NOT NULL DEFAULT 0 CHECK(read_progress >= 0 AND read_progress <= 1),
+			is_archived INTEGER NOT NULL DEFAULT 0 CHECK(is_archived IN (0, 1)),
+			favorite_changed_at INTEGER NOT NULL DEFAULT 0,
+			pinned_changed_at INTEGER NOT NULL DEFAULT 0,
+			read_changed_at INTEGER NOT NULL DEFAULT 0,
+			archived_changed_at INTEGER NOT NULL DEFAULT 0,
+			source_domain TEXT GENERATED ALWAYS AS (
+				CASE WHEN instr(source_url, '://') > 0 THEN
+					substr(
+						lower(substr(
+							substr(source_url, instr(source_url, '://') + 3),
+							1,
+							instr(replace(replace(replace(substr(source_url, instr(source_url, '://') + 3),
+								'?', '/'), '#', '/'), ':', '/') || '/', '/') - 1
+						)),
+						1 + 4 * (substr(source_url, instr(source_url, '://') + 3) LIKE 'www.%')
+					)
+				END
+			) VIRTUAL
 		);
 
 		CREATE INDEX idx_content_items_created_at ON content_items(created_at DESC);
 		CREATE INDEX idx_content_items_media_type ON content_items(media_type);
+
+		CREATE TABLE tags (
+			id TEXT PRIMARY KEY,
+			name TEXT NOT NULL UNIQUE,
+			color TEXT DEFAULT '#3B82F6',
+			created_at INTEGER NOT NULL,
+			updated_at INTEGER NOT NULL,
+			is_deleted INTEGER NOT NULL DEFAULT 0,
+			parent_id TEXT
+		);
+
+		CREATE TABLE content_tags (
+			content_id TEXT NOT NULL REFERENCES content_items(id) ON DELETE CASCADE,
+			tag_id TEXT NOT NULL REFERENCES tags(id) ON DELETE CASCADE,
+			assigned_at INTEGER NOT NULL,
+			PRIMARY KEY (content_id, tag_id)
+		);
+
+		CREATE TABLE content_revisions (
+			id TEXT PRIMARY KEY,
+			content_id TEXT NOT NULL,
+			version INTEGER NOT NULL,
+			title TEXT NOT NULL,
+			content_text TEXT NOT NULL,
+			source_url TEXT,
+			media_type TEXT NOT NULL,
+			tags TEXT NOT NULL DEFAULT '',
+			summary TEXT,
+			content_hash TEXT,
+			edited_at INTEGER NOT NULL,
+			created_at INTEGER NOT NULL
+		);
+
+		CREATE TABLE content_links (
+			source_id TEXT NOT NULL,
+			kind TEXT NOT NULL,
+			target TEXT NOT NULL,
+			target_id TEXT,
+			position INTEGER NOT NULL DEFAULT 0,
+			created_at INTEGER NOT NULL,
+			PRIMARY KEY (source_id, kind, target)
+		);
+
+		CREATE TABLE change_log (
+			id TEXT PRIMARY KEY,
+			item_id TEXT NOT NULL,
+			operation TEXT NOT NULL,
+			version INTEGER NOT NULL,
+			timestamp INTEGER NOT NULL
+		);
+
+		CREATE TABLE content_engagement (
+			content_id TEXT PRIMARY KEY REFERENCES content_items(id) ON DELETE CASCADE,
+			open_count INTEGER NOT NULL DEFAULT 0,
+			last_opened_at INTEGER
+		);
 	`)
 	if err != nil {
 		testDB.Close()
@@ -56,27 +135,59 @@ func setupTestDBWithSearch(t *testing.T) (*sql.DB, func()) {
 			title,
 			content_text,
 			tags,
+			summary,
+			source_domain,
 			content=content_items,
 			content_rowid=rowid,
 			tokenize='porter unicode61'
 		);
 
 		CREATE TRIGGER content_items_ai AFTER INSERT ON content_items BEGIN
-			INSERT INTO content_fts(rowid, title, content_text, tags)
-			VALUES (new.rowid, new.title, new.content_text, new.tags);
+			INSERT INTO content_fts(rowid, title, content_text, tags, summary, source_domain)
+			VALUES (new.rowid, new.title, new.content_text, new.tags, new.summary, new.source_domain);
 		END;
 
 		CREATE TRIGGER content_items_ad AFTER DELETE ON content_items BEGIN
-			INSERT INTO content_fts(content_fts, rowid, title, content_text, tags)
-			VALUES ('delete', old.rowid, old.title, old.content_text, old.tags);
+			INSERT INTO content_fts(content_fts, rowid, title, content_text, tags, summary, source_domain)
+			VALUES ('delete', old.rowid, old.title, old.content_text, old.tags, old.summary, old.source_domain);
 		END;
 
 		CREATE TRIGGER content_items_au AFTER UPDATE ON content_items BEGIN
-			INSERT INTO content_fts(content_fts, rowid, title, content_text, tags)
-			VALUES ('delete', old.rowid, old.title, old.content_text, new.tags);
-			INSERT INTO content_fts(rowid, title, content_text, tags)
-			VALUES (new.rowid, new.title, new.content_text, new.tags);
+			INSERT INTO content_fts(content_fts, rowid, title, content_text, tags, summary, source_domain)
+			VALUES ('delete', old.rowid, old.title, old.content_text, old.tags, old.summary, old.source_domain);
+			INSERT INTO content_fts(rowid, title, content_text, tags, summary, source_domain)
+			VALUES (new.rowid, new.title, new.content_text, new.tags, new.summary, new.source_domain);
 		END;
+
+		CREATE VIRTUAL TABLE content_fts_trigram USING fts5(
+			title,
+			content_text,
+			tags,
+			summary,
+			source_domain,
+			content=content_items,
+			content_rowid=rowid,
+			tokenize='trigram'
+		);
+
+		CREATE TRIGGER content_items_trigram_ai AFTER INSERT ON content_items BEGIN
+			INSERT INTO content_fts_trigram(rowid, title, content_text, tags, summary, source_domain)
+			VALUES (new.rowid, new.title, new.content_text, new.tags, new.summary, new.source_domain);
+		END;
+
+		CREATE TRIGGER content_items_trigram_ad AFTER DELETE ON content_items BEGIN
+			INSERT INTO content_fts_trigram(content_fts_trigram, rowid, title, content_text, tags, summary, source_domain)
+			VALUES ('delete', old.rowid, old.title, old.content_text, old.tags, old.summary, old.source_domain);
+		END;
+
+		CREATE TRIGGER content_items_trigram_au AFTER UPDATE ON content_items BEGIN
+			INSERT INTO content_fts_trigram(content_fts_trigram, rowid, title, content_text, tags, summary, source_domain)
+			VALUES ('delete', old.rowid, old.title, old.content_text, old.tags, old.summary, old.source_domain);
+			INSERT INTO content_fts_trigram(rowid, title, content_text, tags, summary, source_domain)
+			VALUES (new.rowid, new.title, new.content_text, new.tags, new.summary, new.source_domain);
+		END;
+
+		CREATE VIRTUAL TABLE content_fts_vocab USING fts5vocab(content_fts, 'row');
 	`)
 	if err != nil {
 		testDB.Close()
@@ -102,6 +213,22 @@ func insertTestContentItem(t *testing.T, db *sql.DB, title, content, mediaType,
 	if err != nil {
 		t.Fatalf("Failed to insert test content: %v", err)
 	}
+
+	// Link the tags as the repository does
+	for _, tag := range strings.Split(tags, ",") {
+		if tag = strings.TrimSpace(tag); tag == "" {
+			continue
+		}
+		_, err := db.Exec(`INSERT OR IGNORE INTO tags (id, name, created_at, updated_at) VALUES (?, ?, ?, ?)`,
+			string(models.UUID(uuid.New())), tag, createdAt, createdAt)
+		if err == nil {
+			_, err = db.Exec(`INSERT OR IGNORE INTO content_tags (content_id, tag_id, assigned_at)
+				SELECT ?, id, ? FROM tags WHERE name = ?`, string(id), createdAt, tag)
+		}
+		if err != nil {
+			t.Fatalf("Failed to link test tag: %v", err)
+		}
+	}
 	return string(id)
 }
 
@@ -644,6 +771,60 @@ func TestSearchHandler_Search_WithNOT(t *testing.T) {
 	}
 }
 
+func TestSearchHandler_Search_StructuredQuery(t *testing.T) {
+	testDB, cleanup := setupTestDBWithSearch(t)
+	defer cleanup()
+
+	repo := db.NewRepository(testDB)
+	handler := NewSearchHandler(repo)
+
+	insertTestContentItem(t, testDB, "Go Research", "Notes on channels", "pdf", "research", 1000)
+	insertTestContentItem(t, testDB, "Go Draft", "Notes on channels", "pdf", "research,draft", 2000)
+	insertTestContentItem(t, testDB, "Go Blog", "Notes on channels", "web", "research", 3000)
+
+	q := `tag:research type:pdf "notes on" -draft`
+	req := httptest.NewRequest(http.MethodGet, "/search?q="+url.QueryEscape(q), nil)
+	w := httptest.NewRecorder()
+
+	handler.Search(w, req)
+
+	if w.Code != http.StatusOK {
+		t.Fatalf("Expected status 200, got %d. Body: %s", w.Code, w.Body.String())
+	}
+
+	var response map[string]interface{}
+	if err := json.NewDecoder(w.Body).Decode(&response); err != nil {
+		t.Fatalf("Failed to decode response: %v", err)
+	}
+
+	if response["query"] != q {
+		t.Errorf("Expected raw query to be echoed, got %v", response["query"])
+	}
+	if response["total"].(float64) != 1 {
+		t.Errorf("Expected 1 result, got %v", response["total"])
+	}
+}
+
+func TestSearchHandler_Search_QuerySyntaxError(t *testing.T) {
+	testDB, cleanup := setupTestDBWithSearch(t)
+	defer cleanup()
+
+	repo := db.NewRepository(testDB)
+	handler := NewSearchHandler(repo)
+
+	req := httptest.NewRequest(http.MethodGet, "/search?q="+url.QueryEscape(`golang "unclosed`), nil)
+	w := httptest.NewRecorder()
+
+	handler.Search(w, req)
+
+	if w.Code != http.StatusBadRequest {
+		t.Errorf("Expected status 400, got %d", w.Code)
+	}
+	if !strings.Contains(w.Body.String(), "unterminated phrase at position 8") {
+		t.Errorf("Expected positioned syntax error, got: %s", w.Body.String())
+	}
+}
+
 func TestSearchHandler_Search_ResultsIncludeMatchedTerms(t *testing.T) {
 	testDB, cleanup := setupTestDBWithSearch(t)
 	defer cleanup()
@@ -766,3 +947,333 @@ func TestSearchHandler_Search_MethodNotAllowed(t *testing.T) {
 		t.Errorf("Expected status 405, got %d", w.Code)
 	}
 }
+
+func TestSearchHandler_Suggest(t *testing.T) {
+	testDB, cleanup := setupTestDBWithSearch(t)
+	defer cleanup()
+
+	repo := db.NewRepository(testDB)
+	handler := NewSearchHandler(repo)
+
+	insertTestContentItem(t, testDB, "Golang Concurrency", "Goroutines in golang", "web", "golang", 1000)
+
+	req := httptest.NewRequest(http.MethodGet, "/search/suggest?q=golnag", nil)
+	w := httptest.NewRecorder()
+
+	handler.Suggest(w, req)
+
+	if w.Code != http.StatusOK {
+		t.Fatalf("Expected status 200, got %d. Body: %s", w.Code, w.Body.String())
+	}
+
+	var response map[string]interface{}
+	if err := json.NewDecoder(w.Body).Decode(&response); err != nil {
+		t.Fatalf("Failed to decode response: %v", err)
+	}
+
+	suggestions, ok := response["suggestions"].([]interface{})
+	if !ok || len(suggestions) == 0 {
+		t.Fatalf("Expected suggestions, got %v", response["suggestions"])
+	}
+	if first := suggestions[0].(map[string]interface{}); first["query"] != "golang" {
+		t.Errorf("Expected 'golang' suggestion, got %v", first["query"])
+	}
+}
+
+func TestSearchHandler_Search_NoResultsIncludesSuggestions(t *testing.T) {
+	testDB, cleanup := setupTestDBWithSearch(t)
+	defer cleanup()
+
+	repo := db.NewRepository(testDB)
+	handler := NewSearchHandler(repo)
+
+	insertTestContentItem(t, testDB, "Golang Concurrency", "Goroutines in golang", "web", "golang", 1000)
+
+	req := httptest.NewRequest(http.MethodGet, "/search?q=golnag", nil)
+	w := httptest.NewRecorder()
+
+	handler.Search(w, req)
+
+	if w.Code != http.StatusOK {
+		t.Fatalf("Expected status 200, got %d. Body: %s", w.Code, w.Body.String())
+	}
+
+	var response map[string]interface{}
+	if err := json.NewDecoder(w.Body).Decode(&response); err != nil {
+		t.Fatalf("Failed to decode response: %v", err)
+	}
+
+	if response["total"].(float64) != 0 {
+		t.Errorf("Expected 0 results, got %v", response["total"])
+	}
+	if _, ok := response["suggestions"]; !ok {
+		t.Error("Expected suggestions in response for zero results")
+	}
+}
+
+func TestSearchHandler_Suggest_MethodNotAllowed(t *testing.T) {
+	testDB, cleanup := setupTestDBWithSearch(t)
+	defer cleanup()
+
+	handler := NewSearchHandler(db.NewRepository(testDB))
+
+	req := httptest.NewRequest(http.MethodPost, "/search/suggest?q=test", nil)
+	w := httptest.NewRecorder()
+
+	handler.Suggest(w, req)
+
+	if w.Code != http.StatusMethodNotAllowed {
+		t.Errorf("Expected status 405, got %d", w.Code)
+	}
+}
+
+func TestSearchHandler_Search_Facets(t *testing.T) {
+	testDB, cleanup := setupTestDBWithSearch(t)
+	defer cleanup()
+
+	repo := db.NewRepository(testDB)
+	handler := NewSearchHandler(repo)
+
+	insertTestContentItem(t, testDB, "Go Guide", "Notes", "web", "golang", 1000)
+	insertTestContentItem(t, testDB, "Go Paper", "Notes", "pdf", "golang,research", 2000)
+
+	req := httptest.NewRequest(http.MethodGet, "/search?q=notes&facets=true", nil)
+	w := httptest.NewRecorder()
+
+	handler.Search(w, req)
+
+	if w.Code != http.StatusOK {
+		t.Fatalf("Expected status 200, got %d. Body: %s", w.Code, w.Body.String())
+	}
+
+	var response map[string]interface{}
+	if err := json.NewDecoder(w.Body).Decode(&response); err != nil {
+		t.Fatalf("Failed to decode response: %v", err)
+	}
+
+	facets, ok := response["facets"].(map[string]interface{})
+	if !ok {
+		t.Fatalf("Expected facets object, got %v", response["facets"])
+	}
+	for _, key := range []string{"media_type", "tags", "month", "domain"} {
+		if _, ok := facets[key]; !ok {
+			t.Errorf("Expected facet %q", key)
+		}
+	}
+	tags := facets["tags"].([]interface{})
+	if first := tags[0].(map[string]interface{}); first["value"] != "golang" || first["count"].(float64) != 2 {
+		t.Errorf("Unexpected top tag facet: %v", first)
+	}
+
+	// Invalid flag
+	req = httptest.NewRequest(http.MethodGet, "/search?q=notes&facets=maybe", nil)
+	w = httptest.NewRecorder()
+	handler.Search(w, req)
+	if w.Code != http.StatusBadRequest {
+		t.Errorf("Expected status 400 for invalid facets flag, got %d", w.Code)
+	}
+}
+
+func TestSearchHandler_Search_Columns(t *testing.T) {
+	testDB, cleanup := setupTestDBWithSearch(t)
+	defer cleanup()
+
+	repo := db.NewRepository(testDB)
+	handler := NewSearchHandler(repo)
+
+	insertTestContentItem(t, testDB, "Golang Guide", "Notes", "web", "", 1000)
+	insertTestContentItem(t, testDB, "Notes", "All about golang", "web", "", 2000)
+
+	req := httptest.NewRequest(http.MethodGet, "/search?q=golang&columns=title,summary", nil)
+	w := httptest.NewRecorder()
+
+	handler.Search(w, req)
+
+	if w.Code != http.StatusOK {
+		t.Fatalf("Expected status 200, got %d. Body: %s", w.Code, w.Body.String())
+	}
+
+	var response map[string]interface{}
+	if err := json.NewDecoder(w.Body).Decode(&response); err != nil {
+		t.Fatalf("Failed to decode response: %v", err)
+	}
+	if response["total"].(float64) != 1 {
+		t.Errorf("Expected 1 title match, got %v", response["total"])
+	}
+
+	// Unknown column
+	req = httptest.NewRequest(http.MethodGet, "/search?q=golang&columns=source_url", nil)
+	w = httptest.NewRecorder()
+	handler.Search(w, req)
+	if w.Code != http.StatusBadRequest {
+		t.Errorf("Expected status 400 for invalid column, got %d", w.Code)
+	}
+}
+
+func TestSearchHandler_Search_Ranking(t *testing.T) {
+	testDB, cleanup := setupTestDBWithSearch(t)
+	defer cleanup()
+
+	repo := db.NewRepository(testDB)
+	handler := NewSearchHandler(repo)
+
+	insertTestContentItem(t, testDB, "Golang Notes", "Old notes", "web", "", 1000)
+	freshID := insertTestContentItem(t, testDB, "Golang Notes", "New notes", "web", "", time.Now().Unix()-60)
+
+	firstID := func(ranking string) string {
+		t.Helper()
+		req := httptest.NewRequest(http.MethodGet, "/search?q=golang&ranking="+ranking, nil)
+		w := httptest.NewRecorder()
+		handler.Search(w, req)
+		if w.Code != http.StatusOK {
+			t.Fatalf("Expected status 200 for ranking %q, got %d. Body: %s", ranking, w.Code, w.Body.String())
+		}
+		var response struct {
+			Results []struct {
+				Item struct {
+					ID string `json:"id"`
+				} `json:"item"`
+			} `json:"results"`
+		}
+		if err := json.NewDecoder(w.Body).Decode(&response); err != nil {
+			t.Fatalf("Failed to decode response: %v", err)
+		}
+		if len(response.Results) != 2 {
+			t.Fatalf("Expected 2 results for ranking %q, got %d", ranking, len(response.Results))
+		}
+		return response.Results[0].Item.ID
+	}
+
+	// Equal relevance: the older item keeps its place unless recency counts
+	if id := firstID("relevance"); id == freshID {
+		t.Error("Expected relevance ranking to ignore recency")
+	}
+	if id := firstID("recent"); id != freshID {
+		t.Error("Expected recent ranking to put the fresh item first")
+	}
+
+	req := httptest.NewRequest(http.MethodGet, "/search?q=golang&ranking=popular", nil)
+	w := httptest.NewRecorder()
+	handler.Search(w, req)
+	if w.Code != http.StatusBadRequest {
+		t.Errorf("Expected status 400 for invalid ranking, got %d", w.Code)
+	}
+}
+
+func TestSearchHandler_Search_Cursor(t *testing.T) {
+	testDB, cleanup := setupTestDBWithSearch(t)
+	defer cleanup()
+
+	repo := db.NewRepository(testDB)
+	handler := NewSearchHandler(repo)
+
+	for i := 0; i < 5; i++ {
+		insertTestContentItem(t, testDB, "Item "+strconv.Itoa(i), "Content", "web", "test", 1000+int64(i))
+	}
+
+	seen := make(map[string]bool)
+	url := "/search?q=item&limit=2"
+	for pages := 1; ; pages++ {
+		req := httptest.NewRequest(http.MethodGet, url, nil)
+		w := httptest.NewRecorder()
+		handler.Search(w, req)
+
+		if w.Code != http.StatusOK {
+			t.Fatalf("Expected status 200, got %d. Body: %s", w.Code, w.Body.String())
+		}
+
+		var response struct {
+			Results []struct {
+				Item struct {
+					ID string `json:"id"`
+				} `json:"item"`
+			} `json:"results"`
+			Total      int    `json:"total"`
+			NextCursor string `json:"next_cursor"`
+		}
+		if err := json.NewDecoder(w.Body).Decode(&response); err != nil {
+			t.Fatalf("Failed to decode response: %v", err)
+		}
+		if response.Total != 5 {
+			t.Errorf("Expected total 5, got %d", response.Total)
+		}
+		for _, r := range response.Results {
+			if seen[r.Item.ID] {
+				t.Errorf("Item %s returned twice", r.Item.ID)
+			}
+			seen[r.Item.ID] = true
+		}
+
+		if response.NextCursor == "" {
+			if pages != 3 {
+				t.Errorf("Expected 3 pages, got %d", pages)
+			}
+			break
+		}
+		url = "/search?q=item&limit=2&cursor=" + response.NextCursor
+	}
+
+	if len(seen) != 5 {
+		t.Errorf("Expected 5 distinct items, got %d", len(seen))
+	}
+
+	// Malformed cursor
+	req := httptest.NewRequest(http.MethodGet, "/search?q=item&cursor=bogus", nil)
+	w := httptest.NewRecorder()
+	handler.Search(w, req)
+	if w.Code != http.StatusBadRequest {
+		t.Errorf("Expected status 400 for invalid cursor, got %d", w.Code)
+	}
+}
+
+func TestSearchHandler_Search_Highlight(t *testing.T) {
+	testDB, cleanup := setupTestDBWithSearch(t)
+	defer cleanup()
+
+	repo := db.NewRepository(testDB)
+	handler := NewSearchHandler(repo)
+
+	insertTestContentItem(t, testDB, "Programming in Go", "Writing programs", "web", "golang", 1000)
+
+	req := httptest.NewRequest(http.MethodGet, "/search?q=prog*&highlight=true&mark_open=%5B&mark_close=%5D", nil)
+	w := httptest.NewRecorder()
+
+	handler.Search(w, req)
+
+	if w.Code != http.StatusOK {
+		t.Fatalf("Expected status 200, got %d. Body: %s", w.Code, w.Body.String())
+	}
+
+	var response struct {
+		Results []struct {
+			Highlights map[string]struct {
+				Snippet string `json:"snippet"`
+				Spans   []struct {
+					Start int `json:"start"`
+					End   int `json:"end"`
+				} `json:"spans"`
+			} `json:"highlights"`
+		} `json:"results"`
+	}
+	if err := json.NewDecoder(w.Body).Decode(&response); err != nil {
+		t.Fatalf("Failed to decode response: %v", err)
+	}
+	if len(response.Results) != 1 {
+		t.Fatalf("Expected 1 result, got %d", len(response.Results))
+	}
+	title, ok := response.Results[0].Highlights["title"]
+	if !ok || title.Snippet != "[Programming] in Go" {
+		t.Errorf("Unexpected title highlight: %+v", response.Results[0].Highlights)
+	}
+	if len(title.Spans) != 1 || title.Spans[0].Start != 0 || title.Spans[0].End != 11 {
+		t.Errorf("Unexpected title spans: %+v", title.Spans)
+	}
+
+	// Invalid snippet window
+	req = httptest.NewRequest(http.MethodGet, "/search?q=prog*&highlight=true&snippet_tokens=100", nil)
+	w = httptest.NewRecorder()
+	handler.Search(w, req)
+	if w.Code != http.StatusBadRequest {
+		t.Errorf("Expected status 400 for invalid snippet_tokens, got %d", w.Code)
+	}
+}
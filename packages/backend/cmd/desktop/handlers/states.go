@@ -0,0 +1,122 @@
+// Package handlers provides REST API handlers for content item triage states.
+package handlers
+
+import (
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strconv"
+
+	"github.com/kimhsiao/memonexus/backend/internal/db"
+	"github.com/kimhsiao/memonexus/backend/internal/models"
+)
+
+// UpdateState handles PUT /content/{id}/state
+// Body: any of {"favorite": true, "pinned": false, "read_status": "read",
+// "read_progress": 0.4, "archived": true}. Returns the updated item.
+func (h *ContentHandler) UpdateState(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPut {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	var update db.ContentStateUpdate
+	if err := json.NewDecoder(r.Body).Decode(&update); err != nil {
+		http.Error(w, "Invalid request body", http.StatusBadRequest)
+		return
+	}
+
+	id := r.PathValue("id")
+	if _, err := h.repo.UpdateContentStates([]string{id}, &update); err != nil {
+		writeStateError(w, err)
+		return
+	}
+	item, err := h.repo.GetContentItem(id)
+	if err != nil {
+		writeStateError(w, err)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(item)
+}
+
+// BulkUpdateState handles POST /content/bulk/state
+// Body: {"ids": ["..."], "archived": true}, taking the same states as
+// UpdateState. All items change or, if any is missing, none do.
+func (h *ContentHandler) BulkUpdateState(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	var request struct {
+		IDs []string `json:"ids"`
+		db.ContentStateUpdate
+	}
+	if err := json.NewDecoder(r.Body).Decode(&request); err != nil {
+		http.Error(w, "Invalid request body", http.StatusBadRequest)
+		return
+	}
+
+	updated, err := h.repo.UpdateContentStates(request.IDs, &request.ContentStateUpdate)
+	if err != nil {
+		writeStateError(w, err)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(map[string]interface{}{"updated": updated})
+}
+
+// writeStateError writes the response for a failed state update.
+func writeStateError(w http.ResponseWriter, err error) {
+	switch {
+	case err == sql.ErrNoRows:
+		http.Error(w, "Content item not found", http.StatusNotFound)
+	case errors.Is(err, db.ErrInvalidState):
+		http.Error(w, err.Error(), http.StatusBadRequest)
+	default:
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+	}
+}
+
+// addStateFilters adds the favorite, pinned, read_status and archived
+// query parameters to fb. archived is true, false or all; when it is
+// absent, archived items are included unless hideArchived is set.
+func addStateFilters(query url.Values, fb *db.FilterBuilder, hideArchived bool) error {
+	for _, flag := range []string{models.StateFavorite, models.StatePinned} {
+		if value := query.Get(flag); value != "" {
+			b, err := strconv.ParseBool(value)
+			if err != nil {
+				return fmt.Errorf("invalid %s: %s (must be true or false)", flag, value)
+			}
+			fb.StateFlag(flag, b)
+		}
+	}
+
+	if status := query.Get("read_status"); status != "" {
+		if !models.IsReadStatus(status) {
+			return fmt.Errorf("invalid read_status: %s (must be one of: unread, in_progress, read)", status)
+		}
+		fb.ReadStatus(status)
+	}
+
+	switch archived := query.Get("archived"); archived {
+	case "":
+		if hideArchived {
+			fb.Archived(false)
+		}
+	case "all":
+	default:
+		b, err := strconv.ParseBool(archived)
+		if err != nil {
+			return fmt.Errorf("invalid archived: %s (must be true, false or all)", archived)
+		}
+		fb.Archived(b)
+	}
+	return nil
+}
@@ -0,0 +1,106 @@
+// Package handlers tests for content item triage state endpoints.
+package handlers
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/kimhsiao/memonexus/backend/internal/db"
+	"github.com/kimhsiao/memonexus/backend/internal/models"
+)
+
+func TestContentHandler_States(t *testing.T) {
+	testDB := setupMigratedTestDB(t)
+	defer testDB.Close()
+
+	repo := db.NewRepository(testDB)
+	handler := NewContentHandler(repo)
+
+	var ids []string
+	for _, title := range []string{"First", "Second", "Third"} {
+		item := &models.ContentItem{Title: title, ContentText: "body", MediaType: "web"}
+		if err := repo.CreateContentItem(item); err != nil {
+			t.Fatalf("Failed to create test item: %v", err)
+		}
+		ids = append(ids, string(item.ID))
+	}
+
+	update := func(id, body string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(http.MethodPut, "/content/"+id+"/state", strings.NewReader(body))
+		req.SetPathValue("id", id)
+		w := httptest.NewRecorder()
+		handler.UpdateState(w, req)
+		return w
+	}
+
+	w := update(ids[0], `{"pinned": true, "read_progress": 0.25}`)
+	var item models.ContentItem
+	json.NewDecoder(w.Body).Decode(&item)
+	if w.Code != http.StatusOK || !item.IsPinned || item.ReadStatus != models.ReadStatusInProgress {
+		t.Errorf("Update: got %d %+v", w.Code, item)
+	}
+	if w := update(ids[0], `{"read_status": "done"}`); w.Code != http.StatusBadRequest {
+		t.Errorf("Invalid status: expected status 400, got %d", w.Code)
+	}
+	if w := update("00000000-0000-0000-0000-000000000000", `{"favorite": true}`); w.Code != http.StatusNotFound {
+		t.Errorf("Missing item: expected status 404, got %d", w.Code)
+	}
+
+	// Bulk archive
+	body := `{"ids": ["` + ids[1] + `", "` + ids[2] + `"], "archived": true}`
+	req := httptest.NewRequest(http.MethodPost, "/content/bulk/state", strings.NewReader(body))
+	w = httptest.NewRecorder()
+	handler.BulkUpdateState(w, req)
+	var bulk struct {
+		Updated int `json:"updated"`
+	}
+	json.NewDecoder(w.Body).Decode(&bulk)
+	if w.Code != http.StatusOK || bulk.Updated != 2 {
+		t.Errorf("Bulk update: got %d %s", w.Code, w.Body.String())
+	}
+
+	list := func(query string) (int, []string) {
+		w := httptest.NewRecorder()
+		handler.ListContentItems(w, httptest.NewRequest(http.MethodGet, "/content"+query, nil))
+		var response struct {
+			Items []models.ContentItem `json:"items"`
+		}
+		json.NewDecoder(w.Body).Decode(&response)
+		titles := make([]string, len(response.Items))
+		for i, item := range response.Items {
+			titles[i] = item.Title
+		}
+		return w.Code, titles
+	}
+
+	// Archived items are hidden by default
+	if code, titles := list(""); code != http.StatusOK || len(titles) != 1 || titles[0] != "First" {
+		t.Errorf("Default list: got %d %v", code, titles)
+	}
+	if _, titles := list("?archived=all"); len(titles) != 3 || titles[0] != "First" {
+		t.Errorf("All items, pinned first: got %v", titles)
+	}
+	if _, titles := list("?archived=true&read_status=unread"); len(titles) != 2 {
+		t.Errorf("Archived unread items: got %v", titles)
+	}
+	if code, _ := list("?archived=maybe"); code != http.StatusBadRequest {
+		t.Errorf("Invalid archived: expected status 400, got %d", code)
+	}
+
+	// Search still finds archived items unless asked not to
+	search := NewSearchHandler(repo)
+	for query, want := range map[string]int{"body": 3, "body&archived=false": 1, "body&pinned=true": 1} {
+		w := httptest.NewRecorder()
+		search.Search(w, httptest.NewRequest(http.MethodGet, "/search?q="+query, nil))
+		var response struct {
+			Total int `json:"total"`
+		}
+		json.NewDecoder(w.Body).Decode(&response)
+		if w.Code != http.StatusOK || response.Total != want {
+			t.Errorf("Search %q: got %d with %d results, want %d", query, w.Code, response.Total, want)
+		}
+	}
+}
@@ -4,7 +4,10 @@ package handlers
 import (
 	"database/sql"
 	"encoding/json"
+	"errors"
 	"net/http"
+	"strconv"
+	"strings"
 
 	"github.com/kimhsiao/memonexus/backend/internal/db"
 	"github.com/kimhsiao/memonexus/backend/internal/models"
@@ -21,12 +24,34 @@ func NewTagHandler(repo *db.Repository) *TagHandler {
 }
 
 // ListTags handles GET /tags
+// With ?tree=true, tags are returned nested under their parents.
 func (h *TagHandler) ListTags(w http.ResponseWriter, r *http.Request) {
 	if r.Method != http.MethodGet {
 		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
 		return
 	}
 
+	tree := false
+	if t := r.URL.Query().Get("tree"); t != "" {
+		b, err := strconv.ParseBool(t)
+		if err != nil {
+			http.Error(w, "Invalid tree parameter", http.StatusBadRequest)
+			return
+		}
+		tree = b
+	}
+
+	if tree {
+		nodes, err := h.repo.ListTagTree()
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+			return
+		}
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(nodes)
+		return
+	}
+
 	tags, err := h.repo.ListTags()
 	if err != nil {
 		http.Error(w, err.Error(), http.StatusInternalServerError)
@@ -59,10 +84,6 @@ func (h *TagHandler) CreateTag(w http.ResponseWriter, r *http.Request) {
 		http.Error(w, "name is required", http.StatusBadRequest)
 		return
 	}
-	if len(request.Name) > 50 {
-		http.Error(w, "name must be 50 characters or less", http.StatusBadRequest)
-		return
-	}
 
 	// Set default color
 	if request.Color == "" {
@@ -74,8 +95,9 @@ func (h *TagHandler) CreateTag(w http.ResponseWriter, r *http.Request) {
 		Color: request.Color,
 	}
 
+	// The repository checks the length of each path segment
 	if err := h.repo.CreateTag(tag); err != nil {
-		http.Error(w, err.Error(), http.StatusInternalServerError)
+		writeTagError(w, err)
 		return
 	}
 
@@ -123,8 +145,9 @@ func (h *TagHandler) UpdateTag(w http.ResponseWriter, r *http.Request) {
 	}
 
 	var request struct {
-		Name  *string `json:"name"`
-		Color *string `json:"color"`
+		Name     *string `json:"name"`
+		Color    *string `json:"color"`
+		ParentID *string `json:"parent_id"`
 	}
 
 	if err := json.NewDecoder(r.Body).Decode(&request); err != nil {
@@ -149,14 +172,31 @@ func (h *TagHandler) UpdateTag(w http.ResponseWriter, r *http.Request) {
 			http.Error(w, "name must be 50 characters or less", http.StatusBadRequest)
 			return
 		}
-		tag.Name = *request.Name
+		if strings.Contains(*request.Name, "/") {
+			http.Error(w, "name must not contain '/'; use parent_id to move a tag", http.StatusBadRequest)
+			return
+		}
+		// Keep the tag under its current parent
+		if i := strings.LastIndex(tag.Name, "/"); i >= 0 {
+			tag.Name = tag.Name[:i+1] + *request.Name
+		} else {
+			tag.Name = *request.Name
+		}
 	}
 	if request.Color != nil {
 		tag.Color = *request.Color
 	}
 
+	// Move the tag (and its descendants) under a new parent; "" moves it to
+	// the top level
+	if request.ParentID != nil {
+		tag.ParentID = models.UUID(*request.ParentID)
+	}
+
+	// A new name or parent also renames descendants and rewrites tagged
+	// items, all in one transaction
 	if err := h.repo.UpdateTag(tag); err != nil {
-		http.Error(w, err.Error(), http.StatusInternalServerError)
+		writeTagError(w, err)
 		return
 	}
 
@@ -187,3 +227,49 @@ func (h *TagHandler) DeleteTag(w http.ResponseWriter, r *http.Request) {
 
 	w.WriteHeader(http.StatusNoContent)
 }
+
+// MergeTag handles POST /tags/{id}/merge
+// Merges the tag into the tag named by "into": items tagged with it are
+// retagged, its children move to the target and the tag is deleted.
+func (h *TagHandler) MergeTag(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	var request struct {
+		Into string `json:"into"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&request); err != nil {
+		http.Error(w, "Invalid request body", http.StatusBadRequest)
+		return
+	}
+	if request.Into == "" {
+		http.Error(w, "into is required", http.StatusBadRequest)
+		return
+	}
+
+	tag, err := h.repo.MergeTags(r.PathValue("id"), request.Into)
+	if err != nil {
+		writeTagError(w, err)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(tag)
+}
+
+// writeTagError maps tag rename, move and merge errors to HTTP statuses.
+func writeTagError(w http.ResponseWriter, err error) {
+	switch {
+	case err == sql.ErrNoRows:
+		http.Error(w, "Tag not found", http.StatusNotFound)
+	case errors.Is(err, db.ErrInvalidTagParent), errors.Is(err, db.ErrInvalidTagName),
+		errors.Is(err, db.ErrInvalidTagMerge):
+		http.Error(w, err.Error(), http.StatusBadRequest)
+	case errors.Is(err, db.ErrTagExists):
+		http.Error(w, err.Error(), http.StatusConflict)
+	default:
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+	}
+}
@@ -30,7 +30,15 @@ func setupTestDB(t *testing.T) (*sql.DB, func()) {
 			color TEXT NOT NULL DEFAULT '#3B82F6',
 			is_deleted INTEGER DEFAULT 0,
 			created_at INTEGER NOT NULL,
-			updated_at INTEGER NOT NULL
+			updated_at INTEGER NOT NULL,
+			parent_id TEXT
+		);
+
+		CREATE TABLE IF NOT EXISTS content_tags (
+			content_id TEXT NOT NULL,
+			tag_id TEXT NOT NULL,
+			assigned_at INTEGER NOT NULL,
+			PRIMARY KEY (content_id, tag_id)
 		);
 	`)
 	if err != nil {
@@ -473,3 +481,214 @@ func TestTagHandler_DeleteTag_NotFound(t *testing.T) {
 		t.Errorf("Expected status 404, got %d", w.Code)
 	}
 }
+
+func TestTagHandler_ListTags_Tree(t *testing.T) {
+	testDB, cleanup := setupTestDB(t)
+	defer cleanup()
+
+	repo := db.NewRepository(testDB)
+	handler := NewTagHandler(repo)
+
+	for _, name := range []string{"research/ml", "reading"} {
+		if err := repo.CreateTag(&models.Tag{Name: name, Color: "#3B82F6"}); err != nil {
+			t.Fatalf("Failed to create test tag: %v", err)
+		}
+	}
+
+	req := httptest.NewRequest(http.MethodGet, "/tags?tree=true", nil)
+	w := httptest.NewRecorder()
+	handler.ListTags(w, req)
+
+	if w.Code != http.StatusOK {
+		t.Fatalf("Expected status 200, got %d. Body: %s", w.Code, w.Body.String())
+	}
+
+	var tree []db.TagNode
+	if err := json.NewDecoder(w.Body).Decode(&tree); err != nil {
+		t.Fatalf("Failed to decode response: %v", err)
+	}
+	if len(tree) != 2 || tree[1].Name != "research" || len(tree[1].Children) != 1 ||
+		tree[1].Children[0].ParentID != tree[1].ID {
+		t.Errorf("Unexpected tree: %+v", tree)
+	}
+
+	req = httptest.NewRequest(http.MethodGet, "/tags?tree=maybe", nil)
+	w = httptest.NewRecorder()
+	handler.ListTags(w, req)
+	if w.Code != http.StatusBadRequest {
+		t.Errorf("Expected status 400 for invalid tree, got %d", w.Code)
+	}
+}
+
+func TestTagHandler_UpdateTag_Move(t *testing.T) {
+	testDB, cleanup := setupTestDBWithContent(t)
+	defer cleanup()
+
+	repo := db.NewRepository(testDB)
+	handler := NewTagHandler(repo)
+
+	item := &models.ContentItem{Title: "Paper", MediaType: "pdf", Tags: "research/ml"}
+	if err := repo.CreateContentItem(item); err != nil {
+		t.Fatalf("Failed to create test item: %v", err)
+	}
+	parent := &models.Tag{Name: "ai", Color: "#3B82F6"}
+	if err := repo.CreateTag(parent); err != nil {
+		t.Fatalf("Failed to create test tag: %v", err)
+	}
+	tags, _ := repo.ListContentTags(string(item.ID))
+	ml := tags[0]
+
+	move := func(parentID string) *httptest.ResponseRecorder {
+		body, _ := json.Marshal(map[string]string{"parent_id": parentID})
+		req := httptest.NewRequest(http.MethodPut, "/tags/"+string(ml.ID), bytes.NewReader(body))
+		req.SetPathValue("id", string(ml.ID))
+		w := httptest.NewRecorder()
+		handler.UpdateTag(w, req)
+		return w
+	}
+
+	w := move(string(parent.ID))
+	if w.Code != http.StatusOK {
+		t.Fatalf("Expected status 200, got %d. Body: %s", w.Code, w.Body.String())
+	}
+	var tag models.Tag
+	if err := json.NewDecoder(w.Body).Decode(&tag); err != nil {
+		t.Fatalf("Failed to decode response: %v", err)
+	}
+	if tag.Name != "ai/ml" || tag.ParentID != parent.ID {
+		t.Errorf("Moved tag = %q under %q", tag.Name, tag.ParentID)
+	}
+	if got, _ := repo.GetContentItem(string(item.ID)); got.Tags != "ai/ml" {
+		t.Errorf("Item tags after move = %q, want ai/ml", got.Tags)
+	}
+
+	if w := move(string(ml.ID)); w.Code != http.StatusBadRequest {
+		t.Errorf("Expected status 400 moving a tag under itself, got %d", w.Code)
+	}
+
+	// Renames keep the tag under its parent
+	body, _ := json.Marshal(map[string]string{"name": "deep-learning"})
+	req := httptest.NewRequest(http.MethodPut, "/tags/"+string(ml.ID), bytes.NewReader(body))
+	req.SetPathValue("id", string(ml.ID))
+	w = httptest.NewRecorder()
+	handler.UpdateTag(w, req)
+	if err := json.NewDecoder(w.Body).Decode(&tag); err != nil || tag.Name != "ai/deep-learning" {
+		t.Errorf("Renamed tag = %q (%v), want ai/deep-learning", tag.Name, err)
+	}
+
+	body, _ = json.Marshal(map[string]string{"name": "a/b"})
+	req = httptest.NewRequest(http.MethodPut, "/tags/"+string(ml.ID), bytes.NewReader(body))
+	req.SetPathValue("id", string(ml.ID))
+	w = httptest.NewRecorder()
+	handler.UpdateTag(w, req)
+	if w.Code != http.StatusBadRequest {
+		t.Errorf("Expected status 400 for name with '/', got %d", w.Code)
+	}
+}
+
+func TestTagHandler_MergeTag(t *testing.T) {
+	testDB, cleanup := setupTestDBWithContent(t)
+	defer cleanup()
+
+	repo := db.NewRepository(testDB)
+	handler := NewTagHandler(repo)
+
+	item := &models.ContentItem{Title: "Closures", MediaType: "web", Tags: "js"}
+	if err := repo.CreateContentItem(item); err != nil {
+		t.Fatalf("Failed to create test item: %v", err)
+	}
+	target := &models.Tag{Name: "javascript", Color: "#3B82F6"}
+	if err := repo.CreateTag(target); err != nil {
+		t.Fatalf("Failed to create test tag: %v", err)
+	}
+	tags, _ := repo.ListContentTags(string(item.ID))
+	source := tags[0]
+
+	merge := func(id, into string) *httptest.ResponseRecorder {
+		body, _ := json.Marshal(map[string]string{"into": into})
+		req := httptest.NewRequest(http.MethodPost, "/tags/"+id+"/merge", bytes.NewReader(body))
+		req.SetPathValue("id", id)
+		w := httptest.NewRecorder()
+		handler.MergeTag(w, req)
+		return w
+	}
+
+	if w := merge(string(source.ID), ""); w.Code != http.StatusBadRequest {
+		t.Errorf("Expected status 400 without into, got %d", w.Code)
+	}
+	if w := merge(string(source.ID), string(source.ID)); w.Code != http.StatusBadRequest {
+		t.Errorf("Expected status 400 merging into itself, got %d", w.Code)
+	}
+
+	w := merge(string(source.ID), string(target.ID))
+	if w.Code != http.StatusOK {
+		t.Fatalf("Expected status 200, got %d. Body: %s", w.Code, w.Body.String())
+	}
+	var tag models.Tag
+	if err := json.NewDecoder(w.Body).Decode(&tag); err != nil || tag.ID != target.ID {
+		t.Errorf("Expected target tag in response, got %+v (%v)", tag, err)
+	}
+	if got, _ := repo.GetContentItem(string(item.ID)); got.Tags != "javascript" {
+		t.Errorf("Item tags after merge = %q, want javascript", got.Tags)
+	}
+
+	if w := merge(string(source.ID), string(target.ID)); w.Code != http.StatusNotFound {
+		t.Errorf("Expected status 404 merging a deleted tag, got %d", w.Code)
+	}
+}
+
+func TestTagHandler_UpdateTag_NameConflict(t *testing.T) {
+	testDB, cleanup := setupTestDB(t)
+	defer cleanup()
+
+	repo := db.NewRepository(testDB)
+	handler := NewTagHandler(repo)
+
+	var tag *models.Tag
+	for _, name := range []string{"javascript", "js"} {
+		tag = &models.Tag{Name: name, Color: "#3B82F6"}
+		if err := repo.CreateTag(tag); err != nil {
+			t.Fatalf("Failed to create test tag: %v", err)
+		}
+	}
+
+	body, _ := json.Marshal(map[string]string{"name": "JavaScript"})
+	req := httptest.NewRequest(http.MethodPut, "/tags/"+string(tag.ID), bytes.NewReader(body))
+	req.SetPathValue("id", string(tag.ID))
+	w := httptest.NewRecorder()
+	handler.UpdateTag(w, req)
+
+	if w.Code != http.StatusConflict {
+		t.Errorf("Expected status 409, got %d. Body: %s", w.Code, w.Body.String())
+	}
+}
+
+func TestTagHandler_UpdateTag_RenameWithFailedMove(t *testing.T) {
+	testDB, cleanup := setupTestDB(t)
+	defer cleanup()
+
+	repo := db.NewRepository(testDB)
+	handler := NewTagHandler(repo)
+
+	tag := &models.Tag{Name: "js", Color: "#3B82F6"}
+	if err := repo.CreateTag(tag); err != nil {
+		t.Fatalf("Failed to create test tag: %v", err)
+	}
+
+	body, _ := json.Marshal(map[string]string{
+		"name":      "javascript",
+		"parent_id": "00000000-0000-4000-8000-000000000000",
+	})
+	req := httptest.NewRequest(http.MethodPut, "/tags/"+string(tag.ID), bytes.NewReader(body))
+	req.SetPathValue("id", string(tag.ID))
+	w := httptest.NewRecorder()
+	handler.UpdateTag(w, req)
+
+	if w.Code != http.StatusBadRequest {
+		t.Errorf("Expected status 400 for a missing parent, got %d. Body: %s", w.Code, w.Body.String())
+	}
+	// The rename must not land without the move
+	if got, err := repo.GetTag(string(tag.ID)); err != nil || got.Name != "js" || got.ParentID != "" {
+		t.Errorf("Tag after failed update = %+v (%v), want js at the top level", got, err)
+	}
+}
@@ -0,0 +1,111 @@
+// Package handlers provides REST API handlers for the trash bin.
+package handlers
+
+import (
+	"database/sql"
+	"encoding/json"
+	"net/http"
+	"strconv"
+
+	"github.com/kimhsiao/memonexus/backend/internal/db"
+)
+
+// TrashHandler handles listing, restoring and purging deleted content.
+type TrashHandler struct {
+	repo *db.Repository
+}
+
+// NewTrashHandler creates a new TrashHandler.
+func NewTrashHandler(repo *db.Repository) *TrashHandler {
+	return &TrashHandler{repo: repo}
+}
+
+// ListTrash handles GET /trash
+// Returns deleted items, most recently deleted first.
+func (h *TrashHandler) ListTrash(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	page, _ := strconv.Atoi(r.URL.Query().Get("page"))
+	if page < 1 {
+		page = 1
+	}
+	perPage, _ := strconv.Atoi(r.URL.Query().Get("per_page"))
+	if perPage < 1 || perPage > 100 {
+		perPage = 20
+	}
+
+	items, err := h.repo.ListTrash(perPage, (page-1)*perPage)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(map[string]interface{}{
+		"items":    items,
+		"page":     page,
+		"per_page": perPage,
+	})
+}
+
+// EmptyTrash handles DELETE /trash
+// Permanently deletes every item in the trash.
+func (h *TrashHandler) EmptyTrash(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodDelete {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	purged, err := h.repo.EmptyTrash()
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(map[string]interface{}{"purged": purged})
+}
+
+// RestoreItem handles POST /trash/{id}/restore
+func (h *TrashHandler) RestoreItem(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	item, err := h.repo.RestoreContentItem(r.PathValue("id"))
+	if err != nil {
+		if err == sql.ErrNoRows {
+			http.Error(w, "Item not found in trash", http.StatusNotFound)
+			return
+		}
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(item)
+}
+
+// PurgeItem handles DELETE /trash/{id}
+// Permanently deletes one item in the trash.
+func (h *TrashHandler) PurgeItem(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodDelete {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	if err := h.repo.PurgeContentItem(r.PathValue("id")); err != nil {
+		if err == sql.ErrNoRows {
+			http.Error(w, "Item not found in trash", http.StatusNotFound)
+			return
+		}
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
@@ -0,0 +1,114 @@
+// Package handlers tests for trash bin endpoints.
+package handlers
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/kimhsiao/memonexus/backend/internal/db"
+	"github.com/kimhsiao/memonexus/backend/internal/models"
+)
+
+func TestTrashHandler(t *testing.T) {
+	testDB, cleanup := setupTestDBWithContent(t)
+	defer cleanup()
+
+	repo := db.NewRepository(testDB)
+	handler := NewTrashHandler(repo)
+
+	ids := make([]string, 3)
+	for i := range ids {
+		item := &models.ContentItem{Title: "Trashed", MediaType: "web", Tags: "go"}
+		if err := repo.CreateContentItem(item); err != nil {
+			t.Fatalf("Failed to create test item: %v", err)
+		}
+		if err := repo.DeleteContentItem(string(item.ID)); err != nil {
+			t.Fatalf("Failed to delete test item: %v", err)
+		}
+		ids[i] = string(item.ID)
+	}
+
+	// List
+	req := httptest.NewRequest(http.MethodGet, "/trash?per_page=2", nil)
+	w := httptest.NewRecorder()
+	handler.ListTrash(w, req)
+	var list struct {
+		Items   []models.ContentItem `json:"items"`
+		PerPage int                  `json:"per_page"`
+	}
+	if err := json.NewDecoder(w.Body).Decode(&list); err != nil {
+		t.Fatalf("Failed to decode response: %v", err)
+	}
+	if w.Code != http.StatusOK || len(list.Items) != 2 || list.PerPage != 2 {
+		t.Errorf("List: got %d %+v", w.Code, list)
+	}
+
+	// Restore
+	req = httptest.NewRequest(http.MethodPost, "/trash/"+ids[0]+"/restore", nil)
+	req.SetPathValue("id", ids[0])
+	w = httptest.NewRecorder()
+	handler.RestoreItem(w, req)
+	if w.Code != http.StatusOK {
+		t.Fatalf("Restore: expected status 200, got %d. Body: %s", w.Code, w.Body.String())
+	}
+	var restored models.ContentItem
+	if err := json.NewDecoder(w.Body).Decode(&restored); err != nil {
+		t.Fatalf("Failed to decode response: %v", err)
+	}
+	if restored.IsDeleted || restored.Version != 3 {
+		t.Errorf("Expected restored item at version 3, got %+v", restored)
+	}
+
+	// Restoring or purging a live item is not found
+	req = httptest.NewRequest(http.MethodPost, "/trash/"+ids[0]+"/restore", nil)
+	req.SetPathValue("id", ids[0])
+	w = httptest.NewRecorder()
+	handler.RestoreItem(w, req)
+	if w.Code != http.StatusNotFound {
+		t.Errorf("Expected status 404 restoring live item, got %d", w.Code)
+	}
+
+	req = httptest.NewRequest(http.MethodDelete, "/trash/"+ids[0], nil)
+	req.SetPathValue("id", ids[0])
+	w = httptest.NewRecorder()
+	handler.PurgeItem(w, req)
+	if w.Code != http.StatusNotFound {
+		t.Errorf("Expected status 404 purging live item, got %d", w.Code)
+	}
+
+	// Purge
+	req = httptest.NewRequest(http.MethodDelete, "/trash/"+ids[1], nil)
+	req.SetPathValue("id", ids[1])
+	w = httptest.NewRecorder()
+	handler.PurgeItem(w, req)
+	if w.Code != http.StatusNoContent {
+		t.Fatalf("Purge: expected status 204, got %d. Body: %s", w.Code, w.Body.String())
+	}
+
+	// Empty
+	req = httptest.NewRequest(http.MethodDelete, "/trash", nil)
+	w = httptest.NewRecorder()
+	handler.EmptyTrash(w, req)
+	var emptied map[string]int
+	if err := json.NewDecoder(w.Body).Decode(&emptied); err != nil {
+		t.Fatalf("Failed to decode response: %v", err)
+	}
+	if w.Code != http.StatusOK || emptied["purged"] != 1 {
+		t.Errorf("Empty: got %d %v, want 1 purged", w.Code, emptied)
+	}
+	if _, err := repo.GetContentItem(ids[0]); err != nil {
+		t.Errorf("Restored item was purged: %v", err)
+	}
+}
+
+func TestTrashHandler_MethodNotAllowed(t *testing.T) {
+	handler := NewTrashHandler(nil)
+
+	w := httptest.NewRecorder()
+	handler.ListTrash(w, httptest.NewRequest(http.MethodPost, "/trash", nil))
+	if w.Code != http.StatusMethodNotAllowed {
+		t.Errorf("Expected status 405, got %d", w.Code)
+	}
+}
@@ -3,9 +3,13 @@
 package main
 
 import (
+	"context"
 	"log"
 	"net/http"
 	"os"
+	"path/filepath"
+	"strconv"
+	"time"
 
 	"github.com/kimhsiao/memonexus/backend/internal/db"
 	"github.com/kimhsiao/memonexus/backend/internal/logging"
@@ -40,13 +44,53 @@ func main() {
 
 	logging.Info("Database opened", map[string]interface{}{"path": dataDir})
 
-	// Run migrations
-	migrator := db.NewMigrator(database.DB, "./internal/db/migrations")
+	// Run migrations embedded in the binary
+	migrator := db.NewEmbeddedMigrator(database.DB)
 	if err := migrator.Initialize(); err != nil {
 		log.Fatalf("Failed to initialize migrator: %v", err)
 	}
 
-	if err := migrator.Up(); err != nil {
+	previousVersion, _ := migrator.CurrentVersion()
+	drift, err := migrator.VerifyChecksums()
+	if err != nil {
+		log.Fatalf("Failed to verify migrations: %v", err)
+	}
+	for _, d := range drift {
+		logging.Warn("Applied migration differs from embedded migration",
+			map[string]interface{}{
+				"version":     d.Version,
+				"description": d.Description,
+				"applied":     d.Applied,
+				"current":     d.Current,
+			})
+	}
+
+	// MIGRATE_DRY_RUN lists pending migrations and exits without applying them
+	if dryRun, _ := strconv.ParseBool(os.Getenv("MIGRATE_DRY_RUN")); dryRun {
+		pending, err := migrator.Pending()
+		if err != nil {
+			log.Fatalf("Failed to list pending migrations: %v", err)
+		}
+		for _, mig := range pending {
+			logging.Info("Pending migration",
+				map[string]interface{}{"version": mig.Version, "description": mig.Description})
+		}
+		logging.Info("Migration dry run completed", map[string]interface{}{"pending": len(pending)})
+		return
+	}
+
+	// MIGRATE_TO migrates up or down to a schema version instead of the
+	// latest, then exits: the server only runs against the latest schema
+	migrateTo := os.Getenv("MIGRATE_TO")
+	if migrateTo != "" {
+		target, err := strconv.Atoi(migrateTo)
+		if err != nil {
+			log.Fatalf("Invalid MIGRATE_TO: %v", err)
+		}
+		if err := migrator.MigrateTo(target); err != nil {
+			log.Fatalf("Failed to migrate to version %d: %v", target, err)
+		}
+	} else if err := migrator.Up(); err != nil {
 		log.Fatalf("Failed to apply migrations: %v", err)
 	}
 
@@ -56,6 +100,60 @@ func main() {
 	// Create repository
 	repository := db.NewRepository(database.DB)
 
+	// Parse links in content saved before links were tracked
+	if previousVersion < db.ContentLinksVersion && currentVersion >= db.ContentLinksVersion {
+		parsed, err := repository.RebuildContentLinks()
+		if err != nil {
+			log.Fatalf("Failed to parse content links: %v", err)
+		}
+		logging.Info("Content links parsed", map[string]interface{}{"items": parsed})
+	}
+	// A targeted migration exits only now, so links are parsed when it
+	// crosses their version
+	if migrateTo != "" {
+		logging.Info("Targeted migration completed", map[string]interface{}{"version": currentVersion})
+		return
+	}
+
+	// Purge items left in the trash longer than TRASH_RETENTION_DAYS
+	// (0 keeps them forever) and media files no item references
+	retentionDays := services.DefaultTrashRetentionDays
+	if v := os.Getenv("TRASH_RETENTION_DAYS"); v != "" {
+		days, err := strconv.Atoi(v)
+		if err != nil {
+			log.Fatalf("Invalid TRASH_RETENTION_DAYS: %v", err)
+		}
+		retentionDays = days
+	}
+	// Media files live in MEDIA_DIR; content ingestion writes there and
+	// the retention job removes the files no item references
+	mediaDir := os.Getenv("MEDIA_DIR")
+	if mediaDir == "" {
+		mediaDir = filepath.Join(dataDir, "media")
+	}
+	contentService, err := services.NewContentService(database.DB, mediaDir)
+	if err != nil {
+		log.Fatalf("Failed to open media storage: %v", err)
+	}
+	trashRetention := services.NewTrashRetention(repository, contentService.Storage(), retentionDays)
+	trashRetention.Start(context.Background(), 24*time.Hour)
+	defer trashRetention.Stop()
+
+	// Database backups go to BACKUP_DIR, keeping the newest
+	// BACKUP_RETENTION_COUNT (0 keeps all)
+	backupDir := os.Getenv("BACKUP_DIR")
+	if backupDir == "" {
+		backupDir = filepath.Join(dataDir, "backups")
+	}
+	backupRetention := handlers.DefaultBackupRetentionCount
+	if v := os.Getenv("BACKUP_RETENTION_COUNT"); v != "" {
+		count, err := strconv.Atoi(v)
+		if err != nil {
+			log.Fatalf("Invalid BACKUP_RETENTION_COUNT: %v", err)
+		}
+		backupRetention = count
+	}
+
 	// Create WebSocket hub
 	wsHub := NewWSHub()
 
@@ -72,6 +170,11 @@ func main() {
 	contentHandler := handlers.NewContentHandler(repository)
 	tagHandler := handlers.NewTagHandler(repository)
 	searchHandler := handlers.NewSearchHandler(repository)
+	savedSearchHandler := handlers.NewSavedSearchHandler(repository)
+	trashHandler := handlers.NewTrashHandler(repository)
+	collectionHandler := handlers.NewCollectionHandler(repository)
+	annotationHandler := handlers.NewAnnotationHandler(repository)
+	backupHandler := handlers.NewBackupHandler(database, backupDir, backupRetention)
 	aiHandler := handlers.NewAIHandler(repository, analysisService, os.Getenv("MACHINE_ID"))
 	aiHandler.SetWebSocketHub(wsHub) // T145-T147: Enable WebSocket events
 
@@ -118,6 +221,172 @@ func main() {
 		}
 	})
 
+	// Content tag assignment routes
+	mux.HandleFunc("/api/content/{id}/tags", func(w http.ResponseWriter, r *http.Request) {
+		switch r.Method {
+		case http.MethodGet:
+			contentHandler.ListContentTags(w, r)
+		case http.MethodPost:
+			contentHandler.AssignContentTags(w, r)
+		default:
+			http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		}
+	})
+	mux.HandleFunc("/api/content/{id}/tags/{name}", func(w http.ResponseWriter, r *http.Request) {
+		contentHandler.UnassignContentTag(w, r)
+	})
+
+	// Revision history routes
+	mux.HandleFunc("/api/content/{id}/revisions", func(w http.ResponseWriter, r *http.Request) {
+		contentHandler.ListRevisions(w, r)
+	})
+	mux.HandleFunc("/api/content/{id}/revisions/diff", func(w http.ResponseWriter, r *http.Request) {
+		contentHandler.DiffRevisions(w, r)
+	})
+	mux.HandleFunc("/api/content/{id}/revisions/{rev}/restore", func(w http.ResponseWriter, r *http.Request) {
+		contentHandler.RestoreRevision(w, r)
+	})
+
+	// Link routes
+	mux.HandleFunc("/api/content/{id}/links", func(w http.ResponseWriter, r *http.Request) {
+		contentHandler.ListLinks(w, r)
+	})
+	mux.HandleFunc("/api/content/{id}/backlinks", func(w http.ResponseWriter, r *http.Request) {
+		contentHandler.ListBacklinks(w, r)
+	})
+	mux.HandleFunc("/api/links/unresolved", func(w http.ResponseWriter, r *http.Request) {
+		contentHandler.ListUnresolvedLinks(w, r)
+	})
+
+	// Property routes
+	mux.HandleFunc("/api/content/{id}/properties", func(w http.ResponseWriter, r *http.Request) {
+		contentHandler.ListProperties(w, r)
+	})
+	mux.HandleFunc("/api/content/{id}/properties/{key}", func(w http.ResponseWriter, r *http.Request) {
+		switch r.Method {
+		case http.MethodPut:
+			contentHandler.SetProperty(w, r)
+		case http.MethodDelete:
+			contentHandler.DeleteProperty(w, r)
+		default:
+			http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		}
+	})
+
+	// Triage state routes
+	mux.HandleFunc("/api/content/{id}/state", func(w http.ResponseWriter, r *http.Request) {
+		contentHandler.UpdateState(w, r)
+	})
+	mux.HandleFunc("/api/content/bulk/state", func(w http.ResponseWriter, r *http.Request) {
+		contentHandler.BulkUpdateState(w, r)
+	})
+
+	// Annotation routes
+	mux.HandleFunc("/api/content/{id}/annotations", func(w http.ResponseWriter, r *http.Request) {
+		switch r.Method {
+		case http.MethodGet:
+			annotationHandler.ListAnnotations(w, r)
+		case http.MethodPost:
+			annotationHandler.CreateAnnotation(w, r)
+		default:
+			http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		}
+	})
+	mux.HandleFunc("/api/annotations/search", func(w http.ResponseWriter, r *http.Request) {
+		annotationHandler.SearchAnnotations(w, r)
+	})
+	mux.HandleFunc("/api/annotations/{id}", func(w http.ResponseWriter, r *http.Request) {
+		switch r.Method {
+		case http.MethodGet:
+			annotationHandler.GetAnnotation(w, r)
+		case http.MethodPut:
+			annotationHandler.UpdateAnnotation(w, r)
+		case http.MethodDelete:
+			annotationHandler.DeleteAnnotation(w, r)
+		default:
+			http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		}
+	})
+
+	// Trash routes
+	mux.HandleFunc("/api/trash", func(w http.ResponseWriter, r *http.Request) {
+		switch r.Method {
+		case http.MethodGet:
+			trashHandler.ListTrash(w, r)
+		case http.MethodDelete:
+			trashHandler.EmptyTrash(w, r)
+		default:
+			http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		}
+	})
+	mux.HandleFunc("/api/trash/{id}", func(w http.ResponseWriter, r *http.Request) {
+		trashHandler.PurgeItem(w, r)
+	})
+	mux.HandleFunc("/api/trash/{id}/restore", func(w http.ResponseWriter, r *http.Request) {
+		trashHandler.RestoreItem(w, r)
+	})
+
+	// Collection routes
+	mux.HandleFunc("/api/collections", func(w http.ResponseWriter, r *http.Request) {
+		switch r.Method {
+		case http.MethodGet:
+			collectionHandler.ListCollections(w, r)
+		case http.MethodPost:
+			collectionHandler.CreateCollection(w, r)
+		default:
+			http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		}
+	})
+	mux.HandleFunc("/api/collections/order", func(w http.ResponseWriter, r *http.Request) {
+		collectionHandler.ReorderCollections(w, r)
+	})
+	mux.HandleFunc("/api/collections/{id}", func(w http.ResponseWriter, r *http.Request) {
+		switch r.Method {
+		case http.MethodGet:
+			collectionHandler.GetCollection(w, r)
+		case http.MethodPut:
+			collectionHandler.UpdateCollection(w, r)
+		case http.MethodDelete:
+			collectionHandler.DeleteCollection(w, r)
+		default:
+			http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		}
+	})
+	mux.HandleFunc("/api/collections/{id}/items", func(w http.ResponseWriter, r *http.Request) {
+		switch r.Method {
+		case http.MethodGet:
+			collectionHandler.ListCollectionItems(w, r)
+		case http.MethodPost:
+			collectionHandler.AddCollectionItems(w, r)
+		default:
+			http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		}
+	})
+	mux.HandleFunc("/api/collections/{id}/items/order", func(w http.ResponseWriter, r *http.Request) {
+		collectionHandler.ReorderCollectionItems(w, r)
+	})
+	mux.HandleFunc("/api/collections/{id}/items/{item_id}", func(w http.ResponseWriter, r *http.Request) {
+		collectionHandler.RemoveCollectionItem(w, r)
+	})
+
+	// Backup routes
+	mux.HandleFunc("/api/backups", func(w http.ResponseWriter, r *http.Request) {
+		switch r.Method {
+		case http.MethodGet:
+			backupHandler.ListBackups(w, r)
+		case http.MethodPost:
+			backupHandler.CreateBackup(w, r)
+		default:
+			http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		}
+	})
+	mux.HandleFunc("/api/backups/{id}", func(w http.ResponseWriter, r *http.Request) {
+		backupHandler.DeleteBackup(w, r)
+	})
+	mux.HandleFunc("/api/backups/{id}/restore", func(w http.ResponseWriter, r *http.Request) {
+		backupHandler.RestoreBackup(w, r)
+	})
+
 	// Tag routes
 	mux.HandleFunc("/api/tags", func(w http.ResponseWriter, r *http.Request) {
 		switch r.Method {
@@ -142,11 +411,75 @@ func main() {
 			http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
 		}
 	})
+	mux.HandleFunc("/api/tags/{id}/merge", func(w http.ResponseWriter, r *http.Request) {
+		tagHandler.MergeTag(w, r)
+	})
 
 	// Search route
 	mux.HandleFunc("/api/search", func(w http.ResponseWriter, r *http.Request) {
 		searchHandler.Search(w, r)
 	})
+	mux.HandleFunc("/api/search/suggest", func(w http.ResponseWriter, r *http.Request) {
+		searchHandler.Suggest(w, r)
+	})
+	mux.HandleFunc("/api/search/autocomplete", func(w http.ResponseWriter, r *http.Request) {
+		searchHandler.Autocomplete(w, r)
+	})
+
+	// Search history routes
+	mux.HandleFunc("/api/search/history", func(w http.ResponseWriter, r *http.Request) {
+		switch r.Method {
+		case http.MethodGet:
+			searchHandler.ListHistory(w, r)
+		case http.MethodDelete:
+			searchHandler.ClearHistory(w, r)
+		default:
+			http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		}
+	})
+	mux.HandleFunc("/api/search/history/settings", func(w http.ResponseWriter, r *http.Request) {
+		switch r.Method {
+		case http.MethodGet:
+			searchHandler.GetHistorySettings(w, r)
+		case http.MethodPut:
+			searchHandler.UpdateHistorySettings(w, r)
+		default:
+			http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		}
+	})
+	mux.HandleFunc("/api/search/history/{id}", func(w http.ResponseWriter, r *http.Request) {
+		searchHandler.DeleteHistoryEntry(w, r)
+	})
+	mux.HandleFunc("/api/search/history/{id}/click", func(w http.ResponseWriter, r *http.Request) {
+		searchHandler.RecordHistoryClick(w, r)
+	})
+
+	// Saved search routes (smart collections)
+	mux.HandleFunc("/api/saved-searches", func(w http.ResponseWriter, r *http.Request) {
+		switch r.Method {
+		case http.MethodGet:
+			savedSearchHandler.ListSavedSearches(w, r)
+		case http.MethodPost:
+			savedSearchHandler.CreateSavedSearch(w, r)
+		default:
+			http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		}
+	})
+	mux.HandleFunc("/api/saved-searches/{id}", func(w http.ResponseWriter, r *http.Request) {
+		switch r.Method {
+		case http.MethodGet:
+			savedSearchHandler.GetSavedSearch(w, r)
+		case http.MethodPut:
+			savedSearchHandler.UpdateSavedSearch(w, r)
+		case http.MethodDelete:
+			savedSearchHandler.DeleteSavedSearch(w, r)
+		default:
+			http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		}
+	})
+	mux.HandleFunc("/api/saved-searches/{id}/results", func(w http.ResponseWriter, r *http.Request) {
+		savedSearchHandler.RunSavedSearch(w, r)
+	})
 
 	// AI configuration routes (T137-T139)
 	mux.HandleFunc("/api/ai/config", func(w http.ResponseWriter, r *http.Request) {
@@ -12,6 +12,7 @@ package main
 */
 import "C"
 import (
+	"context"
 	"database/sql"
 	"encoding/json"
 	"fmt"
@@ -43,8 +44,8 @@ func Init() {
 			return
 		}
 
-		// Run migrations
-		migrator := db.NewMigrator(database.DB, "./internal/db/migrations")
+		// Run migrations embedded in the library
+		migrator := db.NewEmbeddedMigrator(database.DB)
 		if err := migrator.Initialize(); err != nil {
 			setLastError(fmt.Sprintf("Failed to initialize migrator: %v", err))
 			return
@@ -124,7 +125,8 @@ func ContentCreate(title, contentText, sourceURL, mediaType, tags, contentHash *
 }
 
 //export ContentList
-// ContentList lists content items with pagination.
+// ContentList lists content items with pagination, pinned items first.
+// Archived items are hidden, as in the REST listing.
 // Returns JSON array that must be freed by the caller.
 func ContentList(limit, offset int32) *C.char {
 	if repo == nil {
@@ -132,7 +134,8 @@ func ContentList(limit, offset int32) *C.char {
 		return nil
 	}
 
-	items, err := repo.ListContentItems(int(limit), int(offset), "")
+	items, err := repo.ListContentItemsFiltered(context.Background(), int(limit), int(offset),
+		db.NewFilterBuilder().Archived(false))
 	if err != nil {
 		setLastError(fmt.Sprintf("Failed to list items: %v", err))
 		return nil
@@ -143,6 +146,45 @@ func ContentList(limit, offset int32) *C.char {
 		"items": items,
 		"total": len(items),
 	}
+	if len(items) > 0 && len(items) == int(limit) {
+		response["next_cursor"] = db.ListCursor(items[len(items)-1])
+	}
+
+	data, err := json.Marshal(response)
+	if err != nil {
+		setLastError(fmt.Sprintf("Failed to serialize: %v", err))
+		return nil
+	}
+
+	return C.CString(string(data))
+}
+
+//export ContentListAfter
+// ContentListAfter lists content items after a cursor from a previous
+// ContentList or ContentListAfter call (empty cursor starts from the first
+// item). Archived items are hidden, as in ContentList.
+// Returns JSON with items and next_cursor that must be freed by the caller.
+func ContentListAfter(cursor *C.char, limit int32) *C.char {
+	if repo == nil {
+		setLastError("Repository not initialized")
+		return nil
+	}
+
+	page, err := repo.ListContentItemsPageFiltered(C.GoString(cursor), int(limit),
+		db.NewFilterBuilder().Archived(false))
+	if err != nil {
+		setLastError(fmt.Sprintf("Failed to list items: %v", err))
+		return nil
+	}
+
+	// Build response
+	response := map[string]interface{}{
+		"items": page.Items,
+		"total": len(page.Items),
+	}
+	if page.NextCursor != "" {
+		response["next_cursor"] = page.NextCursor
+	}
 
 	data, err := json.Marshal(response)
 	if err != nil {
@@ -250,86 +292,64 @@ func ContentDelete(id *C.char) int32 {
 
 //export Search
 // Search performs full-text search using FTS5.
+// Accepts the same structured query syntax as the REST search endpoint.
 // Returns JSON array that must be freed by the caller.
 func Search(query *C.char, limit int32) *C.char {
-	if database == nil {
+	if repo == nil {
 		setLastError("Database not initialized")
 		return nil
 	}
 
 	queryStr := C.GoString(query)
 
-	// Build FTS5 query with BM25 ranking
-	sqlQuery := `
-		SELECT
-			ci.id, ci.title, ci.content_text, ci.source_url, ci.media_type,
-			ci.tags, ci.summary, ci.created_at, ci.updated_at, ci.version,
-			bm25(content_fts) as relevance
-		FROM content_items ci
-		INNER JOIN content_fts ON ci.rowid = content_fts.rowid
-		WHERE content_fts MATCH ? AND ci.is_deleted = 0
-		ORDER BY bm25(content_fts) LIMIT ?
-	`
-
-	rows, err := database.DB.Query(sqlQuery, queryStr, limit)
+	parsed, err := db.ParseQuery(queryStr)
 	if err != nil {
-		setLastError(fmt.Sprintf("Search failed: %v", err))
+		setLastError(fmt.Sprintf("Invalid query: %v", err))
 		return nil
 	}
-	defer rows.Close()
 
-	var results []map[string]interface{}
-	for rows.Next() {
-		var id, title, contentText, mediaType, tags string
-		var sourceURL, summary sql.NullString
-		var createdAt, updatedAt, version int64
-		var relevance float64
+	opts := &db.SearchOptions{Limit: int(limit)}
+	parsed.Apply(opts)
 
-		err := rows.Scan(
-			&id, &title, &contentText, &sourceURL, &mediaType,
-			&tags, &summary, &createdAt, &updatedAt, &version,
-			&relevance,
-		)
-		if err != nil {
-			setLastError(fmt.Sprintf("Failed to scan row: %v", err))
-			return nil
-		}
+	response, err := repo.Search(opts)
+	if err != nil {
+		setLastError(fmt.Sprintf("Search failed: %v", err))
+		return nil
+	}
 
+	results := make([]map[string]interface{}, 0, len(response.Results))
+	for _, result := range response.Results {
 		item := map[string]interface{}{
-			"id":          id,
-			"title":       title,
-			"content_text": contentText,
-			"media_type":  mediaType,
-			"tags":        tags,
-			"created_at":  createdAt,
-			"updated_at":  updatedAt,
-			"version":     version,
-			"relevance":   relevance,
+			"id":          result.Item.ID,
+			"title":       result.Item.Title,
+			"content_text": result.Item.ContentText,
+			"media_type":  result.Item.MediaType,
+			"tags":        result.Item.Tags,
+			"created_at":  result.Item.CreatedAt,
+			"updated_at":  result.Item.UpdatedAt,
+			"version":     result.Item.Version,
+			"relevance":   result.Relevance,
+			"score":       result.Score,
 		}
 
-		if sourceURL.Valid {
-			item["source_url"] = sourceURL.String
+		if result.Item.SourceURL != "" {
+			item["source_url"] = result.Item.SourceURL
 		}
-		if summary.Valid {
-			item["summary"] = summary.String
+		if result.Item.Summary != "" {
+			item["summary"] = result.Item.Summary
 		}
 
 		results = append(results, item)
 	}
 
-	if err := rows.Err(); err != nil {
-		setLastError(fmt.Sprintf("Failed to iterate rows: %v", err))
-		return nil
-	}
-
 	// Build response
-	response := map[string]interface{}{
+	payload := map[string]interface{}{
 		"results": results,
-		"total":   len(results),
+		"total":   response.Total,
 		"query":   queryStr,
 	}
 
-	data, err := json.Marshal(response)
+	data, err := json.Marshal(payload)
 	if err != nil {
 		setLastError(fmt.Sprintf("Failed to serialize: %v", err))
 		return nil
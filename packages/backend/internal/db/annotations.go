@@ -0,0 +1,404 @@
+// Package db provides annotation (highlight) persistence.
+package db
+
+import (
+	"database/sql"
+	"errors"
+	"fmt"
+	"slices"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/kimhsiao/memonexus/backend/internal/models"
+	"github.com/kimhsiao/memonexus/backend/internal/uuid"
+)
+
+// Annotations are anchored by character offsets into the item text and by
+// a fingerprint: the quoted text and up to annotationContextLength
+// characters on either side. Stored anchors are left alone when the item is
+// edited; reads look for the quote again, preferring the occurrence whose
+// surroundings match best, then the one nearest the saved offset.
+
+// annotationContextLength is the number of characters kept on either side
+// of the quote.
+const annotationContextLength = 32
+
+// annotationColumns lists the annotations columns read by scanAnnotation.
+const annotationColumns = `id, content_id, start_offset, end_offset, quote, prefix, suffix,
+	note, color, is_deleted, created_at, updated_at, version`
+
+// ErrInvalidAnnotationAnchor is returned when an annotation's offsets do
+// not select text in its item, or its quote cannot be found there.
+var ErrInvalidAnnotationAnchor = errors.New("invalid annotation anchor")
+
+// AnnotationMatch is an annotation found by SearchAnnotations.
+type AnnotationMatch struct {
+	*models.Annotation
+	ContentTitle string `json:"content_title"`
+	Snippet      string `json:"snippet"` // Note excerpt with matches in <mark>
+}
+
+// scanAnnotation scans one row selected with annotationColumns.
+func scanAnnotation(row rowScanner) (*models.Annotation, error) {
+	var a models.Annotation
+	err := row.Scan(&a.ID, &a.ContentID, &a.StartOffset, &a.EndOffset, &a.Quote, &a.Prefix,
+		&a.Suffix, &a.Note, &a.Color, &a.IsDeleted, &a.CreatedAt, &a.UpdatedAt, &a.Version)
+	if err != nil {
+		return nil, err
+	}
+	return &a, nil
+}
+
+// ValidateAnnotation checks an annotation's note and color, defaulting the
+// color to the first of models.AnnotationColors.
+func ValidateAnnotation(a *models.Annotation) error {
+	if a.Color == "" {
+		a.Color = models.AnnotationColors[0]
+	}
+	if !slices.Contains(models.AnnotationColors, a.Color) {
+		return fmt.Errorf("color must be one of %s", strings.Join(models.AnnotationColors, ", "))
+	}
+	if len([]rune(a.Note)) > 10000 {
+		return fmt.Errorf("note must be 10000 characters or less")
+	}
+	return nil
+}
+
+// locateAnnotation finds the quote of a in text, returning its start
+// offset. The saved offsets win if they still select the quote.
+func locateAnnotation(text []rune, a *models.Annotation) (int, bool) {
+	quote := []rune(a.Quote)
+	if len(quote) == 0 || len(quote) > len(text) {
+		return 0, false
+	}
+	if a.StartOffset >= 0 && a.StartOffset+len(quote) <= len(text) &&
+		slices.Equal(text[a.StartOffset:a.StartOffset+len(quote)], quote) {
+		return a.StartOffset, true
+	}
+
+	prefix, suffix := []rune(a.Prefix), []rune(a.Suffix)
+	best, bestScore, bestDistance := -1, -1, 0
+	for i := 0; i+len(quote) <= len(text); i++ {
+		if text[i] != quote[0] || !slices.Equal(text[i:i+len(quote)], quote) {
+			continue
+		}
+		score := commonSuffixLength(text[:i], prefix) + commonPrefixLength(text[i+len(quote):], suffix)
+		distance := i - a.StartOffset
+		if distance < 0 {
+			distance = -distance
+		}
+		if score > bestScore || (score == bestScore && distance < bestDistance) {
+			best, bestScore, bestDistance = i, score, distance
+		}
+	}
+	return best, best >= 0
+}
+
+// commonPrefixLength returns the length of the common prefix of a and b.
+func commonPrefixLength(a, b []rune) int {
+	n := 0
+	for n < len(a) && n < len(b) && a[n] == b[n] {
+		n++
+	}
+	return n
+}
+
+// commonSuffixLength returns the length of the common suffix of a and b.
+func commonSuffixLength(a, b []rune) int {
+	n := 0
+	for n < len(a) && n < len(b) && a[len(a)-1-n] == b[len(b)-1-n] {
+		n++
+	}
+	return n
+}
+
+// anchorAnnotation moves a to where its quote is found in text, or marks
+// it orphaned.
+func anchorAnnotation(text []rune, a *models.Annotation) {
+	start, ok := locateAnnotation(text, a)
+	if !ok {
+		a.Orphaned = true
+		return
+	}
+	a.EndOffset = start + len([]rune(a.Quote))
+	a.StartOffset = start
+}
+
+// placeAnnotation sets the anchor of a in text. If a has a quote, the
+// quote is looked for first, so offsets computed against a slightly
+// different text still land on it; the fingerprint is then taken from
+// text at the resulting offsets.
+func placeAnnotation(text []rune, a *models.Annotation) error {
+	if a.Quote != "" {
+		start, ok := locateAnnotation(text, a)
+		if !ok {
+			return fmt.Errorf("%w: quote not found in the item text", ErrInvalidAnnotationAnchor)
+		}
+		a.EndOffset = start + len([]rune(a.Quote))
+		a.StartOffset = start
+	}
+	if a.StartOffset < 0 || a.EndOffset <= a.StartOffset || a.EndOffset > len(text) {
+		return fmt.Errorf("%w: offsets %d-%d do not select text in the item (%d characters)",
+			ErrInvalidAnnotationAnchor, a.StartOffset, a.EndOffset, len(text))
+	}
+
+	a.Quote = string(text[a.StartOffset:a.EndOffset])
+	a.Prefix = string(text[max(0, a.StartOffset-annotationContextLength):a.StartOffset])
+	a.Suffix = string(text[a.EndOffset:min(len(text), a.EndOffset+annotationContextLength)])
+	a.Orphaned = false
+	return nil
+}
+
+// rowQuerier is implemented by *sql.DB and *sql.Tx.
+type rowQuerier interface {
+	QueryRow(query string, args ...interface{}) *sql.Row
+}
+
+// annotatedText returns the text of a live content item as characters.
+// Returns sql.ErrNoRows if the item does not exist.
+func annotatedText(q rowQuerier, contentID models.UUID) ([]rune, error) {
+	var text string
+	err := q.QueryRow(`SELECT content_text FROM content_items WHERE id = ? AND is_deleted = 0`, contentID).Scan(&text)
+	if err != nil {
+		return nil, err
+	}
+	return []rune(text), nil
+}
+
+// CreateAnnotation creates an annotation on a content item, anchored at
+// its offsets (or its quote, if set). Returns sql.ErrNoRows if the item
+// does not exist and ErrInvalidAnnotationAnchor if the anchor does not fit.
+func (r *Repository) CreateAnnotation(a *models.Annotation) error {
+	tx, err := r.db.Begin()
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	text, err := annotatedText(tx, a.ContentID)
+	if err != nil {
+		return err
+	}
+	if err := placeAnnotation(text, a); err != nil {
+		return err
+	}
+
+	now := time.Now().Unix()
+	a.ID = models.UUID(uuid.New())
+	a.IsDeleted = false
+	a.CreatedAt = now
+	a.UpdatedAt = now
+	a.Version = 1
+
+	_, err = tx.Exec(`
+	INSERT INTO annotations (id, content_id, start_offset, end_offset, quote, prefix, suffix,
+		note, color, is_deleted, created_at, updated_at, version)
+	VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, 0, ?, ?, ?)
+	`, a.ID, a.ContentID, a.StartOffset, a.EndOffset, a.Quote, a.Prefix, a.Suffix,
+		a.Note, a.Color, a.CreatedAt, a.UpdatedAt, a.Version)
+	if err != nil {
+		return err
+	}
+	return tx.Commit()
+}
+
+// GetAnnotation retrieves an annotation anchored in the current text of
+// its item. Returns sql.ErrNoRows if the annotation or its item does not
+// exist or was deleted.
+func (r *Repository) GetAnnotation(id string) (*models.Annotation, error) {
+	a, err := scanAnnotation(r.db.QueryRow(`
+	SELECT `+annotationColumns+` FROM annotations WHERE id = ? AND is_deleted = 0
+	`, id))
+	if err != nil {
+		return nil, err
+	}
+	text, err := annotatedText(r.db, a.ContentID)
+	if err != nil {
+		return nil, err
+	}
+	anchorAnnotation(text, a)
+	return a, nil
+}
+
+// ListAnnotations returns the live annotations of a content item in text
+// order, anchored in its current text; orphaned annotations come last.
+// Returns sql.ErrNoRows if the item does not exist.
+func (r *Repository) ListAnnotations(contentID string) ([]*models.Annotation, error) {
+	text, err := annotatedText(r.db, models.UUID(contentID))
+	if err != nil {
+		return nil, err
+	}
+	annotations, err := r.queryAnnotations(`
+	SELECT `+annotationColumns+` FROM annotations
+	WHERE content_id = ? AND is_deleted = 0
+	ORDER BY start_offset, created_at
+	`, contentID)
+	if err != nil {
+		return nil, err
+	}
+
+	for _, a := range annotations {
+		anchorAnnotation(text, a)
+	}
+	sort.SliceStable(annotations, func(i, j int) bool {
+		if annotations[i].Orphaned != annotations[j].Orphaned {
+			return !annotations[i].Orphaned
+		}
+		return annotations[i].StartOffset < annotations[j].StartOffset
+	})
+	return annotations, nil
+}
+
+// queryAnnotations runs a query selecting annotationColumns.
+func (r *Repository) queryAnnotations(query string, args ...interface{}) ([]*models.Annotation, error) {
+	rows, err := r.db.Query(query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	annotations := make([]*models.Annotation, 0)
+	for rows.Next() {
+		a, err := scanAnnotation(rows)
+		if err != nil {
+			return nil, err
+		}
+		annotations = append(annotations, a)
+	}
+	return annotations, rows.Err()
+}
+
+// UpdateAnnotation saves an annotation's note, color and anchor and bumps
+// its version. The anchor is placed again in the current item text, as by
+// CreateAnnotation, unless the annotation is orphaned. Returns
+// sql.ErrNoRows if the annotation or its item does not exist or was
+// deleted.
+func (r *Repository) UpdateAnnotation(a *models.Annotation) error {
+	tx, err := r.db.Begin()
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	text, err := annotatedText(tx, a.ContentID)
+	if err != nil {
+		return err
+	}
+	if !a.Orphaned {
+		if err := placeAnnotation(text, a); err != nil {
+			return err
+		}
+	}
+
+	var version int
+	err = tx.QueryRow(`SELECT version FROM annotations WHERE id = ? AND is_deleted = 0`, a.ID).Scan(&version)
+	if err != nil {
+		return err
+	}
+	a.Version = version
+	a.Touch()
+
+	_, err = tx.Exec(`
+	UPDATE annotations
+	SET start_offset = ?, end_offset = ?, quote = ?, prefix = ?, suffix = ?, note = ?, color = ?,
+		updated_at = ?, version = ?
+	WHERE id = ?
+	`, a.StartOffset, a.EndOffset, a.Quote, a.Prefix, a.Suffix, a.Note, a.Color,
+		a.UpdatedAt, a.Version, a.ID)
+	if err != nil {
+		return err
+	}
+	return tx.Commit()
+}
+
+// DeleteAnnotation soft deletes an annotation, bumping its version so the
+// deletion syncs. Returns sql.ErrNoRows if it does not exist.
+func (r *Repository) DeleteAnnotation(id string) error {
+	return execAffectingRow(r.db.Exec(`
+	UPDATE annotations SET is_deleted = 1, updated_at = ?, version = version + 1
+	WHERE id = ? AND is_deleted = 0
+	`, time.Now().Unix(), id))
+}
+
+// annotationMatch builds an FTS5 expression matching notes that contain
+// every word of query. Returns "" if query has no words.
+func annotationMatch(query string) string {
+	words := strings.Fields(query)
+	for i, w := range words {
+		words[i] = `"` + strings.ReplaceAll(w, `"`, `""`) + `"`
+	}
+	return strings.Join(words, " ")
+}
+
+// SearchAnnotations returns the live annotations on live items whose notes
+// contain every word of query, best match first.
+func (r *Repository) SearchAnnotations(query string, limit, offset int) ([]*AnnotationMatch, error) {
+	match := annotationMatch(query)
+	if match == "" {
+		return []*AnnotationMatch{}, nil
+	}
+
+	rows, err := r.db.Query(`
+	SELECT a.id, a.content_id, a.start_offset, a.end_offset, a.quote, a.prefix, a.suffix,
+		a.note, a.color, a.is_deleted, a.created_at, a.updated_at, a.version,
+		ci.title, ci.content_text, snippet(annotations_fts, 0, '<mark>', '</mark>', '...', 16)
+	FROM annotations_fts
+	JOIN annotations a ON a.rowid = annotations_fts.rowid
+	JOIN content_items ci ON ci.id = a.content_id AND ci.is_deleted = 0
+	WHERE annotations_fts MATCH ? AND a.is_deleted = 0
+	ORDER BY bm25(annotations_fts), a.updated_at DESC
+	LIMIT ? OFFSET ?
+	`, match, limit, offset)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	matches := make([]*AnnotationMatch, 0)
+	for rows.Next() {
+		var a models.Annotation
+		var m AnnotationMatch
+		var text string
+		err := rows.Scan(&a.ID, &a.ContentID, &a.StartOffset, &a.EndOffset, &a.Quote, &a.Prefix,
+			&a.Suffix, &a.Note, &a.Color, &a.IsDeleted, &a.CreatedAt, &a.UpdatedAt, &a.Version,
+			&m.ContentTitle, &text, &m.Snippet)
+		if err != nil {
+			return nil, err
+		}
+		anchorAnnotation([]rune(text), &a)
+		m.Annotation = &a
+		matches = append(matches, &m)
+	}
+	return matches, rows.Err()
+}
+
+// ListAnnotationsForSync returns every annotation, including deleted ones,
+// with its saved anchor.
+func (r *Repository) ListAnnotationsForSync() ([]*models.Annotation, error) {
+	return r.queryAnnotations(`SELECT ` + annotationColumns + ` FROM annotations ORDER BY id`)
+}
+
+// ApplyRemoteAnnotation stores an annotation received from another device
+// if it is new or has a higher version than the local copy. Returns true if
+// the local copy changed.
+func (r *Repository) ApplyRemoteAnnotation(a *models.Annotation) (bool, error) {
+	result, err := r.db.Exec(`
+	INSERT INTO annotations (id, content_id, start_offset, end_offset, quote, prefix, suffix,
+		note, color, is_deleted, created_at, updated_at, version)
+	VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)
+	ON CONFLICT(id) DO UPDATE SET
+		content_id = excluded.content_id, start_offset = excluded.start_offset,
+		end_offset = excluded.end_offset, quote = excluded.quote, prefix = excluded.prefix,
+		suffix = excluded.suffix, note = excluded.note, color = excluded.color,
+		is_deleted = excluded.is_deleted, updated_at = excluded.updated_at,
+		version = excluded.version
+	WHERE excluded.version > annotations.version
+	`, a.ID, a.ContentID, a.StartOffset, a.EndOffset, a.Quote, a.Prefix, a.Suffix,
+		a.Note, a.Color, a.IsDeleted, a.CreatedAt, a.UpdatedAt, a.Version)
+	if err != nil {
+		return false, err
+	}
+	applied, err := result.RowsAffected()
+	return applied > 0, err
+}
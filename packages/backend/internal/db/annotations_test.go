@@ -0,0 +1,171 @@
+// Package db tests for annotations.
+package db
+
+import (
+	"database/sql"
+	"errors"
+	"testing"
+
+	"github.com/kimhsiao/memonexus/backend/internal/models"
+)
+
+// TestLocateAnnotation verifies anchors follow their quote through edits,
+// using the surrounding text to pick between repeated quotes.
+func TestLocateAnnotation(t *testing.T) {
+	a := &models.Annotation{}
+	original := []rune("The cat sat. The dog sat. The cat ran.")
+	a.StartOffset, a.EndOffset = 30, 33 // the second "cat"
+	if err := placeAnnotation(original, a); err != nil {
+		t.Fatalf("placeAnnotation failed: %v", err)
+	}
+	if a.Quote != "cat" || a.Prefix != "The cat sat. The dog sat. The " || a.Suffix != " ran." {
+		t.Fatalf("Fingerprint = %q %q %q", a.Prefix, a.Quote, a.Suffix)
+	}
+
+	tests := []struct {
+		name  string
+		text  string
+		start int
+		found bool
+	}{
+		{"unchanged", "The cat sat. The dog sat. The cat ran.", 30, true},
+		{"text inserted before", "Intro. The cat sat. The dog sat. The cat ran.", 37, true},
+		{"other occurrence first", "A cat. The cat sat. The dog sat. The cat ran.", 37, true},
+		{"quote removed", "The dog sat.", 0, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := *a
+			anchorAnnotation([]rune(tt.text), &got)
+			if got.Orphaned == tt.found || (tt.found && got.StartOffset != tt.start) {
+				t.Errorf("anchor = %d (orphaned %v), want %d (found %v)", got.StartOffset, got.Orphaned, tt.start, tt.found)
+			}
+		})
+	}
+}
+
+// TestAnnotations verifies annotations are created, re-anchored after
+// edits, searched by note and deleted.
+func TestAnnotations(t *testing.T) {
+	db := setupMigratedTestDB(t)
+	defer db.Close()
+
+	repo := NewRepository(db)
+	item := &models.ContentItem{Title: "Article", ContentText: "Go has goroutines and channels.", MediaType: "web"}
+	if err := repo.CreateContentItem(item); err != nil {
+		t.Fatalf("CreateContentItem failed: %v", err)
+	}
+
+	a := &models.Annotation{ContentID: item.ID, StartOffset: 7, EndOffset: 17, Note: "Lightweight threads", Color: "green"}
+	if err := repo.CreateAnnotation(a); err != nil {
+		t.Fatalf("CreateAnnotation failed: %v", err)
+	}
+	if a.Quote != "goroutines" || a.Version != 1 {
+		t.Errorf("Created annotation = %+v", a)
+	}
+
+	bad := &models.Annotation{ContentID: item.ID, StartOffset: 20, EndOffset: 99}
+	if err := repo.CreateAnnotation(bad); !errors.Is(err, ErrInvalidAnnotationAnchor) {
+		t.Errorf("Create past the end = %v, want ErrInvalidAnnotationAnchor", err)
+	}
+	bad = &models.Annotation{ContentID: item.ID, StartOffset: 0, EndOffset: 2, Quote: "missing"}
+	if err := repo.CreateAnnotation(bad); !errors.Is(err, ErrInvalidAnnotationAnchor) {
+		t.Errorf("Create with unknown quote = %v, want ErrInvalidAnnotationAnchor", err)
+	}
+	bad = &models.Annotation{ContentID: "00000000-0000-4000-8000-000000000000", StartOffset: 0, EndOffset: 2}
+	if err := repo.CreateAnnotation(bad); err != sql.ErrNoRows {
+		t.Errorf("Create on missing item = %v, want sql.ErrNoRows", err)
+	}
+
+	// Editing the item moves the highlight with its quote
+	item.ContentText = "In short, Go has goroutines and channels."
+	if err := repo.UpdateContentItem(item); err != nil {
+		t.Fatalf("UpdateContentItem failed: %v", err)
+	}
+	got, err := repo.GetAnnotation(string(a.ID))
+	if err != nil {
+		t.Fatalf("GetAnnotation failed: %v", err)
+	}
+	if got.StartOffset != 17 || got.EndOffset != 27 || got.Orphaned {
+		t.Errorf("Anchor after edit = %d-%d (orphaned %v), want 17-27", got.StartOffset, got.EndOffset, got.Orphaned)
+	}
+
+	// Updating saves the new anchor and bumps the version
+	got.Note = "Lightweight concurrent threads"
+	if err := repo.UpdateAnnotation(got); err != nil {
+		t.Fatalf("UpdateAnnotation failed: %v", err)
+	}
+	if got.Version != 2 || got.Prefix != "In short, Go has " {
+		t.Errorf("Updated annotation = %+v", got)
+	}
+
+	matches, err := repo.SearchAnnotations("concurrent", 10, 0)
+	if err != nil {
+		t.Fatalf("SearchAnnotations failed: %v", err)
+	}
+	if len(matches) != 1 || matches[0].ID != a.ID || matches[0].ContentTitle != "Article" ||
+		matches[0].Snippet != "Lightweight <mark>concurrent</mark> threads" {
+		t.Errorf("Search matches = %+v", matches)
+	}
+	if matches, _ := repo.SearchAnnotations("Lightweight", 10, 0); len(matches) != 1 {
+		t.Errorf("Search by earlier note word = %d matches, want 1", len(matches))
+	}
+
+	// Removing the quote orphans the annotation
+	item.ContentText = "Go has threads."
+	if err := repo.UpdateContentItem(item); err != nil {
+		t.Fatalf("UpdateContentItem failed: %v", err)
+	}
+	annotations, err := repo.ListAnnotations(string(item.ID))
+	if err != nil || len(annotations) != 1 || !annotations[0].Orphaned {
+		t.Errorf("ListAnnotations = %+v, %v; want one orphaned annotation", annotations, err)
+	}
+
+	if err := repo.DeleteAnnotation(string(a.ID)); err != nil {
+		t.Fatalf("DeleteAnnotation failed: %v", err)
+	}
+	if err := repo.DeleteAnnotation(string(a.ID)); err != sql.ErrNoRows {
+		t.Errorf("Deleting again = %v, want sql.ErrNoRows", err)
+	}
+	if matches, _ := repo.SearchAnnotations("concurrent", 10, 0); len(matches) != 0 {
+		t.Errorf("Deleted annotation found by search")
+	}
+}
+
+// TestAnnotationSync verifies annotations round-trip through the sync
+// methods and only newer versions are applied.
+func TestAnnotationSync(t *testing.T) {
+	local := setupMigratedTestDB(t)
+	defer local.Close()
+	remote := setupMigratedTestDB(t)
+	defer remote.Close()
+
+	localRepo := NewRepository(local)
+	items := createTaggedItems(t, localRepo, "")
+	a := &models.Annotation{ContentID: items[0].ID, StartOffset: 0, EndOffset: 4, Color: "blue"}
+	if err := localRepo.CreateAnnotation(a); err != nil {
+		t.Fatalf("CreateAnnotation failed: %v", err)
+	}
+
+	exported, err := localRepo.ListAnnotationsForSync()
+	if err != nil || len(exported) != 1 {
+		t.Fatalf("ListAnnotationsForSync = %v, %v", exported, err)
+	}
+
+	// The remote device has not synced the item yet; the annotation is kept
+	remoteRepo := NewRepository(remote)
+	applied, err := remoteRepo.ApplyRemoteAnnotation(exported[0])
+	if err != nil || !applied {
+		t.Fatalf("ApplyRemoteAnnotation = %v, %v", applied, err)
+	}
+	if applied, _ := remoteRepo.ApplyRemoteAnnotation(exported[0]); applied {
+		t.Error("Applying the same version again changed the annotation")
+	}
+	synced, _ := remoteRepo.ListAnnotationsForSync()
+	if len(synced) != 1 || synced[0].Quote != "body" || synced[0].Color != "blue" {
+		t.Errorf("Synced annotations = %+v", synced)
+	}
+	if _, err := remoteRepo.GetAnnotation(string(a.ID)); err != sql.ErrNoRows {
+		t.Errorf("GetAnnotation without its item = %v, want sql.ErrNoRows", err)
+	}
+}
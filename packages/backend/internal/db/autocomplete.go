@@ -0,0 +1,298 @@
+// Package db provides search-as-you-type autocomplete.
+package db
+
+import (
+	"sort"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+)
+
+const (
+	// maxAutocompleteSuggestions caps the number of suggestions returned
+	maxAutocompleteSuggestions = 20
+
+	// maxAutocompletePrefix is the longest prefix accepted, in runes
+	maxAutocompletePrefix = 100
+)
+
+// Autocomplete suggestion kinds, in ranking order.
+const (
+	AutocompleteQuery  = "query"
+	AutocompleteTag    = "tag"
+	AutocompleteTitle  = "title"
+	AutocompleteDomain = "domain"
+)
+
+// autocompleteWeights ranks suggestion kinds against each other.
+var autocompleteWeights = map[string]float64{
+	AutocompleteQuery:  4,
+	AutocompleteTag:    3,
+	AutocompleteTitle:  2,
+	AutocompleteDomain: 1,
+}
+
+// AutocompleteOptions configures an autocomplete lookup.
+type AutocompleteOptions struct {
+	// Prefix is the text typed so far
+	Prefix string
+
+	// Limit is the maximum number of suggestions (default 10, max 20)
+	Limit int
+}
+
+// AutocompleteSuggestion is a completion for a partially typed query.
+type AutocompleteSuggestion struct {
+	// Kind is one of the Autocomplete* kinds
+	Kind string
+
+	// Text is the completed text
+	Text string
+
+	// ContentID is the matching item for title suggestions
+	ContentID string
+
+	// Score ranks suggestions; higher is better
+	Score float64
+}
+
+// Autocomplete returns mixed completions for a partially typed query:
+// queries from the search history, tag names, item titles and source
+// domains starting with the prefix. Titles and domains are looked up with
+// FTS5 prefix queries so each keystroke stays within the SC-002 budget.
+// Suggestions are ranked by kind, with exact and leading matches first,
+// and deduplicated case-insensitively.
+func (r *Repository) Autocomplete(opts AutocompleteOptions) ([]*AutocompleteSuggestion, error) {
+	limit := opts.Limit
+	if limit <= 0 {
+		limit = 10
+	}
+	if limit > maxAutocompleteSuggestions {
+		limit = maxAutocompleteSuggestions
+	}
+
+	prefix := strings.TrimSpace(opts.Prefix)
+	if utf8.RuneCountInString(prefix) > maxAutocompletePrefix {
+		prefix = string([]rune(prefix)[:maxAutocompletePrefix])
+	}
+	suggestions := make([]*AutocompleteSuggestion, 0)
+	if prefix == "" {
+		return suggestions, nil
+	}
+
+	var candidates []*AutocompleteSuggestion
+	add := func(kind, text, contentID string, position int) {
+		candidates = append(candidates, &AutocompleteSuggestion{
+			Kind:      kind,
+			Text:      text,
+			ContentID: contentID,
+			Score:     autocompleteScore(kind, text, prefix, position),
+		})
+	}
+
+	// Recent queries
+	queries, err := r.RecentSearchQueries(prefix, limit)
+	if err != nil {
+		return nil, err
+	}
+	for i, q := range queries {
+		add(AutocompleteQuery, q, "", i)
+	}
+
+	// Tag names
+	tags, err := r.autocompleteTags(prefix, limit)
+	if err != nil {
+		return nil, err
+	}
+	for i, tag := range tags {
+		add(AutocompleteTag, tag, "", i)
+	}
+
+	// Titles and source domains
+	titles, err := r.autocompleteTitles(prefix, limit)
+	if err != nil {
+		return nil, err
+	}
+	for i, t := range titles {
+		add(AutocompleteTitle, t.title, t.id, i)
+	}
+	domains, err := r.autocompleteDomains(prefix, limit)
+	if err != nil {
+		return nil, err
+	}
+	for i, domain := range domains {
+		add(AutocompleteDomain, domain, "", i)
+	}
+
+	sort.SliceStable(candidates, func(i, j int) bool {
+		return candidates[i].Score > candidates[j].Score
+	})
+	seen := make(map[string]bool)
+	for _, c := range candidates {
+		key := strings.ToLower(c.Text)
+		if seen[key] {
+			continue
+		}
+		seen[key] = true
+		suggestions = append(suggestions, c)
+		if len(suggestions) == limit {
+			break
+		}
+	}
+	return suggestions, nil
+}
+
+// autocompleteScore scores a candidate by kind, boosted when the whole text
+// equals or starts with the prefix, and lowered by its position in its
+// source's ordering.
+func autocompleteScore(kind, text, prefix string, position int) float64 {
+	score := autocompleteWeights[kind]
+	switch {
+	case strings.EqualFold(text, prefix):
+		score += 1
+	case hasPrefixFold(text, prefix):
+		score += 0.5
+	}
+	return score - float64(position)*0.01
+}
+
+// hasPrefixFold reports whether s starts with prefix, ignoring case. Both
+// are lowered first, as case folding can change a rune's byte length.
+func hasPrefixFold(s, prefix string) bool {
+	return strings.HasPrefix(strings.ToLower(s), strings.ToLower(prefix))
+}
+
+// autocompleteTags returns tag names starting with prefix.
+func (r *Repository) autocompleteTags(prefix string, limit int) ([]string, error) {
+	query := `
+	SELECT name FROM tags
+	WHERE is_deleted = 0 AND name LIKE ? ESCAPE '\'
+	ORDER BY length(name), name
+	LIMIT ?
+	`
+	rows, err := r.db.Query(query, escapeLike(prefix)+"%", limit)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var names []string
+	for rows.Next() {
+		var name string
+		if err := rows.Scan(&name); err != nil {
+			return nil, err
+		}
+		names = append(names, name)
+	}
+	return names, rows.Err()
+}
+
+// titleCompletion is an item title matching an autocomplete prefix.
+type titleCompletion struct {
+	id    string
+	title string
+}
+
+// autocompleteTitles returns titles containing a word sequence that starts
+// with prefix. CJK prefixes are matched as substrings: through the trigram
+// index when long enough, otherwise with LIKE.
+func (r *Repository) autocompleteTitles(prefix string, limit int) ([]titleCompletion, error) {
+	var query string
+	var arg string
+	switch {
+	case HasCJKText(prefix) && utf8.RuneCountInString(prefix) >= trigramMinLength:
+		query = `
+		SELECT ci.id, ci.title FROM content_fts_trigram fts
+		JOIN content_items ci ON ci.rowid = fts.rowid
+		WHERE content_fts_trigram MATCH ? AND ci.is_deleted = 0
+		ORDER BY fts.rank
+		LIMIT ?
+		`
+		arg = `{title} : "` + strings.ReplaceAll(prefix, `"`, `""`) + `"`
+	case HasCJKText(prefix):
+		query = `
+		SELECT id, title FROM content_items
+		WHERE is_deleted = 0 AND title LIKE ? ESCAPE '\'
+		ORDER BY updated_at DESC
+		LIMIT ?
+		`
+		arg = "%" + escapeLike(prefix) + "%"
+	default:
+		match := ftsPrefixPhrase(prefix)
+		if match == "" {
+			return nil, nil
+		}
+		query = `
+		SELECT ci.id, ci.title FROM content_fts fts
+		JOIN content_items ci ON ci.rowid = fts.rowid
+		WHERE content_fts MATCH ? AND ci.is_deleted = 0
+		ORDER BY fts.rank
+		LIMIT ?
+		`
+		arg = `{title} : ` + match
+	}
+
+	rows, err := r.db.Query(query, arg, limit)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var titles []titleCompletion
+	for rows.Next() {
+		var t titleCompletion
+		if err := rows.Scan(&t.id, &t.title); err != nil {
+			return nil, err
+		}
+		titles = append(titles, t)
+	}
+	return titles, rows.Err()
+}
+
+// autocompleteDomains returns source domains starting with prefix, most
+// common first. The FTS prefix query narrows the candidates; the LIKE keeps
+// only domains that start with the prefix rather than containing it.
+func (r *Repository) autocompleteDomains(prefix string, limit int) ([]string, error) {
+	match := ftsPrefixPhrase(prefix)
+	if match == "" || HasCJKText(prefix) {
+		return nil, nil
+	}
+
+	query := `
+	SELECT ci.source_domain FROM content_fts fts
+	JOIN content_items ci ON ci.rowid = fts.rowid
+	WHERE content_fts MATCH ? AND ci.is_deleted = 0
+		AND (ci.source_domain LIKE ? ESCAPE '\' OR ci.source_domain LIKE ? ESCAPE '\')
+	GROUP BY ci.source_domain
+	ORDER BY COUNT(*) DESC, ci.source_domain
+	LIMIT ?
+	`
+	like := escapeLike(prefix) + "%"
+	rows, err := r.db.Query(query, `{source_domain} : `+match, like, "%."+like, limit)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var domains []string
+	for rows.Next() {
+		var domain string
+		if err := rows.Scan(&domain); err != nil {
+			return nil, err
+		}
+		domains = append(domains, domain)
+	}
+	return domains, rows.Err()
+}
+
+// ftsPrefixPhrase builds an FTS5 prefix phrase ("word1 word2"*) from the
+// letters and digits of prefix. Returns "" if prefix has none.
+func ftsPrefixPhrase(prefix string) string {
+	words := strings.FieldsFunc(prefix, func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsDigit(r)
+	})
+	if len(words) == 0 {
+		return ""
+	}
+	return `"` + strings.Join(words, " ") + `"*`
+}
@@ -0,0 +1,175 @@
+// Package db tests for search-as-you-type autocomplete.
+package db
+
+import (
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/kimhsiao/memonexus/backend/internal/models"
+)
+
+// TestFTSPrefixPhrase verifies prefixes are reduced to safe FTS5 phrases.
+func TestFTSPrefixPhrase(t *testing.T) {
+	tests := []struct {
+		prefix string
+		want   string
+	}{
+		{"go", `"go"*`},
+		{"go conc", `"go conc"*`},
+		{`nytimes.c`, `"nytimes c"*`},
+		{`"OR" -NOT*`, `"OR NOT"*`},
+		{"...", ""},
+	}
+
+	for _, tt := range tests {
+		if got := ftsPrefixPhrase(tt.prefix); got != tt.want {
+			t.Errorf("ftsPrefixPhrase(%q) = %q, want %q", tt.prefix, got, tt.want)
+		}
+	}
+}
+
+// TestHasPrefixFold verifies prefixes match by rune, ignoring case.
+func TestHasPrefixFold(t *testing.T) {
+	tests := []struct {
+		s, prefix string
+		want      bool
+	}{
+		{"Golang", "go", true},
+		{"Kelvin", "\u212Ael", true},
+		{"\u212Aelvin", "kel", true},
+		{"日本語", "日本", true},
+		{"日本語", "日", true},
+		{"日本語", "本", false},
+		{"go", "golang", false},
+	}
+
+	for _, tt := range tests {
+		if got := hasPrefixFold(tt.s, tt.prefix); got != tt.want {
+			t.Errorf("hasPrefixFold(%q, %q) = %v, want %v", tt.s, tt.prefix, got, tt.want)
+		}
+	}
+}
+
+// TestAutocomplete verifies suggestions are mixed, ranked and deduplicated.
+func TestAutocomplete(t *testing.T) {
+	db := setupMigratedTestDB(t)
+	defer db.Close()
+
+	repo := NewRepository(db)
+	items := []*models.ContentItem{
+		{Title: "Go Concurrency Patterns", SourceURL: "https://go.dev/blog/pipelines", MediaType: "web"},
+		{Title: "Learning Golang", SourceURL: "https://www.golangweekly.com/issues/1", MediaType: "web"},
+		{Title: "Rust Ownership", SourceURL: "https://doc.rust-lang.org/book", MediaType: "web"},
+		{Title: "個人知識庫", MediaType: "markdown"},
+	}
+	for _, item := range items {
+		if err := repo.CreateContentItem(item); err != nil {
+			t.Fatalf("CreateContentItem failed: %v", err)
+		}
+	}
+	for _, name := range []string{"golang", "go", "rust"} {
+		if err := repo.CreateTag(&models.Tag{Name: name, Color: "#3B82F6"}); err != nil {
+			t.Fatalf("CreateTag failed: %v", err)
+		}
+	}
+
+	for _, q := range []string{"golang generics", "rust"} {
+		if err := repo.RecordSearch(&models.SearchHistoryEntry{Query: q}); err != nil {
+			t.Fatalf("RecordSearch failed: %v", err)
+		}
+	}
+
+	t.Run("Mixed", func(t *testing.T) {
+		got, err := repo.Autocomplete(AutocompleteOptions{Prefix: "go"})
+		if err != nil {
+			t.Fatalf("Autocomplete failed: %v", err)
+		}
+
+		want := []struct{ kind, text string }{
+			{AutocompleteQuery, "golang generics"},
+			{AutocompleteTag, "go"},
+			{AutocompleteTag, "golang"},
+			{AutocompleteTitle, "Go Concurrency Patterns"},
+			{AutocompleteTitle, "Learning Golang"},
+			{AutocompleteDomain, "go.dev"},
+			{AutocompleteDomain, "golangweekly.com"},
+		}
+		if len(got) != len(want) {
+			t.Fatalf("Autocomplete = %d suggestions, want %d", len(got), len(want))
+		}
+		for i, w := range want {
+			if got[i].Kind != w.kind || got[i].Text != w.text {
+				t.Errorf("Suggestion %d = %s %q, want %s %q", i, got[i].Kind, got[i].Text, w.kind, w.text)
+			}
+		}
+		if got[3].ContentID != string(items[0].ID) {
+			t.Errorf("Title ContentID = %q, want %q", got[3].ContentID, items[0].ID)
+		}
+	})
+
+	t.Run("Dedup", func(t *testing.T) {
+		got, err := repo.Autocomplete(AutocompleteOptions{Prefix: "Rust"})
+		if err != nil {
+			t.Fatalf("Autocomplete failed: %v", err)
+		}
+		// "Rust" matches the recent query, the title and a subdomain
+		if len(got) != 3 || got[0].Kind != AutocompleteQuery || got[1].Text != "Rust Ownership" ||
+			got[2].Text != "doc.rust-lang.org" {
+			t.Errorf("Autocomplete = %d suggestions, want the recent query, title and domain", len(got))
+		}
+	})
+
+	t.Run("Limit", func(t *testing.T) {
+		got, err := repo.Autocomplete(AutocompleteOptions{Prefix: "go", Limit: 2})
+		if err != nil || len(got) != 2 {
+			t.Errorf("Autocomplete = %+v, %v; want 2 suggestions", got, err)
+		}
+	})
+
+	t.Run("CJK", func(t *testing.T) {
+		for _, prefix := range []string{"知識", "個人知識"} {
+			got, err := repo.Autocomplete(AutocompleteOptions{Prefix: prefix})
+			if err != nil {
+				t.Fatalf("Autocomplete(%s) failed: %v", prefix, err)
+			}
+			if len(got) != 1 || got[0].Text != "個人知識庫" {
+				t.Errorf("Autocomplete(%s) = %+v", prefix, got)
+			}
+		}
+	})
+
+	t.Run("Empty", func(t *testing.T) {
+		got, err := repo.Autocomplete(AutocompleteOptions{Prefix: "  "})
+		if err != nil || len(got) != 0 {
+			t.Errorf("Autocomplete = %+v, %v; want none", got, err)
+		}
+	})
+}
+
+// BenchmarkAutocomplete10000Items checks per-keystroke latency against the
+// SC-002 target of <100ms for 10,000 items.
+func BenchmarkAutocomplete10000Items(b *testing.B) {
+	db := setupMigratedTestDB(b)
+	defer db.Close()
+
+	if err := populateContentItems(db, 10000); err != nil {
+		b.Fatalf("Failed to populate items: %v", err)
+	}
+	repo := NewRepository(db)
+	b.ResetTimer()
+
+	for _, prefix := range []string{"t", "co", "test content 12"} {
+		b.Run(fmt.Sprintf("Prefix=%s", prefix), func(b *testing.B) {
+			for i := 0; i < b.N; i++ {
+				start := time.Now()
+				if _, err := repo.Autocomplete(AutocompleteOptions{Prefix: prefix}); err != nil {
+					b.Fatalf("Autocomplete failed: %v", err)
+				}
+				if elapsed := time.Since(start); elapsed > 100*time.Millisecond {
+					b.Errorf("Autocomplete took %v, exceeding 100ms threshold (SC-002)", elapsed)
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,287 @@
+// Package db provides online database backup and restore.
+package db
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"modernc.org/sqlite"
+)
+
+// Backups are full copies of the database, including tags, configuration
+// and logs, taken with VACUUM INTO while the server keeps running. A backup
+// is written to a temporary file, checked, and only then renamed into the
+// backup directory, so every file listed there is complete.
+
+// backupPrefix and backupExt frame backup file names, e.g.
+// memonexus-20260102-150405.000000.db.
+const (
+	backupPrefix     = "memonexus-"
+	backupExt        = ".db"
+	backupTimeFormat = "20060102-150405.000000"
+)
+
+var (
+	// ErrInvalidBackup is returned when a backup file fails its integrity
+	// check or is not a MemoNexus database.
+	ErrInvalidBackup = errors.New("invalid backup")
+
+	// ErrBackupTooNew is returned when a backup has a schema version newer
+	// than this build supports.
+	ErrBackupTooNew = errors.New("backup schema version is newer than supported")
+)
+
+// BackupInfo describes a database backup file.
+type BackupInfo struct {
+	ID            string    `json:"id"` // File name within the backup directory
+	FilePath      string    `json:"file_path"`
+	SizeBytes     int64     `json:"size_bytes"`
+	SchemaVersion int       `json:"schema_version"`
+	CreatedAt     time.Time `json:"created_at"`
+}
+
+// IsBackupID reports whether id names a backup file, and nothing outside
+// the backup directory.
+func IsBackupID(id string) bool {
+	return strings.HasPrefix(id, backupPrefix) && strings.HasSuffix(id, backupExt) &&
+		filepath.Base(id) == id
+}
+
+// Backup writes a consistent snapshot of the database to dir and returns it.
+func (db *DB) Backup(dir string) (*BackupInfo, error) {
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return nil, fmt.Errorf("failed to create backup directory: %w", err)
+	}
+
+	id := backupPrefix + time.Now().UTC().Format(backupTimeFormat) + backupExt
+	path := filepath.Join(dir, id)
+	if _, err := os.Stat(path); err == nil {
+		return nil, fmt.Errorf("backup %s already exists", id)
+	}
+
+	// VACUUM INTO refuses to overwrite, so start from a missing file
+	tmpPath := path + ".tmp"
+	os.Remove(tmpPath)
+	if _, err := db.Exec(`VACUUM INTO ?`, tmpPath); err != nil {
+		os.Remove(tmpPath)
+		return nil, fmt.Errorf("failed to snapshot database: %w", err)
+	}
+
+	if _, err := InspectBackup(tmpPath); err != nil {
+		os.Remove(tmpPath)
+		return nil, err
+	}
+	if err := os.Rename(tmpPath, path); err != nil {
+		os.Remove(tmpPath)
+		return nil, fmt.Errorf("failed to save backup: %w", err)
+	}
+	return readBackup(path, false)
+}
+
+// InspectBackup checks the integrity of a backup file and returns its
+// details. Returns an os.ErrNotExist error if the file does not exist.
+func InspectBackup(path string) (*BackupInfo, error) {
+	return readBackup(path, true)
+}
+
+// readBackup returns the details of a backup file, running a full
+// integrity check first if verify is set.
+func readBackup(path string, verify bool) (*BackupInfo, error) {
+	fi, err := os.Stat(path)
+	if err != nil {
+		return nil, err
+	}
+
+	snapshot, err := sql.Open("sqlite", "file:"+path+"?mode=ro")
+	if err != nil {
+		return nil, fmt.Errorf("failed to open backup: %w", err)
+	}
+	defer snapshot.Close()
+
+	if verify {
+		var result string
+		if err := snapshot.QueryRow(`PRAGMA integrity_check`).Scan(&result); err != nil {
+			return nil, fmt.Errorf("%w: %v", ErrInvalidBackup, err)
+		}
+		if result != "ok" {
+			return nil, fmt.Errorf("%w: integrity check failed: %s", ErrInvalidBackup, result)
+		}
+	}
+
+	version, err := NewEmbeddedMigrator(snapshot).CurrentVersion()
+	if err != nil || version == 0 {
+		return nil, fmt.Errorf("%w: no schema version", ErrInvalidBackup)
+	}
+
+	return &BackupInfo{
+		ID:            filepath.Base(path),
+		FilePath:      path,
+		SizeBytes:     fi.Size(),
+		SchemaVersion: version,
+		CreatedAt:     fi.ModTime(),
+	}, nil
+}
+
+// Restore replaces the contents of the database with the backup at path
+// and migrates it to the latest schema. The backup is checked first, and a
+// backup with an older schema is migrated in a temporary copy, so a failing
+// migration leaves the database untouched. The result is copied in with
+// SQLite's backup API in a single step, so other connections see either
+// the old or the restored database. Returns ErrBackupTooNew if the backup
+// was written by a newer schema.
+func (db *DB) Restore(path string) error {
+	info, err := InspectBackup(path)
+	if err != nil {
+		return err
+	}
+
+	latest, err := NewEmbeddedMigrator(db.DB).LatestVersion()
+	if err != nil {
+		return err
+	}
+	if info.SchemaVersion > latest {
+		return fmt.Errorf("%w: %d > %d", ErrBackupTooNew, info.SchemaVersion, latest)
+	}
+
+	if info.SchemaVersion < latest {
+		staged, err := stageMigratedBackup(path)
+		if staged != "" {
+			defer os.RemoveAll(filepath.Dir(staged))
+		}
+		if err != nil {
+			return err
+		}
+		path = staged
+	}
+
+	conn, err := db.Conn(context.Background())
+	if err != nil {
+		return err
+	}
+	err = conn.Raw(func(driverConn interface{}) error {
+		restorer, ok := driverConn.(interface {
+			NewRestore(srcURI string) (*sqlite.Backup, error)
+		})
+		if !ok {
+			return errors.New("driver does not support restore")
+		}
+		restore, err := restorer.NewRestore("file:" + path + "?mode=ro")
+		if err != nil {
+			return err
+		}
+		if _, err := restore.Step(-1); err != nil {
+			restore.Finish()
+			return err
+		}
+		return restore.Finish()
+	})
+	conn.Close()
+	if err != nil {
+		return fmt.Errorf("failed to restore backup: %w", err)
+	}
+	return nil
+}
+
+// stageMigratedBackup copies the backup at path into a new temporary
+// directory and migrates the copy to the latest schema. The returned path
+// is set whenever the directory was created, so the caller can remove it.
+func stageMigratedBackup(path string) (string, error) {
+	dir, err := os.MkdirTemp("", "memonexus-restore-")
+	if err != nil {
+		return "", fmt.Errorf("failed to stage backup: %w", err)
+	}
+	staged := filepath.Join(dir, filepath.Base(path))
+
+	snapshot, err := sql.Open("sqlite", "file:"+path+"?mode=ro")
+	if err != nil {
+		return staged, fmt.Errorf("failed to open backup: %w", err)
+	}
+	_, err = snapshot.Exec(`VACUUM INTO ?`, staged)
+	snapshot.Close()
+	if err != nil {
+		return staged, fmt.Errorf("failed to stage backup: %w", err)
+	}
+
+	copied, err := sql.Open("sqlite", staged)
+	if err != nil {
+		return staged, fmt.Errorf("failed to open staged backup: %w", err)
+	}
+	defer copied.Close()
+	if _, err := copied.Exec("PRAGMA foreign_keys=ON;"); err != nil {
+		return staged, fmt.Errorf("failed to enable foreign keys: %w", err)
+	}
+	if err := NewEmbeddedMigrator(copied).Up(); err != nil {
+		return staged, fmt.Errorf("failed to migrate restored database: %w", err)
+	}
+	return staged, nil
+}
+
+// ListBackups returns the backups in dir, newest first. Files that cannot
+// be read as a MemoNexus database are skipped; integrity is checked on
+// backup and restore, not here.
+func ListBackups(dir string) ([]*BackupInfo, error) {
+	entries, err := os.ReadDir(dir)
+	if os.IsNotExist(err) {
+		return []*BackupInfo{}, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	backups := make([]*BackupInfo, 0, len(entries))
+	for _, entry := range entries {
+		if entry.IsDir() || !IsBackupID(entry.Name()) {
+			continue
+		}
+		info, err := readBackup(filepath.Join(dir, entry.Name()), false)
+		if err != nil {
+			continue
+		}
+		backups = append(backups, info)
+	}
+
+	// Names sort by creation time
+	sort.Slice(backups, func(i, j int) bool {
+		return backups[i].ID > backups[j].ID
+	})
+	return backups, nil
+}
+
+// DeleteBackup removes a backup file.
+func DeleteBackup(path string) error {
+	if err := os.Remove(path); err != nil {
+		return fmt.Errorf("failed to delete backup: %w", err)
+	}
+	return nil
+}
+
+// ApplyBackupRetention removes old backups from dir, keeping the most
+// recent retentionCount, and returns how many were deleted. A count of zero
+// or less keeps every backup.
+func ApplyBackupRetention(dir string, retentionCount int) (int, error) {
+	if retentionCount <= 0 {
+		return 0, nil
+	}
+
+	backups, err := ListBackups(dir)
+	if err != nil {
+		return 0, fmt.Errorf("failed to list backups: %w", err)
+	}
+
+	deleted := 0
+	for _, backup := range backups[min(retentionCount, len(backups)):] {
+		if err := DeleteBackup(backup.FilePath); err != nil {
+			// Continue with the other deletions
+			continue
+		}
+		deleted++
+	}
+	return deleted, nil
+}
@@ -0,0 +1,180 @@
+// Package db tests for online backup and restore.
+package db
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+// TestBackupRestore verifies a backup taken from a live database restores
+// its contents, including tags, into the same connection.
+func TestBackupRestore(t *testing.T) {
+	db := &DB{setupMigratedTestDB(t)}
+	defer db.Close()
+
+	repo := NewRepository(db.DB)
+	item := createTaggedItems(t, repo, "go")[0]
+	dir := t.TempDir()
+
+	backup, err := db.Backup(dir)
+	if err != nil {
+		t.Fatalf("Backup failed: %v", err)
+	}
+	latest, _ := NewEmbeddedMigrator(db.DB).LatestVersion()
+	if !IsBackupID(backup.ID) || backup.SchemaVersion != latest || backup.SizeBytes == 0 {
+		t.Errorf("Backup = %+v", backup)
+	}
+
+	item.Title = "Changed"
+	if err := repo.UpdateContentItem(item); err != nil {
+		t.Fatalf("UpdateContentItem failed: %v", err)
+	}
+	if _, err := repo.AssignTags(string(item.ID), []string{"rust"}); err != nil {
+		t.Fatalf("AssignTags failed: %v", err)
+	}
+
+	if err := db.Restore(backup.FilePath); err != nil {
+		t.Fatalf("Restore failed: %v", err)
+	}
+	restored, err := repo.GetContentItem(string(item.ID))
+	if err != nil {
+		t.Fatalf("GetContentItem failed: %v", err)
+	}
+	if restored.Title != "Item" || restored.Tags != "go" {
+		t.Errorf("Restored item = %+v, want the backed up state", restored)
+	}
+	var tags int
+	db.QueryRow(`SELECT COUNT(*) FROM tags WHERE name = 'rust'`).Scan(&tags)
+	if tags != 0 {
+		t.Errorf("Tags created after the backup = %d, want 0", tags)
+	}
+}
+
+// TestRestore_invalid verifies backups that are corrupt, foreign or too
+// new are rejected without touching the database.
+func TestRestore_invalid(t *testing.T) {
+	db := &DB{setupMigratedTestDB(t)}
+	defer db.Close()
+	dir := t.TempDir()
+
+	if err := db.Restore(filepath.Join(dir, "missing.db")); !os.IsNotExist(err) {
+		t.Errorf("Restore of missing file = %v, want not exist", err)
+	}
+
+	garbage := filepath.Join(dir, "garbage.db")
+	os.WriteFile(garbage, []byte("not a database"), 0644)
+	if err := db.Restore(garbage); !errors.Is(err, ErrInvalidBackup) {
+		t.Errorf("Restore of garbage = %v, want ErrInvalidBackup", err)
+	}
+
+	backup, err := db.Backup(dir)
+	if err != nil {
+		t.Fatalf("Backup failed: %v", err)
+	}
+	newer := &DB{setupMigratedTestDB(t)}
+	defer newer.Close()
+	if err := newer.Restore(backup.FilePath); err != nil {
+		t.Fatalf("Restore failed: %v", err)
+	}
+	if _, err := newer.Exec(`INSERT INTO schema_migrations VALUES (999, 1, 'future', ?)`,
+		migrationChecksum(nil)); err != nil {
+		t.Fatalf("Failed to record future migration: %v", err)
+	}
+	future, err := newer.Backup(t.TempDir())
+	if err != nil {
+		t.Fatalf("Backup failed: %v", err)
+	}
+	if err := db.Restore(future.FilePath); !errors.Is(err, ErrBackupTooNew) {
+		t.Errorf("Restore of newer schema = %v, want ErrBackupTooNew", err)
+	}
+	if version, _ := NewEmbeddedMigrator(db.DB).CurrentVersion(); version == 999 {
+		t.Error("Rejected backup was restored")
+	}
+}
+
+// TestRestore_migrationFails verifies a backup whose migration fails is
+// not restored.
+func TestRestore_migrationFails(t *testing.T) {
+	db := &DB{setupMigratedTestDB(t)}
+	defer db.Close()
+	item := createTaggedItems(t, NewRepository(db.DB), "go")[0]
+
+	// An older backup missing a table its next migration uses
+	older := &DB{setupMigratedTestDB(t)}
+	defer older.Close()
+	m := NewEmbeddedMigrator(older.DB)
+	latest, _ := m.LatestVersion()
+	if err := m.MigrateTo(latest - 1); err != nil {
+		t.Fatalf("MigrateTo failed: %v", err)
+	}
+	if _, err := older.Exec(`DROP TABLE content_tags`); err != nil {
+		t.Fatalf("Failed to drop content_tags: %v", err)
+	}
+	backup, err := older.Backup(t.TempDir())
+	if err != nil {
+		t.Fatalf("Backup failed: %v", err)
+	}
+
+	if err := db.Restore(backup.FilePath); err == nil {
+		t.Fatal("Restore of an unmigratable backup should fail")
+	}
+	if version, _ := NewEmbeddedMigrator(db.DB).CurrentVersion(); version != latest {
+		t.Errorf("Schema version after failed restore = %d, want %d", version, latest)
+	}
+	if _, err := NewRepository(db.DB).GetContentItem(string(item.ID)); err != nil {
+		t.Errorf("Item lost by failed restore: %v", err)
+	}
+}
+
+// TestApplyBackupRetention verifies only the most recent backups are kept.
+func TestApplyBackupRetention(t *testing.T) {
+	db := &DB{setupMigratedTestDB(t)}
+	defer db.Close()
+	dir := t.TempDir()
+
+	var ids []string
+	for i := 0; i < 3; i++ {
+		backup, err := db.Backup(dir)
+		if err != nil {
+			t.Fatalf("Backup failed: %v", err)
+		}
+		ids = append(ids, backup.ID)
+	}
+	os.WriteFile(filepath.Join(dir, "notes.txt"), []byte("keep"), 0644)
+
+	if deleted, _ := ApplyBackupRetention(dir, 0); deleted != 0 {
+		t.Errorf("Zero retention deleted %d, want 0", deleted)
+	}
+	deleted, err := ApplyBackupRetention(dir, 2)
+	if err != nil {
+		t.Fatalf("ApplyBackupRetention failed: %v", err)
+	}
+	if deleted != 1 {
+		t.Errorf("Deleted = %d, want 1", deleted)
+	}
+
+	backups, _ := ListBackups(dir)
+	if len(backups) != 2 || backups[0].ID != ids[2] || backups[1].ID != ids[1] {
+		t.Errorf("Remaining backups = %+v, want the two newest", backups)
+	}
+	if _, err := os.Stat(filepath.Join(dir, "notes.txt")); err != nil {
+		t.Errorf("Unrelated file removed: %v", err)
+	}
+}
+
+func TestIsBackupID(t *testing.T) {
+	tests := map[string]bool{
+		"memonexus-20260102-150405.000000.db":     true,
+		"memonexus-20260102-150405.000000.db.tmp": false,
+		"../memonexus-20260102-150405.000000.db":  false,
+		"other.db":                                false,
+		"memonexus-x/../../memonexus-escape.db":   false,
+	}
+	for id, want := range tests {
+		if got := IsBackupID(id); got != want {
+			t.Errorf("IsBackupID(%q) = %v, want %v", id, got, want)
+		}
+	}
+}
@@ -0,0 +1,620 @@
+// Package db provides collection (notebook) persistence.
+package db
+
+import (
+	"database/sql"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/kimhsiao/memonexus/backend/internal/models"
+	"github.com/kimhsiao/memonexus/backend/internal/uuid"
+)
+
+// Collections hold content items in an explicit order. Every change to a
+// collection, including to its membership or item order, bumps its version
+// so the collection and its items sync as one record. Memberships of items
+// that are in the trash or not synced yet are kept but not listed.
+
+// collectionColumns lists the collections columns read by scanCollection.
+const collectionColumns = `id, name, description, parent_id, position, is_deleted,
+	created_at, updated_at, version`
+
+// collectionSubtreeCTE selects the ids of a collection (the single
+// argument) and all of its descendants, deleted or not, as the CTE
+// "subtree".
+const collectionSubtreeCTE = `WITH RECURSIVE subtree(id) AS (
+	SELECT ?
+	UNION
+	SELECT c.id FROM collections c INNER JOIN subtree s ON c.parent_id = s.id
+)`
+
+var (
+	// ErrInvalidCollectionParent is returned when a collection is placed
+	// under a missing collection or under itself or one of its descendants.
+	ErrInvalidCollectionParent = errors.New("invalid parent collection")
+
+	// ErrInvalidCollectionItem is returned when a content item to add to a
+	// collection does not exist.
+	ErrInvalidCollectionItem = errors.New("invalid collection item")
+
+	// ErrInvalidCollectionOrder is returned when a reorder request does not
+	// list exactly the items or collections being reordered.
+	ErrInvalidCollectionOrder = errors.New("invalid collection order")
+)
+
+// CollectionNode is a collection with its child collections, for tree
+// listings.
+type CollectionNode struct {
+	*models.Collection
+	Children []*CollectionNode `json:"children"`
+}
+
+// scanCollection scans one row selected with collectionColumns.
+func scanCollection(row rowScanner) (*models.Collection, error) {
+	var c models.Collection
+	var parentID sql.NullString
+	err := row.Scan(&c.ID, &c.Name, &c.Description, &parentID, &c.Position, &c.IsDeleted,
+		&c.CreatedAt, &c.UpdatedAt, &c.Version)
+	if err != nil {
+		return nil, err
+	}
+	c.ParentID = models.UUID(parentID.String)
+	return &c, nil
+}
+
+// nullCollectionID stores an empty collection id as NULL.
+func nullCollectionID(id models.UUID) sql.NullString {
+	return sql.NullString{String: string(id), Valid: id != ""}
+}
+
+// ValidateCollection checks that a collection has a name and that its name
+// and description fit.
+func ValidateCollection(c *models.Collection) error {
+	c.Name = strings.TrimSpace(c.Name)
+	if c.Name == "" {
+		return fmt.Errorf("name is required")
+	}
+	if len([]rune(c.Name)) > 100 {
+		return fmt.Errorf("name must be 100 characters or less")
+	}
+	if len([]rune(c.Description)) > 1000 {
+		return fmt.Errorf("description must be 1000 characters or less")
+	}
+	return nil
+}
+
+// checkCollectionParent verifies that parentID, if set, is a live
+// collection outside the subtree of collection id.
+func checkCollectionParent(tx *sql.Tx, id, parentID models.UUID) error {
+	if parentID == "" {
+		return nil
+	}
+	var exists int
+	err := tx.QueryRow(`SELECT 1 FROM collections WHERE id = ? AND is_deleted = 0`, parentID).Scan(&exists)
+	if err == sql.ErrNoRows {
+		return fmt.Errorf("%w: collection %s not found", ErrInvalidCollectionParent, parentID)
+	}
+	if err != nil {
+		return err
+	}
+	if id == "" {
+		return nil
+	}
+
+	var inSubtree int
+	err = tx.QueryRow(collectionSubtreeCTE+` SELECT COUNT(*) FROM subtree WHERE id = ?`, id, parentID).Scan(&inSubtree)
+	if err != nil {
+		return err
+	}
+	if inSubtree > 0 {
+		return fmt.Errorf("%w: cannot move a collection under itself", ErrInvalidCollectionParent)
+	}
+	return nil
+}
+
+// CreateCollection creates a new collection. Returns
+// ErrInvalidCollectionParent if its parent does not exist.
+func (r *Repository) CreateCollection(c *models.Collection) error {
+	tx, err := r.db.Begin()
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	if err := checkCollectionParent(tx, "", c.ParentID); err != nil {
+		return err
+	}
+
+	now := time.Now().Unix()
+	c.ID = models.UUID(uuid.New())
+	c.IsDeleted = false
+	c.CreatedAt = now
+	c.UpdatedAt = now
+	c.Version = 1
+	c.ItemIDs = nil
+
+	_, err = tx.Exec(`
+	INSERT INTO collections (id, name, description, parent_id, position, is_deleted,
+		created_at, updated_at, version)
+	VALUES (?, ?, ?, ?, ?, 0, ?, ?, ?)
+	`, c.ID, c.Name, c.Description, nullCollectionID(c.ParentID), c.Position, c.CreatedAt, c.UpdatedAt, c.Version)
+	if err != nil {
+		return err
+	}
+	return tx.Commit()
+}
+
+// GetCollection retrieves a collection by ID, with the IDs of its listed
+// items in order. Returns sql.ErrNoRows if it does not exist or was deleted.
+func (r *Repository) GetCollection(id string) (*models.Collection, error) {
+	c, err := scanCollection(r.db.QueryRow(`
+	SELECT `+collectionColumns+` FROM collections WHERE id = ? AND is_deleted = 0
+	`, id))
+	if err != nil {
+		return nil, err
+	}
+
+	rows, err := r.db.Query(`
+	SELECT cli.content_id
+	FROM collection_items cli
+	INNER JOIN content_items ci ON ci.id = cli.content_id AND ci.is_deleted = 0
+	WHERE cli.collection_id = ?
+	ORDER BY cli.position, cli.added_at
+	`, id)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	c.ItemIDs = make([]models.UUID, 0)
+	for rows.Next() {
+		var itemID models.UUID
+		if err := rows.Scan(&itemID); err != nil {
+			return nil, err
+		}
+		c.ItemIDs = append(c.ItemIDs, itemID)
+	}
+	return c, rows.Err()
+}
+
+// ListCollections returns live collections in sibling order, then by name.
+func (r *Repository) ListCollections() ([]*models.Collection, error) {
+	return r.queryCollections(`
+	SELECT ` + collectionColumns + ` FROM collections WHERE is_deleted = 0
+	ORDER BY position, name COLLATE NOCASE
+	`)
+}
+
+// queryCollections runs a query selecting collectionColumns.
+func (r *Repository) queryCollections(query string, args ...interface{}) ([]*models.Collection, error) {
+	rows, err := r.db.Query(query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	collections := make([]*models.Collection, 0)
+	for rows.Next() {
+		c, err := scanCollection(rows)
+		if err != nil {
+			return nil, err
+		}
+		collections = append(collections, c)
+	}
+	return collections, rows.Err()
+}
+
+// ListCollectionTree returns live collections as a forest in sibling
+// order. Collections whose parent was deleted are listed at the top level.
+func (r *Repository) ListCollectionTree() ([]*CollectionNode, error) {
+	collections, err := r.ListCollections()
+	if err != nil {
+		return nil, err
+	}
+
+	nodes := make(map[models.UUID]*CollectionNode, len(collections))
+	for _, c := range collections {
+		nodes[c.ID] = &CollectionNode{Collection: c, Children: make([]*CollectionNode, 0)}
+	}
+	roots := make([]*CollectionNode, 0)
+	for _, c := range collections {
+		if parent, ok := nodes[c.ParentID]; ok {
+			parent.Children = append(parent.Children, nodes[c.ID])
+		} else {
+			roots = append(roots, nodes[c.ID])
+		}
+	}
+	return roots, nil
+}
+
+// UpdateCollection saves a collection's name, description, parent and
+// position and bumps its version. Returns sql.ErrNoRows if it does not
+// exist or was deleted, and ErrInvalidCollectionParent if the new parent
+// is missing or inside the collection.
+func (r *Repository) UpdateCollection(c *models.Collection) error {
+	tx, err := r.db.Begin()
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	var version int
+	err = tx.QueryRow(`SELECT version FROM collections WHERE id = ? AND is_deleted = 0`, c.ID).Scan(&version)
+	if err != nil {
+		return err
+	}
+	if err := checkCollectionParent(tx, c.ID, c.ParentID); err != nil {
+		return err
+	}
+
+	c.Version = version
+	c.Touch()
+	_, err = tx.Exec(`
+	UPDATE collections
+	SET name = ?, description = ?, parent_id = ?, position = ?, updated_at = ?, version = ?
+	WHERE id = ?
+	`, c.Name, c.Description, nullCollectionID(c.ParentID), c.Position, c.UpdatedAt, c.Version, c.ID)
+	if err != nil {
+		return err
+	}
+	return tx.Commit()
+}
+
+// DeleteCollection soft deletes a collection. Its items are untouched and
+// its child collections move to the top level of tree listings. The
+// version is bumped so the deletion syncs to other devices.
+func (r *Repository) DeleteCollection(id string) error {
+	query := `
+	UPDATE collections SET is_deleted = 1, updated_at = ?, version = version + 1
+	WHERE id = ? AND is_deleted = 0
+	`
+	return execAffectingRow(r.db.Exec(query, time.Now().Unix(), id))
+}
+
+// ListCollectionItems returns the listed items of a collection in order.
+// Returns sql.ErrNoRows if the collection does not exist.
+func (r *Repository) ListCollectionItems(id string, limit, offset int) ([]*models.ContentItem, error) {
+	var exists int
+	if err := r.db.QueryRow(`SELECT 1 FROM collections WHERE id = ? AND is_deleted = 0`, id).Scan(&exists); err != nil {
+		return nil, err
+	}
+
+	rows, err := r.db.Query(`
+	SELECT `+prefixedContentItemColumns+`
+	FROM collection_items cli
+	INNER JOIN content_items ci ON ci.id = cli.content_id AND ci.is_deleted = 0
+	WHERE cli.collection_id = ?
+	ORDER BY cli.position, cli.added_at
+	LIMIT ? OFFSET ?
+	`, id, limit, offset)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	items := make([]*models.ContentItem, 0)
+	for rows.Next() {
+		item, err := scanContentItem(rows)
+		if err != nil {
+			return nil, err
+		}
+		items = append(items, item)
+	}
+	return items, rows.Err()
+}
+
+// AddCollectionItems appends content items to the end of a collection, in
+// the order given, and returns the updated collection. Items already in the
+// collection keep their place. Returns sql.ErrNoRows if the collection does
+// not exist and ErrInvalidCollectionItem if an item does not.
+func (r *Repository) AddCollectionItems(id string, contentIDs []string) (*models.Collection, error) {
+	err := r.editCollectionItems(id, func(tx *sql.Tx, now int64) (bool, error) {
+		var next int
+		err := tx.QueryRow(`
+		SELECT COALESCE(MAX(position) + 1, 0) FROM collection_items WHERE collection_id = ?
+		`, id).Scan(&next)
+		if err != nil {
+			return false, err
+		}
+
+		changed := false
+		for _, contentID := range contentIDs {
+			var exists int
+			err := tx.QueryRow(`SELECT 1 FROM content_items WHERE id = ? AND is_deleted = 0`, contentID).Scan(&exists)
+			if err == sql.ErrNoRows {
+				return false, fmt.Errorf("%w: content item %s not found", ErrInvalidCollectionItem, contentID)
+			}
+			if err != nil {
+				return false, err
+			}
+
+			result, err := tx.Exec(`
+			INSERT OR IGNORE INTO collection_items (collection_id, content_id, position, added_at)
+			VALUES (?, ?, ?, ?)
+			`, id, contentID, next, now)
+			if err != nil {
+				return false, fmt.Errorf("failed to add item %s: %w", contentID, err)
+			}
+			if added, _ := result.RowsAffected(); added > 0 {
+				next++
+				changed = true
+			}
+		}
+		return changed, nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return r.GetCollection(id)
+}
+
+// RemoveCollectionItem removes a content item from a collection. Returns
+// sql.ErrNoRows if the collection does not exist or does not hold the item.
+func (r *Repository) RemoveCollectionItem(id, contentID string) error {
+	return r.editCollectionItems(id, func(tx *sql.Tx, now int64) (bool, error) {
+		err := execAffectingRow(tx.Exec(`
+		DELETE FROM collection_items WHERE collection_id = ? AND content_id = ?
+		`, id, contentID))
+		return err == nil, err
+	})
+}
+
+// ReorderCollectionItems puts the items of a collection in the given order
+// and returns the updated collection. contentIDs must list every listed
+// item exactly once; unlisted memberships (items in the trash or not
+// synced yet) move to the end. Returns sql.ErrNoRows if the collection does
+// not exist and ErrInvalidCollectionOrder if contentIDs do not match.
+func (r *Repository) ReorderCollectionItems(id string, contentIDs []string) (*models.Collection, error) {
+	err := r.editCollectionItems(id, func(tx *sql.Tx, now int64) (bool, error) {
+		rows, err := tx.Query(`
+		SELECT cli.content_id, ci.id IS NOT NULL AND ci.is_deleted = 0
+		FROM collection_items cli
+		LEFT JOIN content_items ci ON ci.id = cli.content_id
+		WHERE cli.collection_id = ?
+		ORDER BY cli.position, cli.added_at
+		`, id)
+		if err != nil {
+			return false, err
+		}
+		var current, unlisted []string
+		listed := make(map[string]bool)
+		for rows.Next() {
+			var contentID string
+			var live bool
+			if err := rows.Scan(&contentID, &live); err != nil {
+				rows.Close()
+				return false, err
+			}
+			current = append(current, contentID)
+			if live {
+				listed[contentID] = true
+			} else {
+				unlisted = append(unlisted, contentID)
+			}
+		}
+		rows.Close()
+		if err := rows.Err(); err != nil {
+			return false, err
+		}
+
+		if err := checkOrder(contentIDs, listed); err != nil {
+			return false, err
+		}
+		order := append(append([]string{}, contentIDs...), unlisted...)
+		if strings.Join(order, ",") == strings.Join(current, ",") {
+			return false, nil
+		}
+		for position, contentID := range order {
+			_, err := tx.Exec(`
+			UPDATE collection_items SET position = ? WHERE collection_id = ? AND content_id = ?
+			`, position, id, contentID)
+			if err != nil {
+				return false, fmt.Errorf("failed to move item %s: %w", contentID, err)
+			}
+		}
+		return true, nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return r.GetCollection(id)
+}
+
+// editCollectionItems runs edit on the items of a live collection in a
+// transaction, bumping the collection's version if edit reports a change.
+func (r *Repository) editCollectionItems(id string, edit func(tx *sql.Tx, now int64) (bool, error)) error {
+	tx, err := r.db.Begin()
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	var exists int
+	if err := tx.QueryRow(`SELECT 1 FROM collections WHERE id = ? AND is_deleted = 0`, id).Scan(&exists); err != nil {
+		return err
+	}
+
+	now := time.Now().Unix()
+	changed, err := edit(tx, now)
+	if err != nil {
+		return err
+	}
+	if changed {
+		_, err := tx.Exec(`
+		UPDATE collections SET updated_at = MAX(updated_at, ?), version = version + 1 WHERE id = ?
+		`, now, id)
+		if err != nil {
+			return err
+		}
+	}
+	return tx.Commit()
+}
+
+// ReorderCollections puts the live child collections of parentID (the top
+// level if empty) in the given order and returns them. ids must list every
+// child exactly once. Returns ErrInvalidCollectionOrder if they do not.
+func (r *Repository) ReorderCollections(parentID string, ids []string) ([]*models.Collection, error) {
+	tx, err := r.db.Begin()
+	if err != nil {
+		return nil, fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	rows, err := tx.Query(`
+	SELECT id, position FROM collections
+	WHERE is_deleted = 0 AND parent_id IS ?
+	`, nullCollectionID(models.UUID(parentID)))
+	if err != nil {
+		return nil, err
+	}
+	positions := make(map[string]int)
+	children := make(map[string]bool)
+	for rows.Next() {
+		var id string
+		var position int
+		if err := rows.Scan(&id, &position); err != nil {
+			rows.Close()
+			return nil, err
+		}
+		positions[id] = position
+		children[id] = true
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	if err := checkOrder(ids, children); err != nil {
+		return nil, err
+	}
+	now := time.Now().Unix()
+	for position, id := range ids {
+		if positions[id] == position {
+			continue
+		}
+		_, err := tx.Exec(`
+		UPDATE collections SET position = ?, updated_at = MAX(updated_at, ?), version = version + 1
+		WHERE id = ?
+		`, position, now, id)
+		if err != nil {
+			return nil, fmt.Errorf("failed to move collection %s: %w", id, err)
+		}
+	}
+
+	if err := tx.Commit(); err != nil {
+		return nil, fmt.Errorf("failed to commit transaction: %w", err)
+	}
+
+	ordered := make([]*models.Collection, 0, len(ids))
+	for _, id := range ids {
+		c, err := r.GetCollection(id)
+		if err != nil {
+			return nil, err
+		}
+		c.ItemIDs = nil
+		ordered = append(ordered, c)
+	}
+	return ordered, nil
+}
+
+// checkOrder verifies that ids lists every key of want exactly once.
+func checkOrder(ids []string, want map[string]bool) error {
+	if len(ids) != len(want) {
+		return fmt.Errorf("%w: expected %d ids, got %d", ErrInvalidCollectionOrder, len(want), len(ids))
+	}
+	seen := make(map[string]bool, len(ids))
+	for _, id := range ids {
+		if !want[id] || seen[id] {
+			return fmt.Errorf("%w: unexpected or repeated id %s", ErrInvalidCollectionOrder, id)
+		}
+		seen[id] = true
+	}
+	return nil
+}
+
+// =====================================================
+// Collection Sync
+// =====================================================
+
+// ListCollectionsForSync returns every collection, including deleted ones,
+// each with its complete membership in ItemIDs.
+func (r *Repository) ListCollectionsForSync() ([]*models.Collection, error) {
+	collections, err := r.queryCollections(`SELECT ` + collectionColumns + ` FROM collections ORDER BY id`)
+	if err != nil {
+		return nil, err
+	}
+	byID := make(map[models.UUID]*models.Collection, len(collections))
+	for _, c := range collections {
+		c.ItemIDs = make([]models.UUID, 0)
+		byID[c.ID] = c
+	}
+
+	rows, err := r.db.Query(`
+	SELECT collection_id, content_id FROM collection_items
+	ORDER BY collection_id, position, added_at
+	`)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	for rows.Next() {
+		var collectionID, contentID models.UUID
+		if err := rows.Scan(&collectionID, &contentID); err != nil {
+			return nil, err
+		}
+		if c, ok := byID[collectionID]; ok {
+			c.ItemIDs = append(c.ItemIDs, contentID)
+		}
+	}
+	return collections, rows.Err()
+}
+
+// ApplyRemoteCollection stores a collection received from another device,
+// replacing its membership with c.ItemIDs, if it is new or has a higher
+// version than the local copy. Returns true if the local copy changed.
+func (r *Repository) ApplyRemoteCollection(c *models.Collection) (bool, error) {
+	tx, err := r.db.Begin()
+	if err != nil {
+		return false, fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	result, err := tx.Exec(`
+	INSERT INTO collections (id, name, description, parent_id, position, is_deleted,
+		created_at, updated_at, version)
+	VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?)
+	ON CONFLICT(id) DO UPDATE SET
+		name = excluded.name, description = excluded.description,
+		parent_id = excluded.parent_id, position = excluded.position,
+		is_deleted = excluded.is_deleted, updated_at = excluded.updated_at,
+		version = excluded.version
+	WHERE excluded.version > collections.version
+	`, c.ID, c.Name, c.Description, nullCollectionID(c.ParentID), c.Position, c.IsDeleted,
+		c.CreatedAt, c.UpdatedAt, c.Version)
+	if err != nil {
+		return false, err
+	}
+	if applied, err := result.RowsAffected(); err != nil || applied == 0 {
+		return false, err
+	}
+
+	if _, err := tx.Exec(`DELETE FROM collection_items WHERE collection_id = ?`, c.ID); err != nil {
+		return false, err
+	}
+	for position, contentID := range c.ItemIDs {
+		_, err := tx.Exec(`
+		INSERT OR IGNORE INTO collection_items (collection_id, content_id, position, added_at)
+		VALUES (?, ?, ?, ?)
+		`, c.ID, contentID, position, c.UpdatedAt)
+		if err != nil {
+			return false, fmt.Errorf("failed to add item %s: %w", contentID, err)
+		}
+	}
+
+	if err := tx.Commit(); err != nil {
+		return false, fmt.Errorf("failed to commit transaction: %w", err)
+	}
+	return true, nil
+}
@@ -0,0 +1,163 @@
+// Package db tests for collections.
+package db
+
+import (
+	"database/sql"
+	"errors"
+	"reflect"
+	"testing"
+
+	"github.com/kimhsiao/memonexus/backend/internal/models"
+)
+
+// createCollection creates a collection or fails the test.
+func createCollection(t *testing.T, repo *Repository, name string, parentID models.UUID) *models.Collection {
+	t.Helper()
+	c := &models.Collection{Name: name, ParentID: parentID}
+	if err := repo.CreateCollection(c); err != nil {
+		t.Fatalf("CreateCollection failed: %v", err)
+	}
+	return c
+}
+
+// TestCollections verifies collections nest, hold items in order and bump
+// their version on every change.
+func TestCollections(t *testing.T) {
+	db := setupMigratedTestDB(t)
+	defer db.Close()
+
+	repo := NewRepository(db)
+	items := createTaggedItems(t, repo, "", "", "")
+	ids := []string{string(items[0].ID), string(items[1].ID), string(items[2].ID)}
+
+	course := createCollection(t, repo, "Course", "")
+	week := createCollection(t, repo, "Week 1", course.ID)
+	if err := repo.CreateCollection(&models.Collection{Name: "Orphan", ParentID: items[0].ID}); !errors.Is(err, ErrInvalidCollectionParent) {
+		t.Errorf("Create under missing parent = %v, want ErrInvalidCollectionParent", err)
+	}
+
+	c, err := repo.AddCollectionItems(string(week.ID), []string{ids[2], ids[0], ids[2]})
+	if err != nil {
+		t.Fatalf("AddCollectionItems failed: %v", err)
+	}
+	c, _ = repo.AddCollectionItems(string(week.ID), []string{ids[1], ids[0]})
+	if want := []models.UUID{items[2].ID, items[0].ID, items[1].ID}; !reflect.DeepEqual(c.ItemIDs, want) || c.Version != 3 {
+		t.Errorf("Collection after adds = %v v%d, want %v v3", c.ItemIDs, c.Version, want)
+	}
+	if _, err := repo.AddCollectionItems(string(week.ID), []string{"00000000-0000-4000-8000-000000000000"}); !errors.Is(err, ErrInvalidCollectionItem) {
+		t.Errorf("Add unknown item = %v, want ErrInvalidCollectionItem", err)
+	}
+
+	c, err = repo.ReorderCollectionItems(string(week.ID), []string{ids[0], ids[1], ids[2]})
+	if err != nil {
+		t.Fatalf("ReorderCollectionItems failed: %v", err)
+	}
+	if want := []models.UUID{items[0].ID, items[1].ID, items[2].ID}; !reflect.DeepEqual(c.ItemIDs, want) || c.Version != 4 {
+		t.Errorf("Collection after reorder = %v v%d, want %v v4", c.ItemIDs, c.Version, want)
+	}
+	if _, err := repo.ReorderCollectionItems(string(week.ID), []string{ids[0], ids[0], ids[2]}); !errors.Is(err, ErrInvalidCollectionOrder) {
+		t.Errorf("Reorder with repeated id = %v, want ErrInvalidCollectionOrder", err)
+	}
+
+	// Trashed items are hidden, but keep their place for when they return
+	if err := repo.DeleteContentItem(ids[1]); err != nil {
+		t.Fatalf("DeleteContentItem failed: %v", err)
+	}
+	listed, err := repo.ListCollectionItems(string(week.ID), 10, 0)
+	if err != nil || len(listed) != 2 || listed[0].ID != items[0].ID || listed[1].ID != items[2].ID {
+		t.Errorf("ListCollectionItems = %v, %v", listed, err)
+	}
+
+	if err := repo.RemoveCollectionItem(string(week.ID), ids[0]); err != nil {
+		t.Fatalf("RemoveCollectionItem failed: %v", err)
+	}
+	if err := repo.RemoveCollectionItem(string(week.ID), ids[0]); err != sql.ErrNoRows {
+		t.Errorf("Removing a missing item = %v, want sql.ErrNoRows", err)
+	}
+
+	// Filters match items in the collection and in nested collections
+	opts := &SearchOptions{Filters: NewFilterBuilder().Collection(string(course.ID)), Limit: 10}
+	response, err := repo.Search(opts)
+	if err != nil {
+		t.Fatalf("Search failed: %v", err)
+	}
+	if len(response.Results) != 1 || response.Results[0].Item.ID != items[2].ID {
+		t.Errorf("Collection filter matched %d items, want only the item left in Week 1", len(response.Results))
+	}
+
+	// Moving a collection under its own descendant is rejected
+	course.ParentID = week.ID
+	if err := repo.UpdateCollection(course); !errors.Is(err, ErrInvalidCollectionParent) {
+		t.Errorf("Move under descendant = %v, want ErrInvalidCollectionParent", err)
+	}
+
+	if err := repo.DeleteCollection(string(course.ID)); err != nil {
+		t.Fatalf("DeleteCollection failed: %v", err)
+	}
+	tree, _ := repo.ListCollectionTree()
+	if len(tree) != 1 || tree[0].ID != week.ID {
+		t.Errorf("Tree after deleting parent = %+v, want Week 1 at the top level", tree)
+	}
+}
+
+// TestReorderCollections verifies siblings must be listed exactly once and
+// only moved collections get a new version.
+func TestReorderCollections(t *testing.T) {
+	db := setupMigratedTestDB(t)
+	defer db.Close()
+
+	repo := NewRepository(db)
+	a := createCollection(t, repo, "A", "")
+	b := createCollection(t, repo, "B", "")
+	createCollection(t, repo, "Nested", a.ID)
+
+	if _, err := repo.ReorderCollections("", []string{string(a.ID)}); !errors.Is(err, ErrInvalidCollectionOrder) {
+		t.Errorf("Reorder missing a sibling = %v, want ErrInvalidCollectionOrder", err)
+	}
+	ordered, err := repo.ReorderCollections("", []string{string(b.ID), string(a.ID)})
+	if err != nil {
+		t.Fatalf("ReorderCollections failed: %v", err)
+	}
+	if ordered[0].ID != b.ID || ordered[0].Position != 0 || ordered[1].Position != 1 {
+		t.Errorf("Reordered = %+v", ordered)
+	}
+	if ordered[0].Version != 1 || ordered[1].Version != 2 {
+		t.Errorf("Versions = %d, %d; want 1 (unmoved), 2", ordered[0].Version, ordered[1].Version)
+	}
+}
+
+// TestCollectionSync verifies collections round-trip through the sync
+// methods with their full membership.
+func TestCollectionSync(t *testing.T) {
+	local := setupMigratedTestDB(t)
+	defer local.Close()
+	remote := setupMigratedTestDB(t)
+	defer remote.Close()
+
+	localRepo := NewRepository(local)
+	items := createTaggedItems(t, localRepo, "", "")
+	c := createCollection(t, localRepo, "Reading", "")
+	localRepo.AddCollectionItems(string(c.ID), []string{string(items[1].ID), string(items[0].ID)})
+
+	exported, err := localRepo.ListCollectionsForSync()
+	if err != nil {
+		t.Fatalf("ListCollectionsForSync failed: %v", err)
+	}
+
+	// The remote device has not synced the items yet; membership is kept
+	remoteRepo := NewRepository(remote)
+	applied, err := remoteRepo.ApplyRemoteCollection(exported[0])
+	if err != nil || !applied {
+		t.Fatalf("ApplyRemoteCollection = %v, %v", applied, err)
+	}
+	if applied, _ := remoteRepo.ApplyRemoteCollection(exported[0]); applied {
+		t.Error("Applying the same version again changed the collection")
+	}
+	synced, _ := remoteRepo.ListCollectionsForSync()
+	if len(synced) != 1 || !reflect.DeepEqual(synced[0].ItemIDs, []models.UUID{items[1].ID, items[0].ID}) {
+		t.Errorf("Synced collections = %+v", synced)
+	}
+	if got, _ := remoteRepo.GetCollection(string(c.ID)); len(got.ItemIDs) != 0 {
+		t.Errorf("Unsynced items listed: %v", got.ItemIDs)
+	}
+}
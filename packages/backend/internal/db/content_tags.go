@@ -0,0 +1,323 @@
+// Package db provides tag assignment for content items.
+package db
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"github.com/kimhsiao/memonexus/backend/internal/models"
+	"github.com/kimhsiao/memonexus/backend/internal/uuid"
+)
+
+// maxTagSegmentLength is the longest segment of a tag path, and so the
+// longest top-level tag name.
+const maxTagSegmentLength = 50
+
+// maxTagNameLength is the longest full tag path the tags table accepts.
+const maxTagNameLength = 255
+
+// defaultTagColor is the color given to tags created by assignment.
+const defaultTagColor = "#3B82F6"
+
+// ErrInvalidTagName is returned when a tag name is empty or too long: each
+// segment is limited to maxTagSegmentLength, the path to maxTagNameLength.
+var ErrInvalidTagName = errors.New("tag name segments must be 50 characters or less and paths 255 or less")
+
+// content_tags is the source of truth for which tags an item carries.
+// content_items.tags holds the same names comma-separated, in assignment
+// order, only so tags stay searchable through FTS; every write below keeps
+// the two in step. Tag names match case-insensitively.
+
+// validTagName reports whether a normalized tag path fits the segment and
+// path length limits.
+func validTagName(name string) bool {
+	if name == "" || utf8.RuneCountInString(name) > maxTagNameLength {
+		return false
+	}
+	for _, segment := range strings.Split(name, tagPathSeparator) {
+		if utf8.RuneCountInString(segment) > maxTagSegmentLength {
+			return false
+		}
+	}
+	return true
+}
+
+// normalizeTagNames trims names (and each segment of a tag path), drops
+// empty ones and case-insensitive duplicates, and rejects names that are
+// too long (see validTagName).
+func normalizeTagNames(names []string) ([]string, error) {
+	result := make([]string, 0, len(names))
+	seen := make(map[string]bool, len(names))
+	for _, name := range names {
+		name = normalizeTagPath(name)
+		if name == "" {
+			continue
+		}
+		if !validTagName(name) {
+			return nil, fmt.Errorf("%w: %q", ErrInvalidTagName, name)
+		}
+		key := strings.ToLower(name)
+		if !seen[key] {
+			seen[key] = true
+			result = append(result, name)
+		}
+	}
+	return result, nil
+}
+
+// resolveTags returns the tag for each name, creating missing tags and
+// restoring deleted ones.
+func resolveTags(ctx context.Context, tx *sql.Tx, names []string, now int64) ([]*models.Tag, error) {
+	tags := make([]*models.Tag, 0, len(names))
+	for _, name := range names {
+		tag, err := resolveTag(ctx, tx, name, now)
+		if err != nil {
+			return nil, err
+		}
+		tags = append(tags, tag)
+	}
+	return tags, nil
+}
+
+// resolveTag returns the live tag named name. A missing tag is created and
+// a deleted one restored, together with any missing or deleted ancestors.
+func resolveTag(ctx context.Context, tx *sql.Tx, name string, now int64) (*models.Tag, error) {
+	tag, err := scanTag(tx.QueryRowContext(ctx, `
+	SELECT `+tagColumns+` FROM tags
+	WHERE name = ? COLLATE NOCASE ORDER BY is_deleted, name LIMIT 1
+	`, name))
+	if err != nil && err != sql.ErrNoRows {
+		return nil, fmt.Errorf("failed to look up tag %q: %w", name, err)
+	}
+	if err == nil && !tag.IsDeleted {
+		return tag, nil
+	}
+
+	var parentID models.UUID
+	if parentPath := parentTagPath(name); parentPath != "" {
+		parent, err := resolveTag(ctx, tx, parentPath, now)
+		if err != nil {
+			return nil, err
+		}
+		parentID = parent.ID
+		// Use the existing parent's spelling of the path
+		name = parent.Name + tagPathSeparator + tagLeaf(name)
+	}
+
+	if tag != nil {
+		tag.IsDeleted = false
+		tag.ParentID = parentID
+		tag.UpdatedAt = now
+		_, err = tx.ExecContext(ctx, `UPDATE tags SET is_deleted = 0, parent_id = ?, updated_at = ? WHERE id = ?`,
+			nullTagID(parentID), now, tag.ID)
+		if err != nil {
+			return nil, fmt.Errorf("failed to restore tag %q: %w", name, err)
+		}
+		return tag, nil
+	}
+
+	tag = &models.Tag{
+		ID:        models.UUID(uuid.New()),
+		Name:      name,
+		ParentID:  parentID,
+		Color:     defaultTagColor,
+		CreatedAt: now,
+		UpdatedAt: now,
+	}
+	_, err = tx.ExecContext(ctx, `
+	INSERT INTO tags (id, name, color, is_deleted, created_at, updated_at, parent_id)
+	VALUES (?, ?, ?, 0, ?, ?, ?)
+	`, tag.ID, tag.Name, tag.Color, tag.CreatedAt, tag.UpdatedAt, nullTagID(tag.ParentID))
+	if err != nil {
+		return nil, fmt.Errorf("failed to create tag %q: %w", name, err)
+	}
+	return tag, nil
+}
+
+// resolveItemTags resolves the comma-separated item.Tags to tags and
+// rewrites item.Tags with their canonical names.
+func resolveItemTags(ctx context.Context, tx *sql.Tx, item *models.ContentItem, now int64) ([]*models.Tag, error) {
+	names, err := normalizeTagNames(strings.Split(item.Tags, ","))
+	if err != nil {
+		return nil, err
+	}
+	tags, err := resolveTags(ctx, tx, names, now)
+	if err != nil {
+		return nil, err
+	}
+	item.Tags = tagString(tags)
+	return tags, nil
+}
+
+// tagString returns the denormalized content_items.tags value for tags.
+func tagString(tags []*models.Tag) string {
+	names := make([]string, len(tags))
+	for i, tag := range tags {
+		names[i] = tag.Name
+	}
+	return strings.Join(names, ",")
+}
+
+// linkContentTags makes tags the complete tag set of a content item.
+// Links that already exist keep their assignment time.
+func linkContentTags(ctx context.Context, tx *sql.Tx, contentID string, tags []*models.Tag, now int64) error {
+	keep := make([]string, len(tags))
+	args := []interface{}{contentID}
+	for i, tag := range tags {
+		keep[i] = "?"
+		args = append(args, tag.ID)
+	}
+	query := `DELETE FROM content_tags WHERE content_id = ?`
+	if len(tags) > 0 {
+		query += ` AND tag_id NOT IN (` + strings.Join(keep, ", ") + `)`
+	}
+	if _, err := tx.ExecContext(ctx, query, args...); err != nil {
+		return fmt.Errorf("failed to unlink tags: %w", err)
+	}
+
+	for _, tag := range tags {
+		_, err := tx.ExecContext(ctx, `
+		INSERT OR IGNORE INTO content_tags (content_id, tag_id, assigned_at) VALUES (?, ?, ?)
+		`, contentID, tag.ID, now)
+		if err != nil {
+			return fmt.Errorf("failed to link tag %q: %w", tag.Name, err)
+		}
+	}
+	return nil
+}
+
+// contentTags returns the live tags linked to a content item, in
+// assignment order.
+func contentTags(tx *sql.Tx, contentID string) ([]*models.Tag, error) {
+	rows, err := tx.Query(`
+	SELECT `+tagColumns+`
+	FROM content_tags ct
+	INNER JOIN tags ON tags.id = ct.tag_id
+	WHERE ct.content_id = ? AND tags.is_deleted = 0
+	ORDER BY ct.assigned_at, ct.rowid
+	`, contentID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	tags := make([]*models.Tag, 0)
+	for rows.Next() {
+		tag, err := scanTag(rows)
+		if err != nil {
+			return nil, err
+		}
+		tags = append(tags, tag)
+	}
+	return tags, rows.Err()
+}
+
+// ListContentTags returns the tags assigned to a content item, in
+// assignment order. Returns sql.ErrNoRows if the item does not exist.
+func (r *Repository) ListContentTags(contentID string) ([]*models.Tag, error) {
+	tx, err := r.db.Begin()
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback()
+
+	var exists int
+	err = tx.QueryRow(`SELECT 1 FROM content_items WHERE id = ? AND is_deleted = 0`, contentID).Scan(&exists)
+	if err != nil {
+		return nil, err
+	}
+	return contentTags(tx, contentID)
+}
+
+// AssignTags adds tags to a content item, creating tags that do not exist
+// yet, and returns the updated item. Names already assigned are ignored.
+// Returns sql.ErrNoRows if the item does not exist.
+func (r *Repository) AssignTags(contentID string, names []string) (*models.ContentItem, error) {
+	return r.editContentTags(context.Background(), contentID, func(current []string) []string {
+		return append(current, names...)
+	})
+}
+
+// UnassignTags removes tags from a content item and returns the updated
+// item. Names not assigned are ignored; the tags themselves are kept.
+// Returns sql.ErrNoRows if the item does not exist.
+func (r *Repository) UnassignTags(contentID string, names []string) (*models.ContentItem, error) {
+	return r.editContentTags(context.Background(), contentID, func(current []string) []string {
+		kept := current[:0]
+		for _, name := range current {
+			removed := false
+			for _, n := range names {
+				if strings.EqualFold(name, strings.TrimSpace(n)) {
+					removed = true
+					break
+				}
+			}
+			if !removed {
+				kept = append(kept, name)
+			}
+		}
+		return kept
+	})
+}
+
+// editContentTags replaces an item's tag names with edit(current names).
+// The item's version is bumped only if its tags changed.
+func (r *Repository) editContentTags(ctx context.Context, contentID string, edit func(current []string) []string) (*models.ContentItem, error) {
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	var exists int
+	err = tx.QueryRowContext(ctx, `SELECT 1 FROM content_items WHERE id = ? AND is_deleted = 0`, contentID).Scan(&exists)
+	if err != nil {
+		return nil, err
+	}
+
+	current, err := contentTags(tx, contentID)
+	if err != nil {
+		return nil, err
+	}
+	before := tagString(current)
+	names, err := normalizeTagNames(edit(strings.Split(before, ",")))
+	if err != nil {
+		return nil, err
+	}
+
+	now := time.Now().Unix()
+	tags, err := resolveTags(ctx, tx, names, now)
+	if err != nil {
+		return nil, err
+	}
+	if after := tagString(tags); after != before {
+		_, err = tx.ExecContext(ctx, `
+		UPDATE content_items SET tags = ?, updated_at = ?, version = version + 1
+		WHERE id = ?
+		`, after, now, contentID)
+		if err != nil {
+			return nil, err
+		}
+		if err := linkContentTags(ctx, tx, contentID, tags, now); err != nil {
+			return nil, err
+		}
+
+		var version int
+		if err := tx.QueryRowContext(ctx, `SELECT version FROM content_items WHERE id = ?`, contentID).Scan(&version); err != nil {
+			return nil, err
+		}
+		if err := insertChangeLog(ctx, tx, contentID, "update", version, now); err != nil {
+			return nil, fmt.Errorf("failed to log tag change: %w", err)
+		}
+	}
+
+	if err := tx.Commit(); err != nil {
+		return nil, fmt.Errorf("failed to commit transaction: %w", err)
+	}
+	return r.GetContentItemContext(ctx, contentID)
+}
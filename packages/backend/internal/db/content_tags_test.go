@@ -0,0 +1,320 @@
+// Package db tests for content tag assignment.
+package db
+
+import (
+	"database/sql"
+	"errors"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/kimhsiao/memonexus/backend/internal/models"
+)
+
+// tagNames returns the names of tags in order.
+func tagNames(tags []*models.Tag) []string {
+	names := make([]string, len(tags))
+	for i, tag := range tags {
+		names[i] = tag.Name
+	}
+	return names
+}
+
+// TestContentTags verifies creating, assigning and unassigning tags keeps
+// content_tags and the denormalized string in step.
+func TestContentTags(t *testing.T) {
+	db := setupMigratedTestDB(t)
+	defer db.Close()
+
+	repo := NewRepository(db)
+	if err := repo.CreateTag(&models.Tag{Name: "Go", Color: "#00ADD8"}); err != nil {
+		t.Fatalf("CreateTag failed: %v", err)
+	}
+
+	// Names are trimmed, deduplicated and matched to existing tags
+	item := &models.ContentItem{Title: "Concurrency", MediaType: "web", Tags: " go , research,GO,"}
+	if err := repo.CreateContentItem(item); err != nil {
+		t.Fatalf("CreateContentItem failed: %v", err)
+	}
+	if item.Tags != "Go,research" {
+		t.Errorf("item.Tags = %q, want %q", item.Tags, "Go,research")
+	}
+	tags, err := repo.ListContentTags(string(item.ID))
+	if err != nil || !reflect.DeepEqual(tagNames(tags), []string{"Go", "research"}) {
+		t.Fatalf("ListContentTags = %v, %v", tagNames(tags), err)
+	}
+	if tags[0].Color != "#00ADD8" || tags[1].Color != defaultTagColor {
+		t.Errorf("Unexpected tag colors: %s, %s", tags[0].Color, tags[1].Color)
+	}
+
+	updated, err := repo.AssignTags(string(item.ID), []string{"golang", "research"})
+	if err != nil {
+		t.Fatalf("AssignTags failed: %v", err)
+	}
+	if updated.Tags != "Go,research,golang" || updated.Version != 2 {
+		t.Errorf("After assign: tags %q, version %d", updated.Tags, updated.Version)
+	}
+
+	// Assigning tags the item already has changes nothing
+	if updated, err = repo.AssignTags(string(item.ID), []string{"GOLANG"}); err != nil || updated.Version != 2 {
+		t.Errorf("No-op assign = version %d, %v; want version 2", updated.Version, err)
+	}
+
+	updated, err = repo.UnassignTags(string(item.ID), []string{"go", "missing"})
+	if err != nil {
+		t.Fatalf("UnassignTags failed: %v", err)
+	}
+	if updated.Tags != "research,golang" || updated.Version != 3 {
+		t.Errorf("After unassign: tags %q, version %d", updated.Tags, updated.Version)
+	}
+
+	// Updating the item replaces its tag set
+	updated.Tags = "rust"
+	if err := repo.UpdateContentItem(updated); err != nil {
+		t.Fatalf("UpdateContentItem failed: %v", err)
+	}
+	if tags, _ := repo.ListContentTags(string(item.ID)); !reflect.DeepEqual(tagNames(tags), []string{"rust"}) {
+		t.Errorf("Tags after update = %v, want [rust]", tagNames(tags))
+	}
+
+	// Unassigned tags are kept
+	all, err := repo.ListTags()
+	if err != nil || len(all) != 4 {
+		t.Errorf("ListTags = %d tags, %v; want 4", len(all), err)
+	}
+
+	if _, err := repo.AssignTags("00000000-0000-4000-8000-000000000000", []string{"x"}); err != sql.ErrNoRows {
+		t.Errorf("AssignTags on missing item = %v, want sql.ErrNoRows", err)
+	}
+	if _, err := repo.AssignTags(string(item.ID), []string{strings.Repeat("x", 51)}); !errors.Is(err, ErrInvalidTagName) {
+		t.Errorf("AssignTags with long name = %v, want ErrInvalidTagName", err)
+	}
+}
+
+// TestContentTags_deletedTag verifies assigning a deleted tag restores it
+// and deleted tags no longer match filters.
+func TestContentTags_deletedTag(t *testing.T) {
+	db := setupMigratedTestDB(t)
+	defer db.Close()
+
+	repo := NewRepository(db)
+	item := &models.ContentItem{Title: "Notes", ContentText: "notes", MediaType: "markdown", Tags: "archive"}
+	if err := repo.CreateContentItem(item); err != nil {
+		t.Fatalf("CreateContentItem failed: %v", err)
+	}
+	tags, _ := repo.ListContentTags(string(item.ID))
+	if err := repo.DeleteTag(string(tags[0].ID)); err != nil {
+		t.Fatalf("DeleteTag failed: %v", err)
+	}
+
+	resp, err := repo.Search(&SearchOptions{Query: "notes", Tags: "archive"})
+	if err != nil || resp.Total != 0 {
+		t.Errorf("Deleted tag matched %d items (%v)", resp.Total, err)
+	}
+
+	// The tag leaves the item, and saving the item does not restore it
+	saved, err := repo.GetContentItem(string(item.ID))
+	if err != nil || saved.Tags != "" || saved.Version != 2 {
+		t.Fatalf("Item after DeleteTag = %+v (%v), want no tags at version 2", saved, err)
+	}
+	var logged int
+	db.QueryRow(`SELECT COUNT(*) FROM change_log WHERE item_id = ? AND version = 2`, item.ID).Scan(&logged)
+	if logged != 1 {
+		t.Errorf("Expected a change_log entry for the unassignment, got %d", logged)
+	}
+	if err := repo.UpdateContentItem(saved); err != nil {
+		t.Fatalf("UpdateContentItem failed: %v", err)
+	}
+	if tag, _ := repo.GetTag(string(tags[0].ID)); !tag.IsDeleted {
+		t.Error("Saving a formerly tagged item restored the deleted tag")
+	}
+
+	if _, err := repo.AssignTags(string(item.ID), []string{"Archive"}); err != nil {
+		t.Fatalf("AssignTags failed: %v", err)
+	}
+	restored, err := repo.GetTag(string(tags[0].ID))
+	if err != nil || restored.IsDeleted {
+		t.Errorf("Expected tag restored, got %+v (%v)", restored, err)
+	}
+	resp, err = repo.Search(&SearchOptions{Query: "notes", Tags: "archive"})
+	if err != nil || resp.Total != 1 {
+		t.Errorf("Restored tag matched %d items (%v), want 1", resp.Total, err)
+	}
+}
+
+// TestSearch_exactTagFilter verifies tag filters no longer match substrings.
+func TestSearch_exactTagFilter(t *testing.T) {
+	db := setupMigratedTestDB(t)
+	defer db.Close()
+
+	repo := NewRepository(db)
+	for _, tags := range []string{"go", "golang", "go,rust", "Go"} {
+		item := &models.ContentItem{Title: "Notes " + tags, ContentText: "notes", MediaType: "web", Tags: tags}
+		if err := repo.CreateContentItem(item); err != nil {
+			t.Fatalf("CreateContentItem failed: %v", err)
+		}
+	}
+
+	tests := []struct {
+		query string
+		tags  string
+		want  int
+	}{
+		{"notes", "go", 3},
+		{"notes", "golang, rust", 2},
+		{"tag:go", "", 3},
+		{"notes -tag:go", "", 1},
+	}
+	for _, tt := range tests {
+		parsed, err := ParseQuery(tt.query)
+		if err != nil {
+			t.Fatalf("ParseQuery(%q) failed: %v", tt.query, err)
+		}
+		opts := &SearchOptions{Tags: tt.tags}
+		parsed.Apply(opts)
+		resp, err := repo.Search(opts)
+		if err != nil {
+			t.Fatalf("Search(%q, tags %q) failed: %v", tt.query, tt.tags, err)
+		}
+		if resp.Total != tt.want {
+			t.Errorf("Search(%q, tags %q) = %d results, want %d", tt.query, tt.tags, resp.Total, tt.want)
+		}
+	}
+}
+
+// TestMigrations_contentTagsBackfill verifies V9 links items to the tags
+// named in their tag strings.
+func TestMigrations_contentTagsBackfill(t *testing.T) {
+	db, err := sql.Open("sqlite", filepath.Join(t.TempDir(), "test.db"))
+	if err != nil {
+		t.Fatalf("Failed to open database: %v", err)
+	}
+	defer db.Close()
+
+	m := NewMigrator(db, "migrations")
+	if err := m.Initialize(); err != nil {
+		t.Fatalf("Initialize() failed: %v", err)
+	}
+	if err := m.Up(); err != nil {
+		t.Fatalf("Up() failed: %v", err)
+	}
+	if err := m.MigrateTo(8); err != nil {
+		t.Fatalf("MigrateTo(8) failed: %v", err)
+	}
+
+	// Items as written before V9: tag strings only, one tag deleted
+	_, err = db.Exec(`
+	INSERT INTO tags (id, name, created_at, updated_at, is_deleted)
+	VALUES ('00000000-0000-4000-8000-0000000000a1', 'Research', 100, 100, 1);
+	INSERT INTO content_items (id, title, media_type, tags, created_at, updated_at)
+	VALUES ('00000000-0000-4000-8000-000000000001', 'One', 'web', 'go, research,', 100, 100),
+	       ('00000000-0000-4000-8000-000000000002', 'Two', 'web', 'golang,GO', 200, 200),
+	       ('00000000-0000-4000-8000-000000000003', 'Three', 'web', '', 300, 300);
+	`)
+	if err != nil {
+		t.Fatalf("Failed to seed pre-V9 data: %v", err)
+	}
+	if err := m.Up(); err != nil {
+		t.Fatalf("Up() failed: %v", err)
+	}
+
+	repo := NewRepository(db)
+	want := map[string][]string{
+		"00000000-0000-4000-8000-000000000001": {"go", "Research"},
+		"00000000-0000-4000-8000-000000000002": {"go", "golang"},
+		"00000000-0000-4000-8000-000000000003": {},
+	}
+	for id, names := range want {
+		tags, err := repo.ListContentTags(id)
+		if err != nil {
+			t.Fatalf("ListContentTags failed: %v", err)
+		}
+		got := tagNames(tags)
+		if len(got) != len(names) {
+			t.Errorf("Item %s tags = %v, want %v", id, got, names)
+			continue
+		}
+		for _, name := range names {
+			if !strings.Contains(","+strings.Join(got, ",")+",", ","+name+",") {
+				t.Errorf("Item %s tags = %v, want %v", id, got, names)
+			}
+		}
+	}
+
+	all, err := repo.ListTags()
+	if err != nil || len(all) != 3 {
+		t.Errorf("ListTags = %v, %v; want go, golang, Research", tagNames(all), err)
+	}
+	for _, tag := range all {
+		if len(tag.ID) != 36 {
+			t.Errorf("Backfilled tag %s has invalid id %q", tag.Name, tag.ID)
+		}
+	}
+}
+
+// TestMigrations_deletedTagCleanup verifies V17 unassigns tags deleted
+// before DeleteTag did, dropping their names from item strings.
+func TestMigrations_deletedTagCleanup(t *testing.T) {
+	db, err := sql.Open("sqlite", filepath.Join(t.TempDir(), "test.db"))
+	if err != nil {
+		t.Fatalf("Failed to open database: %v", err)
+	}
+	defer db.Close()
+
+	m := NewMigrator(db, "migrations")
+	if err := m.Initialize(); err != nil {
+		t.Fatalf("Initialize() failed: %v", err)
+	}
+	if err := m.Up(); err != nil {
+		t.Fatalf("Up() failed: %v", err)
+	}
+	if err := m.MigrateTo(16); err != nil {
+		t.Fatalf("MigrateTo(16) failed: %v", err)
+	}
+
+	// "archive" was deleted but kept its link and its place in the string;
+	// a deleted "Go" shares its name with the live "go"
+	_, err = db.Exec(`
+	INSERT INTO tags (id, name, created_at, updated_at, is_deleted)
+	VALUES ('00000000-0000-4000-8000-0000000000a1', 'archive', 100, 100, 1),
+	       ('00000000-0000-4000-8000-0000000000a2', 'go', 100, 100, 0),
+	       ('00000000-0000-4000-8000-0000000000a3', 'Go', 100, 100, 1);
+	INSERT INTO content_items (id, title, media_type, tags, created_at, updated_at)
+	VALUES ('00000000-0000-4000-8000-000000000001', 'One', 'web', 'Archive,go', 100, 100),
+	       ('00000000-0000-4000-8000-000000000002', 'Two', 'web', 'go', 200, 200);
+	INSERT INTO content_tags (content_id, tag_id, assigned_at)
+	VALUES ('00000000-0000-4000-8000-000000000001', '00000000-0000-4000-8000-0000000000a1', 100),
+	       ('00000000-0000-4000-8000-000000000001', '00000000-0000-4000-8000-0000000000a2', 100),
+	       ('00000000-0000-4000-8000-000000000002', '00000000-0000-4000-8000-0000000000a2', 200);
+	`)
+	if err != nil {
+		t.Fatalf("Failed to seed pre-V17 data: %v", err)
+	}
+	if err := m.Up(); err != nil {
+		t.Fatalf("Up() failed: %v", err)
+	}
+
+	repo := NewRepository(db)
+	for id, want := range map[string]string{
+		"00000000-0000-4000-8000-000000000001": "go",
+		"00000000-0000-4000-8000-000000000002": "go",
+	} {
+		item, err := repo.GetContentItem(id)
+		if err != nil {
+			t.Fatalf("GetContentItem failed: %v", err)
+		}
+		if item.Tags != want {
+			t.Errorf("Item %s tags string = %q, want %q", id, item.Tags, want)
+		}
+	}
+
+	var links int
+	db.QueryRow(`SELECT COUNT(*) FROM content_tags WHERE tag_id = '00000000-0000-4000-8000-0000000000a1'`).Scan(&links)
+	if links != 0 {
+		t.Errorf("Deleted tag still has %d links", links)
+	}
+	if resp, err := repo.Search(&SearchOptions{Query: "archive"}); err != nil || resp.Total != 0 {
+		t.Errorf("Deleted tag name matched %d items (%v)", resp.Total, err)
+	}
+}
@@ -0,0 +1,82 @@
+// Package db provides opaque keyset pagination cursors.
+package db
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+
+	"github.com/kimhsiao/memonexus/backend/internal/models"
+)
+
+// ErrInvalidCursor is returned when a pagination cursor cannot be decoded
+// or was issued by a different kind of listing.
+var ErrInvalidCursor = errors.New("invalid cursor")
+
+// Cursor kinds keep list cursors and search cursors from being mixed up.
+const (
+	cursorKindList   = "list"
+	cursorKindSearch = "search"
+)
+
+// cursorPayload is the JSON inside a cursor token. Clients treat tokens as
+// opaque strings; the layout may change between releases.
+type cursorPayload struct {
+	Kind string `json:"k"`
+
+	// List cursors: position of the last item returned
+	CreatedAt int64  `json:"t,omitempty"`
+	ID        string `json:"id,omitempty"`
+	Pinned    bool   `json:"p,omitempty"` // Pinned-first listings only
+
+	// Search cursors: sort rank and rowid of the last result returned
+	Rank  float64 `json:"r,omitempty"`
+	RowID int64   `json:"row,omitempty"`
+
+	// Search cursors: the time blended ranking scores were computed at, so
+	// later pages rank against the same clock
+	Now int64 `json:"now,omitempty"`
+}
+
+// ContentPage is one page of a keyset-paginated listing.
+type ContentPage struct {
+	Items []*models.ContentItem
+
+	// NextCursor continues the listing after Items; empty on the last page
+	NextCursor string
+}
+
+// encodeCursor serializes a payload as an unpadded URL-safe base64 token.
+func encodeCursor(p cursorPayload) string {
+	data, _ := json.Marshal(p)
+	return base64.RawURLEncoding.EncodeToString(data)
+}
+
+// decodeCursor parses a token and checks it was issued for kind.
+func decodeCursor(token, kind string) (*cursorPayload, error) {
+	data, err := base64.RawURLEncoding.DecodeString(token)
+	if err != nil {
+		return nil, ErrInvalidCursor
+	}
+	var p cursorPayload
+	if err := json.Unmarshal(data, &p); err != nil || p.Kind != kind {
+		return nil, ErrInvalidCursor
+	}
+	return &p, nil
+}
+
+// ListCursor returns the cursor that continues a content listing after item.
+func ListCursor(item *models.ContentItem) string {
+	return encodeCursor(cursorPayload{
+		Kind:      cursorKindList,
+		CreatedAt: item.CreatedAt,
+		ID:        string(item.ID),
+		Pinned:    item.IsPinned,
+	})
+}
+
+// searchCursor returns the cursor that continues a search after the result
+// with the given sort rank and rowid, ranked at time now.
+func searchCursor(rank float64, rowid, now int64) string {
+	return encodeCursor(cursorPayload{Kind: cursorKindSearch, Rank: rank, RowID: rowid, Now: now})
+}
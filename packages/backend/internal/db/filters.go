@@ -3,8 +3,12 @@ package db
 
 import (
 	"fmt"
+	"slices"
+	"strconv"
 	"strings"
 	"time"
+
+	"github.com/kimhsiao/memonexus/backend/internal/models"
 )
 
 // Filter represents a single search filter condition.
@@ -93,7 +97,16 @@ func (f *DateRangeFilter) Args() []interface{} {
 	return args
 }
 
-// TagsFilter filters by tag names.
+// tagFilterSQL matches items linked to any live tag named in the IN list
+// or to one of its live descendants.
+const tagFilterSQL = "EXISTS (SELECT 1 FROM content_tags ct WHERE ct.content_id = ci.id AND ct.tag_id IN (" +
+	"WITH RECURSIVE tag_tree(id) AS (" +
+	"SELECT id FROM tags WHERE is_deleted = 0 AND name COLLATE NOCASE IN (%s)" +
+	" UNION SELECT t.id FROM tags t INNER JOIN tag_tree tt ON t.parent_id = tt.id WHERE t.is_deleted = 0)" +
+	" SELECT id FROM tag_tree))"
+
+// TagsFilter filters by tag names. A parent tag also matches items tagged
+// with its descendants.
 type TagsFilter struct {
 	Tags []string // Tag names to match
 }
@@ -112,18 +125,20 @@ func (f *TagsFilter) Valid() bool {
 }
 
 // SQL returns the SQL fragment for tag filtering.
-// Uses OR logic to match any of the specified tags.
+// Uses OR logic to match any of the specified tags. Tags are matched
+// exactly (case-insensitively) through content_tags, so "go" does not
+// match an item tagged "golang".
 func (f *TagsFilter) SQL() string {
-	var conditions []string
+	var placeholders []string
 	for _, tag := range f.Tags {
 		if tag != "" {
-			conditions = append(conditions, "ci.tags LIKE ?")
+			placeholders = append(placeholders, "?")
 		}
 	}
-	if len(conditions) == 0 {
+	if len(placeholders) == 0 {
 		return "1=0" // No valid tags, never match
 	}
-	return "(" + strings.Join(conditions, " OR ") + ")"
+	return fmt.Sprintf(tagFilterSQL, strings.Join(placeholders, ", "))
 }
 
 // Args returns the arguments for tag filtering.
@@ -131,12 +146,204 @@ func (f *TagsFilter) Args() []interface{} {
 	var args []interface{}
 	for _, tag := range f.Tags {
 		if tag != "" {
-			args = append(args, "%"+tag+"%")
+			args = append(args, strings.TrimSpace(tag))
 		}
 	}
 	return args
 }
 
+// ExcludeTagsFilter excludes items carrying any of the given tags.
+type ExcludeTagsFilter struct {
+	Tags []string // Tag names to exclude
+}
+
+// Valid checks if the exclusion filter is valid.
+func (f *ExcludeTagsFilter) Valid() bool {
+	return (&TagsFilter{Tags: f.Tags}).Valid()
+}
+
+// SQL returns the SQL fragment for tag exclusion.
+func (f *ExcludeTagsFilter) SQL() string {
+	return "NOT " + (&TagsFilter{Tags: f.Tags}).SQL()
+}
+
+// Args returns the arguments for tag exclusion.
+func (f *ExcludeTagsFilter) Args() []interface{} {
+	return (&TagsFilter{Tags: f.Tags}).Args()
+}
+
+// collectionFilterSQL matches items in the live collection with the given
+// id or in one of its live descendants.
+const collectionFilterSQL = "EXISTS (SELECT 1 FROM collection_items cli WHERE cli.content_id = ci.id AND cli.collection_id IN (" +
+	"WITH RECURSIVE collection_tree(id) AS (" +
+	"SELECT id FROM collections WHERE is_deleted = 0 AND id = ?" +
+	" UNION SELECT c.id FROM collections c INNER JOIN collection_tree ct ON c.parent_id = ct.id WHERE c.is_deleted = 0)" +
+	" SELECT id FROM collection_tree))"
+
+// CollectionFilter filters by collection. A parent collection also matches
+// items in its descendants.
+type CollectionFilter struct {
+	CollectionID string
+}
+
+// Valid checks if a collection is set.
+func (f *CollectionFilter) Valid() bool {
+	return strings.TrimSpace(f.CollectionID) != ""
+}
+
+// SQL returns the SQL fragment for collection filtering.
+func (f *CollectionFilter) SQL() string {
+	return collectionFilterSQL
+}
+
+// Args returns the arguments for collection filtering.
+func (f *CollectionFilter) Args() []interface{} {
+	return []interface{}{strings.TrimSpace(f.CollectionID)}
+}
+
+// PropertyOperators lists the comparison operators of property filters.
+// Two-character operators come first so conditions split on the longest
+// match.
+var PropertyOperators = []string{"!=", ">=", "<=", "=", ">", "<"}
+
+// propertyFilterSQL matches items with a live property compared according
+// to its type: numbers and dates numerically, strings and URLs as text
+// ignoring case. %[1]s is the operator.
+const propertyFilterSQL = "EXISTS (SELECT 1 FROM content_properties cp WHERE cp.content_id = ci.id" +
+	" AND cp.is_deleted = 0 AND cp.key = ? AND CASE cp.type" +
+	" WHEN 'number' THEN cp.value_num %[1]s ?" +
+	" WHEN 'date' THEN cp.value_num %[1]s ?" +
+	" ELSE cp.value %[1]s ? COLLATE NOCASE END)"
+
+// PropertyFilter filters by a typed item property, e.g. author = "Jane" or
+// published > 2024-01-01. The value is compared as a number against number
+// properties, as a date (the start of the given year, month or day) against
+// date properties and as text otherwise; items without the property never
+// match, even for "!=".
+type PropertyFilter struct {
+	Key   string
+	Op    string // One of PropertyOperators
+	Value string
+}
+
+// Valid checks the key, operator and value.
+func (f *PropertyFilter) Valid() bool {
+	if _, err := NormalizePropertyKey(f.Key); err != nil {
+		return false
+	}
+	return slices.Contains(PropertyOperators, f.Op) && strings.TrimSpace(f.Value) != ""
+}
+
+// SQL returns the SQL fragment for property filtering.
+func (f *PropertyFilter) SQL() string {
+	return fmt.Sprintf(propertyFilterSQL, f.Op)
+}
+
+// Args returns the arguments for property filtering. A value that is not a
+// number or a date compares as NULL against those types, never matching.
+func (f *PropertyFilter) Args() []interface{} {
+	key, _ := NormalizePropertyKey(f.Key)
+	value := strings.TrimSpace(f.Value)
+
+	var number, date interface{}
+	if n, err := strconv.ParseFloat(value, 64); err == nil {
+		number = n
+	}
+	if t, err := parsePropertyDate(value); err == nil {
+		date = t.Unix()
+	}
+	return []interface{}{key, number, date, value}
+}
+
+// SplitPropertyCondition splits a condition such as "published>=2024" into
+// key, operator and value. Returns false if it has no operator or no key.
+func SplitPropertyCondition(condition string) (key, op, value string, ok bool) {
+	idx := strings.IndexAny(condition, "!=<>")
+	if idx <= 0 {
+		return "", "", "", false
+	}
+	for _, candidate := range PropertyOperators {
+		if strings.HasPrefix(condition[idx:], candidate) {
+			return strings.TrimSpace(condition[:idx]), candidate, strings.TrimSpace(condition[idx+len(candidate):]), true
+		}
+	}
+	return "", "", "", false
+}
+
+// stateFlagColumns maps triage flags to their content_items columns.
+var stateFlagColumns = map[string]string{
+	models.StateFavorite: "ci.is_favorite",
+	models.StatePinned:   "ci.is_pinned",
+	models.StateArchived: "ci.is_archived",
+}
+
+// StateFlagFilter filters by a triage flag: favorite, pinned or archived.
+type StateFlagFilter struct {
+	Flag  string // models.StateFavorite, StatePinned or StateArchived
+	Value bool
+}
+
+// Valid checks the flag is known.
+func (f *StateFlagFilter) Valid() bool {
+	_, ok := stateFlagColumns[f.Flag]
+	return ok
+}
+
+// SQL returns the SQL fragment for flag filtering.
+func (f *StateFlagFilter) SQL() string {
+	return stateFlagColumns[f.Flag] + " = ?"
+}
+
+// Args returns the arguments for flag filtering.
+func (f *StateFlagFilter) Args() []interface{} {
+	return []interface{}{f.Value}
+}
+
+// ReadStatusFilter filters by read status.
+type ReadStatusFilter struct {
+	Status string // One of the models.ReadStatus values
+	Not    bool   // Match every other status instead
+}
+
+// Valid checks the read status is known.
+func (f *ReadStatusFilter) Valid() bool {
+	return models.IsReadStatus(f.Status)
+}
+
+// SQL returns the SQL fragment for read status filtering.
+func (f *ReadStatusFilter) SQL() string {
+	if f.Not {
+		return "ci.read_status != ?"
+	}
+	return "ci.read_status = ?"
+}
+
+// Args returns the arguments for read status filtering.
+func (f *ReadStatusFilter) Args() []interface{} {
+	return []interface{}{f.Status}
+}
+
+// ExcludeMatchFilter excludes items matching an FTS5 expression.
+// Used for queries made only of negated terms, which FTS5 cannot express.
+type ExcludeMatchFilter struct {
+	Match string // FTS5 MATCH expression
+}
+
+// Valid checks if the match expression is set.
+func (f *ExcludeMatchFilter) Valid() bool {
+	return strings.TrimSpace(f.Match) != ""
+}
+
+// SQL returns the SQL fragment for match exclusion.
+func (f *ExcludeMatchFilter) SQL() string {
+	return "ci.rowid NOT IN (SELECT rowid FROM content_fts WHERE content_fts MATCH ?)"
+}
+
+// Args returns the arguments for match exclusion.
+func (f *ExcludeMatchFilter) Args() []interface{} {
+	return []interface{}{f.Match}
+}
+
 // FilterBuilder builds SQL filter conditions from multiple filters.
 type FilterBuilder struct {
 	filters []Filter
@@ -186,6 +393,85 @@ func (fb *FilterBuilder) Tags(tags ...string) *FilterBuilder {
 	return fb
 }
 
+// ExcludeTags adds a tag exclusion filter.
+func (fb *FilterBuilder) ExcludeTags(tags ...string) *FilterBuilder {
+	filter := &ExcludeTagsFilter{Tags: tags}
+	if filter.Valid() {
+		fb.filters = append(fb.filters, filter)
+	}
+	return fb
+}
+
+// Collection adds a collection filter.
+func (fb *FilterBuilder) Collection(collectionID string) *FilterBuilder {
+	filter := &CollectionFilter{CollectionID: collectionID}
+	if filter.Valid() {
+		fb.filters = append(fb.filters, filter)
+	}
+	return fb
+}
+
+// Property adds a typed property filter, e.g. Property("author", "=", "Jane").
+func (fb *FilterBuilder) Property(key, op, value string) *FilterBuilder {
+	filter := &PropertyFilter{Key: key, Op: op, Value: value}
+	if filter.Valid() {
+		fb.filters = append(fb.filters, filter)
+	}
+	return fb
+}
+
+// Favorite adds a filter on the favorite flag.
+func (fb *FilterBuilder) Favorite(value bool) *FilterBuilder {
+	return fb.StateFlag(models.StateFavorite, value)
+}
+
+// Pinned adds a filter on the pinned flag.
+func (fb *FilterBuilder) Pinned(value bool) *FilterBuilder {
+	return fb.StateFlag(models.StatePinned, value)
+}
+
+// Archived adds a filter on the archived flag.
+func (fb *FilterBuilder) Archived(value bool) *FilterBuilder {
+	return fb.StateFlag(models.StateArchived, value)
+}
+
+// StateFlag adds a filter on a triage flag by name.
+func (fb *FilterBuilder) StateFlag(flag string, value bool) *FilterBuilder {
+	filter := &StateFlagFilter{Flag: flag, Value: value}
+	if filter.Valid() {
+		fb.filters = append(fb.filters, filter)
+	}
+	return fb
+}
+
+// ReadStatus adds a read status filter.
+func (fb *FilterBuilder) ReadStatus(status string) *FilterBuilder {
+	return fb.addReadStatus(status, false)
+}
+
+// ExcludeReadStatus adds a filter matching every read status but status.
+func (fb *FilterBuilder) ExcludeReadStatus(status string) *FilterBuilder {
+	return fb.addReadStatus(status, true)
+}
+
+// addReadStatus adds a read status filter if the status is known.
+func (fb *FilterBuilder) addReadStatus(status string, not bool) *FilterBuilder {
+	filter := &ReadStatusFilter{Status: status, Not: not}
+	if filter.Valid() {
+		fb.filters = append(fb.filters, filter)
+	}
+	return fb
+}
+
+// ExcludeMatch adds an FTS5 match exclusion filter.
+func (fb *FilterBuilder) ExcludeMatch(match string) *FilterBuilder {
+	filter := &ExcludeMatchFilter{Match: match}
+	if filter.Valid() {
+		fb.filters = append(fb.filters, filter)
+	}
+	return fb
+}
+
 // TagsFromCommaString adds tags from a comma-separated string.
 func (fb *FilterBuilder) TagsFromCommaString(tagsStr string) *FilterBuilder {
 	tags := strings.Split(tagsStr, ",")
@@ -2,6 +2,7 @@
 package db
 
 import (
+	"fmt"
 	"strings"
 	"testing"
 	"time"
@@ -176,8 +177,8 @@ func TestTagsFilter_SQL(t *testing.T) {
 		tags     []string
 		expected string
 	}{
-		{"single tag", []string{"python"}, "(ci.tags LIKE ?)"},
-		{"multiple tags", []string{"python", "golang"}, "(ci.tags LIKE ? OR ci.tags LIKE ?)"},
+		{"single tag", []string{"python"}, fmt.Sprintf(tagFilterSQL, "?")},
+		{"multiple tags", []string{"python", "golang"}, fmt.Sprintf(tagFilterSQL, "?, ?")},
 		{"empty tags", []string{}, "1=0"},
 	}
 
@@ -199,11 +200,11 @@ func TestTagsFilter_Args(t *testing.T) {
 	if len(args) != 2 {
 		t.Fatalf("Args() returned %d args, want 2", len(args))
 	}
-	if args[0] != "%python%" {
-		t.Errorf("Args()[0] = %q, want '%%python%%'", args[0])
+	if args[0] != "python" {
+		t.Errorf("Args()[0] = %q, want 'python'", args[0])
 	}
-	if args[1] != "%golang%" {
-		t.Errorf("Args()[1] = %q, want '%%golang%%'", args[1])
+	if args[1] != "golang" {
+		t.Errorf("Args()[1] = %q, want 'golang'", args[1])
 	}
 }
 
@@ -361,8 +362,8 @@ func TestFilterBuilder_TagsFromCommaString(t *testing.T) {
 	}
 
 	sql, args := fb.Build()
-	if !strings.Contains(sql, "ci.tags LIKE ?") {
-		t.Error("SQL should contain ci.tags LIKE ?")
+	if !strings.Contains(sql, "name COLLATE NOCASE IN (?, ?, ?)") {
+		t.Error("SQL should match the three tag names")
 	}
 	if len(args) != 3 {
 		t.Errorf("Args length = %d, want 3", len(args))
@@ -429,7 +430,7 @@ func TestFilterBuilder_Build(t *testing.T) {
 			from:          1000,
 			to:            2000,
 			tags:          []string{"python"},
-			expectedSQL:   "ci.media_type = ? AND ci.created_at >= ? AND ci.created_at <= ? AND (ci.tags LIKE ?)",
+			expectedSQL:   "ci.media_type = ? AND ci.created_at >= ? AND ci.created_at <= ? AND " + fmt.Sprintf(tagFilterSQL, "?"),
 			expectedArgLen: 4,
 		},
 	}
@@ -712,10 +713,10 @@ func TestParseSearchOptionsToFilters(t *testing.T) {
 	if !strings.Contains(sql, "ci.created_at") {
 		t.Error("SQL should contain date filter")
 	}
-	if !strings.Contains(sql, "ci.tags LIKE") {
+	if !strings.Contains(sql, "content_tags") {
 		t.Error("SQL should contain tags filter")
 	}
-	if len(args) != 5 { // 1 mediaType + 2 dates + 2 tags (2 LIKE conditions)
+	if len(args) != 5 { // 1 mediaType + 2 dates + 2 tag names
 		t.Errorf("Args length = %d, want 5", len(args))
 	}
 }
@@ -23,15 +23,24 @@ type HighlightOptions struct {
 
 	// TagClose is the HTML tag to use for closing highlight (default: </mark>)
 	TagClose string
+
+	// Ellipsis marks text trimmed from either side of a snippet (default: ...)
+	Ellipsis string
+
+	// ContextTokens is the snippet window size in tokens for FTS5 snippet()
+	// (default: 16, max: 64)
+	ContextTokens int
 }
 
 // DefaultHighlightOptions returns sensible defaults for highlighting.
 func DefaultHighlightOptions() *HighlightOptions {
 	return &HighlightOptions{
-		MaxResults: 3,
-		MaxChars:   150,
-		TagOpen:    "<mark>",
-		TagClose:   "</mark>",
+		MaxResults:    3,
+		MaxChars:      150,
+		TagOpen:       "<mark>",
+		TagClose:      "</mark>",
+		Ellipsis:      "...",
+		ContextTokens: 16,
 	}
 }
 
@@ -206,7 +215,7 @@ func buildHighlightPattern(terms []string) (*regexp.Regexp, error) {
 		patterns = append(patterns, escaped)
 	}
 
-	pattern := "(?i)(" + strings.Join(patterns, "|") + "))"
+	pattern := "(?i)(" + strings.Join(patterns, "|") + ")"
 	return regexp.Compile(pattern)
 }
 
@@ -302,7 +311,7 @@ func ExtractMatchedTerms(text, query string) []string {
 	matches := pattern.FindAllString(text, -1)
 	for _, match := range matches {
 		upperMatch := strings.ToUpper(match)
-		if !seen[upperMatch] {
+		if match != "" && !seen[upperMatch] {
 			matched = append(matched, match)
 			seen[upperMatch] = true
 		}
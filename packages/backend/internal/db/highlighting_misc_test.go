@@ -2,6 +2,7 @@
 package db
 
 import (
+	"reflect"
 	"regexp"
 	"testing"
 )
@@ -197,9 +198,14 @@ func TestBuildHighlightPattern(t *testing.T) {
 		t.Error("empty pattern should match 'test'")
 	}
 
-	// Note: The function has a known bug where it generates "(?i)(term))"
-	// with an extra closing parenthesis. This causes compilation errors
-	// for non-empty term lists. We skip those tests to avoid false failures.
+	// Terms are matched case-insensitively and literally
+	pattern, err = buildHighlightPattern([]string{"GO", "c++"})
+	if err != nil {
+		t.Fatalf("buildHighlightPattern() error = %v", err)
+	}
+	if got := pattern.FindAllString("Go and C++ and cxx", -1); !reflect.DeepEqual(got, []string{"Go", "C++"}) {
+		t.Errorf("pattern matches = %v, want [Go C++]", got)
+	}
 }
 
 // TestExtractSnippet verifies snippet extraction logic.
@@ -400,15 +406,17 @@ func TestIsHighlightQuery(t *testing.T) {
 
 // TestExtractMatchedTerms verifies matched term extraction.
 func TestExtractMatchedTerms(t *testing.T) {
-	// Test with empty query (edge case that works without buildHighlightPattern)
+	// Test with empty query
 	result := ExtractMatchedTerms("hello world", "")
 	if result != nil {
 		t.Errorf("ExtractMatchedTerms() with empty query should return nil, got %v", result)
 	}
 
-	// Note: Full testing of ExtractMatchedTerms requires buildHighlightPattern
-	// to be fixed first (it has a regex syntax bug). We test basic functionality
-	// that doesn't depend on the pattern building.
+	// Distinct matches keep the case found in the text
+	result = ExtractMatchedTerms("Hello world, hello again", "hello world")
+	if !reflect.DeepEqual(result, []string{"Hello", "world"}) {
+		t.Errorf("ExtractMatchedTerms() = %v, want [Hello world]", result)
+	}
 }
 
 // TestTruncateWords verifies intelligent word truncation.
@@ -0,0 +1,302 @@
+// Package db provides link operations for content items.
+package db
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"regexp"
+	"strings"
+	"time"
+
+	"github.com/kimhsiao/memonexus/backend/internal/models"
+)
+
+// ContentLinksVersion is the schema version that added content_links.
+// Content saved before it is parsed once with RebuildContentLinks.
+const ContentLinksVersion = 13
+
+var (
+	// wikiLinkPattern matches [[Title]], [[Title|alias]] and [[Title#heading]].
+	wikiLinkPattern = regexp.MustCompile(`\[\[([^\[\]\n]+?)\]\]`)
+
+	// idLinkPattern matches memonexus://item/<id>.
+	idLinkPattern = regexp.MustCompile(`memonexus://item/([0-9a-fA-F]{8}-[0-9a-fA-F]{4}-[0-9a-fA-F]{4}-[0-9a-fA-F]{4}-[0-9a-fA-F]{12})`)
+)
+
+// linkedMediaTypes lists the media types whose content is parsed for links.
+var linkedMediaTypes = map[string]bool{
+	"markdown": true,
+	"web":      true,
+}
+
+// contentLinkColumns lists the content_links columns read by
+// scanContentLink, followed by the source and target titles and whether the
+// target is live. Queries join content_items as s (source) and t (target).
+const contentLinkColumns = `l.source_id, l.kind, l.target, l.target_id, l.position, l.created_at,
+	s.title, t.title, t.id IS NOT NULL`
+
+// scanContentLink scans one row selected with contentLinkColumns.
+func scanContentLink(row rowScanner) (*models.ContentLink, error) {
+	var link models.ContentLink
+	var targetID, targetTitle sql.NullString
+	err := row.Scan(&link.SourceID, &link.Kind, &link.Target, &targetID, &link.Position,
+		&link.CreatedAt, &link.SourceTitle, &targetTitle, &link.Resolved)
+	if err != nil {
+		return nil, err
+	}
+	link.TargetID = models.UUID(targetID.String)
+	link.TargetTitle = targetTitle.String
+	return &link, nil
+}
+
+// ParseContentLinks returns the links written in content, in order of first
+// occurrence. Each target appears once; wiki titles compare case-insensitively
+// and are cut at an alias (|) or heading (#).
+func ParseContentLinks(content string) []models.ContentLink {
+	type match struct {
+		at   int
+		link models.ContentLink
+	}
+	var matches []match
+	for _, m := range wikiLinkPattern.FindAllStringSubmatchIndex(content, -1) {
+		title := content[m[2]:m[3]]
+		if i := strings.IndexAny(title, "|#"); i >= 0 {
+			title = title[:i]
+		}
+		if title = strings.TrimSpace(title); title != "" {
+			matches = append(matches, match{m[0], models.ContentLink{Kind: models.LinkKindWiki, Target: title}})
+		}
+	}
+	for _, m := range idLinkPattern.FindAllStringSubmatchIndex(content, -1) {
+		id := strings.ToLower(content[m[2]:m[3]])
+		matches = append(matches, match{m[0], models.ContentLink{Kind: models.LinkKindID, Target: id}})
+	}
+
+	// Merge the two kinds back into document order
+	for i := 1; i < len(matches); i++ {
+		for j := i; j > 0 && matches[j].at < matches[j-1].at; j-- {
+			matches[j], matches[j-1] = matches[j-1], matches[j]
+		}
+	}
+
+	seen := make(map[string]bool)
+	var links []models.ContentLink
+	for _, m := range matches {
+		key := m.link.Kind + ":" + strings.ToLower(m.link.Target)
+		if seen[key] {
+			continue
+		}
+		seen[key] = true
+		m.link.Position = len(links)
+		links = append(links, m.link)
+	}
+	return links
+}
+
+// replaceContentLinks rebuilds the links of item from its content inside
+// tx. Items whose media type is not parsed for links have none.
+func replaceContentLinks(ctx context.Context, tx *sql.Tx, item *models.ContentItem, now int64) error {
+	if _, err := tx.ExecContext(ctx, `DELETE FROM content_links WHERE source_id = ?`, item.ID); err != nil {
+		return fmt.Errorf("failed to clear links: %w", err)
+	}
+	if !linkedMediaTypes[item.MediaType] {
+		return nil
+	}
+
+	for _, link := range ParseContentLinks(item.ContentText) {
+		var targetID sql.NullString
+		switch link.Kind {
+		case models.LinkKindWiki:
+			err := tx.QueryRowContext(ctx, `
+			SELECT id FROM content_items
+			WHERE is_deleted = 0 AND title = ? COLLATE NOCASE AND id != ?
+			ORDER BY created_at, id LIMIT 1
+			`, link.Target, item.ID).Scan(&targetID)
+			if err != nil && err != sql.ErrNoRows {
+				return fmt.Errorf("failed to resolve link: %w", err)
+			}
+		case models.LinkKindID:
+			if link.Target == string(item.ID) {
+				continue
+			}
+			targetID = sql.NullString{String: link.Target, Valid: true}
+		}
+
+		_, err := tx.ExecContext(ctx, `
+		INSERT INTO content_links (source_id, kind, target, target_id, position, created_at)
+		VALUES (?, ?, ?, ?, ?, ?)
+		`, item.ID, link.Kind, link.Target, targetID, link.Position, now)
+		if err != nil {
+			return fmt.Errorf("failed to save link: %w", err)
+		}
+	}
+	return nil
+}
+
+// resolveWikiLinks points unresolved wiki links whose title matches the
+// item at it, inside tx. Links already resolved to another live item keep
+// their target.
+func resolveWikiLinks(ctx context.Context, tx *sql.Tx, id, title string) error {
+	_, err := tx.ExecContext(ctx, `
+	UPDATE content_links SET target_id = ?
+	WHERE kind = 'wiki' AND target = ? COLLATE NOCASE AND source_id != ?
+	  AND (target_id IS NULL OR target_id NOT IN (SELECT id FROM content_items WHERE is_deleted = 0))
+	`, id, title, id)
+	if err != nil {
+		return fmt.Errorf("failed to resolve links: %w", err)
+	}
+	return nil
+}
+
+// renameLinkTarget updates the wiki links to an item whose title changed
+// from oldTitle, inside tx. Linking items have [[oldTitle]] rewritten to the
+// new title, keeping any alias or heading, and are saved as ordinary
+// updates so the change syncs. Unresolved links matching the new title are
+// then resolved to the item.
+func renameLinkTarget(ctx context.Context, tx *sql.Tx, item *models.ContentItem, oldTitle string) error {
+	rows, err := tx.QueryContext(ctx, `
+	SELECT DISTINCT l.source_id FROM content_links l
+	JOIN content_items s ON s.id = l.source_id AND s.is_deleted = 0
+	WHERE l.kind = 'wiki' AND l.target_id = ? AND l.target = ? COLLATE NOCASE
+	`, item.ID, oldTitle)
+	if err != nil {
+		return fmt.Errorf("failed to find linking items: %w", err)
+	}
+	var sourceIDs []string
+	for rows.Next() {
+		var id string
+		if err := rows.Scan(&id); err != nil {
+			rows.Close()
+			return err
+		}
+		sourceIDs = append(sourceIDs, id)
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return err
+	}
+
+	pattern, err := regexp.Compile(`(?i)\[\[\s*` + regexp.QuoteMeta(oldTitle) + `\s*([|#][^\[\]\n]*)?\]\]`)
+	if err != nil {
+		return err
+	}
+	replacement := "[[" + strings.ReplaceAll(item.Title, "$", "$$") + "${1}]]"
+	for _, id := range sourceIDs {
+		source, err := scanContentItem(tx.QueryRowContext(ctx, `SELECT `+contentItemColumns+` FROM content_items WHERE id = ?`, id))
+		if err != nil {
+			return err
+		}
+		text := pattern.ReplaceAllString(source.ContentText, replacement)
+		if text == source.ContentText {
+			continue
+		}
+		source.ContentText = text
+		source.Touch()
+		if err := updateContentItem(ctx, tx, source); err != nil {
+			return fmt.Errorf("failed to update linking item %s: %w", id, err)
+		}
+	}
+	return resolveWikiLinks(ctx, tx, string(item.ID), item.Title)
+}
+
+// ListOutgoingLinks returns the links written in a content item, in order.
+// Returns sql.ErrNoRows if the item does not exist.
+func (r *Repository) ListOutgoingLinks(id string) ([]*models.ContentLink, error) {
+	if err := r.checkContentItem(id); err != nil {
+		return nil, err
+	}
+	return r.queryContentLinks(`WHERE l.source_id = ? ORDER BY l.position`, id)
+}
+
+// ListBacklinks returns the links from live items to a content item, most
+// recently updated source first. Returns sql.ErrNoRows if the item does not
+// exist.
+func (r *Repository) ListBacklinks(id string) ([]*models.ContentLink, error) {
+	if err := r.checkContentItem(id); err != nil {
+		return nil, err
+	}
+	return r.queryContentLinks(`
+	WHERE l.target_id = ? AND s.is_deleted = 0
+	ORDER BY s.updated_at DESC, s.id, l.position
+	`, id)
+}
+
+// ListUnresolvedLinks returns links from live items whose target is not a
+// live item, grouped by target.
+func (r *Repository) ListUnresolvedLinks(limit, offset int) ([]*models.ContentLink, error) {
+	return r.queryContentLinks(`
+	WHERE s.is_deleted = 0 AND t.id IS NULL
+	ORDER BY l.target COLLATE NOCASE, s.updated_at DESC, s.id
+	LIMIT ? OFFSET ?
+	`, limit, offset)
+}
+
+// queryContentLinks runs a content_links query with the given clauses.
+func (r *Repository) queryContentLinks(clauses string, args ...interface{}) ([]*models.ContentLink, error) {
+	rows, err := r.db.Query(`
+	SELECT `+contentLinkColumns+`
+	FROM content_links l
+	JOIN content_items s ON s.id = l.source_id
+	LEFT JOIN content_items t ON t.id = l.target_id AND t.is_deleted = 0
+	`+clauses, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	links := []*models.ContentLink{}
+	for rows.Next() {
+		link, err := scanContentLink(rows)
+		if err != nil {
+			return nil, err
+		}
+		links = append(links, link)
+	}
+	return links, rows.Err()
+}
+
+// checkContentItem returns sql.ErrNoRows unless id is a live content item.
+func (r *Repository) checkContentItem(id string) error {
+	var exists int
+	return r.db.QueryRow(`SELECT 1 FROM content_items WHERE id = ? AND is_deleted = 0`, id).Scan(&exists)
+}
+
+// RebuildContentLinks reparses the links of every live item, for content
+// saved before links were tracked. Returns the number of items parsed.
+func (r *Repository) RebuildContentLinks() (int, error) {
+	tx, err := r.db.Begin()
+	if err != nil {
+		return 0, fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	rows, err := tx.Query(`SELECT ` + contentItemColumns + ` FROM content_items WHERE is_deleted = 0`)
+	if err != nil {
+		return 0, err
+	}
+	var items []*models.ContentItem
+	for rows.Next() {
+		item, err := scanContentItem(rows)
+		if err != nil {
+			rows.Close()
+			return 0, err
+		}
+		items = append(items, item)
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return 0, err
+	}
+
+	now := time.Now().Unix()
+	for _, item := range items {
+		if err := replaceContentLinks(context.Background(), tx, item, now); err != nil {
+			return 0, err
+		}
+	}
+	if err := tx.Commit(); err != nil {
+		return 0, fmt.Errorf("failed to commit transaction: %w", err)
+	}
+	return len(items), nil
+}
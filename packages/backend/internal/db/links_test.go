@@ -0,0 +1,127 @@
+// Package db tests for content links.
+package db
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/kimhsiao/memonexus/backend/internal/models"
+)
+
+func TestParseContentLinks(t *testing.T) {
+	id := "0F0E0D0C-0000-4000-8000-000000000001"
+	content := "See [[Go Notes|notes]] and memonexus://item/" + id +
+		", then [[ go notes#Intro ]], [[Missing]] and [[]]."
+
+	got := ParseContentLinks(content)
+	want := []models.ContentLink{
+		{Kind: models.LinkKindWiki, Target: "Go Notes", Position: 0},
+		{Kind: models.LinkKindID, Target: "0f0e0d0c-0000-4000-8000-000000000001", Position: 1},
+		{Kind: models.LinkKindWiki, Target: "Missing", Position: 2},
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("ParseContentLinks() = %+v, want %+v", got, want)
+	}
+}
+
+// createNote creates a markdown item or fails the test.
+func createNote(t *testing.T, repo *Repository, title, content string) *models.ContentItem {
+	t.Helper()
+	item := &models.ContentItem{Title: title, ContentText: content, MediaType: "markdown"}
+	if err := repo.CreateContentItem(item); err != nil {
+		t.Fatalf("CreateContentItem failed: %v", err)
+	}
+	return item
+}
+
+// TestContentLinks verifies links resolve as targets appear, are listed as
+// backlinks and follow a renamed target.
+func TestContentLinks(t *testing.T) {
+	db := setupMigratedTestDB(t)
+	defer db.Close()
+
+	repo := NewRepository(db)
+	source := createNote(t, repo, "Index", "Read [[Go Notes|the notes]] and [[Rust]].")
+
+	links, err := repo.ListOutgoingLinks(string(source.ID))
+	if err != nil {
+		t.Fatalf("ListOutgoingLinks failed: %v", err)
+	}
+	if len(links) != 2 || links[0].Resolved || links[1].Resolved {
+		t.Fatalf("Outgoing links before targets exist = %+v", links)
+	}
+	unresolved, _ := repo.ListUnresolvedLinks(10, 0)
+	if len(unresolved) != 2 || unresolved[0].Target != "Go Notes" {
+		t.Errorf("Unresolved links = %+v", unresolved)
+	}
+
+	// Creating the target resolves the link
+	target := createNote(t, repo, "go notes", "")
+	backlinks, err := repo.ListBacklinks(string(target.ID))
+	if err != nil {
+		t.Fatalf("ListBacklinks failed: %v", err)
+	}
+	if len(backlinks) != 1 || backlinks[0].SourceID != source.ID || backlinks[0].SourceTitle != "Index" {
+		t.Errorf("Backlinks = %+v", backlinks)
+	}
+
+	// Renaming the target rewrites the link in the source
+	target.Title = "Golang"
+	if err := repo.UpdateContentItem(target); err != nil {
+		t.Fatalf("UpdateContentItem failed: %v", err)
+	}
+	updated, _ := repo.GetContentItem(string(source.ID))
+	if updated.ContentText != "Read [[Golang|the notes]] and [[Rust]]." || updated.Version != 2 {
+		t.Errorf("Source after rename = %q v%d", updated.ContentText, updated.Version)
+	}
+	links, _ = repo.ListOutgoingLinks(string(source.ID))
+	if !links[0].Resolved || links[0].TargetID != target.ID || links[0].TargetTitle != "Golang" {
+		t.Errorf("Link after rename = %+v", links[0])
+	}
+
+	// Trashed targets leave the link unresolved until restored
+	if err := repo.DeleteContentItem(string(target.ID)); err != nil {
+		t.Fatalf("DeleteContentItem failed: %v", err)
+	}
+	if unresolved, _ := repo.ListUnresolvedLinks(10, 0); len(unresolved) != 2 {
+		t.Errorf("Unresolved links with target in trash = %d, want 2", len(unresolved))
+	}
+	if _, err := repo.RestoreContentItem(string(target.ID)); err != nil {
+		t.Fatalf("RestoreContentItem failed: %v", err)
+	}
+	if backlinks, _ := repo.ListBacklinks(string(target.ID)); len(backlinks) != 1 {
+		t.Errorf("Backlinks after restore = %d, want 1", len(backlinks))
+	}
+
+	// Links by ID survive renames untouched
+	idSource := createNote(t, repo, "Refs", "memonexus://item/"+string(target.ID))
+	if backlinks, _ := repo.ListBacklinks(string(target.ID)); len(backlinks) != 2 {
+		t.Errorf("Backlinks with an ID link = %d, want 2", len(backlinks))
+	}
+	if err := repo.DeleteContentItem(string(idSource.ID)); err != nil {
+		t.Fatalf("DeleteContentItem failed: %v", err)
+	}
+	if backlinks, _ := repo.ListBacklinks(string(target.ID)); len(backlinks) != 1 {
+		t.Errorf("Backlinks from trashed source = %d, want 1", len(backlinks))
+	}
+}
+
+func TestRebuildContentLinks(t *testing.T) {
+	db := setupMigratedTestDB(t)
+	defer db.Close()
+
+	repo := NewRepository(db)
+	createNote(t, repo, "A", "")
+	b := createNote(t, repo, "B", "[[A]]")
+	if _, err := db.Exec(`DELETE FROM content_links`); err != nil {
+		t.Fatalf("Failed to clear links: %v", err)
+	}
+
+	parsed, err := repo.RebuildContentLinks()
+	if err != nil || parsed != 2 {
+		t.Fatalf("RebuildContentLinks() = %d, %v; want 2", parsed, err)
+	}
+	if links, _ := repo.ListOutgoingLinks(string(b.ID)); len(links) != 1 || !links[0].Resolved {
+		t.Errorf("Links after rebuild = %+v", links)
+	}
+}
@@ -4,36 +4,77 @@ package db
 import (
 	"crypto/sha256"
 	"database/sql"
+	"embed"
 	"encoding/hex"
 	"fmt"
+	"io/fs"
 	"os"
-	"path/filepath"
 	"sort"
 	"strconv"
 	"strings"
 	"time"
 )
 
+// embeddedMigrations holds the migration SQL files compiled into the binary,
+// so packaged builds do not depend on the source tree.
+//
+//go:embed migrations/*.sql
+var embeddedMigrations embed.FS
+
 // Migration represents a database schema migration.
 type Migration struct {
-	Version    int
-	AppliedAt  time.Time
+	Version     int
+	AppliedAt   time.Time
 	Description string
 	Checksum    string
 }
 
+// ChecksumDrift describes an applied migration whose SQL no longer matches
+// the checksum recorded when it was applied.
+type ChecksumDrift struct {
+	Version     int
+	Description string
+	Applied     string // Checksum stored in schema_migrations
+	Current     string // Checksum of the migration file, empty if missing
+}
+
+// migrationFile is an up migration found in the migrations directory.
+type migrationFile struct {
+	version     int
+	description string
+	up          string // File name
+}
+
 // Migrator handles database schema migrations.
 type Migrator struct {
-	db      *sql.DB
-	migrateDir string
+	db   *sql.DB
+	fsys fs.FS
 }
 
-// NewMigrator creates a new Migrator instance.
+// NewMigrator creates a new Migrator instance reading migrations from
+// migrateDir on disk.
 func NewMigrator(db *sql.DB, migrateDir string) *Migrator {
+	return NewMigratorFS(db, os.DirFS(migrateDir))
+}
+
+// NewMigratorFS creates a new Migrator instance reading migrations from the
+// root of fsys.
+func NewMigratorFS(db *sql.DB, fsys fs.FS) *Migrator {
 	return &Migrator{
-		db:         db,
-		migrateDir: migrateDir,
+		db:   db,
+		fsys: fsys,
+	}
+}
+
+// NewEmbeddedMigrator creates a new Migrator instance using the migrations
+// embedded in the binary.
+func NewEmbeddedMigrator(db *sql.DB) *Migrator {
+	fsys, err := fs.Sub(embeddedMigrations, "migrations")
+	if err != nil {
+		// The embed pattern guarantees the directory exists
+		panic(fmt.Sprintf("embedded migrations: %v", err))
 	}
+	return NewMigratorFS(db, fsys)
 }
 
 // Initialize creates the schema_migrations table if it doesn't exist.
@@ -56,6 +97,18 @@ func (m *Migrator) CurrentVersion() (int, error) {
 	return version, err
 }
 
+// LatestVersion returns the highest migration version available.
+func (m *Migrator) LatestVersion() (int, error) {
+	files, err := m.migrationFiles()
+	if err != nil {
+		return 0, err
+	}
+	if len(files) == 0 {
+		return 0, nil
+	}
+	return files[len(files)-1].version, nil
+}
+
 // GetAppliedMigrations returns all applied migrations.
 func (m *Migrator) GetAppliedMigrations() ([]Migration, error) {
 	rows, err := m.db.Query("SELECT version, applied_at, description, checksum FROM schema_migrations ORDER BY version")
@@ -78,29 +131,14 @@ func (m *Migrator) GetAppliedMigrations() ([]Migration, error) {
 	return migrations, nil
 }
 
-// Up applies all pending migrations.
-func (m *Migrator) Up() error {
-	// Get applied versions
-	applied, err := m.GetAppliedMigrations()
-	if err != nil {
-		return fmt.Errorf("failed to get applied migrations: %w", err)
-	}
-	appliedVersions := make(map[int]bool)
-	for _, mig := range applied {
-		appliedVersions[mig.Version] = true
-	}
-
-	// List migration files
-	entries, err := os.ReadDir(m.migrateDir)
+// migrationFiles lists the migrations available, sorted by version.
+func (m *Migrator) migrationFiles() ([]migrationFile, error) {
+	entries, err := fs.ReadDir(m.fsys, ".")
 	if err != nil {
-		return fmt.Errorf("failed to read migrations directory: %w", err)
+		return nil, fmt.Errorf("failed to read migrations directory: %w", err)
 	}
 
-	// Process migration files
-	var migrations []struct {
-		version int
-		name    string
-	}
+	var files []migrationFile
 	for _, entry := range entries {
 		if entry.IsDir() {
 			continue
@@ -112,47 +150,175 @@ func (m *Migrator) Up() error {
 			continue
 		}
 
-		parts := strings.Split(strings.TrimSuffix(name, ".up.sql"), "__")
+		parts := strings.SplitN(strings.TrimSuffix(name, ".up.sql"), "__", 2)
 		if len(parts) < 2 {
 			continue
 		}
-
-		versionStr := strings.TrimPrefix(parts[0], "V")
-		version, err := strconv.Atoi(versionStr)
+		version, err := strconv.Atoi(strings.TrimPrefix(parts[0], "V"))
 		if err != nil {
 			continue
 		}
 
-		migrations = append(migrations, struct {
-			version int
-			name    string
-		}{version, name})
+		files = append(files, migrationFile{version: version, description: parts[1], up: name})
 	}
 
-	// Sort by version
-	sort.Slice(migrations, func(i, j int) bool {
-		return migrations[i].version < migrations[j].version
+	sort.Slice(files, func(i, j int) bool {
+		return files[i].version < files[j].version
 	})
+	return files, nil
+}
+
+// pending returns the migrations up to target that are not applied yet.
+func (m *Migrator) pending(target int) ([]migrationFile, error) {
+	applied, err := m.GetAppliedMigrations()
+	if err != nil {
+		return nil, fmt.Errorf("failed to get applied migrations: %w", err)
+	}
+	appliedVersions := make(map[int]bool)
+	for _, mig := range applied {
+		appliedVersions[mig.Version] = true
+	}
+
+	files, err := m.migrationFiles()
+	if err != nil {
+		return nil, err
+	}
+	var pending []migrationFile
+	for _, file := range files {
+		if file.version <= target && !appliedVersions[file.version] {
+			pending = append(pending, file)
+		}
+	}
+	return pending, nil
+}
+
+// Pending lists the migrations Up would apply, without applying them.
+func (m *Migrator) Pending() ([]Migration, error) {
+	latest, err := m.LatestVersion()
+	if err != nil {
+		return nil, err
+	}
+	files, err := m.pending(latest)
+	if err != nil {
+		return nil, err
+	}
 
-	// Apply pending migrations
-	for _, mig := range migrations {
-		if appliedVersions[mig.version] {
-			continue // Already applied
+	migrations := make([]Migration, 0, len(files))
+	for _, file := range files {
+		content, err := fs.ReadFile(m.fsys, file.up)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read migration file: %w", err)
 		}
+		migrations = append(migrations, Migration{
+			Version:     file.version,
+			Description: file.description,
+			Checksum:    migrationChecksum(content),
+		})
+	}
+	return migrations, nil
+}
+
+// Up applies all pending migrations.
+func (m *Migrator) Up() error {
+	latest, err := m.LatestVersion()
+	if err != nil {
+		return err
+	}
+	return m.up(latest)
+}
 
-		if err := m.applyMigration(mig.version, mig.name); err != nil {
-			return fmt.Errorf("failed to apply migration V%d: %w", mig.version, err)
+// up applies the pending migrations up to target.
+func (m *Migrator) up(target int) error {
+	files, err := m.pending(target)
+	if err != nil {
+		return err
+	}
+	for _, file := range files {
+		if err := m.applyMigration(file.version, file.up); err != nil {
+			return fmt.Errorf("failed to apply migration V%d: %w", file.version, err)
 		}
 	}
+	return nil
+}
+
+// MigrateTo applies or rolls back migrations until target is the current
+// version. Target 0 rolls back every migration.
+func (m *Migrator) MigrateTo(target int) error {
+	latest, err := m.LatestVersion()
+	if err != nil {
+		return err
+	}
+	if target < 0 || target > latest {
+		return fmt.Errorf("target version %d out of range 0-%d", target, latest)
+	}
 
+	current, err := m.CurrentVersion()
+	if err != nil {
+		return err
+	}
+	if target >= current {
+		return m.up(target)
+	}
+	for current > target {
+		if err := m.Down(); err != nil {
+			return err
+		}
+		if current, err = m.CurrentVersion(); err != nil {
+			return err
+		}
+	}
 	return nil
 }
 
+// VerifyChecksums compares applied migrations with the migration files and
+// returns those whose SQL changed, or whose file is missing, since they
+// were applied.
+func (m *Migrator) VerifyChecksums() ([]ChecksumDrift, error) {
+	applied, err := m.GetAppliedMigrations()
+	if err != nil {
+		return nil, fmt.Errorf("failed to get applied migrations: %w", err)
+	}
+	files, err := m.migrationFiles()
+	if err != nil {
+		return nil, err
+	}
+	upFiles := make(map[int]string, len(files))
+	for _, file := range files {
+		upFiles[file.version] = file.up
+	}
+
+	var drift []ChecksumDrift
+	for _, mig := range applied {
+		current := ""
+		if name, ok := upFiles[mig.Version]; ok {
+			content, err := fs.ReadFile(m.fsys, name)
+			if err != nil {
+				return nil, fmt.Errorf("failed to read migration file: %w", err)
+			}
+			current = migrationChecksum(content)
+		}
+		if current != mig.Checksum {
+			drift = append(drift, ChecksumDrift{
+				Version:     mig.Version,
+				Description: mig.Description,
+				Applied:     mig.Checksum,
+				Current:     current,
+			})
+		}
+	}
+	return drift, nil
+}
+
+// migrationChecksum returns the SHA-256 checksum of migration SQL content.
+func migrationChecksum(content []byte) string {
+	hash := sha256.Sum256(content)
+	return hex.EncodeToString(hash[:])
+}
+
 // applyMigration applies a single migration.
 func (m *Migrator) applyMigration(version int, filename string) error {
 	// Read migration SQL
-	path := filepath.Join(m.migrateDir, filename)
-	content, err := os.ReadFile(path)
+	content, err := fs.ReadFile(m.fsys, filename)
 	if err != nil {
 		return fmt.Errorf("failed to read migration file: %w", err)
 	}
@@ -174,10 +340,7 @@ func (m *Migrator) applyMigration(version int, filename string) error {
 	description = strings.TrimPrefix(description, fmt.Sprintf("V%d__", version))
 	query := `INSERT INTO schema_migrations (version, applied_at, description, checksum)
 			  VALUES (?, ?, ?, ?)`
-	// Compute SHA-256 checksum of migration SQL content
-	hash := sha256.Sum256(content)
-	checksum := hex.EncodeToString(hash[:])
-	if _, err := tx.Exec(query, version, time.Now().Unix(), description, checksum); err != nil {
+	if _, err := tx.Exec(query, version, time.Now().Unix(), description, migrationChecksum(content)); err != nil {
 		return fmt.Errorf("failed to record migration: %w", err)
 	}
 
@@ -195,8 +358,7 @@ func (m *Migrator) Down() error {
 	}
 
 	// Find the down migration file (V%d__*.down.sql pattern)
-	pattern := fmt.Sprintf("V%d__*.down.sql", current)
-	matches, err := filepath.Glob(filepath.Join(m.migrateDir, pattern))
+	matches, err := fs.Glob(m.fsys, fmt.Sprintf("V%d__*.down.sql", current))
 	if err != nil {
 		return fmt.Errorf("failed to search for rollback migration: %w", err)
 	}
@@ -204,9 +366,7 @@ func (m *Migrator) Down() error {
 		return fmt.Errorf("no rollback migration found for version %d", current)
 	}
 	// Use the first match (there should be only one)
-	path := matches[0]
-
-	content, err := os.ReadFile(path)
+	content, err := fs.ReadFile(m.fsys, matches[0])
 	if err != nil {
 		return fmt.Errorf("failed to read rollback migration: %w", err)
 	}
@@ -5,8 +5,12 @@ import (
 	"database/sql"
 	"os"
 	"path/filepath"
+	"reflect"
 	"strings"
 	"testing"
+	"testing/fstest"
+
+	"github.com/kimhsiao/memonexus/backend/internal/models"
 )
 
 // TestNewMigrator verifies Migrator initialization.
@@ -28,8 +32,8 @@ func TestNewMigrator(t *testing.T) {
 		t.Error("Migrator.db not set correctly")
 	}
 
-	if m.migrateDir != migrateDir {
-		t.Errorf("Migrator.migrateDir = %q, want %q", m.migrateDir, migrateDir)
+	if m.fsys == nil {
+		t.Error("Migrator.fsys not set")
 	}
 }
 
@@ -271,3 +275,247 @@ func TestUp_appliesMigration(t *testing.T) {
 		t.Errorf("Up() second time failed: %v", err)
 	}
 }
+
+// setupMigratedTestDB opens a temporary database with every real migration
+// applied.
+func setupMigratedTestDB(t testing.TB) *sql.DB {
+	t.Helper()
+	db, err := sql.Open("sqlite", filepath.Join(t.TempDir(), "test.db"))
+	if err != nil {
+		t.Fatalf("Failed to open database: %v", err)
+	}
+	m := NewMigrator(db, "migrations")
+	if err := m.Initialize(); err != nil {
+		t.Fatalf("Initialize() failed: %v", err)
+	}
+	if err := m.Up(); err != nil {
+		t.Fatalf("Up() failed: %v", err)
+	}
+	return db
+}
+
+// TestMigrations_searchSchema applies the real migrations and rolls the
+// search schema changes back and forth.
+func TestMigrations_searchSchema(t *testing.T) {
+	db, err := sql.Open("sqlite", filepath.Join(t.TempDir(), "test.db"))
+	if err != nil {
+		t.Fatalf("Failed to open database: %v", err)
+	}
+	defer db.Close()
+
+	m := NewMigrator(db, "migrations")
+	if err := m.Initialize(); err != nil {
+		t.Fatalf("Initialize() failed: %v", err)
+	}
+	if err := m.Up(); err != nil {
+		t.Fatalf("Up() failed: %v", err)
+	}
+
+	repo := NewRepository(db)
+	item := &models.ContentItem{
+		Title:       "Election Coverage",
+		ContentText: "Polling numbers",
+		SourceURL:   "https://www.nytimes.com/2024/11/05/us/elections.html",
+		MediaType:   "web",
+		Summary:     "Swing states decide the outcome",
+	}
+	if err := repo.CreateContentItem(item); err != nil {
+		t.Fatalf("CreateContentItem failed: %v", err)
+	}
+
+	for _, query := range []string{`"nytimes"`, `"swing states"`, `"知識庫"`} {
+		if query == `"知識庫"` {
+			item.Title = "個人知識庫"
+			if err := repo.UpdateContentItem(item); err != nil {
+				t.Fatalf("UpdateContentItem failed: %v", err)
+			}
+		}
+		resp, err := repo.SearchSimple(query, 10)
+		if err != nil {
+			t.Fatalf("Search(%s) failed: %v", query, err)
+		}
+		if resp.Total != 1 {
+			t.Errorf("Search(%s) = %d results, want 1", query, resp.Total)
+		}
+	}
+
+	if err := repo.RebuildFTSIndex(); err != nil {
+		t.Fatalf("RebuildFTSIndex failed: %v", err)
+	}
+	if ok, err := repo.FTSIntegrityCheck(); err != nil || !ok {
+		t.Errorf("FTSIntegrityCheck = %v, %v after rebuild", ok, err)
+	}
+
+	// Roll back to the V2 schema and re-apply
+	if err := m.MigrateTo(2); err != nil {
+		t.Fatalf("MigrateTo(2) failed: %v", err)
+	}
+	var count int
+	err = db.QueryRow(`SELECT COUNT(*) FROM sqlite_master WHERE name IN ('content_fts_trigram', 'content_fts_vocab')`).Scan(&count)
+	if err != nil || count != 0 {
+		t.Errorf("Expected search tables dropped, found %d (%v)", count, err)
+	}
+	if err := m.Up(); err != nil {
+		t.Fatalf("Up() after rollback failed: %v", err)
+	}
+	resp, err := repo.SearchSimple(`"nytimes"`, 10)
+	if err != nil || resp.Total != 1 {
+		t.Errorf("Search after re-applying migrations = %v, %v", resp, err)
+	}
+}
+
+// migrationTestFS returns three migrations creating and dropping tables t1-t3.
+func migrationTestFS() fstest.MapFS {
+	fsys := fstest.MapFS{}
+	for _, name := range []string{"1__t1", "2__t2", "3__t3"} {
+		table := name[3:]
+		fsys["V"+name+".up.sql"] = &fstest.MapFile{Data: []byte("CREATE TABLE " + table + " (id INTEGER);")}
+		fsys["V"+name+".down.sql"] = &fstest.MapFile{Data: []byte("DROP TABLE " + table + ";")}
+	}
+	return fsys
+}
+
+// TestMigrateTo verifies migrating up and down to a target version and
+// listing pending migrations.
+func TestMigrateTo(t *testing.T) {
+	db, err := sql.Open("sqlite", filepath.Join(t.TempDir(), "test.db"))
+	if err != nil {
+		t.Fatalf("Failed to open database: %v", err)
+	}
+	defer db.Close()
+
+	m := NewMigratorFS(db, migrationTestFS())
+	if err := m.Initialize(); err != nil {
+		t.Fatalf("Initialize() failed: %v", err)
+	}
+
+	pending, err := m.Pending()
+	if err != nil || len(pending) != 3 || pending[0].Description != "t1" || len(pending[0].Checksum) != 64 {
+		t.Fatalf("Pending() = %+v, %v; want 3 migrations", pending, err)
+	}
+	if version, _ := m.CurrentVersion(); version != 0 {
+		t.Errorf("Pending() applied migrations, version = %d", version)
+	}
+
+	for _, tt := range []struct{ target, want int }{{2, 2}, {3, 3}, {1, 1}, {0, 0}, {3, 3}} {
+		if err := m.MigrateTo(tt.target); err != nil {
+			t.Fatalf("MigrateTo(%d) failed: %v", tt.target, err)
+		}
+		if version, _ := m.CurrentVersion(); version != tt.want {
+			t.Errorf("MigrateTo(%d): version = %d, want %d", tt.target, version, tt.want)
+		}
+	}
+	if pending, _ := m.Pending(); len(pending) != 0 {
+		t.Errorf("Pending() after migrating = %+v, want none", pending)
+	}
+	if err := m.MigrateTo(4); err == nil {
+		t.Error("MigrateTo(4) beyond the latest migration should fail")
+	}
+}
+
+// TestMigrateTo_embedded verifies the embedded migrations roll back step
+// by step to an empty schema and apply again.
+func TestMigrateTo_embedded(t *testing.T) {
+	db, err := sql.Open("sqlite", filepath.Join(t.TempDir(), "test.db"))
+	if err != nil {
+		t.Fatalf("Failed to open database: %v", err)
+	}
+	defer db.Close()
+
+	m := NewEmbeddedMigrator(db)
+	if err := m.Initialize(); err != nil {
+		t.Fatalf("Initialize() failed: %v", err)
+	}
+	if err := m.Up(); err != nil {
+		t.Fatalf("Up() failed: %v", err)
+	}
+	latest, _ := m.LatestVersion()
+
+	for target := latest - 1; target >= 0; target-- {
+		if err := m.MigrateTo(target); err != nil {
+			t.Fatalf("MigrateTo(%d) failed: %v", target, err)
+		}
+		if version, _ := m.CurrentVersion(); version != target {
+			t.Fatalf("MigrateTo(%d): version = %d", target, version)
+		}
+	}
+
+	var tables []string
+	rows, err := db.Query(`SELECT name FROM sqlite_master WHERE type = 'table' AND name NOT LIKE 'sqlite_%' ORDER BY name`)
+	if err != nil {
+		t.Fatalf("Failed to list tables: %v", err)
+	}
+	for rows.Next() {
+		var name string
+		rows.Scan(&name)
+		tables = append(tables, name)
+	}
+	rows.Close()
+	if !reflect.DeepEqual(tables, []string{"schema_migrations"}) {
+		t.Errorf("Tables after MigrateTo(0) = %v, want schema_migrations only", tables)
+	}
+
+	if err := m.MigrateTo(latest); err != nil {
+		t.Fatalf("MigrateTo(%d) failed: %v", latest, err)
+	}
+	if drift, err := m.VerifyChecksums(); err != nil || len(drift) != 0 {
+		t.Errorf("VerifyChecksums() = %+v, %v; want no drift", drift, err)
+	}
+}
+
+// TestVerifyChecksums verifies edited and missing migration files are
+// reported as drift.
+func TestVerifyChecksums(t *testing.T) {
+	db, err := sql.Open("sqlite", filepath.Join(t.TempDir(), "test.db"))
+	if err != nil {
+		t.Fatalf("Failed to open database: %v", err)
+	}
+	defer db.Close()
+
+	fsys := migrationTestFS()
+	m := NewMigratorFS(db, fsys)
+	if err := m.Initialize(); err != nil {
+		t.Fatalf("Initialize() failed: %v", err)
+	}
+	if err := m.Up(); err != nil {
+		t.Fatalf("Up() failed: %v", err)
+	}
+	if drift, err := m.VerifyChecksums(); err != nil || len(drift) != 0 {
+		t.Fatalf("VerifyChecksums() = %+v, %v; want no drift", drift, err)
+	}
+
+	fsys["V2__t2.up.sql"] = &fstest.MapFile{Data: []byte("CREATE TABLE t2 (id INTEGER, name TEXT);")}
+	delete(fsys, "V3__t3.up.sql")
+	drift, err := m.VerifyChecksums()
+	if err != nil {
+		t.Fatalf("VerifyChecksums() failed: %v", err)
+	}
+	versions := make([]int, len(drift))
+	for i, d := range drift {
+		versions[i] = d.Version
+	}
+	if !reflect.DeepEqual(versions, []int{2, 3}) || drift[0].Current == "" || drift[1].Current != "" {
+		t.Errorf("VerifyChecksums() = %+v, want drift for V2 (edited) and V3 (missing)", drift)
+	}
+}
+
+// TestEmbeddedMigrator verifies the embedded migrations match the files on
+// disk.
+func TestEmbeddedMigrator(t *testing.T) {
+	db := setupMigratedTestDB(t)
+	defer db.Close()
+
+	m := NewEmbeddedMigrator(db)
+	disk := NewMigrator(db, "migrations")
+	embedded, _ := m.LatestVersion()
+	latest, _ := disk.LatestVersion()
+	if embedded == 0 || embedded != latest {
+		t.Errorf("Embedded latest version = %d, want %d", embedded, latest)
+	}
+	if drift, err := m.VerifyChecksums(); err != nil || len(drift) != 0 {
+		t.Errorf("VerifyChecksums() = %+v, %v; want no drift", drift, err)
+	}
+	if pending, err := m.Pending(); err != nil || len(pending) != 0 {
+		t.Errorf("Pending() = %+v, %v; want none", pending, err)
+	}
+}
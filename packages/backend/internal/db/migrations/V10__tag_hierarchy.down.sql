@@ -0,0 +1,10 @@
+-- V10__tag_hierarchy.down.sql
+-- Rollback hierarchical tags
+-- Ancestor tags created by the upgrade are kept; without parent_id they are
+-- ordinary flat tags.
+
+DROP INDEX IF EXISTS idx_tags_parent_id;
+ALTER TABLE tags DROP COLUMN parent_id;
+
+-- Remove migration record
+DELETE FROM schema_migrations WHERE version = 10;
@@ -0,0 +1,47 @@
+-- V10__tag_hierarchy.up.sql
+-- Hierarchical tags. A tag's name is its full slash-separated path
+-- (e.g. research/ml/transformers) and parent_id points at the tag named by
+-- the path without its last segment. parent_id carries no foreign key so the
+-- column can be dropped again; the repository keeps it consistent.
+
+ALTER TABLE tags ADD COLUMN parent_id TEXT CHECK(parent_id IS NULL OR length(parent_id) = 36);
+
+CREATE INDEX idx_tags_parent_id ON tags(parent_id);
+
+-- Existing tags may already use slash paths. Create every missing ancestor
+-- path; rtrim(path, <path without slashes>) cuts the last segment.
+CREATE TEMP TABLE tag_ancestors AS
+WITH RECURSIVE ancestor(path) AS (
+    SELECT rtrim(rtrim(name, replace(name, '/', '')), '/')
+    FROM tags
+    WHERE is_deleted = 0 AND instr(name, '/') > 0
+    UNION
+    SELECT rtrim(rtrim(path, replace(path, '/', '')), '/')
+    FROM ancestor
+    WHERE instr(path, '/') > 0
+)
+SELECT path FROM ancestor WHERE path != '';
+
+-- Random version 4 UUIDs, as in V9
+INSERT INTO tags (id, name, color, created_at, updated_at, is_deleted)
+SELECT lower(hex(randomblob(4))) || '-' || lower(hex(randomblob(2))) || '-4' ||
+       substr(lower(hex(randomblob(2))), 2) || '-' ||
+       substr('89ab', 1 + abs(random()) % 4, 1) || substr(lower(hex(randomblob(2))), 2) || '-' ||
+       lower(hex(randomblob(6))),
+       path, '#3B82F6', CAST(strftime('%s', 'now') AS INTEGER), CAST(strftime('%s', 'now') AS INTEGER), 0
+FROM tag_ancestors
+WHERE lower(path) NOT IN (SELECT lower(name) FROM tags)
+GROUP BY lower(path);
+
+-- Ancestors that were deleted are restored so their children stay reachable
+UPDATE tags SET is_deleted = 0
+WHERE is_deleted = 1 AND lower(name) IN (SELECT lower(path) FROM tag_ancestors);
+
+UPDATE tags SET parent_id = (
+    SELECT p.id FROM tags p
+    WHERE p.name = rtrim(rtrim(tags.name, replace(tags.name, '/', '')), '/') COLLATE NOCASE
+    ORDER BY p.is_deleted, p.name LIMIT 1
+)
+WHERE instr(name, '/') > 0;
+
+DROP TABLE tag_ancestors;
@@ -0,0 +1,8 @@
+-- V11__content_revisions.down.sql
+-- Rollback content revision history
+
+DROP INDEX IF EXISTS idx_content_revisions_content;
+DROP TABLE IF EXISTS content_revisions;
+
+-- Remove migration record
+DELETE FROM schema_migrations WHERE version = 11;
@@ -0,0 +1,30 @@
+-- V11__content_revisions.up.sql
+-- Revision history for content items
+-- Each update that changes an item snapshots the state it replaces, so
+-- edits and last-write-wins sync overwrites can be undone. Revisions are
+-- local: restoring one writes a new version of the item, which syncs.
+
+CREATE TABLE IF NOT EXISTS content_revisions (
+    id TEXT PRIMARY KEY NOT NULL CHECK(length(id) = 36),
+    content_id TEXT NOT NULL CHECK(length(content_id) = 36),
+
+    -- Item version this revision captured
+    version INTEGER NOT NULL CHECK(version > 0),
+
+    title TEXT NOT NULL,
+    content_text TEXT NOT NULL,
+    source_url TEXT,
+    media_type TEXT NOT NULL,
+    tags TEXT NOT NULL DEFAULT '',
+    summary TEXT,
+    content_hash TEXT,
+
+    -- When the item was saved in this state
+    edited_at INTEGER NOT NULL CHECK(edited_at > 0),
+    -- When this state was replaced
+    created_at INTEGER NOT NULL CHECK(created_at > 0),
+
+    FOREIGN KEY (content_id) REFERENCES content_items(id) ON DELETE CASCADE
+);
+
+CREATE INDEX IF NOT EXISTS idx_content_revisions_content ON content_revisions(content_id, created_at DESC);
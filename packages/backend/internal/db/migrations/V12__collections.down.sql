@@ -0,0 +1,11 @@
+-- V12__collections.down.sql
+-- Rollback collections
+
+DROP INDEX IF EXISTS idx_collection_items_content;
+DROP INDEX IF EXISTS idx_collection_items_position;
+DROP TABLE IF EXISTS collection_items;
+DROP INDEX IF EXISTS idx_collections_parent;
+DROP TABLE IF EXISTS collections;
+
+-- Remove migration record
+DELETE FROM schema_migrations WHERE version = 12;
@@ -0,0 +1,42 @@
+-- V12__collections.up.sql
+-- Collections (notebooks): named, nestable, manually ordered groups of
+-- content items, such as a reading list. Unlike tags, an item's position in
+-- a collection is meaningful.
+-- Collections are soft-deleted and versioned so they sync like saved
+-- searches; membership changes bump the collection's version and sync with
+-- it. parent_id and collection_items.content_id carry no foreign keys, so
+-- synced rows may arrive before what they point at; reads join live rows.
+
+CREATE TABLE IF NOT EXISTS collections (
+    id TEXT PRIMARY KEY NOT NULL CHECK(length(id) = 36),
+    name TEXT NOT NULL CHECK(length(name) > 0 AND length(name) <= 100),
+    description TEXT NOT NULL DEFAULT '' CHECK(length(description) <= 1000),
+
+    -- Parent collection, NULL for top-level collections
+    parent_id TEXT CHECK(parent_id IS NULL OR length(parent_id) = 36),
+
+    -- Order among siblings
+    position INTEGER NOT NULL DEFAULT 0,
+
+    is_deleted INTEGER NOT NULL DEFAULT 0 CHECK(is_deleted IN (0, 1)),
+    created_at INTEGER NOT NULL CHECK(created_at > 0),
+    updated_at INTEGER NOT NULL CHECK(updated_at >= created_at),
+    version INTEGER NOT NULL DEFAULT 1 CHECK(version > 0)
+);
+
+CREATE INDEX IF NOT EXISTS idx_collections_parent ON collections(is_deleted, parent_id, position);
+
+CREATE TABLE IF NOT EXISTS collection_items (
+    collection_id TEXT NOT NULL,
+    content_id TEXT NOT NULL CHECK(length(content_id) = 36),
+
+    -- Order within the collection
+    position INTEGER NOT NULL DEFAULT 0,
+    added_at INTEGER NOT NULL CHECK(added_at > 0),
+
+    PRIMARY KEY (collection_id, content_id),
+    FOREIGN KEY (collection_id) REFERENCES collections(id) ON DELETE CASCADE
+);
+
+CREATE INDEX IF NOT EXISTS idx_collection_items_position ON collection_items(collection_id, position);
+CREATE INDEX IF NOT EXISTS idx_collection_items_content ON collection_items(content_id);
@@ -0,0 +1,9 @@
+-- V13__content_links.down.sql
+-- Rollback content links
+
+DROP INDEX IF EXISTS idx_content_links_unresolved;
+DROP INDEX IF EXISTS idx_content_links_target;
+DROP TABLE IF EXISTS content_links;
+
+-- Remove migration record
+DELETE FROM schema_migrations WHERE version = 13;
@@ -0,0 +1,25 @@
+-- V13__content_links.up.sql
+-- Links between content items, parsed from markdown and web content when an
+-- item is saved: [[Title]] wiki links and memonexus://item/<id> links.
+-- Rows are derived from content_text, so they are rebuilt on every save and
+-- are not synced themselves. target_id is resolved at save time and carries
+-- no foreign key; it is NULL while no live item matches a wiki link.
+
+CREATE TABLE IF NOT EXISTS content_links (
+    source_id TEXT NOT NULL CHECK(length(source_id) = 36),
+    kind TEXT NOT NULL CHECK(kind IN ('wiki', 'id')),
+
+    -- The target as written: a title for wiki links, an item ID otherwise
+    target TEXT NOT NULL CHECK(length(target) > 0),
+    target_id TEXT CHECK(target_id IS NULL OR length(target_id) = 36),
+
+    -- Order of first occurrence in the source
+    position INTEGER NOT NULL DEFAULT 0,
+    created_at INTEGER NOT NULL CHECK(created_at > 0),
+
+    PRIMARY KEY (source_id, kind, target),
+    FOREIGN KEY (source_id) REFERENCES content_items(id) ON DELETE CASCADE
+);
+
+CREATE INDEX IF NOT EXISTS idx_content_links_target ON content_links(target_id);
+CREATE INDEX IF NOT EXISTS idx_content_links_unresolved ON content_links(target COLLATE NOCASE) WHERE kind = 'wiki';
@@ -0,0 +1,12 @@
+-- V14__annotations.down.sql
+-- Rollback annotations
+
+DROP TRIGGER IF EXISTS annotations_au;
+DROP TRIGGER IF EXISTS annotations_ad;
+DROP TRIGGER IF EXISTS annotations_ai;
+DROP TABLE IF EXISTS annotations_fts;
+DROP INDEX IF EXISTS idx_annotations_content;
+DROP TABLE IF EXISTS annotations;
+
+-- Remove migration record
+DELETE FROM schema_migrations WHERE version = 14;
@@ -0,0 +1,51 @@
+-- V14__annotations.up.sql
+-- Highlights and notes on the text of content items.
+-- An annotation is anchored by character offsets into content_text plus a
+-- fingerprint of the highlighted quote and the text around it, so it can
+-- be found again after the item is edited. Annotations are soft-deleted
+-- and versioned so they sync like collections; content_id carries no
+-- foreign key, so synced annotations may arrive before their item.
+
+CREATE TABLE IF NOT EXISTS annotations (
+    id TEXT PRIMARY KEY NOT NULL CHECK(length(id) = 36),
+    content_id TEXT NOT NULL CHECK(length(content_id) = 36),
+
+    -- Anchor: [start_offset, end_offset) in characters of content_text when
+    -- the annotation was saved, the quoted text and its surroundings
+    start_offset INTEGER NOT NULL CHECK(start_offset >= 0),
+    end_offset INTEGER NOT NULL CHECK(end_offset > start_offset),
+    quote TEXT NOT NULL CHECK(length(quote) > 0),
+    prefix TEXT NOT NULL DEFAULT '',
+    suffix TEXT NOT NULL DEFAULT '',
+
+    note TEXT NOT NULL DEFAULT '' CHECK(length(note) <= 10000),
+    color TEXT NOT NULL DEFAULT 'yellow' CHECK(length(color) <= 20),
+
+    is_deleted INTEGER NOT NULL DEFAULT 0 CHECK(is_deleted IN (0, 1)),
+    created_at INTEGER NOT NULL CHECK(created_at > 0),
+    updated_at INTEGER NOT NULL CHECK(updated_at >= created_at),
+    version INTEGER NOT NULL DEFAULT 1 CHECK(version > 0)
+);
+
+CREATE INDEX IF NOT EXISTS idx_annotations_content ON annotations(content_id, is_deleted, start_offset);
+
+-- annotations_fts: full-text index of annotation notes
+CREATE VIRTUAL TABLE IF NOT EXISTS annotations_fts USING fts5(
+    note,
+    content=annotations,
+    content_rowid=rowid,
+    tokenize='unicode61 remove_diacritics 1'
+);
+
+CREATE TRIGGER IF NOT EXISTS annotations_ai AFTER INSERT ON annotations BEGIN
+    INSERT INTO annotations_fts(rowid, note) VALUES (new.rowid, new.note);
+END;
+
+CREATE TRIGGER IF NOT EXISTS annotations_ad AFTER DELETE ON annotations BEGIN
+    INSERT INTO annotations_fts(annotations_fts, rowid, note) VALUES ('delete', old.rowid, old.note);
+END;
+
+CREATE TRIGGER IF NOT EXISTS annotations_au AFTER UPDATE OF note ON annotations BEGIN
+    INSERT INTO annotations_fts(annotations_fts, rowid, note) VALUES ('delete', old.rowid, old.note);
+    INSERT INTO annotations_fts(rowid, note) VALUES (new.rowid, new.note);
+END;
@@ -0,0 +1,8 @@
+-- V15__content_properties.down.sql
+-- Rollback content properties
+
+DROP INDEX IF EXISTS idx_content_properties_key;
+DROP TABLE IF EXISTS content_properties;
+
+-- Remove migration record
+DELETE FROM schema_migrations WHERE version = 15;
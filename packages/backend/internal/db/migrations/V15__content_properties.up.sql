@@ -0,0 +1,28 @@
+-- V15__content_properties.up.sql
+-- Typed key/value metadata on content items, such as the author and
+-- publish date found by the parser or keys set by the user.
+-- value holds the canonical text of the value; value_num holds numbers and
+-- dates (as Unix seconds) so they compare numerically in filters.
+-- Properties are soft-deleted and versioned per key so they sync field by
+-- field; content_id carries no foreign key, so synced properties may arrive
+-- before their item.
+
+CREATE TABLE IF NOT EXISTS content_properties (
+    content_id TEXT NOT NULL CHECK(length(content_id) = 36),
+    key TEXT NOT NULL CHECK(length(key) > 0 AND length(key) <= 64),
+    type TEXT NOT NULL CHECK(type IN ('string', 'number', 'date', 'url')),
+    value TEXT NOT NULL CHECK(length(value) <= 2000),
+    value_num REAL,
+
+    -- Who set the value: 'parser' values never overwrite 'user' ones
+    source TEXT NOT NULL DEFAULT 'user' CHECK(source IN ('parser', 'user')),
+
+    is_deleted INTEGER NOT NULL DEFAULT 0 CHECK(is_deleted IN (0, 1)),
+    updated_at INTEGER NOT NULL CHECK(updated_at > 0),
+    version INTEGER NOT NULL DEFAULT 1 CHECK(version > 0),
+
+    PRIMARY KEY (content_id, key)
+);
+
+CREATE INDEX IF NOT EXISTS idx_content_properties_key ON content_properties(key, value_num)
+    WHERE is_deleted = 0;
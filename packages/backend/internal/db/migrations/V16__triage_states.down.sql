@@ -0,0 +1,14 @@
+-- V16__triage_states.down.sql
+-- Rollback triage states
+
+DROP INDEX IF EXISTS idx_content_items_listing;
+ALTER TABLE content_items DROP COLUMN archived_changed_at;
+ALTER TABLE content_items DROP COLUMN read_changed_at;
+ALTER TABLE content_items DROP COLUMN pinned_changed_at;
+ALTER TABLE content_items DROP COLUMN favorite_changed_at;
+ALTER TABLE content_items DROP COLUMN is_archived;
+ALTER TABLE content_items DROP COLUMN read_progress;
+ALTER TABLE content_items DROP COLUMN read_status;
+
+-- Remove migration record
+DELETE FROM schema_migrations WHERE version = 16;
@@ -0,0 +1,22 @@
+-- V16__triage_states.up.sql
+-- Triage states on content items: read status and progress and the
+-- archived flag, next to the pinned and favorite flags added in V8.
+-- Archived items are left out of the default listing but stay searchable.
+-- Each state records when it last changed, so sync can merge concurrent
+-- changes field by field: the newer change of each state wins, whichever
+-- device made it. Read status and progress change together.
+
+ALTER TABLE content_items ADD COLUMN read_status TEXT NOT NULL DEFAULT 'unread'
+    CHECK(read_status IN ('unread', 'in_progress', 'read'));
+ALTER TABLE content_items ADD COLUMN read_progress REAL NOT NULL DEFAULT 0
+    CHECK(read_progress >= 0 AND read_progress <= 1);
+ALTER TABLE content_items ADD COLUMN is_archived INTEGER NOT NULL DEFAULT 0 CHECK(is_archived IN (0, 1));
+
+ALTER TABLE content_items ADD COLUMN favorite_changed_at INTEGER NOT NULL DEFAULT 0;
+ALTER TABLE content_items ADD COLUMN pinned_changed_at INTEGER NOT NULL DEFAULT 0;
+ALTER TABLE content_items ADD COLUMN read_changed_at INTEGER NOT NULL DEFAULT 0;
+ALTER TABLE content_items ADD COLUMN archived_changed_at INTEGER NOT NULL DEFAULT 0;
+
+-- Default listing: live, unarchived items, pinned first, newest first
+CREATE INDEX IF NOT EXISTS idx_content_items_listing
+    ON content_items(is_deleted, is_archived, is_pinned DESC, created_at DESC, id DESC);
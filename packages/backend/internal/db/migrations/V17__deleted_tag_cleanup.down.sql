@@ -0,0 +1,7 @@
+-- V17__deleted_tag_cleanup.down.sql
+-- Rollback deleted tag cleanup
+-- The cleanup only removed links and names of deleted tags, which earlier
+-- versions treat the same way, so nothing is restored.
+
+-- Remove migration record
+DELETE FROM schema_migrations WHERE version = 17;
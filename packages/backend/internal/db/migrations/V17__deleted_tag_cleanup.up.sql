@@ -0,0 +1,44 @@
+-- V17__deleted_tag_cleanup.up.sql
+-- Deleting a tag used to leave its content_tags links and its name in item
+-- tag strings (and FTS), so saving such an item restored the tag. DeleteTag
+-- now unassigns the tag; this applies the same to tags deleted before. The
+-- deletion stands: links to deleted tags are removed and their names are
+-- dropped from item strings, unless a live tag has the same name.
+
+CREATE TEMP TABLE deleted_tag_names AS
+SELECT DISTINCT lower(name) AS name FROM tags
+WHERE is_deleted = 1 AND lower(name) NOT IN (SELECT lower(name) FROM tags WHERE is_deleted = 0);
+
+CREATE TEMP TABLE item_tag_names AS
+WITH RECURSIVE split(content_id, pos, name, rest) AS (
+    SELECT id, 0, '', tags || ','
+    FROM content_items
+    WHERE tags IS NOT NULL AND tags != ''
+    UNION ALL
+    SELECT content_id, pos + 1,
+           trim(substr(rest, 1, instr(rest, ',') - 1)),
+           substr(rest, instr(rest, ',') + 1)
+    FROM split
+    WHERE rest != ''
+)
+SELECT content_id, pos, name
+FROM split
+WHERE name != '';
+
+UPDATE content_items
+SET tags = coalesce((
+    SELECT group_concat(n.name, ',' ORDER BY n.pos)
+    FROM item_tag_names n
+    WHERE n.content_id = content_items.id
+      AND lower(n.name) NOT IN (SELECT name FROM deleted_tag_names)
+), '')
+WHERE id IN (
+    SELECT content_id FROM item_tag_names
+    WHERE lower(name) IN (SELECT name FROM deleted_tag_names)
+);
+
+DELETE FROM content_tags
+WHERE tag_id IN (SELECT id FROM tags WHERE is_deleted = 1);
+
+DROP TABLE item_tag_names;
+DROP TABLE deleted_tag_names;
@@ -0,0 +1,7 @@
+-- V18__tag_path_length.down.sql
+-- Rollback tag path length
+-- The rebuilt tags table only accepts longer names. Earlier versions check
+-- name lengths themselves, so the table is kept as it is.
+
+-- Remove migration record
+DELETE FROM schema_migrations WHERE version = 18;
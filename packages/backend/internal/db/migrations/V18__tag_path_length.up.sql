@@ -0,0 +1,36 @@
+-- V18__tag_path_length.up.sql
+-- Tag names are full slash-separated paths (V10), so the 50 character limit
+-- from V1 capped the hierarchy at about three levels. Each segment keeps
+-- that limit, checked by the repository, and a whole path may now be up to
+-- 255 characters. SQLite cannot change a CHECK constraint in place, so the
+-- tags table is rebuilt with its indexes. Dropping tags would cascade to
+-- content_tags, so the links are set aside and put back.
+
+CREATE TEMP TABLE content_tags_copy AS
+SELECT content_id, tag_id, assigned_at FROM content_tags;
+DELETE FROM content_tags;
+
+CREATE TABLE tags_new (
+    id TEXT PRIMARY KEY NOT NULL CHECK(length(id) = 36),
+    name TEXT NOT NULL UNIQUE CHECK(length(name) > 0 AND length(name) <= 255),
+    color TEXT DEFAULT '#3B82F6' CHECK(length(color) = 7 AND color LIKE '#%'),
+    created_at INTEGER NOT NULL CHECK(created_at > 0),
+    updated_at INTEGER NOT NULL CHECK(updated_at >= created_at),
+    is_deleted INTEGER NOT NULL DEFAULT 0 CHECK(is_deleted IN (0, 1)),
+    parent_id TEXT CHECK(parent_id IS NULL OR length(parent_id) = 36)
+);
+
+INSERT INTO tags_new (id, name, color, created_at, updated_at, is_deleted, parent_id)
+SELECT id, name, color, created_at, updated_at, is_deleted, parent_id FROM tags;
+
+DROP TABLE tags;
+ALTER TABLE tags_new RENAME TO tags;
+
+CREATE INDEX idx_tags_name ON tags(name);
+CREATE INDEX idx_tags_is_deleted ON tags(is_deleted);
+CREATE INDEX idx_tags_is_deleted_name ON tags(is_deleted, name);
+CREATE INDEX idx_tags_parent_id ON tags(parent_id);
+
+INSERT INTO content_tags (content_id, tag_id, assigned_at)
+SELECT content_id, tag_id, assigned_at FROM content_tags_copy;
+DROP TABLE content_tags_copy;
@@ -1,6 +1,8 @@
 -- V1__initial_schema.down.sql
 -- Rollback migration for initial schema
--- This will drop all tables and return to empty database
+-- This will drop all tables and return to empty database. schema_migrations
+-- belongs to the migrator (Migrator.Initialize), which removes this
+-- migration's record from it, so it is kept.
 
 -- Drop FTS triggers and table
 DROP TRIGGER IF EXISTS content_items_au;
@@ -18,4 +20,3 @@ DROP TABLE IF EXISTS change_log;
 DROP TABLE IF EXISTS content_tags;
 DROP TABLE IF EXISTS tags;
 DROP TABLE IF EXISTS content_items;
-DROP TABLE IF EXISTS schema_migrations;
@@ -0,0 +1,13 @@
+-- V3__cjk_trigram_fts.down.sql
+-- Rollback trigram FTS5 index
+
+-- Drop sync triggers
+DROP TRIGGER IF EXISTS content_items_trigram_ai;
+DROP TRIGGER IF EXISTS content_items_trigram_ad;
+DROP TRIGGER IF EXISTS content_items_trigram_au;
+
+-- Drop trigram table
+DROP TABLE IF EXISTS content_fts_trigram;
+
+-- Remove migration record
+DELETE FROM schema_migrations WHERE version = 3;
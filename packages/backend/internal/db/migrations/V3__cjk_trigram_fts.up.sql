@@ -0,0 +1,46 @@
+-- V3__cjk_trigram_fts.up.sql
+-- Add a trigram FTS5 index for CJK search
+-- unicode61 treats a run of CJK characters as a single token, so a word inside
+-- a longer run (e.g. 知識 in 個人知識庫) is never matched. The trigram tokenizer
+-- indexes every 3-character sequence and supports substring matching.
+
+-- =====================================================
+-- Trigram FTS5 Table
+-- =====================================================
+
+CREATE VIRTUAL TABLE IF NOT EXISTS content_fts_trigram USING fts5(
+    title,
+    content_text,
+    tags,
+    content=content_items,
+    content_rowid=rowid,
+    tokenize='trigram'
+);
+
+-- =====================================================
+-- Sync Triggers
+-- =====================================================
+
+CREATE TRIGGER IF NOT EXISTS content_items_trigram_ai AFTER INSERT ON content_items BEGIN
+    INSERT INTO content_fts_trigram(rowid, title, content_text, tags)
+    VALUES (new.rowid, new.title, new.content_text, new.tags);
+END;
+
+CREATE TRIGGER IF NOT EXISTS content_items_trigram_ad AFTER DELETE ON content_items BEGIN
+    INSERT INTO content_fts_trigram(content_fts_trigram, rowid, title, content_text, tags)
+    VALUES ('delete', old.rowid, old.title, old.content_text, old.tags);
+END;
+
+CREATE TRIGGER IF NOT EXISTS content_items_trigram_au AFTER UPDATE ON content_items BEGIN
+    INSERT INTO content_fts_trigram(content_fts_trigram, rowid, title, content_text, tags)
+    VALUES ('delete', old.rowid, old.title, old.content_text, old.tags);
+    INSERT INTO content_fts_trigram(rowid, title, content_text, tags)
+    VALUES (new.rowid, new.title, new.content_text, new.tags);
+END;
+
+-- =====================================================
+-- Backfill
+-- =====================================================
+
+-- Index existing content
+INSERT INTO content_fts_trigram(content_fts_trigram) VALUES('rebuild');
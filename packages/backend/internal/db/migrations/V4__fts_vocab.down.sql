@@ -0,0 +1,7 @@
+-- V4__fts_vocab.down.sql
+-- Rollback FTS vocabulary table
+
+DROP TABLE IF EXISTS content_fts_vocab;
+
+-- Remove migration record
+DELETE FROM schema_migrations WHERE version = 4;
@@ -0,0 +1,7 @@
+-- V4__fts_vocab.up.sql
+-- Expose the content_fts vocabulary for "did you mean" suggestions
+-- fts5vocab is a read-only view over the FTS index; it needs no triggers
+-- and always reflects the current index contents.
+
+-- One row per distinct term: term, doc (documents containing it), cnt (total occurrences)
+CREATE VIRTUAL TABLE IF NOT EXISTS content_fts_vocab USING fts5vocab(content_fts, 'row');
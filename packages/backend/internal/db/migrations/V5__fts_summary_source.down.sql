@@ -0,0 +1,85 @@
+-- V5__fts_summary_source.down.sql
+-- Rollback summary and source domain indexing
+
+-- =====================================================
+-- Restore content_fts (title, content_text, tags)
+-- =====================================================
+
+DROP TRIGGER IF EXISTS content_items_ai;
+DROP TRIGGER IF EXISTS content_items_ad;
+DROP TRIGGER IF EXISTS content_items_au;
+DROP TABLE IF EXISTS content_fts;
+
+CREATE VIRTUAL TABLE content_fts USING fts5(
+    title,
+    content_text,
+    tags,
+    content=content_items,
+    content_rowid=rowid,
+    tokenize='unicode61 remove_diacritics 1'
+);
+
+CREATE TRIGGER content_items_ai AFTER INSERT ON content_items BEGIN
+    INSERT INTO content_fts(rowid, title, content_text, tags)
+    VALUES (new.rowid, new.title, new.content_text, new.tags);
+END;
+
+CREATE TRIGGER content_items_ad AFTER DELETE ON content_items BEGIN
+    INSERT INTO content_fts(content_fts, rowid, title, content_text, tags)
+    VALUES ('delete', old.rowid, old.title, old.content_text, old.tags);
+END;
+
+CREATE TRIGGER content_items_au AFTER UPDATE ON content_items BEGIN
+    INSERT INTO content_fts(content_fts, rowid, title, content_text, tags)
+    VALUES ('delete', old.rowid, old.title, old.content_text, old.tags);
+    INSERT INTO content_fts(rowid, title, content_text, tags)
+    VALUES (new.rowid, new.title, new.content_text, new.tags);
+END;
+
+INSERT INTO content_fts(content_fts) VALUES('rebuild');
+
+-- =====================================================
+-- Restore content_fts_trigram (title, content_text, tags)
+-- =====================================================
+
+DROP TRIGGER IF EXISTS content_items_trigram_ai;
+DROP TRIGGER IF EXISTS content_items_trigram_ad;
+DROP TRIGGER IF EXISTS content_items_trigram_au;
+DROP TABLE IF EXISTS content_fts_trigram;
+
+CREATE VIRTUAL TABLE content_fts_trigram USING fts5(
+    title,
+    content_text,
+    tags,
+    content=content_items,
+    content_rowid=rowid,
+    tokenize='trigram'
+);
+
+CREATE TRIGGER content_items_trigram_ai AFTER INSERT ON content_items BEGIN
+    INSERT INTO content_fts_trigram(rowid, title, content_text, tags)
+    VALUES (new.rowid, new.title, new.content_text, new.tags);
+END;
+
+CREATE TRIGGER content_items_trigram_ad AFTER DELETE ON content_items BEGIN
+    INSERT INTO content_fts_trigram(content_fts_trigram, rowid, title, content_text, tags)
+    VALUES ('delete', old.rowid, old.title, old.content_text, old.tags);
+END;
+
+CREATE TRIGGER content_items_trigram_au AFTER UPDATE ON content_items BEGIN
+    INSERT INTO content_fts_trigram(content_fts_trigram, rowid, title, content_text, tags)
+    VALUES ('delete', old.rowid, old.title, old.content_text, old.tags);
+    INSERT INTO content_fts_trigram(rowid, title, content_text, tags)
+    VALUES (new.rowid, new.title, new.content_text, new.tags);
+END;
+
+INSERT INTO content_fts_trigram(content_fts_trigram) VALUES('rebuild');
+
+-- =====================================================
+-- Drop Source Domain Column
+-- =====================================================
+
+ALTER TABLE content_items DROP COLUMN source_domain;
+
+-- Remove migration record
+DELETE FROM schema_migrations WHERE version = 5;
@@ -0,0 +1,107 @@
+-- V5__fts_summary_source.up.sql
+-- Index summaries and source URL domains in full-text search
+-- AI-generated summaries and the site an item came from were not searchable,
+-- so "that article from nytimes" or a phrase only in the summary found nothing.
+
+-- =====================================================
+-- Source Domain Column
+-- =====================================================
+
+-- Host of source_url, lowercased and without a leading "www."
+-- (https://www.NYTimes.com/2024/x?y=1 -> nytimes.com). Computed by SQLite so
+-- every insert path, including sync and batch import, stays consistent.
+-- The host is the text after "://" up to the first '/', '?', '#' or ':';
+-- (... LIKE 'www.%') is 0 or 1, so the outer substr skips "www." when present.
+ALTER TABLE content_items ADD COLUMN source_domain TEXT GENERATED ALWAYS AS (
+    CASE WHEN instr(source_url, '://') > 0 THEN
+        substr(
+            lower(substr(
+                substr(source_url, instr(source_url, '://') + 3),
+                1,
+                instr(replace(replace(replace(substr(source_url, instr(source_url, '://') + 3),
+                    '?', '/'), '#', '/'), ':', '/') || '/', '/') - 1
+            )),
+            1 + 4 * (substr(source_url, instr(source_url, '://') + 3) LIKE 'www.%')
+        )
+    END
+) VIRTUAL;
+
+-- =====================================================
+-- Rebuild content_fts
+-- =====================================================
+
+DROP TRIGGER IF EXISTS content_items_ai;
+DROP TRIGGER IF EXISTS content_items_ad;
+DROP TRIGGER IF EXISTS content_items_au;
+DROP TABLE IF EXISTS content_fts;
+
+CREATE VIRTUAL TABLE content_fts USING fts5(
+    title,
+    content_text,
+    tags,
+    summary,
+    source_domain,
+    content=content_items,
+    content_rowid=rowid,
+    tokenize='unicode61 remove_diacritics 1'
+);
+
+CREATE TRIGGER content_items_ai AFTER INSERT ON content_items BEGIN
+    INSERT INTO content_fts(rowid, title, content_text, tags, summary, source_domain)
+    VALUES (new.rowid, new.title, new.content_text, new.tags, new.summary, new.source_domain);
+END;
+
+CREATE TRIGGER content_items_ad AFTER DELETE ON content_items BEGIN
+    INSERT INTO content_fts(content_fts, rowid, title, content_text, tags, summary, source_domain)
+    VALUES ('delete', old.rowid, old.title, old.content_text, old.tags, old.summary, old.source_domain);
+END;
+
+CREATE TRIGGER content_items_au AFTER UPDATE ON content_items BEGIN
+    INSERT INTO content_fts(content_fts, rowid, title, content_text, tags, summary, source_domain)
+    VALUES ('delete', old.rowid, old.title, old.content_text, old.tags, old.summary, old.source_domain);
+    INSERT INTO content_fts(rowid, title, content_text, tags, summary, source_domain)
+    VALUES (new.rowid, new.title, new.content_text, new.tags, new.summary, new.source_domain);
+END;
+
+INSERT INTO content_fts(content_fts) VALUES('rebuild');
+
+-- =====================================================
+-- Rebuild content_fts_trigram
+-- =====================================================
+-- Both indexes need the same columns: CJK queries run against both, and
+-- column filters (e.g. summary:) must resolve in each.
+
+DROP TRIGGER IF EXISTS content_items_trigram_ai;
+DROP TRIGGER IF EXISTS content_items_trigram_ad;
+DROP TRIGGER IF EXISTS content_items_trigram_au;
+DROP TABLE IF EXISTS content_fts_trigram;
+
+CREATE VIRTUAL TABLE content_fts_trigram USING fts5(
+    title,
+    content_text,
+    tags,
+    summary,
+    source_domain,
+    content=content_items,
+    content_rowid=rowid,
+    tokenize='trigram'
+);
+
+CREATE TRIGGER content_items_trigram_ai AFTER INSERT ON content_items BEGIN
+    INSERT INTO content_fts_trigram(rowid, title, content_text, tags, summary, source_domain)
+    VALUES (new.rowid, new.title, new.content_text, new.tags, new.summary, new.source_domain);
+END;
+
+CREATE TRIGGER content_items_trigram_ad AFTER DELETE ON content_items BEGIN
+    INSERT INTO content_fts_trigram(content_fts_trigram, rowid, title, content_text, tags, summary, source_domain)
+    VALUES ('delete', old.rowid, old.title, old.content_text, old.tags, old.summary, old.source_domain);
+END;
+
+CREATE TRIGGER content_items_trigram_au AFTER UPDATE ON content_items BEGIN
+    INSERT INTO content_fts_trigram(content_fts_trigram, rowid, title, content_text, tags, summary, source_domain)
+    VALUES ('delete', old.rowid, old.title, old.content_text, old.tags, old.summary, old.source_domain);
+    INSERT INTO content_fts_trigram(rowid, title, content_text, tags, summary, source_domain)
+    VALUES (new.rowid, new.title, new.content_text, new.tags, new.summary, new.source_domain);
+END;
+
+INSERT INTO content_fts_trigram(content_fts_trigram) VALUES('rebuild');
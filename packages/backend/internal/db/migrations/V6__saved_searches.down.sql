@@ -0,0 +1,8 @@
+-- V6__saved_searches.down.sql
+-- Rollback saved searches
+
+DROP INDEX IF EXISTS idx_saved_searches_sidebar;
+DROP TABLE IF EXISTS saved_searches;
+
+-- Remove migration record
+DELETE FROM schema_migrations WHERE version = 6;
@@ -0,0 +1,27 @@
+-- V6__saved_searches.up.sql
+-- Saved searches and sidebar smart collections
+-- A saved search stores a structured query (see ParseQuery) plus filters;
+-- results are computed when it is run, so it always reflects current content.
+-- Rows are soft-deleted and versioned so they sync like content items.
+
+CREATE TABLE IF NOT EXISTS saved_searches (
+    id TEXT PRIMARY KEY NOT NULL CHECK(length(id) = 36),
+    name TEXT NOT NULL CHECK(length(name) > 0 AND length(name) <= 100),
+
+    -- Structured query text, '' for filter-only searches
+    query TEXT NOT NULL DEFAULT '' CHECK(length(query) <= 500),
+
+    -- JSON-encoded models.SearchFilters
+    filters TEXT NOT NULL DEFAULT '{}',
+
+    -- Smart collection: pinned searches are shown in the sidebar by position
+    is_pinned INTEGER NOT NULL DEFAULT 0 CHECK(is_pinned IN (0, 1)),
+    position INTEGER NOT NULL DEFAULT 0,
+
+    is_deleted INTEGER NOT NULL DEFAULT 0 CHECK(is_deleted IN (0, 1)),
+    created_at INTEGER NOT NULL CHECK(created_at > 0),
+    updated_at INTEGER NOT NULL CHECK(updated_at >= created_at),
+    version INTEGER NOT NULL DEFAULT 1 CHECK(version > 0)
+);
+
+CREATE INDEX IF NOT EXISTS idx_saved_searches_sidebar ON saved_searches(is_deleted, is_pinned, position);
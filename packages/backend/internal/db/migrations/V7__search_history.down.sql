@@ -0,0 +1,9 @@
+-- V7__search_history.down.sql
+-- Rollback search history and application settings
+
+DROP TABLE IF EXISTS app_settings;
+DROP INDEX IF EXISTS idx_search_history_recent;
+DROP TABLE IF EXISTS search_history;
+
+-- Remove migration record
+DELETE FROM schema_migrations WHERE version = 7;
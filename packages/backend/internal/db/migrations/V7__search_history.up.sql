@@ -0,0 +1,33 @@
+-- V7__search_history.up.sql
+-- Local search history and application settings
+-- Each search run from the UI is recorded with its filters, result count and
+-- the result the user opened, feeding autocomplete and the recent searches
+-- list. History stays on this device unless the user opts in to syncing it
+-- (app_settings key 'search_history.sync').
+
+CREATE TABLE IF NOT EXISTS search_history (
+    id TEXT PRIMARY KEY NOT NULL CHECK(length(id) = 36),
+    query TEXT NOT NULL CHECK(length(query) > 0 AND length(query) <= 500),
+
+    -- JSON-encoded models.SearchFilters
+    filters TEXT NOT NULL DEFAULT '{}',
+
+    result_count INTEGER NOT NULL DEFAULT 0 CHECK(result_count >= 0),
+
+    -- Last result opened from this search, if any
+    clicked_content_id TEXT,
+
+    is_deleted INTEGER NOT NULL DEFAULT 0 CHECK(is_deleted IN (0, 1)),
+    created_at INTEGER NOT NULL CHECK(created_at > 0),
+    updated_at INTEGER NOT NULL CHECK(updated_at >= created_at),
+    version INTEGER NOT NULL DEFAULT 1 CHECK(version > 0)
+);
+
+CREATE INDEX IF NOT EXISTS idx_search_history_recent ON search_history(is_deleted, created_at DESC);
+
+-- app_settings: Local key/value preferences (never synced)
+CREATE TABLE IF NOT EXISTS app_settings (
+    key TEXT PRIMARY KEY NOT NULL CHECK(length(key) > 0),
+    value TEXT NOT NULL,
+    updated_at INTEGER NOT NULL CHECK(updated_at > 0)
+);
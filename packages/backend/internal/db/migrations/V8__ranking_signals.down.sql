@@ -0,0 +1,9 @@
+-- V8__ranking_signals.down.sql
+-- Rollback ranking signals
+
+DROP TABLE IF EXISTS content_engagement;
+ALTER TABLE content_items DROP COLUMN is_favorite;
+ALTER TABLE content_items DROP COLUMN is_pinned;
+
+-- Remove migration record
+DELETE FROM schema_migrations WHERE version = 8;
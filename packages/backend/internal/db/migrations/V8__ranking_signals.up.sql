@@ -0,0 +1,15 @@
+-- V8__ranking_signals.up.sql
+-- Signals used by search ranking profiles (see internal/db/ranking.go)
+-- Pinned and favorite are user-set flags on the item. Open counts are local
+-- engagement statistics kept in their own table so recording an open does
+-- not touch content_items (and its FTS triggers) or the item's version.
+
+ALTER TABLE content_items ADD COLUMN is_pinned INTEGER NOT NULL DEFAULT 0 CHECK(is_pinned IN (0, 1));
+ALTER TABLE content_items ADD COLUMN is_favorite INTEGER NOT NULL DEFAULT 0 CHECK(is_favorite IN (0, 1));
+
+CREATE TABLE IF NOT EXISTS content_engagement (
+    content_id TEXT PRIMARY KEY NOT NULL CHECK(length(content_id) = 36),
+    open_count INTEGER NOT NULL DEFAULT 0 CHECK(open_count >= 0),
+    last_opened_at INTEGER,
+    FOREIGN KEY (content_id) REFERENCES content_items(id) ON DELETE CASCADE
+);
@@ -0,0 +1,7 @@
+-- V9__content_tags_backfill.down.sql
+-- Rollback content_tags backfill
+-- The backfill only added rows. Earlier versions never read content_tags
+-- and tolerate the extra tags, so they are kept.
+
+-- Remove migration record
+DELETE FROM schema_migrations WHERE version = 9;
@@ -0,0 +1,49 @@
+-- V9__content_tags_backfill.up.sql
+-- content_tags becomes the source of truth for tag assignments. Until now
+-- only the denormalized content_items.tags string was written, so link every
+-- item to the tags named in its string, creating missing tags on the way.
+-- Names are matched case-insensitively; names longer than a tag allows are
+-- left in the string (and FTS) only.
+
+CREATE TEMP TABLE tag_backfill AS
+WITH RECURSIVE split(content_id, assigned_at, name, rest) AS (
+    SELECT id, created_at, '', tags || ','
+    FROM content_items
+    WHERE tags IS NOT NULL AND tags != ''
+    UNION ALL
+    SELECT content_id, assigned_at,
+           trim(substr(rest, 1, instr(rest, ',') - 1)),
+           substr(rest, instr(rest, ',') + 1)
+    FROM split
+    WHERE rest != ''
+)
+SELECT content_id, assigned_at, name
+FROM split
+WHERE name != '' AND length(name) <= 50;
+
+-- Create tags that only existed in strings (random version 4 UUIDs). With
+-- MIN() in the select list SQLite takes name from the same row, so the
+-- spelling used by the oldest item wins.
+INSERT INTO tags (id, name, color, created_at, updated_at, is_deleted)
+SELECT lower(hex(randomblob(4))) || '-' || lower(hex(randomblob(2))) || '-4' ||
+       substr(lower(hex(randomblob(2))), 2) || '-' ||
+       substr('89ab', 1 + abs(random()) % 4, 1) || substr(lower(hex(randomblob(2))), 2) || '-' ||
+       lower(hex(randomblob(6))),
+       name, '#3B82F6', MIN(assigned_at), MIN(assigned_at), 0
+FROM tag_backfill
+WHERE lower(name) NOT IN (SELECT lower(name) FROM tags)
+GROUP BY lower(name);
+
+INSERT OR IGNORE INTO content_tags (content_id, tag_id, assigned_at)
+SELECT b.content_id,
+       (SELECT t.id FROM tags t WHERE t.name = b.name COLLATE NOCASE
+        ORDER BY t.is_deleted, t.name LIMIT 1),
+       b.assigned_at
+FROM tag_backfill b;
+
+-- Deleting a tag never removed it from item strings, so tags still named by
+-- an item are restored
+UPDATE tags SET is_deleted = 0
+WHERE is_deleted = 1 AND id IN (SELECT tag_id FROM content_tags);
+
+DROP TABLE tag_backfill;
@@ -0,0 +1,314 @@
+// Package db provides typed content property persistence.
+package db
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"math"
+	"net/url"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/kimhsiao/memonexus/backend/internal/models"
+)
+
+// contentPropertyColumns lists the content_properties columns read by
+// scanContentProperty.
+const contentPropertyColumns = `content_id, key, type, value, source, is_deleted, updated_at, version`
+
+// maxPropertyValueLength is the maximum length of a property value, in
+// characters.
+const maxPropertyValueLength = 2000
+
+// propertyKeyPattern matches normalized property keys, e.g. "word_count".
+var propertyKeyPattern = regexp.MustCompile(`^[a-z][a-z0-9_]{0,63}$`)
+
+// ErrInvalidProperty is returned when a property's key, type or value is
+// not valid.
+var ErrInvalidProperty = errors.New("invalid property")
+
+// scanContentProperty scans one row selected with contentPropertyColumns.
+func scanContentProperty(row rowScanner) (*models.ContentProperty, error) {
+	var p models.ContentProperty
+	err := row.Scan(&p.ContentID, &p.Key, &p.Type, &p.Value, &p.Source, &p.IsDeleted,
+		&p.UpdatedAt, &p.Version)
+	if err != nil {
+		return nil, err
+	}
+	return &p, nil
+}
+
+// NormalizePropertyKey lowercases and trims a property key and checks that
+// it is a letter followed by up to 63 letters, digits or underscores.
+func NormalizePropertyKey(key string) (string, error) {
+	normalized := strings.ToLower(strings.TrimSpace(key))
+	if !propertyKeyPattern.MatchString(normalized) {
+		return "", fmt.Errorf("%w: key %q must be a letter followed by up to 63 letters, digits or underscores", ErrInvalidProperty, key)
+	}
+	return normalized, nil
+}
+
+// parsePropertyDate parses an RFC 3339 timestamp, a date-time without zone
+// or YYYY, YYYY-MM or YYYY-MM-DD, in UTC unless a zone is given.
+func parsePropertyDate(value string) (time.Time, error) {
+	for _, layout := range []string{time.RFC3339, "2006-01-02T15:04:05", "2006-01-02", "2006-01", "2006"} {
+		if t, err := time.Parse(layout, value); err == nil {
+			return t.UTC(), nil
+		}
+	}
+	return time.Time{}, fmt.Errorf("invalid date: %s", value)
+}
+
+// propertyValue checks value against a property type and returns its
+// canonical text and, for numbers and dates (as Unix seconds), its
+// numeric value.
+func propertyValue(propertyType, value string) (string, sql.NullFloat64, error) {
+	value = strings.TrimSpace(value)
+	if value == "" {
+		return "", sql.NullFloat64{}, fmt.Errorf("%w: value is required", ErrInvalidProperty)
+	}
+	if len([]rune(value)) > maxPropertyValueLength {
+		return "", sql.NullFloat64{}, fmt.Errorf("%w: value must be %d characters or less", ErrInvalidProperty, maxPropertyValueLength)
+	}
+
+	switch propertyType {
+	case models.PropertyTypeString:
+		return value, sql.NullFloat64{}, nil
+
+	case models.PropertyTypeNumber:
+		n, err := strconv.ParseFloat(value, 64)
+		if err != nil || math.IsInf(n, 0) || math.IsNaN(n) {
+			return "", sql.NullFloat64{}, fmt.Errorf("%w: %q is not a number", ErrInvalidProperty, value)
+		}
+		return strconv.FormatFloat(n, 'f', -1, 64), sql.NullFloat64{Float64: n, Valid: true}, nil
+
+	case models.PropertyTypeDate:
+		t, err := parsePropertyDate(value)
+		if err != nil {
+			return "", sql.NullFloat64{}, fmt.Errorf("%w: %q is not a date (use RFC 3339 or YYYY-MM-DD)", ErrInvalidProperty, value)
+		}
+		return t.Format(time.RFC3339), sql.NullFloat64{Float64: float64(t.Unix()), Valid: true}, nil
+
+	case models.PropertyTypeURL:
+		u, err := url.Parse(value)
+		if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+			return "", sql.NullFloat64{}, fmt.Errorf("%w: %q is not an http or https URL", ErrInvalidProperty, value)
+		}
+		return u.String(), sql.NullFloat64{}, nil
+	}
+
+	return "", sql.NullFloat64{}, fmt.Errorf("%w: type must be one of string, number, date, url", ErrInvalidProperty)
+}
+
+// validateContentProperty normalizes a property's key and value in place,
+// defaulting the type to string and the source to user, and returns the
+// numeric value to store.
+func validateContentProperty(p *models.ContentProperty) (sql.NullFloat64, error) {
+	key, err := NormalizePropertyKey(p.Key)
+	if err != nil {
+		return sql.NullFloat64{}, err
+	}
+	if p.Type == "" {
+		p.Type = models.PropertyTypeString
+	}
+	if p.Source == "" {
+		p.Source = models.PropertySourceUser
+	}
+	if p.Source != models.PropertySourceUser && p.Source != models.PropertySourceParser {
+		return sql.NullFloat64{}, fmt.Errorf("%w: source must be parser or user", ErrInvalidProperty)
+	}
+	value, num, err := propertyValue(p.Type, p.Value)
+	if err != nil {
+		return sql.NullFloat64{}, err
+	}
+	p.Key = key
+	p.Value = value
+	return num, nil
+}
+
+// ListContentProperties returns the live properties of a content item,
+// ordered by key. Returns sql.ErrNoRows if the item does not exist.
+func (r *Repository) ListContentProperties(contentID string) ([]*models.ContentProperty, error) {
+	if err := r.checkContentItem(contentID); err != nil {
+		return nil, err
+	}
+	return r.queryContentProperties(`
+	SELECT `+contentPropertyColumns+` FROM content_properties
+	WHERE content_id = ? AND is_deleted = 0
+	ORDER BY key
+	`, contentID)
+}
+
+// queryContentProperties runs a query selecting contentPropertyColumns.
+func (r *Repository) queryContentProperties(query string, args ...interface{}) ([]*models.ContentProperty, error) {
+	rows, err := r.db.Query(query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	properties := make([]*models.ContentProperty, 0)
+	for rows.Next() {
+		p, err := scanContentProperty(rows)
+		if err != nil {
+			return nil, err
+		}
+		properties = append(properties, p)
+	}
+	return properties, rows.Err()
+}
+
+// SetContentProperty sets a property on a content item, replacing any
+// value the key already has and bumping its version. The key and value are
+// normalized in place. Returns sql.ErrNoRows if the item does not exist and
+// ErrInvalidProperty if the property does not validate.
+func (r *Repository) SetContentProperty(p *models.ContentProperty) error {
+	num, err := validateContentProperty(p)
+	if err != nil {
+		return err
+	}
+
+	tx, err := r.db.Begin()
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	var exists int
+	err = tx.QueryRow(`SELECT 1 FROM content_items WHERE id = ? AND is_deleted = 0`, p.ContentID).Scan(&exists)
+	if err != nil {
+		return err
+	}
+
+	p.IsDeleted = false
+	p.UpdatedAt = time.Now().Unix()
+	_, err = tx.Exec(`
+	INSERT INTO content_properties (content_id, key, type, value, value_num, source, is_deleted, updated_at, version)
+	VALUES (?, ?, ?, ?, ?, ?, 0, ?, 1)
+	ON CONFLICT(content_id, key) DO UPDATE SET
+		type = excluded.type, value = excluded.value, value_num = excluded.value_num,
+		source = excluded.source, is_deleted = 0, updated_at = excluded.updated_at,
+		version = content_properties.version + 1
+	`, p.ContentID, p.Key, p.Type, p.Value, num, p.Source, p.UpdatedAt)
+	if err != nil {
+		return err
+	}
+	err = tx.QueryRow(`SELECT version FROM content_properties WHERE content_id = ? AND key = ?`,
+		p.ContentID, p.Key).Scan(&p.Version)
+	if err != nil {
+		return err
+	}
+	return tx.Commit()
+}
+
+// SetParsedProperties stores metadata extracted by the parser on a content
+// item. Keys the user has set keep their values, and values that do not
+// validate are skipped. Returns the number of properties stored.
+func (r *Repository) SetParsedProperties(contentID models.UUID, properties []*models.ContentProperty) (int, error) {
+	stored := 0
+	err := r.WithTx(context.Background(), func(tx *sql.Tx) error {
+		var err error
+		stored, err = setParsedProperties(context.Background(), tx, contentID, properties, time.Now().Unix())
+		return err
+	})
+	if err != nil {
+		return 0, err
+	}
+	return stored, nil
+}
+
+// CreateParsedContentItem creates a content item together with the
+// metadata its parser extracted (see SetParsedProperties) in one
+// transaction, so the item never exists without its properties.
+func (r *Repository) CreateParsedContentItem(ctx context.Context, item *models.ContentItem, properties []*models.ContentProperty) error {
+	return r.WithTx(ctx, func(tx *sql.Tx) error {
+		now := time.Now().Unix()
+		if err := createContentItem(ctx, tx, item, now); err != nil {
+			return err
+		}
+		_, err := setParsedProperties(ctx, tx, item.ID, properties, now)
+		return err
+	})
+}
+
+// setParsedProperties stores parser metadata on a content item inside tx.
+func setParsedProperties(ctx context.Context, tx *sql.Tx, contentID models.UUID, properties []*models.ContentProperty, now int64) (int, error) {
+	stored := 0
+	for _, p := range properties {
+		p.ContentID = contentID
+		p.Source = models.PropertySourceParser
+		num, err := validateContentProperty(p)
+		if err != nil {
+			continue
+		}
+		result, err := tx.ExecContext(ctx, `
+		INSERT INTO content_properties (content_id, key, type, value, value_num, source, is_deleted, updated_at, version)
+		VALUES (?, ?, ?, ?, ?, 'parser', 0, ?, 1)
+		ON CONFLICT(content_id, key) DO UPDATE SET
+			type = excluded.type, value = excluded.value, value_num = excluded.value_num,
+			source = 'parser', is_deleted = 0, updated_at = excluded.updated_at,
+			version = content_properties.version + 1
+		WHERE content_properties.source = 'parser' OR content_properties.is_deleted = 1
+		`, p.ContentID, p.Key, p.Type, p.Value, num, now)
+		if err != nil {
+			return 0, fmt.Errorf("failed to store property %s: %w", p.Key, err)
+		}
+		if n, _ := result.RowsAffected(); n > 0 {
+			stored++
+		}
+	}
+	return stored, nil
+}
+
+// DeleteContentProperty soft deletes a property of a content item, bumping
+// its version so the deletion syncs. Returns sql.ErrNoRows if the item has
+// no such property.
+func (r *Repository) DeleteContentProperty(contentID, key string) error {
+	normalized, err := NormalizePropertyKey(key)
+	if err != nil {
+		return sql.ErrNoRows
+	}
+	return execAffectingRow(r.db.Exec(`
+	UPDATE content_properties SET is_deleted = 1, updated_at = ?, version = version + 1
+	WHERE content_id = ? AND key = ? AND is_deleted = 0
+	`, time.Now().Unix(), contentID, normalized))
+}
+
+// =====================================================
+// Content Property Sync
+// =====================================================
+
+// ListContentPropertiesForSync returns every property, including deleted
+// ones.
+func (r *Repository) ListContentPropertiesForSync() ([]*models.ContentProperty, error) {
+	return r.queryContentProperties(`SELECT ` + contentPropertyColumns + ` FROM content_properties ORDER BY content_id, key`)
+}
+
+// ApplyRemoteContentProperty stores a property received from another
+// device if it is new or has a higher version than the local copy. Each key
+// is versioned on its own, so concurrent edits to different keys of an item
+// both survive. Returns true if the local copy changed.
+func (r *Repository) ApplyRemoteContentProperty(p *models.ContentProperty) (bool, error) {
+	num, err := validateContentProperty(p)
+	if err != nil {
+		return false, err
+	}
+	result, err := r.db.Exec(`
+	INSERT INTO content_properties (content_id, key, type, value, value_num, source, is_deleted, updated_at, version)
+	VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?)
+	ON CONFLICT(content_id, key) DO UPDATE SET
+		type = excluded.type, value = excluded.value, value_num = excluded.value_num,
+		source = excluded.source, is_deleted = excluded.is_deleted,
+		updated_at = excluded.updated_at, version = excluded.version
+	WHERE excluded.version > content_properties.version
+	`, p.ContentID, p.Key, p.Type, p.Value, num, p.Source, p.IsDeleted, p.UpdatedAt, p.Version)
+	if err != nil {
+		return false, err
+	}
+	applied, err := result.RowsAffected()
+	return applied > 0, err
+}
@@ -0,0 +1,248 @@
+// Package db tests for content properties.
+package db
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"testing"
+
+	"github.com/kimhsiao/memonexus/backend/internal/models"
+)
+
+// TestPropertyValue verifies values are checked and canonicalized by type.
+func TestPropertyValue(t *testing.T) {
+	tests := []struct {
+		propertyType string
+		value        string
+		want         string
+		valid        bool
+	}{
+		{models.PropertyTypeString, "  Jane Doe ", "Jane Doe", true},
+		{models.PropertyTypeString, " ", "", false},
+		{models.PropertyTypeNumber, "1.50", "1.5", true},
+		{models.PropertyTypeNumber, "many", "", false},
+		{models.PropertyTypeDate, "2024-03-01", "2024-03-01T00:00:00Z", true},
+		{models.PropertyTypeDate, "2024-03-01T10:00:00+02:00", "2024-03-01T08:00:00Z", true},
+		{models.PropertyTypeDate, "yesterday", "", false},
+		{models.PropertyTypeURL, "https://example.com/a", "https://example.com/a", true},
+		{models.PropertyTypeURL, "example.com/a", "", false},
+		{"color", "red", "", false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.propertyType+" "+tt.value, func(t *testing.T) {
+			got, _, err := propertyValue(tt.propertyType, tt.value)
+			if tt.valid != (err == nil) {
+				t.Fatalf("propertyValue error = %v, want valid %v", err, tt.valid)
+			}
+			if err != nil && !errors.Is(err, ErrInvalidProperty) {
+				t.Errorf("Expected ErrInvalidProperty, got %v", err)
+			}
+			if got != tt.want {
+				t.Errorf("propertyValue = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+// TestContentProperties verifies properties are set, listed and deleted,
+// and that parsed metadata does not overwrite user values.
+func TestContentProperties(t *testing.T) {
+	db := setupMigratedTestDB(t)
+	defer db.Close()
+
+	repo := NewRepository(db)
+	item := &models.ContentItem{Title: "Article", MediaType: "web"}
+	if err := repo.CreateContentItem(item); err != nil {
+		t.Fatalf("CreateContentItem failed: %v", err)
+	}
+
+	p := &models.ContentProperty{ContentID: item.ID, Key: " Author ", Value: "Jane Doe"}
+	if err := repo.SetContentProperty(p); err != nil {
+		t.Fatalf("SetContentProperty failed: %v", err)
+	}
+	if p.Key != "author" || p.Type != models.PropertyTypeString || p.Source != models.PropertySourceUser || p.Version != 1 {
+		t.Errorf("Unexpected property: %+v", p)
+	}
+
+	stored, err := repo.SetParsedProperties(item.ID, []*models.ContentProperty{
+		{Key: "author", Type: models.PropertyTypeString, Value: "Parser Name"},
+		{Key: "word_count", Type: models.PropertyTypeNumber, Value: "1200"},
+		{Key: "canonical_url", Type: models.PropertyTypeURL, Value: "/relative"},
+	})
+	if err != nil {
+		t.Fatalf("SetParsedProperties failed: %v", err)
+	}
+	if stored != 1 {
+		t.Errorf("SetParsedProperties stored %d properties, want 1", stored)
+	}
+
+	properties, err := repo.ListContentProperties(string(item.ID))
+	if err != nil {
+		t.Fatalf("ListContentProperties failed: %v", err)
+	}
+	if len(properties) != 2 || properties[0].Value != "Jane Doe" || properties[1].Key != "word_count" ||
+		properties[1].Source != models.PropertySourceParser {
+		t.Errorf("Unexpected properties: %+v %+v", properties[0], properties[1])
+	}
+
+	// Setting a key again replaces its value and bumps its version
+	p = &models.ContentProperty{ContentID: item.ID, Key: "author", Value: "J. Doe"}
+	if err := repo.SetContentProperty(p); err != nil || p.Version != 2 {
+		t.Errorf("SetContentProperty again: version %d, err %v", p.Version, err)
+	}
+
+	if err := repo.DeleteContentProperty(string(item.ID), "author"); err != nil {
+		t.Fatalf("DeleteContentProperty failed: %v", err)
+	}
+	if err := repo.DeleteContentProperty(string(item.ID), "author"); err != sql.ErrNoRows {
+		t.Errorf("Deleting twice: expected sql.ErrNoRows, got %v", err)
+	}
+	all, err := repo.ListContentPropertiesForSync()
+	if err != nil || len(all) != 2 || !all[0].IsDeleted || all[0].Version != 3 {
+		t.Errorf("ListContentPropertiesForSync = %+v, %v", all, err)
+	}
+
+	// A deleted key may be filled again by the parser
+	if stored, _ := repo.SetParsedProperties(item.ID, []*models.ContentProperty{{Key: "author", Value: "Parser Name"}}); stored != 1 {
+		t.Errorf("SetParsedProperties after delete stored %d properties, want 1", stored)
+	}
+
+	err = repo.SetContentProperty(&models.ContentProperty{ContentID: item.ID, Key: "size", Type: models.PropertyTypeNumber, Value: "big"})
+	if !errors.Is(err, ErrInvalidProperty) {
+		t.Errorf("Invalid value: expected ErrInvalidProperty, got %v", err)
+	}
+	err = repo.SetContentProperty(&models.ContentProperty{ContentID: "00000000-0000-0000-0000-000000000000", Key: "author", Value: "x"})
+	if err != sql.ErrNoRows {
+		t.Errorf("Missing item: expected sql.ErrNoRows, got %v", err)
+	}
+}
+
+// TestApplyRemoteContentProperty verifies the higher version of each key
+// wins.
+func TestApplyRemoteContentProperty(t *testing.T) {
+	db := setupMigratedTestDB(t)
+	defer db.Close()
+
+	repo := NewRepository(db)
+	item := &models.ContentItem{Title: "Article", MediaType: "web"}
+	if err := repo.CreateContentItem(item); err != nil {
+		t.Fatalf("CreateContentItem failed: %v", err)
+	}
+	if err := repo.SetContentProperty(&models.ContentProperty{ContentID: item.ID, Key: "rating", Type: "number", Value: "3"}); err != nil {
+		t.Fatalf("SetContentProperty failed: %v", err)
+	}
+
+	remote := &models.ContentProperty{ContentID: item.ID, Key: "rating", Type: "number", Value: "5",
+		Source: models.PropertySourceUser, UpdatedAt: 1700000000, Version: 1}
+	if applied, err := repo.ApplyRemoteContentProperty(remote); err != nil || applied {
+		t.Errorf("Same version: applied %v, err %v", applied, err)
+	}
+	remote.Version = 2
+	if applied, err := repo.ApplyRemoteContentProperty(remote); err != nil || !applied {
+		t.Errorf("Newer version: applied %v, err %v", applied, err)
+	}
+
+	properties, _ := repo.ListContentProperties(string(item.ID))
+	if len(properties) != 1 || properties[0].Value != "5" {
+		t.Errorf("Unexpected properties after sync: %+v", properties)
+	}
+}
+
+// TestPropertyFilter verifies property conditions in structured queries
+// compare by the type of each property.
+func TestPropertyFilter(t *testing.T) {
+	db := setupMigratedTestDB(t)
+	defer db.Close()
+
+	repo := NewRepository(db)
+	set := func(item *models.ContentItem, key, propertyType, value string) {
+		t.Helper()
+		p := &models.ContentProperty{ContentID: item.ID, Key: key, Type: propertyType, Value: value}
+		if err := repo.SetContentProperty(p); err != nil {
+			t.Fatalf("SetContentProperty failed: %v", err)
+		}
+	}
+	items := createTaggedItems(t, repo, "", "", "")
+	set(items[0], "author", "string", "Jane Doe")
+	set(items[0], "published", "date", "2023-05-01")
+	set(items[0], "word_count", "number", "900")
+	set(items[1], "author", "string", "John Roe")
+	set(items[1], "published", "date", "2024-07-15")
+	set(items[1], "word_count", "number", "12000")
+
+	tests := []struct {
+		query string
+		want  int
+	}{
+		{`prop:author="jane doe"`, 1},
+		{`prop:author!="Jane Doe"`, 1},
+		{`prop:published>2024`, 1},
+		{`prop:published<=2024-07-15`, 2},
+		{`prop:word_count>=1000`, 1},
+		{`prop:word_count>1e3`, 1},
+		{`prop:word_count>many`, 0},
+		{`prop:author=Jane prop:published>2020`, 0},
+		{`body prop:published>=2023-05`, 2},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.query, func(t *testing.T) {
+			pq, err := ParseQuery(tt.query)
+			if err != nil {
+				t.Fatalf("ParseQuery error: %v", err)
+			}
+			opts := &SearchOptions{Limit: 10}
+			pq.Apply(opts)
+
+			resp, err := repo.Search(opts)
+			if err != nil {
+				t.Fatalf("Search failed: %v", err)
+			}
+			if resp.Total != tt.want {
+				t.Errorf("Expected %d results, got %d", tt.want, resp.Total)
+			}
+		})
+	}
+
+	// The builder ignores incomplete conditions
+	fb := NewFilterBuilder().Property("author", "~", "Jane").Property("", "=", "x").Property("author", "=", " ")
+	if fb.HasFilters() {
+		t.Errorf("Invalid property filters were added: %s", fb)
+	}
+}
+
+// TestCreateParsedContentItem verifies an item and its parsed properties
+// are written together, and neither is written if one fails.
+func TestCreateParsedContentItem(t *testing.T) {
+	db := setupMigratedTestDB(t)
+	defer db.Close()
+
+	repo := NewRepository(db)
+	item := &models.ContentItem{Title: "Article", MediaType: "web"}
+	err := repo.CreateParsedContentItem(context.Background(), item, []*models.ContentProperty{
+		{Key: "author", Type: models.PropertyTypeString, Value: "Jane Doe"},
+	})
+	if err != nil {
+		t.Fatalf("CreateParsedContentItem failed: %v", err)
+	}
+	properties, err := repo.ListContentProperties(string(item.ID))
+	if err != nil || len(properties) != 1 || properties[0].Source != models.PropertySourceParser {
+		t.Errorf("ListContentProperties = %+v, %v; want the parsed author", properties, err)
+	}
+
+	if _, err := db.Exec(`DROP TABLE content_properties`); err != nil {
+		t.Fatalf("Failed to drop content_properties: %v", err)
+	}
+	failed := &models.ContentItem{Title: "Broken", MediaType: "web"}
+	err = repo.CreateParsedContentItem(context.Background(), failed, []*models.ContentProperty{
+		{Key: "author", Type: models.PropertyTypeString, Value: "Jane Doe"},
+	})
+	if err == nil {
+		t.Fatal("CreateParsedContentItem without a properties table should fail")
+	}
+	if _, err := repo.GetContentItem(string(failed.ID)); err != sql.ErrNoRows {
+		t.Errorf("GetContentItem after failed create = %v, want sql.ErrNoRows", err)
+	}
+}
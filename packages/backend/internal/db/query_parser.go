@@ -0,0 +1,614 @@
+// Package db provides the structured search query language.
+package db
+
+import (
+	"fmt"
+	"strings"
+	"time"
+	"unicode"
+	"unicode/utf8"
+
+	"github.com/kimhsiao/memonexus/backend/internal/models"
+)
+
+// Structured query grammar shared by the REST search endpoint and the mobile FFI:
+//
+//	query   := or
+//	or      := and ( "OR" and )*
+//	and     := unary ( ["AND"] unary )*
+//	unary   := ( "-" | "NOT" ) primary | primary
+//	primary := "(" or ")" | term | field ":" value
+//	term    := word ["*"] | '"' phrase '"' ["*"]
+//
+// Text terms compile to a quoted FTS5 MATCH expression, so FTS5 syntax typed
+// by the user can never reach the query engine unescaped. Field operators
+// (tag:, type:, after:, before:, prop:, is:) compile to FilterBuilder clauses and
+// are only allowed at the top level, outside OR and parentheses.
+//
+// Text fields: title:, body: (or content:), summary: and site: (source URL domain).
+//
+// Property conditions take a key, an operator (=, !=, >, >=, <, <=) and a
+// value, which may be quoted: prop:author="Jane Doe" prop:published>2024-06
+//
+// State conditions match triage states: is:favorite, is:pinned, is:archived,
+// is:unread, is:in_progress and is:read. All of them can be negated.
+//
+// Example: tag:research type:pdf after:2025-01-01 "exact phrase" -draft title:golang
+
+// minTermLength is the minimum length of a non-prefix word term.
+const minTermLength = 2
+
+// queryColumns maps text field operators to content_fts columns.
+var queryColumns = map[string]string{
+	"title":   "title",
+	"body":    "content_text",
+	"content": "content_text",
+	"summary": "summary",
+	"site":    "source_domain",
+}
+
+// QueryError describes a malformed structured query.
+type QueryError struct {
+	// Pos is the 1-based character position of the offending token
+	Pos int
+
+	// Msg describes the problem
+	Msg string
+}
+
+// Error implements the error interface.
+func (e *QueryError) Error() string {
+	return fmt.Sprintf("%s at position %d", e.Msg, e.Pos)
+}
+
+// ParsedQuery is a structured query compiled for Repository.Search.
+type ParsedQuery struct {
+	// Raw is the original query string
+	Raw string
+
+	// Match is the FTS5 MATCH expression (empty if the query only has filters)
+	Match string
+
+	// Filters holds the clauses compiled from field operators
+	Filters *FilterBuilder
+
+	// Terms lists the positive text terms, in query order
+	Terms []string
+}
+
+// Apply copies the compiled query into search options.
+func (pq *ParsedQuery) Apply(opts *SearchOptions) {
+	opts.Query = pq.Match
+	opts.RawQuery = pq.Raw
+	if pq.Filters.HasFilters() {
+		opts.Filters = pq.Filters
+	}
+}
+
+// ParseQuery parses a structured search query.
+// Returns a *QueryError for malformed input.
+func ParseQuery(input string) (*ParsedQuery, error) {
+	tokens, err := lexQuery(input)
+	if err != nil {
+		return nil, err
+	}
+	if len(tokens) == 0 {
+		return nil, &QueryError{Pos: 1, Msg: "empty query"}
+	}
+
+	p := &queryParser{tokens: tokens, filters: NewFilterBuilder()}
+	root, err := p.parseOr()
+	if err != nil {
+		return nil, err
+	}
+	if tok := p.peek(); tok != nil {
+		if tok.kind == tokRParen {
+			return nil, &QueryError{Pos: tok.pos, Msg: "unmatched ')'"}
+		}
+		return nil, &QueryError{Pos: tok.pos, Msg: fmt.Sprintf("unexpected %q", tok.text)}
+	}
+	if len(root.children) > 1 && root.op == "OR" && p.firstFilterPos > 0 {
+		return nil, &QueryError{Pos: p.firstFilterPos, Msg: "field filters cannot be combined with OR"}
+	}
+
+	pq := &ParsedQuery{Raw: input, Filters: p.filters, Terms: p.terms}
+
+	// A top-level group made only of negations cannot be expressed in FTS5,
+	// so it becomes an exclusion filter instead.
+	if root.op == "AND" && len(root.children) == 0 && len(root.negated) > 0 {
+		p.filters.ExcludeMatch(joinQueryNodes(root.negated, " OR "))
+		return pq, nil
+	}
+	if root.empty() {
+		return pq, nil
+	}
+
+	match, err := root.compile()
+	if err != nil {
+		return nil, err
+	}
+	pq.Match = match
+	return pq, nil
+}
+
+// =====================================================
+// Lexer
+// =====================================================
+
+type queryTokenKind int
+
+const (
+	tokWord queryTokenKind = iota
+	tokPhrase
+	tokLParen
+	tokRParen
+	tokMinus
+)
+
+type queryToken struct {
+	kind   queryTokenKind
+	text   string
+	prefix bool // trailing '*'
+	pos    int  // 1-based rune position of the first character
+	end    int  // 1-based rune position just past the token
+}
+
+// lexQuery splits the input into words, phrases, parentheses and negations.
+func lexQuery(input string) ([]*queryToken, error) {
+	runes := []rune(input)
+	var tokens []*queryToken
+
+	for i := 0; i < len(runes); {
+		r := runes[i]
+		switch {
+		case unicode.IsSpace(r):
+			i++
+		case r == '(':
+			tokens = append(tokens, &queryToken{kind: tokLParen, text: "(", pos: i + 1, end: i + 2})
+			i++
+		case r == ')':
+			tokens = append(tokens, &queryToken{kind: tokRParen, text: ")", pos: i + 1, end: i + 2})
+			i++
+		case r == '-':
+			// A lone hyphen is punctuation, not a negation
+			if i+1 < len(runes) && !unicode.IsSpace(runes[i+1]) {
+				tokens = append(tokens, &queryToken{kind: tokMinus, text: "-", pos: i + 1, end: i + 2})
+			}
+			i++
+		case r == '"':
+			start := i
+			i++
+			var sb strings.Builder
+			closed := false
+			for i < len(runes) {
+				if runes[i] == '"' {
+					closed = true
+					i++
+					break
+				}
+				sb.WriteRune(runes[i])
+				i++
+			}
+			if !closed {
+				return nil, &QueryError{Pos: start + 1, Msg: "unterminated phrase"}
+			}
+			tok := &queryToken{kind: tokPhrase, text: sb.String(), pos: start + 1}
+			if i < len(runes) && runes[i] == '*' {
+				tok.prefix = true
+				i++
+			}
+			tok.end = i + 1
+			tokens = append(tokens, tok)
+		default:
+			start := i
+			for i < len(runes) && !unicode.IsSpace(runes[i]) && runes[i] != '(' && runes[i] != ')' && runes[i] != '"' {
+				i++
+			}
+			tokens = append(tokens, &queryToken{kind: tokWord, text: string(runes[start:i]), pos: start + 1, end: i + 1})
+		}
+	}
+
+	return tokens, nil
+}
+
+// =====================================================
+// Parser
+// =====================================================
+
+// queryNode is a compiled text expression: a term or a boolean group.
+type queryNode struct {
+	// Term fields
+	text   string
+	prefix bool
+	column string
+
+	// Group fields
+	op       string // "AND" or "OR"; empty for terms
+	children []*queryNode
+	negated  []*queryNode // AND groups only
+	pos      int
+}
+
+func (n *queryNode) isTerm() bool {
+	return n.op == ""
+}
+
+func (n *queryNode) empty() bool {
+	return !n.isTerm() && len(n.children) == 0 && len(n.negated) == 0
+}
+
+// compile renders the node as an FTS5 expression.
+func (n *queryNode) compile() (string, error) {
+	if n.isTerm() {
+		expr := `"` + strings.ReplaceAll(n.text, `"`, `""`) + `"`
+		if n.prefix {
+			expr += "*"
+		}
+		if n.column != "" {
+			expr = n.column + " : " + expr
+		}
+		return expr, nil
+	}
+
+	if len(n.children) == 0 {
+		return "", &QueryError{Pos: n.pos, Msg: "negated terms need a positive term in the same group"}
+	}
+
+	parts := make([]string, 0, len(n.children))
+	for _, child := range n.children {
+		expr, err := child.compile()
+		if err != nil {
+			return "", err
+		}
+		if !child.isTerm() && len(child.children)+len(child.negated) > 1 {
+			expr = "(" + expr + ")"
+		}
+		parts = append(parts, expr)
+	}
+	expr := strings.Join(parts, " "+n.op+" ")
+
+	if len(n.negated) > 0 {
+		if len(parts) > 1 {
+			expr = "(" + expr + ")"
+		}
+		expr += " NOT (" + joinQueryNodes(n.negated, " OR ") + ")"
+	}
+	return expr, nil
+}
+
+// joinQueryNodes compiles and joins nodes, parenthesizing groups.
+// Only called on nodes that already passed compile validation.
+func joinQueryNodes(nodes []*queryNode, sep string) string {
+	parts := make([]string, 0, len(nodes))
+	for _, node := range nodes {
+		expr, _ := node.compile()
+		if !node.isTerm() {
+			expr = "(" + expr + ")"
+		}
+		parts = append(parts, expr)
+	}
+	return strings.Join(parts, sep)
+}
+
+type queryParser struct {
+	tokens []*queryToken
+	pos    int
+	depth  int // parenthesis nesting level
+
+	filters        *FilterBuilder
+	firstFilterPos int
+	terms          []string
+}
+
+func (p *queryParser) peek() *queryToken {
+	if p.pos < len(p.tokens) {
+		return p.tokens[p.pos]
+	}
+	return nil
+}
+
+func (p *queryParser) next() *queryToken {
+	tok := p.peek()
+	if tok != nil {
+		p.pos++
+	}
+	return tok
+}
+
+func isKeyword(tok *queryToken, keyword string) bool {
+	return tok != nil && tok.kind == tokWord && tok.text == keyword
+}
+
+// parseOr parses: and ( "OR" and )*
+func (p *queryParser) parseOr() (*queryNode, error) {
+	first, err := p.parseAnd()
+	if err != nil {
+		return nil, err
+	}
+	if !isKeyword(p.peek(), "OR") {
+		return first, nil
+	}
+
+	group := &queryNode{op: "OR", pos: first.pos, children: []*queryNode{first}}
+	for isKeyword(p.peek(), "OR") {
+		orTok := p.next()
+		if first.empty() {
+			return nil, &QueryError{Pos: orTok.pos, Msg: "OR needs a term on both sides"}
+		}
+		branch, err := p.parseAnd()
+		if err != nil {
+			return nil, err
+		}
+		if branch.empty() {
+			return nil, &QueryError{Pos: orTok.pos, Msg: "OR needs a term on both sides"}
+		}
+		group.children = append(group.children, branch)
+	}
+	return group, nil
+}
+
+// parseAnd parses: unary ( ["AND"] unary )*
+func (p *queryParser) parseAnd() (*queryNode, error) {
+	group := &queryNode{op: "AND"}
+	if tok := p.peek(); tok != nil {
+		group.pos = tok.pos
+	}
+
+	for {
+		tok := p.peek()
+		if tok == nil || tok.kind == tokRParen || isKeyword(tok, "OR") {
+			break
+		}
+		if isKeyword(tok, "AND") {
+			p.next()
+			if next := p.peek(); next == nil || next.kind == tokRParen || isKeyword(next, "OR") || isKeyword(next, "AND") {
+				return nil, &QueryError{Pos: tok.pos, Msg: "AND needs a term on both sides"}
+			}
+			continue
+		}
+
+		negate := false
+		if tok.kind == tokMinus || isKeyword(tok, "NOT") {
+			p.next()
+			negate = true
+			if next := p.peek(); next == nil || next.kind == tokRParen {
+				return nil, &QueryError{Pos: tok.pos, Msg: fmt.Sprintf("%s needs a term", tok.text)}
+			}
+		}
+
+		node, err := p.parsePrimary(negate)
+		if err != nil {
+			return nil, err
+		}
+		if node == nil {
+			continue // field filter, already recorded
+		}
+		if negate {
+			group.negated = append(group.negated, node)
+		} else {
+			group.children = append(group.children, node)
+		}
+	}
+
+	// Collapse single-term groups
+	if len(group.children) == 1 && len(group.negated) == 0 {
+		return group.children[0], nil
+	}
+	return group, nil
+}
+
+// parsePrimary parses a parenthesized group, a term or a field operator.
+// Returns nil for field operators that compile to filters.
+func (p *queryParser) parsePrimary(negated bool) (*queryNode, error) {
+	tok := p.next()
+
+	switch tok.kind {
+	case tokLParen:
+		p.depth++
+		inner, err := p.parseOr()
+		if err != nil {
+			return nil, err
+		}
+		closing := p.next()
+		if closing == nil || closing.kind != tokRParen {
+			return nil, &QueryError{Pos: tok.pos, Msg: "unmatched '('"}
+		}
+		p.depth--
+		if inner.empty() {
+			return nil, &QueryError{Pos: tok.pos, Msg: "empty group"}
+		}
+		return inner, nil
+
+	case tokRParen:
+		return nil, &QueryError{Pos: tok.pos, Msg: "unmatched ')'"}
+
+	case tokMinus:
+		return nil, &QueryError{Pos: tok.pos, Msg: "unexpected '-'"}
+
+	case tokPhrase:
+		return p.phraseTerm(tok, negated, "")
+	}
+
+	// Word token
+	if tok.text == "AND" || tok.text == "OR" || tok.text == "NOT" {
+		return nil, &QueryError{Pos: tok.pos, Msg: fmt.Sprintf("%s needs a term on both sides", tok.text)}
+	}
+	if strings.HasPrefix(tok.text, "NEAR") && (len(tok.text) == 4 || tok.text[4] == '/') {
+		return nil, &QueryError{Pos: tok.pos, Msg: "unsupported operator: NEAR"}
+	}
+	if strings.HasPrefix(tok.text, "^") {
+		return nil, &QueryError{Pos: tok.pos, Msg: "unsupported operator: ^"}
+	}
+
+	if idx := strings.Index(tok.text, ":"); idx > 0 && isFieldName(tok.text[:idx]) {
+		return p.parseField(tok, idx, negated)
+	}
+
+	return p.wordTerm(tok, tok.text, tok.pos, negated, "")
+}
+
+// parseField handles field:value tokens.
+func (p *queryParser) parseField(tok *queryToken, idx int, negated bool) (*queryNode, error) {
+	field := strings.ToLower(tok.text[:idx])
+	value := tok.text[idx+1:]
+	valuePos := tok.pos + utf8.RuneCountInString(tok.text[:idx+1])
+
+	// Text fields compile to FTS5 column filters
+	if column, ok := queryColumns[field]; ok {
+		if value == "" {
+			next := p.peek()
+			if next == nil || next.kind != tokPhrase || next.pos != tok.end {
+				return nil, &QueryError{Pos: valuePos, Msg: fmt.Sprintf("%s: needs a value", field)}
+			}
+			return p.phraseTerm(p.next(), negated, column)
+		}
+		return p.wordTerm(tok, value, valuePos, negated, column)
+	}
+
+	// Filter fields compile to SQL clauses and only apply to the whole query
+	if p.depth > 0 {
+		return nil, &QueryError{Pos: tok.pos, Msg: fmt.Sprintf("%s: cannot be used inside parentheses", field)}
+	}
+	if value == "" {
+		next := p.peek()
+		if next == nil || next.kind != tokPhrase || next.pos != tok.end {
+			return nil, &QueryError{Pos: valuePos, Msg: fmt.Sprintf("%s: needs a value", field)}
+		}
+		value = p.next().text
+	}
+	if p.firstFilterPos == 0 {
+		p.firstFilterPos = tok.pos
+	}
+
+	switch field {
+	case "tag", "tags":
+		tags := TagsFromCommaString(value)
+		if len(tags) == 0 {
+			return nil, &QueryError{Pos: valuePos, Msg: "tag: needs a value"}
+		}
+		if negated {
+			p.filters.ExcludeTags(tags...)
+		} else {
+			p.filters.Tags(tags...)
+		}
+
+	case "type":
+		if negated {
+			return nil, &QueryError{Pos: tok.pos, Msg: "type: cannot be negated"}
+		}
+		mediaType, err := MediaTypeFromString(strings.ToLower(value))
+		if err != nil {
+			return nil, &QueryError{Pos: valuePos, Msg: fmt.Sprintf("unknown media type %q (must be one of: web, image, video, pdf, markdown)", value)}
+		}
+		p.filters.MediaType(mediaType)
+
+	case "after", "before":
+		if negated {
+			return nil, &QueryError{Pos: tok.pos, Msg: fmt.Sprintf("%s: cannot be negated", field)}
+		}
+		ts, err := parseQueryDate(value)
+		if err != nil {
+			return nil, &QueryError{Pos: valuePos, Msg: fmt.Sprintf("invalid date %q for %s: (use YYYY, YYYY-MM or YYYY-MM-DD)", value, field)}
+		}
+		count := p.filters.Count()
+		if field == "after" {
+			p.filters.DateFrom(ts)
+		} else {
+			p.filters.DateTo(ts - 1)
+		}
+		if p.filters.Count() == count {
+			return nil, &QueryError{Pos: valuePos, Msg: fmt.Sprintf("date %q is out of range for %s:", value, field)}
+		}
+
+	case "prop":
+		if negated {
+			return nil, &QueryError{Pos: tok.pos, Msg: "prop: cannot be negated"}
+		}
+		key, op, propValue, ok := SplitPropertyCondition(value)
+		if !ok {
+			return nil, &QueryError{Pos: valuePos, Msg: "prop: needs a key, an operator and a value (e.g. prop:author=Jane)"}
+		}
+		if propValue == "" {
+			// prop:author="Jane Doe" lexes as a word ending in the operator
+			// followed by a phrase
+			next := p.peek()
+			if next == nil || next.kind != tokPhrase || next.pos != tok.end {
+				return nil, &QueryError{Pos: valuePos, Msg: fmt.Sprintf("prop:%s%s needs a value", key, op)}
+			}
+			propValue = p.next().text
+		}
+		if _, err := NormalizePropertyKey(key); err != nil {
+			return nil, &QueryError{Pos: valuePos, Msg: fmt.Sprintf("invalid property key %q", key)}
+		}
+		count := p.filters.Count()
+		p.filters.Property(key, op, propValue)
+		if p.filters.Count() == count {
+			return nil, &QueryError{Pos: valuePos, Msg: fmt.Sprintf("prop:%s%s needs a value", key, op)}
+		}
+
+	case "is":
+		state := strings.ToLower(value)
+		switch {
+		case stateFlagColumns[state] != "":
+			p.filters.StateFlag(state, !negated)
+		case models.IsReadStatus(state) && negated:
+			p.filters.ExcludeReadStatus(state)
+		case models.IsReadStatus(state):
+			p.filters.ReadStatus(state)
+		default:
+			return nil, &QueryError{Pos: valuePos, Msg: fmt.Sprintf("unknown state %q (must be one of: favorite, pinned, archived, unread, in_progress, read)", value)}
+		}
+	}
+
+	return nil, nil
+}
+
+// wordTerm builds a term node from a bare word.
+func (p *queryParser) wordTerm(tok *queryToken, word string, pos int, negated bool, column string) (*queryNode, error) {
+	prefix := strings.HasSuffix(word, "*")
+	word = strings.TrimSuffix(word, "*")
+	if word == "" {
+		return nil, &QueryError{Pos: pos, Msg: "wildcard needs a prefix"}
+	}
+	if strings.Contains(word, "*") {
+		return nil, &QueryError{Pos: pos, Msg: "wildcard is only allowed at the end of a term"}
+	}
+	if !prefix && utf8.RuneCountInString(word) < minTermLength {
+		return nil, &QueryError{Pos: pos, Msg: fmt.Sprintf("search terms must be at least %d characters", minTermLength)}
+	}
+	if !negated {
+		p.terms = append(p.terms, word)
+	}
+	return &queryNode{text: word, prefix: prefix, column: column, pos: tok.pos}, nil
+}
+
+// phraseTerm builds a term node from a quoted phrase.
+func (p *queryParser) phraseTerm(tok *queryToken, negated bool, column string) (*queryNode, error) {
+	if strings.TrimSpace(tok.text) == "" {
+		return nil, &QueryError{Pos: tok.pos, Msg: "empty phrase"}
+	}
+	if !negated {
+		p.terms = append(p.terms, tok.text)
+	}
+	return &queryNode{text: tok.text, prefix: tok.prefix, column: column, pos: tok.pos}, nil
+}
+
+// isFieldName reports whether name is a supported field operator.
+func isFieldName(name string) bool {
+	switch strings.ToLower(name) {
+	case "tag", "tags", "type", "after", "before", "prop", "is":
+		return true
+	}
+	_, ok := queryColumns[strings.ToLower(name)]
+	return ok
+}
+
+// parseQueryDate parses YYYY, YYYY-MM or YYYY-MM-DD as a UTC Unix timestamp.
+func parseQueryDate(value string) (int64, error) {
+	for _, layout := range []string{"2006-01-02", "2006-01", "2006"} {
+		if t, err := time.Parse(layout, value); err == nil {
+			return t.Unix(), nil
+		}
+	}
+	return 0, fmt.Errorf("invalid date: %s", value)
+}
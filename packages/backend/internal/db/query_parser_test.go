@@ -0,0 +1,224 @@
+// Package db tests for the structured search query language.
+package db
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"testing"
+	"time"
+)
+
+// TestParseQuery_match verifies text terms compile to quoted FTS5 expressions.
+func TestParseQuery_match(t *testing.T) {
+	tests := []struct {
+		name  string
+		input string
+		match string
+	}{
+		{"single word", "golang", `"golang"`},
+		{"implicit AND", "go programming", `"go" AND "programming"`},
+		{"explicit AND", "go AND guide", `"go" AND "guide"`},
+		{"OR", "rust OR golang", `"rust" OR "golang"`},
+		{"phrase", `"exact phrase"`, `"exact phrase"`},
+		{"prefix", "program*", `"program"*`},
+		{"single char prefix", "p*", `"p"*`},
+		{"minus negation", "programming -python", `"programming" NOT ("python")`},
+		{"NOT negation", "programming NOT python", `"programming" NOT ("python")`},
+		{"multiple negations", "golang -draft -todo", `"golang" NOT ("draft" OR "todo")`},
+		{"negation with group", "go web -draft", `("go" AND "web") NOT ("draft")`},
+		{"parentheses", "(rust OR golang) guide", `("rust" OR "golang") AND "guide"`},
+		{"title field", "title:golang", `title : "golang"`},
+		{"body field", "body:channels", `content_text : "channels"`},
+		{"title phrase", `title:"go guide"`, `title : "go guide"`},
+		{"FTS syntax is quoted", `col:val+x`, `"col:val+x"`},
+		{"quote in word", `it's`, `"it's"`},
+		{"lowercase keywords are terms", "cats and dogs", `"cats" AND "and" AND "dogs"`},
+		{"hyphenated word", "e-mail", `"e-mail"`},
+		{"CJK", "學習", `"學習"`},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			pq, err := ParseQuery(tt.input)
+			if err != nil {
+				t.Fatalf("ParseQuery(%q) error: %v", tt.input, err)
+			}
+			if pq.Match != tt.match {
+				t.Errorf("ParseQuery(%q).Match = %q, want %q", tt.input, pq.Match, tt.match)
+			}
+			if pq.Raw != tt.input {
+				t.Errorf("Raw = %q, want %q", pq.Raw, tt.input)
+			}
+		})
+	}
+}
+
+// TestParseQuery_filters verifies field operators compile to filter clauses.
+func TestParseQuery_filters(t *testing.T) {
+	pq, err := ParseQuery(`tag:research type:pdf after:2025-01-01 "exact phrase" -draft title:golang`)
+	if err != nil {
+		t.Fatalf("ParseQuery error: %v", err)
+	}
+
+	if want := `("exact phrase" AND title : "golang") NOT ("draft")`; pq.Match != want {
+		t.Errorf("Match = %q, want %q", pq.Match, want)
+	}
+	if pq.Filters.Count() != 3 {
+		t.Fatalf("Expected 3 filters, got %d (%s)", pq.Filters.Count(), pq.Filters)
+	}
+
+	sql, args := pq.Filters.Build()
+	want := fmt.Sprintf(tagFilterSQL, "?") + " AND ci.media_type = ? AND ci.created_at >= ?"
+	if sql != want {
+		t.Errorf("SQL = %q, want %q", sql, want)
+	}
+	after := time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC).Unix()
+	if len(args) != 3 || args[0] != "research" || args[1] != "pdf" || args[2] != after {
+		t.Errorf("Unexpected args: %v", args)
+	}
+
+	if len(pq.Terms) != 2 || pq.Terms[0] != "exact phrase" || pq.Terms[1] != "golang" {
+		t.Errorf("Terms = %v, want [exact phrase golang]", pq.Terms)
+	}
+}
+
+// TestParseQuery_filterOnly verifies queries without text terms.
+func TestParseQuery_filterOnly(t *testing.T) {
+	pq, err := ParseQuery("tag:work,personal -tag:archived before:2024")
+	if err != nil {
+		t.Fatalf("ParseQuery error: %v", err)
+	}
+	if pq.Match != "" {
+		t.Errorf("Match = %q, want empty", pq.Match)
+	}
+
+	sql, args := pq.Filters.Build()
+	want := fmt.Sprintf(tagFilterSQL, "?, ?") + " AND NOT " + fmt.Sprintf(tagFilterSQL, "?") + " AND ci.created_at <= ?"
+	if sql != want {
+		t.Errorf("SQL = %q, want %q", sql, want)
+	}
+	before := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC).Unix() - 1
+	if len(args) != 4 || args[3] != before {
+		t.Errorf("Unexpected args: %v", args)
+	}
+
+	// Only negated terms become a match exclusion filter
+	pq, err = ParseQuery("-draft -todo")
+	if err != nil {
+		t.Fatalf("ParseQuery error: %v", err)
+	}
+	if pq.Match != "" {
+		t.Errorf("Match = %q, want empty", pq.Match)
+	}
+	sql, args = pq.Filters.Build()
+	if !strings.Contains(sql, "NOT IN") || len(args) != 1 || args[0] != `"draft" OR "todo"` {
+		t.Errorf("Unexpected exclusion filter: %s %v", sql, args)
+	}
+}
+
+// TestParseQuery_errors verifies malformed queries report a position.
+func TestParseQuery_errors(t *testing.T) {
+	tests := []struct {
+		name  string
+		input string
+		msg   string
+		pos   int
+	}{
+		{"empty", "   ", "empty query", 1},
+		{"short term", "go a", "at least 2 characters", 4},
+		{"unterminated phrase", `golang "exact`, "unterminated phrase", 8},
+		{"empty phrase", `golang ""`, "empty phrase", 8},
+		{"unmatched open", "(golang rust", "unmatched '('", 1},
+		{"unmatched close", "golang)", "unmatched ')'", 7},
+		{"empty group", "golang ()", "empty group", 8},
+		{"dangling OR", "golang OR", "OR needs a term", 8},
+		{"leading OR", "OR golang", "OR needs a term", 1},
+		{"dangling AND", "golang AND", "AND needs a term", 8},
+		{"dangling NOT", "golang NOT", "NOT needs a term", 8},
+		{"NEAR", "test NEAR/10 query", "unsupported operator", 6},
+		{"caret", "^golang", "unsupported operator", 1},
+		{"bad wildcard", "go*lang", "end of a term", 1},
+		{"bare wildcard", "*", "wildcard needs a prefix", 1},
+		{"unknown media type", "type:audio", "unknown media type", 6},
+		{"negated type", "-type:pdf", "cannot be negated", 2},
+		{"bad date", "after:yesterday", "invalid date", 7},
+		{"future date", "before:2999-01-01", "out of range", 8},
+		{"empty tag", "tag:", "needs a value", 5},
+		{"negated prop", "-prop:author=Jane", "cannot be negated", 2},
+		{"prop without operator", "prop:author", "needs a key, an operator and a value", 6},
+		{"prop without value", "prop:author=", "needs a value", 6},
+		{"bad property key", "prop:9lives=1", "invalid property key", 6},
+		{"unknown state", "is:starred", "unknown state", 4},
+		{"filter in group", "(golang tag:work)", "inside parentheses", 9},
+		{"filter with OR", "golang OR rust tag:work", "combined with OR", 16},
+		{"only negations in group", "golang (-draft)", "positive term", 9},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := ParseQuery(tt.input)
+			if err == nil {
+				t.Fatalf("ParseQuery(%q) expected error", tt.input)
+			}
+			var qe *QueryError
+			if !errors.As(err, &qe) {
+				t.Fatalf("Expected *QueryError, got %T", err)
+			}
+			if !strings.Contains(qe.Msg, tt.msg) {
+				t.Errorf("Msg = %q, want it to contain %q", qe.Msg, tt.msg)
+			}
+			if qe.Pos != tt.pos {
+				t.Errorf("Pos = %d, want %d", qe.Pos, tt.pos)
+			}
+		})
+	}
+}
+
+// TestSearch_structuredQuery runs compiled queries against the FTS index.
+func TestSearch_structuredQuery(t *testing.T) {
+	db := setupSearchTestDB(t)
+	defer db.Close()
+
+	repo := NewRepository(db)
+
+	old := time.Date(2024, 6, 1, 0, 0, 0, 0, time.UTC).Unix()
+	recent := time.Date(2025, 3, 1, 0, 0, 0, 0, time.UTC).Unix()
+	insertTestContentItem(t, db, "Golang Research Notes", "Exact phrase about channels", "pdf", "research", recent)
+	insertTestContentItem(t, db, "Golang Draft", "Exact phrase in a draft", "pdf", "research", recent)
+	insertTestContentItem(t, db, "Old Golang Paper", "Exact phrase from last year", "pdf", "research", old)
+	insertTestContentItem(t, db, "Golang Blog", "Exact phrase on the web", "web", "research", recent)
+	insertTestContentItem(t, db, "Archived Notes", "Nothing relevant", "web", "archived", recent)
+
+	tests := []struct {
+		query string
+		want  int
+	}{
+		{`tag:research type:pdf after:2025-01-01 "exact phrase" -draft title:golang`, 1},
+		{`title:golang`, 4},
+		{`title:golang -title:draft`, 3},
+		{`type:pdf`, 3},
+		{`-tag:research`, 1},
+		{`-golang`, 1},
+		{`(blog OR paper) golang`, 2},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.query, func(t *testing.T) {
+			pq, err := ParseQuery(tt.query)
+			if err != nil {
+				t.Fatalf("ParseQuery error: %v", err)
+			}
+			opts := &SearchOptions{Limit: 10}
+			pq.Apply(opts)
+
+			resp, err := repo.Search(opts)
+			if err != nil {
+				t.Fatalf("Search failed: %v", err)
+			}
+			if resp.Total != tt.want || len(resp.Results) != tt.want {
+				t.Errorf("Expected %d results, got %d (total %d)", tt.want, len(resp.Results), resp.Total)
+			}
+		})
+	}
+}
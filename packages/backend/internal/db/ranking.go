@@ -0,0 +1,139 @@
+// Package db provides search ranking profiles.
+package db
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+)
+
+// Ranking profile names.
+const (
+	// RankingRelevance ranks by weighted BM25 only (the default)
+	RankingRelevance = "relevance"
+
+	// RankingRecent strongly favors recently updated items
+	RankingRecent = "recent"
+
+	// RankingBalanced blends relevance, recency and engagement
+	RankingBalanced = "balanced"
+)
+
+// RankingProfile blends BM25 relevance with recency and engagement signals.
+// Scores follow the BM25 convention: lower is better. The blended score is
+//
+//	Relevance*bm25
+//	  - Recency*0.5^(age_days/HalfLifeDays)
+//	  - Pinned*is_pinned - Favorite*is_favorite
+//	  - Opens*ln(1+open_count)
+//
+// where bm25 is negative for matches and age is measured from updated_at.
+type RankingProfile struct {
+	// Name identifies the profile
+	Name string
+
+	// Relevance scales the BM25 score
+	Relevance float64
+
+	// Recency is the boost for an item updated just now; it halves every
+	// HalfLifeDays
+	Recency      float64
+	HalfLifeDays float64
+
+	// Pinned and Favorite are flat boosts for flagged items
+	Pinned   float64
+	Favorite float64
+
+	// Opens is the boost per e-fold of the item's open count
+	Opens float64
+}
+
+// rankingProfiles defines every ranking profile. A BM25 score for a good
+// match is typically between -1 and -10 with the default column weights,
+// which sets the scale of the other signals.
+var rankingProfiles = map[string]RankingProfile{
+	RankingRelevance: {
+		Name:      RankingRelevance,
+		Relevance: 1,
+	},
+	RankingRecent: {
+		Name:         RankingRecent,
+		Relevance:    0.25,
+		Recency:      8,
+		HalfLifeDays: 7,
+		Pinned:       1,
+		Favorite:     0.5,
+		Opens:        0.25,
+	},
+	RankingBalanced: {
+		Name:         RankingBalanced,
+		Relevance:    1,
+		Recency:      2,
+		HalfLifeDays: 30,
+		Pinned:       2,
+		Favorite:     1,
+		Opens:        0.5,
+	},
+}
+
+// RankingProfiles returns the names of the available ranking profiles.
+func RankingProfiles() []string {
+	names := make([]string, 0, len(rankingProfiles))
+	for name := range rankingProfiles {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// LookupRankingProfile returns the named ranking profile. An empty name
+// selects RankingRelevance.
+func LookupRankingProfile(name string) (RankingProfile, error) {
+	if name == "" {
+		name = RankingRelevance
+	}
+	profile, ok := rankingProfiles[name]
+	if !ok {
+		return RankingProfile{}, fmt.Errorf("invalid ranking profile: %s (valid: %s)",
+			name, strings.Join(RankingProfiles(), ", "))
+	}
+	return profile, nil
+}
+
+// blended reports whether the profile uses anything besides BM25.
+func (p RankingProfile) blended() bool {
+	return p.Recency != 0 || p.Pinned != 0 || p.Favorite != 0 || p.Opens != 0
+}
+
+// scoreSQL wraps a BM25 score expression in the profile's blend. It expects
+// content_items aliased as ci and content_engagement left-joined as ce
+// (see rankingJoin). now is the Unix time recency is measured from.
+func (p RankingProfile) scoreSQL(bm25 string, now int64) (string, []interface{}) {
+	expr := `((` + bm25 + `) * ?
+		- ? * exp(-0.6931471805599453 * max(0, ? - ci.updated_at) / ?)
+		- ? * ci.is_pinned - ? * ci.is_favorite
+		- ? * ln(1 + coalesce(ce.open_count, 0)))`
+	halfLife := p.HalfLifeDays * 86400
+	if halfLife <= 0 {
+		halfLife = 1
+	}
+	return expr, []interface{}{p.Relevance, p.Recency, now, halfLife, p.Pinned, p.Favorite, p.Opens}
+}
+
+// rankingJoin joins the engagement statistics used by scoreSQL.
+const rankingJoin = `
+		LEFT JOIN content_engagement ce ON ce.content_id = ci.id`
+
+// RecordContentOpen counts an open of a content item for engagement
+// ranking. Counts live outside content_items so recording an open neither
+// bumps the item version nor touches the FTS index.
+func (r *Repository) RecordContentOpen(id string) error {
+	query := `
+	INSERT INTO content_engagement (content_id, open_count, last_opened_at) VALUES (?, 1, ?)
+	ON CONFLICT(content_id) DO UPDATE SET
+		open_count = open_count + 1, last_opened_at = excluded.last_opened_at
+	`
+	_, err := r.db.Exec(query, id, time.Now().Unix())
+	return err
+}
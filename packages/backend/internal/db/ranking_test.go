@@ -0,0 +1,226 @@
+// Package db tests for search ranking profiles.
+package db
+
+import (
+	"database/sql"
+	"reflect"
+	"testing"
+
+	"github.com/kimhsiao/memonexus/backend/internal/models"
+)
+
+// rankingNow is the fixed clock used by the ranking tests.
+const rankingNow = int64(1_700_000_000)
+
+// setupRankingTestDB creates four items matching "golang":
+//
+//	strong:   title and body hit, updated a year ago
+//	fresh:    body hit, updated an hour ago
+//	flagged:  body hit, updated a year ago, pinned and favorite
+//	opened:   body hit, updated a year ago, opened five times
+//
+// fresh, flagged and opened have identical text, so their BM25 scores tie.
+// Twenty unrelated web items are added as well.
+func setupRankingTestDB(t *testing.T) (*Repository, map[string]string) {
+	t.Helper()
+
+	db := setupMigratedTestDB(t)
+	t.Cleanup(func() { db.Close() })
+
+	repo := NewRepository(db)
+	yearAgo := rankingNow - 365*86400
+	items := []struct {
+		name      string
+		title     string
+		updatedAt int64
+		flags     string
+	}{
+		{"strong", "Golang", yearAgo, ""},
+		{"fresh", "Notes", rankingNow - 3600, ""},
+		{"flagged", "Notes", yearAgo, "is_pinned = 1, is_favorite = 1,"},
+		{"opened", "Notes", yearAgo, ""},
+	}
+	ids := make(map[string]string, len(items))
+	for _, it := range items {
+		item := &models.ContentItem{Title: it.title, ContentText: "golang", MediaType: "markdown"}
+		if err := repo.CreateContentItem(item); err != nil {
+			t.Fatalf("CreateContentItem failed: %v", err)
+		}
+		_, err := db.Exec(`UPDATE content_items SET `+it.flags+` created_at = ?, updated_at = ? WHERE id = ?`,
+			yearAgo, it.updatedAt, item.ID)
+		if err != nil {
+			t.Fatalf("update failed: %v", err)
+		}
+		ids[string(item.ID)] = it.name
+	}
+	// Unrelated items give "golang" a meaningful IDF
+	for i := 0; i < 20; i++ {
+		filler := &models.ContentItem{Title: "Filler", ContentText: "rust notes", MediaType: "web"}
+		if err := repo.CreateContentItem(filler); err != nil {
+			t.Fatalf("CreateContentItem failed: %v", err)
+		}
+	}
+	for id, name := range ids {
+		if name != "opened" {
+			continue
+		}
+		for i := 0; i < 5; i++ {
+			if err := repo.RecordContentOpen(id); err != nil {
+				t.Fatalf("RecordContentOpen failed: %v", err)
+			}
+		}
+	}
+	return repo, ids
+}
+
+// rankedNames runs a search and returns the result names in rank order.
+func rankedNames(t *testing.T, repo *Repository, ids map[string]string, opts *SearchOptions) []string {
+	t.Helper()
+	resp, err := repo.Search(opts)
+	if err != nil {
+		t.Fatalf("Search(%+v) failed: %v", opts, err)
+	}
+	names := make([]string, len(resp.Results))
+	for i, r := range resp.Results {
+		names[i] = ids[string(r.Item.ID)]
+	}
+	return names
+}
+
+// TestSearch_rankingProfiles verifies each profile orders results as designed.
+func TestSearch_rankingProfiles(t *testing.T) {
+	repo, ids := setupRankingTestDB(t)
+
+	tests := []struct {
+		ranking string
+		want    []string
+	}{
+		// Pure BM25: ties keep insertion order
+		{"", []string{"strong", "fresh", "flagged", "opened"}},
+		{RankingRelevance, []string{"strong", "fresh", "flagged", "opened"}},
+		// Recency dominates; the stale items fall back to flags and opens
+		{RankingRecent, []string{"fresh", "flagged", "opened", "strong"}},
+		// Flags and recency outweigh a modestly stronger match; five opens
+		// alone do not
+		{RankingBalanced, []string{"flagged", "fresh", "strong", "opened"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.ranking, func(t *testing.T) {
+			got := rankedNames(t, repo, ids, &SearchOptions{Query: "golang", Ranking: tt.ranking, Now: rankingNow})
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("Ranking %q = %v, want %v", tt.ranking, got, tt.want)
+			}
+		})
+	}
+}
+
+// TestSearch_rankingRelevance verifies Relevance is the normalized BM25
+// score under every profile, while Score carries the blend.
+func TestSearch_rankingRelevance(t *testing.T) {
+	repo, _ := setupRankingTestDB(t)
+
+	relevance := func(ranking string) map[models.UUID]*SearchResult {
+		resp, err := repo.Search(&SearchOptions{Query: "golang", Ranking: ranking, Now: rankingNow})
+		if err != nil {
+			t.Fatalf("Search failed: %v", err)
+		}
+		byID := make(map[models.UUID]*SearchResult, len(resp.Results))
+		for _, r := range resp.Results {
+			byID[r.Item.ID] = r
+		}
+		return byID
+	}
+
+	plain := relevance(RankingRelevance)
+	for id, r := range relevance(RankingBalanced) {
+		if r.Relevance != plain[id].Relevance {
+			t.Errorf("Balanced relevance = %f, want BM25 relevance %f", r.Relevance, plain[id].Relevance)
+		}
+		if r.Score == plain[id].Score {
+			t.Errorf("Balanced score %f should differ from BM25 score %f", r.Score, plain[id].Score)
+		}
+	}
+}
+
+// TestSearch_rankingCursor verifies blended rankings page consistently.
+func TestSearch_rankingCursor(t *testing.T) {
+	repo, ids := setupRankingTestDB(t)
+
+	for _, ranking := range RankingProfiles() {
+		want := rankedNames(t, repo, ids, &SearchOptions{Query: "golang", Ranking: ranking, Now: rankingNow})
+
+		var got []string
+		cursor := ""
+		for {
+			resp, err := repo.Search(&SearchOptions{Query: "golang", Ranking: ranking, Now: rankingNow, Limit: 1, Cursor: cursor})
+			if err != nil {
+				t.Fatalf("Search failed: %v", err)
+			}
+			for _, r := range resp.Results {
+				got = append(got, ids[string(r.Item.ID)])
+			}
+			if resp.NextCursor == "" {
+				break
+			}
+			cursor = resp.NextCursor
+		}
+		if !reflect.DeepEqual(got, want) {
+			t.Errorf("Ranking %q paged = %v, want %v", ranking, got, want)
+		}
+	}
+}
+
+// TestSearch_rankingFilterOnly verifies blended profiles also rank
+// filter-only queries, which otherwise list the newest items first.
+func TestSearch_rankingFilterOnly(t *testing.T) {
+	repo, ids := setupRankingTestDB(t)
+
+	parsed, err := ParseQuery("type:markdown")
+	if err != nil {
+		t.Fatalf("ParseQuery failed: %v", err)
+	}
+	opts := &SearchOptions{Ranking: RankingRecent, Now: rankingNow}
+	parsed.Apply(opts)
+
+	got := rankedNames(t, repo, ids, opts)
+	if len(got) != 4 || got[0] != "fresh" || got[1] != "flagged" {
+		t.Errorf("Filter-only recent ranking = %v, want fresh, flagged first", got)
+	}
+}
+
+// TestLookupRankingProfile verifies profile lookup and the default.
+func TestLookupRankingProfile(t *testing.T) {
+	if p, err := LookupRankingProfile(""); err != nil || p.Name != RankingRelevance || p.blended() {
+		t.Errorf("Default profile = %+v, %v; want unblended relevance", p, err)
+	}
+	for _, name := range []string{RankingRecent, RankingBalanced} {
+		if p, err := LookupRankingProfile(name); err != nil || !p.blended() {
+			t.Errorf("LookupRankingProfile(%q) = %+v, %v; want blended", name, p, err)
+		}
+	}
+	if _, err := LookupRankingProfile("popular"); err == nil {
+		t.Error("Expected error for unknown profile")
+	}
+
+	repo := NewRepository(setupMigratedTestDB(t))
+	if _, err := repo.Search(&SearchOptions{Query: "golang", Ranking: "popular"}); err == nil {
+		t.Error("Expected Search to reject an unknown profile")
+	}
+}
+
+// TestRecordContentOpen verifies open counts accumulate.
+func TestRecordContentOpen(t *testing.T) {
+	repo, ids := setupRankingTestDB(t)
+
+	for id, name := range ids {
+		var count int
+		err := repo.db.QueryRow(`SELECT open_count FROM content_engagement WHERE content_id = ?`, id).Scan(&count)
+		switch {
+		case name == "opened" && (err != nil || count != 5):
+			t.Errorf("open_count = %d, %v; want 5", count, err)
+		case name != "opened" && err != sql.ErrNoRows:
+			t.Errorf("Unexpected engagement for %s: %d, %v", name, count, err)
+		}
+	}
+}
@@ -2,8 +2,10 @@
 package db
 
 import (
+	"context"
 	"database/sql"
 	"fmt"
+	"strings"
 	"sync"
 	"time"
 
@@ -66,35 +68,135 @@ func NewRepository(db *sql.DB) *Repository {
 	return &Repository{db: db}
 }
 
+// WithTx runs fn in a transaction bound to ctx. The transaction commits if
+// fn returns nil and rolls back otherwise, including when ctx is cancelled.
+func (r *Repository) WithTx(ctx context.Context, fn func(tx *sql.Tx) error) error {
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	if err := fn(tx); err != nil {
+		return err
+	}
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("failed to commit transaction: %w", err)
+	}
+	return nil
+}
+
 // =====================================================
 // ContentItem Operations
 // =====================================================
 
 // CreateContentItem creates a new content item.
+// item.Tags (comma-separated) is assigned through content_tags and rewritten
+// with the canonical tag names.
 func (r *Repository) CreateContentItem(item *models.ContentItem) error {
-	now := time.Now().Unix()
+	return r.CreateContentItemContext(context.Background(), item)
+}
+
+// CreateContentItemContext is CreateContentItem with a context. The item
+// and its change_log entry are written in one transaction.
+func (r *Repository) CreateContentItemContext(ctx context.Context, item *models.ContentItem) error {
+	return r.WithTx(ctx, func(tx *sql.Tx) error {
+		return createContentItem(ctx, tx, item, time.Now().Unix())
+	})
+}
+
+// createContentItem inserts a new content item with its tags, links and
+// change_log entry inside tx.
+func createContentItem(ctx context.Context, tx *sql.Tx, item *models.ContentItem, now int64) error {
 	item.ID = models.UUID(uuid.New())
 	item.CreatedAt = now
 	item.UpdatedAt = now
 	item.Version = 1
+	if item.ReadStatus == "" {
+		item.ReadStatus = models.ReadStatusUnread
+	}
+
+	tags, err := resolveItemTags(ctx, tx, item, now)
+	if err != nil {
+		return err
+	}
 
 	query := `
 	INSERT INTO content_items (id, title, content_text, source_url, media_type, tags, summary,
-		is_deleted, created_at, updated_at, version, content_hash)
-	VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)
+		is_deleted, created_at, updated_at, version, content_hash,
+		is_favorite, is_pinned, read_status, read_progress, is_archived,
+		favorite_changed_at, pinned_changed_at, read_changed_at, archived_changed_at)
+	VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)
 	`
-	_, err := r.db.Exec(query, item.ID, item.Title, item.ContentText, item.SourceURL,
+	_, err = tx.ExecContext(ctx, query, item.ID, item.Title, item.ContentText, item.SourceURL,
 		item.MediaType, item.Tags, item.Summary, item.IsDeleted,
-		item.CreatedAt, item.UpdatedAt, item.Version, item.ContentHash)
-	return err
+		item.CreatedAt, item.UpdatedAt, item.Version, item.ContentHash,
+		item.IsFavorite, item.IsPinned, item.ReadStatus, item.ReadProgress, item.IsArchived,
+		item.FavoriteChangedAt, item.PinnedChangedAt, item.ReadChangedAt, item.ArchivedChangedAt)
+	if err != nil {
+		return err
+	}
+	if len(tags) > 0 {
+		if err := linkContentTags(ctx, tx, string(item.ID), tags, now); err != nil {
+			return err
+		}
+	}
+	if err := replaceContentLinks(ctx, tx, item, now); err != nil {
+		return err
+	}
+	if err := resolveWikiLinks(ctx, tx, string(item.ID), item.Title); err != nil {
+		return err
+	}
+	return insertChangeLog(ctx, tx, string(item.ID), "create", item.Version, now)
+}
+
+// contentItemColumns lists the content_items columns read by scanContentItem.
+const contentItemColumns = `id, title, content_text, source_url, media_type, tags, summary,
+		   is_deleted, created_at, updated_at, version, content_hash,
+		   is_favorite, is_pinned, read_status, read_progress, is_archived,
+		   favorite_changed_at, pinned_changed_at, read_changed_at, archived_changed_at`
+
+// scanContentItem scans one row selected with contentItemColumns, followed
+// by any extra columns into extra.
+func scanContentItem(row rowScanner, extra ...interface{}) (*models.ContentItem, error) {
+	var item models.ContentItem
+	var sourceURL, summary, contentHash sql.NullString
+	dest := []interface{}{
+		&item.ID, &item.Title, &item.ContentText, &sourceURL, &item.MediaType,
+		&item.Tags, &summary, &item.IsDeleted, &item.CreatedAt, &item.UpdatedAt,
+		&item.Version, &contentHash,
+		&item.IsFavorite, &item.IsPinned, &item.ReadStatus, &item.ReadProgress, &item.IsArchived,
+		&item.FavoriteChangedAt, &item.PinnedChangedAt, &item.ReadChangedAt, &item.ArchivedChangedAt,
+	}
+	if err := row.Scan(append(dest, extra...)...); err != nil {
+		return nil, err
+	}
+	item.SourceURL = sourceURL.String
+	item.Summary = summary.String
+	item.ContentHash = contentHash.String
+	return &item, nil
 }
 
+// prefixedContentItemColumns is contentItemColumns qualified with the ci
+// alias, for queries joining content_items to other tables.
+var prefixedContentItemColumns = func() string {
+	columns := strings.Split(contentItemColumns, ",")
+	for i, column := range columns {
+		columns[i] = "ci." + strings.TrimSpace(column)
+	}
+	return strings.Join(columns, ", ")
+}()
+
 // GetContentItem retrieves a content item by ID.
 // T222: Uses prepared statement for repeated queries.
 func (r *Repository) GetContentItem(id string) (*models.ContentItem, error) {
+	return r.GetContentItemContext(context.Background(), id)
+}
+
+// GetContentItemContext is GetContentItem with a context.
+func (r *Repository) GetContentItemContext(ctx context.Context, id string) (*models.ContentItem, error) {
 	query := `
-	SELECT id, title, content_text, source_url, media_type, tags, summary,
-		   is_deleted, created_at, updated_at, version, content_hash
+	SELECT ` + contentItemColumns + `
 	FROM content_items WHERE id = ? AND is_deleted = 0
 	`
 	// T222: Use prepared statement from cache
@@ -102,49 +204,53 @@ func (r *Repository) GetContentItem(id string) (*models.ContentItem, error) {
 	if err != nil {
 		return nil, err
 	}
-
-	var item models.ContentItem
-	var sourceURL, summary, contentHash sql.NullString
-	err = stmt.QueryRow(id).Scan(
-		&item.ID, &item.Title, &item.ContentText, &sourceURL, &item.MediaType,
-		&item.Tags, &summary, &item.IsDeleted, &item.CreatedAt, &item.UpdatedAt,
-		&item.Version, &contentHash,
-	)
-	if err != nil {
-		return nil, err
-	}
-	if sourceURL.Valid {
-		item.SourceURL = sourceURL.String
-	}
-	if summary.Valid {
-		item.Summary = summary.String
-	}
-	if contentHash.Valid {
-		item.ContentHash = contentHash.String
-	}
-	return &item, nil
+	return scanContentItem(stmt.QueryRowContext(ctx, id))
 }
 
 // ListContentItems returns content items with pagination and filters.
 // T222: Uses prepared statements for both query variants (with/without mediaType filter).
 func (r *Repository) ListContentItems(limit, offset int, mediaType string) ([]*models.ContentItem, error) {
-	// T222: Build query based on filters
-	baseQuery := `
-	SELECT id, title, content_text, source_url, media_type, tags, summary,
-		   is_deleted, created_at, updated_at, version, content_hash
-	FROM content_items WHERE is_deleted = 0
-	`
-	orderLimit := " ORDER BY created_at DESC LIMIT ? OFFSET ?"
+	return r.ListContentItemsContext(context.Background(), limit, offset, mediaType)
+}
 
-	var query string
-	var args []interface{}
+// ListContentItemsContext is ListContentItems with a context. It lists
+// every live item, archived ones included, newest first.
+func (r *Repository) ListContentItemsContext(ctx context.Context, limit, offset int, mediaType string) ([]*models.ContentItem, error) {
+	return r.listContentItems(ctx, limit, offset, mediaTypeFilters(mediaType), false)
+}
+
+// ListContentItemsFiltered returns the live content items matching
+// filters, pinned items first and then newest first. A nil filters lists
+// every live item.
+func (r *Repository) ListContentItemsFiltered(ctx context.Context, limit, offset int, filters *FilterBuilder) ([]*models.ContentItem, error) {
+	return r.listContentItems(ctx, limit, offset, filters, true)
+}
 
+// mediaTypeFilters returns the filters of the unfiltered listings. Unlike
+// FilterBuilder.MediaType it keeps unknown media types, which match nothing.
+func mediaTypeFilters(mediaType string) *FilterBuilder {
+	fb := NewFilterBuilder()
 	if mediaType != "" {
-		query = baseQuery + " AND media_type = ?" + orderLimit
-		args = []interface{}{mediaType, limit, offset}
-	} else {
-		query = baseQuery + orderLimit
-		args = []interface{}{limit, offset}
+		fb.filters = append(fb.filters, &MediaTypeFilter{MediaType: mediaType})
+	}
+	return fb
+}
+
+// contentListOrder returns the ORDER BY columns of a content listing.
+func contentListOrder(pinnedFirst bool) string {
+	if pinnedFirst {
+		return " ORDER BY ci.is_pinned DESC, ci.created_at DESC, ci.id DESC"
+	}
+	return " ORDER BY ci.created_at DESC, ci.id DESC"
+}
+
+// queryContentList runs a content listing query. Only unfiltered listings,
+// which have a fixed set of shapes, use the statement cache; filtered ones
+// are built from arbitrary filter combinations and would grow it without
+// bound.
+func (r *Repository) queryContentList(ctx context.Context, query string, filters *FilterBuilder, args []interface{}) (*sql.Rows, error) {
+	if filters != nil && filters.HasFilters() {
+		return r.db.QueryContext(ctx, query, args...)
 	}
 
 	// T222: Use prepared statement from cache
@@ -152,8 +258,22 @@ func (r *Repository) ListContentItems(limit, offset int, mediaType string) ([]*m
 	if err != nil {
 		return nil, err
 	}
+	return stmt.QueryContext(ctx, args...)
+}
+
+// listContentItems runs a LIMIT/OFFSET content listing.
+func (r *Repository) listContentItems(ctx context.Context, limit, offset int, filters *FilterBuilder, pinnedFirst bool) ([]*models.ContentItem, error) {
+	query := `SELECT ` + contentItemColumns + ` FROM content_items ci WHERE ci.is_deleted = 0`
+	var args []interface{}
+	if filters != nil && filters.HasFilters() {
+		filterSQL, filterArgs := filters.Build()
+		query += " AND " + filterSQL
+		args = append(args, filterArgs...)
+	}
+	query += contentListOrder(pinnedFirst) + " LIMIT ? OFFSET ?"
+	args = append(args, limit, offset)
 
-	rows, err := stmt.Query(args...)
+	rows, err := r.queryContentList(ctx, query, filters, args)
 	if err != nil {
 		return nil, err
 	}
@@ -161,44 +281,135 @@ func (r *Repository) ListContentItems(limit, offset int, mediaType string) ([]*m
 
 	var items []*models.ContentItem
 	for rows.Next() {
-		var item models.ContentItem
-		var sourceURL, summary, contentHash sql.NullString
-		err := rows.Scan(
-			&item.ID, &item.Title, &item.ContentText, &sourceURL, &item.MediaType,
-			&item.Tags, &summary, &item.IsDeleted, &item.CreatedAt, &item.UpdatedAt,
-			&item.Version, &contentHash,
-		)
+		item, err := scanContentItem(rows)
 		if err != nil {
 			return nil, err
 		}
-		if sourceURL.Valid {
-			item.SourceURL = sourceURL.String
+		items = append(items, item)
+	}
+	// Check for errors that occurred during iteration
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+// ListContentItemsPage returns the page of content items following cursor,
+// newest first. An empty cursor starts from the newest item.
+// Keyset pagination on (created_at, id) keeps pages stable while items are
+// added and costs the same at any depth, unlike LIMIT/OFFSET.
+// Returns ErrInvalidCursor if cursor is malformed.
+func (r *Repository) ListContentItemsPage(cursor string, limit int, mediaType string) (*ContentPage, error) {
+	return r.listContentItemsPage(cursor, limit, mediaTypeFilters(mediaType), false)
+}
+
+// ListContentItemsPageFiltered is ListContentItemsPage for the items
+// matching filters, pinned items first. Its keyset is (is_pinned,
+// created_at, id).
+func (r *Repository) ListContentItemsPageFiltered(cursor string, limit int, filters *FilterBuilder) (*ContentPage, error) {
+	return r.listContentItemsPage(cursor, limit, filters, true)
+}
+
+// listContentItemsPage runs a keyset-paginated content listing.
+func (r *Repository) listContentItemsPage(cursor string, limit int, filters *FilterBuilder, pinnedFirst bool) (*ContentPage, error) {
+	if limit <= 0 {
+		limit = 20
+	}
+
+	query := `SELECT ` + contentItemColumns + ` FROM content_items ci WHERE ci.is_deleted = 0`
+	var args []interface{}
+
+	if filters != nil && filters.HasFilters() {
+		filterSQL, filterArgs := filters.Build()
+		query += " AND " + filterSQL
+		args = append(args, filterArgs...)
+	}
+	if cursor != "" {
+		after, err := decodeCursor(cursor, cursorKindList)
+		if err != nil {
+			return nil, err
 		}
-		if summary.Valid {
-			item.Summary = summary.String
+		keyset := "(ci.created_at < ? OR (ci.created_at = ? AND ci.id < ?))"
+		keysetArgs := []interface{}{after.CreatedAt, after.CreatedAt, after.ID}
+		if pinnedFirst {
+			keyset = "(ci.is_pinned < ? OR (ci.is_pinned = ? AND " + keyset + "))"
+			keysetArgs = append([]interface{}{after.Pinned, after.Pinned}, keysetArgs...)
 		}
-		if contentHash.Valid {
-			item.ContentHash = contentHash.String
+		query += " AND " + keyset
+		args = append(args, keysetArgs...)
+	}
+
+	// Fetch one extra row to learn whether another page follows
+	query += contentListOrder(pinnedFirst) + " LIMIT ?"
+	args = append(args, limit+1)
+
+	rows, err := r.queryContentList(context.Background(), query, filters, args)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	page := &ContentPage{Items: []*models.ContentItem{}}
+	for rows.Next() {
+		item, err := scanContentItem(rows)
+		if err != nil {
+			return nil, err
 		}
-		items = append(items, &item)
+		page.Items = append(page.Items, item)
 	}
-	// Check for errors that occurred during iteration
 	if err := rows.Err(); err != nil {
 		return nil, err
 	}
-	return items, nil
+
+	if len(page.Items) > limit {
+		page.Items = page.Items[:limit]
+		page.NextCursor = ListCursor(page.Items[limit-1])
+	}
+	return page, nil
 }
 
 // UpdateContentItem updates an existing content item.
+// item.Tags (comma-separated) replaces the item's content_tags and is
+// rewritten with the canonical tag names. The replaced state is kept as a
+// revision when it differs.
 func (r *Repository) UpdateContentItem(item *models.ContentItem) error {
+	return r.UpdateContentItemContext(context.Background(), item)
+}
+
+// UpdateContentItemContext is UpdateContentItem with a context. The item
+// and its change_log entry are written in one transaction.
+func (r *Repository) UpdateContentItemContext(ctx context.Context, item *models.ContentItem) error {
 	item.Touch()
+
+	return r.WithTx(ctx, func(tx *sql.Tx) error {
+		return updateContentItem(ctx, tx, item)
+	})
+}
+
+// updateContentItem writes a touched item and its change_log entry inside
+// tx, first recording the state it replaces as a revision. A changed title
+// is carried into the wiki links that point at the item.
+func updateContentItem(ctx context.Context, tx *sql.Tx, item *models.ContentItem) error {
+	tags, err := resolveItemTags(ctx, tx, item, item.UpdatedAt)
+	if err != nil {
+		return err
+	}
+	var oldTitle string
+	err = tx.QueryRowContext(ctx, `SELECT title FROM content_items WHERE id = ? AND is_deleted = 0`, item.ID).Scan(&oldTitle)
+	if err != nil && err != sql.ErrNoRows {
+		return err
+	}
+	if err := snapshotContentItem(ctx, tx, item); err != nil {
+		return err
+	}
+
 	query := `
 	UPDATE content_items
 	SET title = ?, content_text = ?, source_url = ?, media_type = ?, tags = ?,
 		summary = ?, updated_at = ?, version = ?, content_hash = ?
 	WHERE id = ? AND is_deleted = 0
 	`
-	result, err := r.db.Exec(query, item.Title, item.ContentText, item.SourceURL,
+	result, err := tx.ExecContext(ctx, query, item.Title, item.ContentText, item.SourceURL,
 		item.MediaType, item.Tags, item.Summary, item.UpdatedAt, item.Version,
 		item.ContentHash, item.ID)
 	if err != nil {
@@ -208,59 +419,103 @@ func (r *Repository) UpdateContentItem(item *models.ContentItem) error {
 	if rows == 0 {
 		return fmt.Errorf("content item not found: %s", item.ID)
 	}
-	return nil
+	if err := linkContentTags(ctx, tx, string(item.ID), tags, item.UpdatedAt); err != nil {
+		return err
+	}
+	if err := replaceContentLinks(ctx, tx, item, item.UpdatedAt); err != nil {
+		return err
+	}
+	if oldTitle != item.Title {
+		if err := renameLinkTarget(ctx, tx, item, oldTitle); err != nil {
+			return err
+		}
+	}
+	return insertChangeLog(ctx, tx, string(item.ID), "update", item.Version, item.UpdatedAt)
 }
 
 // DeleteContentItem soft deletes a content item.
 func (r *Repository) DeleteContentItem(id string) error {
-	query := `UPDATE content_items SET is_deleted = 1, updated_at = ? WHERE id = ?`
+	return r.DeleteContentItemContext(context.Background(), id)
+}
+
+// DeleteContentItemContext is DeleteContentItem with a context. The
+// deletion gets a new version, logged to change_log in the same
+// transaction so it syncs.
+func (r *Repository) DeleteContentItemContext(ctx context.Context, id string) error {
 	now := time.Now().Unix()
-	result, err := r.db.Exec(query, now, id)
-	if err != nil {
-		return err
-	}
-	rows, _ := result.RowsAffected()
-	if rows == 0 {
-		return fmt.Errorf("content item not found: %s", id)
-	}
-	return nil
+
+	return r.WithTx(ctx, func(tx *sql.Tx) error {
+		query := `UPDATE content_items SET is_deleted = 1, updated_at = ?, version = version + 1 WHERE id = ?`
+		result, err := tx.ExecContext(ctx, query, now, id)
+		if err != nil {
+			return err
+		}
+		rows, _ := result.RowsAffected()
+		if rows == 0 {
+			return fmt.Errorf("content item not found: %s", id)
+		}
+
+		var version int
+		if err := tx.QueryRowContext(ctx, `SELECT version FROM content_items WHERE id = ?`, id).Scan(&version); err != nil {
+			return err
+		}
+		return insertChangeLog(ctx, tx, id, "delete", version, now)
+	})
 }
 
 // =====================================================
 // Tag Operations
 // =====================================================
 
-// CreateTag creates a new tag.
+// CreateTag creates a new tag. A slash-separated name creates the tag
+// under its parent path, creating missing ancestors.
 func (r *Repository) CreateTag(tag *models.Tag) error {
+	tx, err := r.db.Begin()
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+
 	now := time.Now().Unix()
 	tag.ID = models.UUID(uuid.New())
+	tag.Name = normalizeTagPath(tag.Name)
+	if !validTagName(tag.Name) {
+		return fmt.Errorf("%w: %q", ErrInvalidTagName, tag.Name)
+	}
+	tag.ParentID = ""
 	tag.CreatedAt = now
 	tag.UpdatedAt = now
 
+	if parentPath := parentTagPath(tag.Name); parentPath != "" {
+		parent, err := resolveTag(context.Background(), tx, parentPath, now)
+		if err != nil {
+			return err
+		}
+		tag.Name = parent.Name + tagPathSeparator + tagLeaf(tag.Name)
+		tag.ParentID = parent.ID
+	}
+
 	query := `
-	INSERT INTO tags (id, name, color, is_deleted, created_at, updated_at)
-	VALUES (?, ?, ?, ?, ?, ?)
+	INSERT INTO tags (id, name, color, is_deleted, created_at, updated_at, parent_id)
+	VALUES (?, ?, ?, ?, ?, ?, ?)
 	`
-	_, err := r.db.Exec(query, tag.ID, tag.Name, tag.Color, tag.IsDeleted,
-		tag.CreatedAt, tag.UpdatedAt)
-	return err
+	_, err = tx.Exec(query, tag.ID, tag.Name, tag.Color, tag.IsDeleted,
+		tag.CreatedAt, tag.UpdatedAt, nullTagID(tag.ParentID))
+	if err != nil {
+		return err
+	}
+	return tx.Commit()
 }
 
 // GetTag retrieves a tag by ID.
 func (r *Repository) GetTag(id string) (*models.Tag, error) {
-	query := `SELECT id, name, color, is_deleted, created_at, updated_at FROM tags WHERE id = ?`
-	var tag models.Tag
-	err := r.db.QueryRow(query, id).Scan(&tag.ID, &tag.Name, &tag.Color,
-		&tag.IsDeleted, &tag.CreatedAt, &tag.UpdatedAt)
-	if err != nil {
-		return nil, err
-	}
-	return &tag, nil
+	query := `SELECT ` + tagColumns + ` FROM tags WHERE id = ?`
+	return scanTag(r.db.QueryRow(query, id))
 }
 
 // ListTags returns all tags.
 func (r *Repository) ListTags() ([]*models.Tag, error) {
-	query := `SELECT id, name, color, is_deleted, created_at, updated_at FROM tags WHERE is_deleted = 0 ORDER BY name`
+	query := `SELECT ` + tagColumns + ` FROM tags WHERE is_deleted = 0 ORDER BY name`
 	rows, err := r.db.Query(query)
 	if err != nil {
 		return nil, err
@@ -269,49 +524,82 @@ func (r *Repository) ListTags() ([]*models.Tag, error) {
 
 	var tags []*models.Tag
 	for rows.Next() {
-		var tag models.Tag
-		err := rows.Scan(&tag.ID, &tag.Name, &tag.Color, &tag.IsDeleted,
-			&tag.CreatedAt, &tag.UpdatedAt)
+		tag, err := scanTag(rows)
 		if err != nil {
 			return nil, err
 		}
-		tags = append(tags, &tag)
+		tags = append(tags, tag)
 	}
 	return tags, nil
 }
 
-// UpdateTag updates an existing tag.
+// UpdateTag updates an existing tag. A new name renames the tag and its
+// descendants and a new ParentID moves them under that tag (or to the top
+// level if empty), rewriting the tags of every affected content item in the
+// same transaction (see renameTag), so either all changes land or none do.
 func (r *Repository) UpdateTag(tag *models.Tag) error {
-	tag.UpdatedAt = time.Now().Unix()
-	query := `UPDATE tags SET name = ?, color = ?, updated_at = ? WHERE id = ? AND is_deleted = 0`
-	result, err := r.db.Exec(query, tag.Name, tag.Color, tag.UpdatedAt, tag.ID)
+	tx, err := r.db.Begin()
 	if err != nil {
-		return err
+		return fmt.Errorf("failed to begin transaction: %w", err)
 	}
-	rows, err := result.RowsAffected()
+	defer tx.Rollback()
+
+	current, err := scanTag(tx.QueryRow(`SELECT `+tagColumns+` FROM tags WHERE id = ? AND is_deleted = 0`, tag.ID))
 	if err != nil {
 		return err
 	}
-	if rows == 0 {
-		return sql.ErrNoRows
+
+	now := time.Now().Unix()
+	if name := normalizeTagPath(tag.Name); name != current.Name || tag.ParentID != current.ParentID {
+		if err := renameTag(tx, current, name, tag.ParentID, now); err != nil {
+			return err
+		}
+	}
+
+	query := `UPDATE tags SET color = ?, updated_at = ? WHERE id = ?`
+	if _, err := tx.Exec(query, tag.Color, now, tag.ID); err != nil {
+		return err
+	}
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("failed to commit transaction: %w", err)
 	}
+
+	tag.Name = current.Name
+	tag.ParentID = current.ParentID
+	tag.UpdatedAt = now
 	return nil
 }
 
-// DeleteTag soft deletes a tag.
+// DeleteTag soft deletes a tag and unassigns it from every content item in
+// one transaction. Items that carried the tag get new tag strings, versions
+// and change_log entries, so the removal reaches search and sync and the
+// tag is not restored by the next save of one of them. Child tags are kept.
 func (r *Repository) DeleteTag(id string) error {
-	query := `UPDATE tags SET is_deleted = 1, updated_at = ? WHERE id = ? AND is_deleted = 0`
+	tx, err := r.db.Begin()
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+
 	now := time.Now().Unix()
-	result, err := r.db.Exec(query, now, id)
+	err = execAffectingRow(tx.Exec(`UPDATE tags SET is_deleted = 1, updated_at = ? WHERE id = ? AND is_deleted = 0`, now, id))
 	if err != nil {
 		return err
 	}
-	rows, err := result.RowsAffected()
+
+	// Tag strings are rebuilt from live tags, so they drop the deleted tag
+	// while its links still select the items
+	err = refreshContentTagStrings(tx, `SELECT DISTINCT content_id FROM content_tags WHERE tag_id = ?`,
+		[]interface{}{id}, now)
 	if err != nil {
 		return err
 	}
-	if rows == 0 {
-		return sql.ErrNoRows
+	if _, err := tx.Exec(`DELETE FROM content_tags WHERE tag_id = ?`, id); err != nil {
+		return fmt.Errorf("failed to unassign tag: %w", err)
+	}
+
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("failed to commit transaction: %w", err)
 	}
 	return nil
 }
@@ -321,7 +609,13 @@ func (r *Repository) DeleteTag(id string) error {
 // =====================================================
 
 // CreateChangeLog creates a new change log entry.
+// Content mutations log themselves; this is for entries not tied to one.
 func (r *Repository) CreateChangeLog(log *models.ChangeLog) error {
+	return r.CreateChangeLogContext(context.Background(), log)
+}
+
+// CreateChangeLogContext is CreateChangeLog with a context.
+func (r *Repository) CreateChangeLogContext(ctx context.Context, log *models.ChangeLog) error {
 	log.ID = models.UUID(uuid.New())
 	log.Timestamp = time.Now().Unix()
 
@@ -329,7 +623,17 @@ func (r *Repository) CreateChangeLog(log *models.ChangeLog) error {
 	INSERT INTO change_log (id, item_id, operation, version, timestamp)
 	VALUES (?, ?, ?, ?, ?)
 	`
-	_, err := r.db.Exec(query, log.ID, log.ItemID, log.Operation, log.Version, log.Timestamp)
+	_, err := r.db.ExecContext(ctx, query, log.ID, log.ItemID, log.Operation, log.Version, log.Timestamp)
+	return err
+}
+
+// insertChangeLog records a mutation of a content item inside tx.
+func insertChangeLog(ctx context.Context, tx *sql.Tx, itemID, operation string, version int, timestamp int64) error {
+	query := `
+	INSERT INTO change_log (id, item_id, operation, version, timestamp)
+	VALUES (?, ?, ?, ?, ?)
+	`
+	_, err := tx.ExecContext(ctx, query, models.UUID(uuid.New()), itemID, operation, version, timestamp)
 	return err
 }
 
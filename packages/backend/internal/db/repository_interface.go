@@ -2,6 +2,9 @@
 package db
 
 import (
+	"context"
+	"database/sql"
+
 	"github.com/kimhsiao/memonexus/backend/internal/models"
 )
 
@@ -24,6 +27,29 @@ type ContentItemRepository interface {
 	DeleteContentItem(id string) error
 }
 
+// ContentItemContextRepository defines context-aware content item
+// operations. Each mutation writes its change_log entry in the same
+// transaction, so sync sees every committed change.
+type ContentItemContextRepository interface {
+	// WithTx runs fn in a transaction, committing if it returns nil.
+	WithTx(ctx context.Context, fn func(tx *sql.Tx) error) error
+
+	// CreateContentItemContext creates a new content item.
+	CreateContentItemContext(ctx context.Context, item *models.ContentItem) error
+
+	// GetContentItemContext retrieves a content item by ID.
+	GetContentItemContext(ctx context.Context, id string) (*models.ContentItem, error)
+
+	// ListContentItemsContext returns content items with pagination and filters.
+	ListContentItemsContext(ctx context.Context, limit, offset int, mediaType string) ([]*models.ContentItem, error)
+
+	// UpdateContentItemContext updates an existing content item.
+	UpdateContentItemContext(ctx context.Context, item *models.ContentItem) error
+
+	// DeleteContentItemContext soft deletes a content item.
+	DeleteContentItemContext(ctx context.Context, id string) error
+}
+
 // ChangeLogRepository defines operations for change log persistence.
 type ChangeLogRepository interface {
 	// CreateChangeLog creates a new change log entry.
@@ -36,6 +62,67 @@ type ConflictLogRepository interface {
 	CreateConflictLog(log *models.ConflictLog) error
 }
 
+// SavedSearchSyncRepository defines the saved search operations used by sync.
+// Sync repositories that also implement it sync saved searches alongside
+// content items.
+type SavedSearchSyncRepository interface {
+	// ListSavedSearchesForSync returns all saved searches, including deleted ones.
+	ListSavedSearchesForSync() ([]*models.SavedSearch, error)
+
+	// ApplyRemoteSavedSearch stores a remote saved search if it is newer.
+	ApplyRemoteSavedSearch(ss *models.SavedSearch) (bool, error)
+}
+
+// SearchHistorySyncRepository defines the search history operations used by
+// sync. History is only exchanged when SearchHistorySyncEnabled is true.
+type SearchHistorySyncRepository interface {
+	// SearchHistorySyncEnabled reports whether the user opted in to syncing history.
+	SearchHistorySyncEnabled() (bool, error)
+
+	// ListSearchHistoryForSync returns all history entries, including deleted ones.
+	ListSearchHistoryForSync() ([]*models.SearchHistoryEntry, error)
+
+	// ApplyRemoteSearchHistory stores a remote history entry if it is newer.
+	ApplyRemoteSearchHistory(entry *models.SearchHistoryEntry) (bool, error)
+}
+
+// CollectionSyncRepository defines the collection operations used by sync.
+// Collections sync with their complete membership.
+type CollectionSyncRepository interface {
+	// ListCollectionsForSync returns all collections, including deleted ones.
+	ListCollectionsForSync() ([]*models.Collection, error)
+
+	// ApplyRemoteCollection stores a remote collection if it is newer.
+	ApplyRemoteCollection(c *models.Collection) (bool, error)
+}
+
+// AnnotationSyncRepository defines the annotation operations used by sync
+// and by export archives.
+type AnnotationSyncRepository interface {
+	// ListAnnotationsForSync returns all annotations, including deleted ones.
+	ListAnnotationsForSync() ([]*models.Annotation, error)
+
+	// ApplyRemoteAnnotation stores a remote annotation if it is newer.
+	ApplyRemoteAnnotation(a *models.Annotation) (bool, error)
+}
+
+// ContentPropertySyncRepository defines the content property operations
+// used by sync.
+type ContentPropertySyncRepository interface {
+	// ListContentPropertiesForSync returns all properties, including deleted ones.
+	ListContentPropertiesForSync() ([]*models.ContentProperty, error)
+
+	// ApplyRemoteContentProperty stores a remote property if it is newer.
+	ApplyRemoteContentProperty(p *models.ContentProperty) (bool, error)
+}
+
+// ContentStateSyncRepository defines the triage state operations used by
+// sync.
+type ContentStateSyncRepository interface {
+	// ApplyRemoteContentState merges remote triage states by change time.
+	ApplyRemoteContentState(item *models.ContentItem) ([]string, error)
+}
+
 // SyncRepository combines repositories needed for sync operations.
 // This is a marker interface that groups related repositories for convenience.
 type SyncRepository interface {
@@ -46,8 +133,16 @@ type SyncRepository interface {
 
 // Ensure *Repository implements the interfaces at compile time.
 var (
-	_ ContentItemRepository = (*Repository)(nil)
-	_ ChangeLogRepository   = (*Repository)(nil)
-	_ ConflictLogRepository = (*Repository)(nil)
-	_ SyncRepository        = (*Repository)(nil)
+	_ ContentItemRepository        = (*Repository)(nil)
+	_ ContentItemContextRepository = (*Repository)(nil)
+	_ ChangeLogRepository          = (*Repository)(nil)
+	_ ConflictLogRepository        = (*Repository)(nil)
+	_ SyncRepository               = (*Repository)(nil)
+
+	_ SavedSearchSyncRepository     = (*Repository)(nil)
+	_ SearchHistorySyncRepository   = (*Repository)(nil)
+	_ CollectionSyncRepository      = (*Repository)(nil)
+	_ AnnotationSyncRepository      = (*Repository)(nil)
+	_ ContentPropertySyncRepository = (*Repository)(nil)
+	_ ContentStateSyncRepository    = (*Repository)(nil)
 )
@@ -2,8 +2,12 @@
 package db
 
 import (
+	"context"
 	"database/sql"
 	"encoding/json"
+	"errors"
+	"fmt"
+	"reflect"
 	"testing"
 	"time"
 
@@ -34,7 +38,16 @@ func setupTestDB(t *testing.T) *sql.DB {
 			created_at INTEGER NOT NULL,
 			updated_at INTEGER NOT NULL,
 			version INTEGER NOT NULL DEFAULT 1,
-			content_hash TEXT
+			content_hash TEXT,
+			is_pinned INTEGER NOT NULL DEFAULT 0 CHECK(is_pinned IN (0, 1)),
+			is_favorite INTEGER NOT NULL DEFAULT 0 CHECK(is_favorite IN (0, 1)),
+			read_status TEXT NOT NULL DEFAULT 'unread' CHECK(read_status IN ('unread', 'in_progress', 'read')),
+			read_progress REAL NOT NULL DEFAULT 0 CHECK(read_progress >= 0 AND read_progress <= 1),
+			is_archived INTEGER NOT NULL DEFAULT 0 CHECK(is_archived IN (0, 1)),
+			favorite_changed_at INTEGER NOT NULL DEFAULT 0,
+			pinned_changed_at INTEGER NOT NULL DEFAULT 0,
+			read_changed_at INTEGER NOT NULL DEFAULT 0,
+			archived_changed_at INTEGER NOT NULL DEFAULT 0
 		);
 
 		CREATE TABLE tags (
@@ -43,7 +56,40 @@ func setupTestDB(t *testing.T) *sql.DB {
 			color TEXT DEFAULT '#3B82F6',
 			is_deleted INTEGER NOT NULL DEFAULT 0,
 			created_at INTEGER NOT NULL,
-			updated_at INTEGER NOT NULL
+			updated_at INTEGER NOT NULL,
+			parent_id TEXT
+		);
+
+		CREATE TABLE content_tags (
+			content_id TEXT NOT NULL,
+			tag_id TEXT NOT NULL,
+			assigned_at INTEGER NOT NULL,
+			PRIMARY KEY (content_id, tag_id)
+		);
+
+		CREATE TABLE content_revisions (
+			id TEXT PRIMARY KEY,
+			content_id TEXT NOT NULL,
+			version INTEGER NOT NULL,
+			title TEXT NOT NULL,
+			content_text TEXT NOT NULL,
+			source_url TEXT,
+			media_type TEXT NOT NULL,
+			tags TEXT NOT NULL DEFAULT '',
+			summary TEXT,
+			content_hash TEXT,
+			edited_at INTEGER NOT NULL,
+			created_at INTEGER NOT NULL
+		);
+
+		CREATE TABLE content_links (
+			source_id TEXT NOT NULL,
+			kind TEXT NOT NULL,
+			target TEXT NOT NULL,
+			target_id TEXT,
+			position INTEGER NOT NULL DEFAULT 0,
+			created_at INTEGER NOT NULL,
+			PRIMARY KEY (source_id, kind, target)
 		);
 
 		CREATE TABLE change_log (
@@ -345,6 +391,89 @@ func TestListContentItemsWithPagination(t *testing.T) {
 	}
 }
 
+func TestListContentItemsPage(t *testing.T) {
+	db := setupTestDB(t)
+	defer db.Close()
+	repo := NewRepository(db)
+
+	// Items created in the same second are ordered by id
+	for i := 0; i < 25; i++ {
+		item := &models.ContentItem{
+			Title:       "Article",
+			ContentText: "Content",
+			MediaType:   "web",
+		}
+		if err := repo.CreateContentItem(item); err != nil {
+			t.Fatalf("CreateContentItem failed: %v", err)
+		}
+	}
+
+	seen := make(map[models.UUID]bool)
+	cursor := ""
+	pages := 0
+	for {
+		page, err := repo.ListContentItemsPage(cursor, 10, "")
+		if err != nil {
+			t.Fatalf("ListContentItemsPage failed: %v", err)
+		}
+		pages++
+		for _, item := range page.Items {
+			if seen[item.ID] {
+				t.Errorf("Item %s returned twice", item.ID)
+			}
+			seen[item.ID] = true
+		}
+
+		// Items added mid-scroll are newer than the cursor and not returned
+		if pages == 1 {
+			late := &models.ContentItem{Title: "Late", ContentText: "Content", MediaType: "web"}
+			if err := repo.CreateContentItem(late); err != nil {
+				t.Fatalf("CreateContentItem failed: %v", err)
+			}
+			db.Exec(`UPDATE content_items SET created_at = created_at + 60 WHERE id = ?`, late.ID)
+		}
+
+		if page.NextCursor == "" {
+			break
+		}
+		cursor = page.NextCursor
+	}
+
+	if pages != 3 {
+		t.Errorf("Expected 3 pages, got %d", pages)
+	}
+	if len(seen) != 25 {
+		t.Errorf("Expected 25 distinct items, got %d", len(seen))
+	}
+
+	// The offset listing uses the same order
+	offsetItems, err := repo.ListContentItems(10, 0, "")
+	if err != nil {
+		t.Fatalf("ListContentItems failed: %v", err)
+	}
+	firstPage, err := repo.ListContentItemsPage("", 10, "")
+	if err != nil {
+		t.Fatalf("ListContentItemsPage failed: %v", err)
+	}
+	for i := range offsetItems {
+		if offsetItems[i].ID != firstPage.Items[i].ID {
+			t.Fatalf("Offset and cursor listings differ at %d", i)
+		}
+	}
+}
+
+func TestListContentItemsPage_invalidCursor(t *testing.T) {
+	db := setupTestDB(t)
+	defer db.Close()
+	repo := NewRepository(db)
+
+	for _, cursor := range []string{"not-a-cursor", searchCursor(-1.5, 3, 0)} {
+		if _, err := repo.ListContentItemsPage(cursor, 10, ""); !errors.Is(err, ErrInvalidCursor) {
+			t.Errorf("ListContentItemsPage(%q) error = %v, want ErrInvalidCursor", cursor, err)
+		}
+	}
+}
+
 // =====================================================
 // Tag Repository Tests (T084)
 // =====================================================
@@ -549,6 +678,94 @@ func TestCreateChangeLog(t *testing.T) {
 	}
 }
 
+// TestContentMutations_changeLog verifies every content mutation logs its
+// new version to change_log.
+func TestContentMutations_changeLog(t *testing.T) {
+	db := setupMigratedTestDB(t)
+	defer db.Close()
+	repo := NewRepository(db)
+	ctx := context.Background()
+
+	item := &models.ContentItem{Title: "Logged", ContentText: "body", MediaType: "web"}
+	if err := repo.CreateContentItemContext(ctx, item); err != nil {
+		t.Fatalf("CreateContentItemContext failed: %v", err)
+	}
+	item.Title = "Edited"
+	if err := repo.UpdateContentItemContext(ctx, item); err != nil {
+		t.Fatalf("UpdateContentItemContext failed: %v", err)
+	}
+	if _, err := repo.AssignTags(string(item.ID), []string{"go"}); err != nil {
+		t.Fatalf("AssignTags failed: %v", err)
+	}
+	if err := repo.DeleteContentItemContext(ctx, string(item.ID)); err != nil {
+		t.Fatalf("DeleteContentItemContext failed: %v", err)
+	}
+
+	rows, err := db.Query(`SELECT operation, version FROM change_log WHERE item_id = ? ORDER BY version`, item.ID)
+	if err != nil {
+		t.Fatalf("Failed to query change_log: %v", err)
+	}
+	defer rows.Close()
+	var got []string
+	for rows.Next() {
+		var op string
+		var version int
+		rows.Scan(&op, &version)
+		got = append(got, fmt.Sprintf("%s@%d", op, version))
+	}
+	want := []string{"create@1", "update@2", "update@3", "delete@4"}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("change_log = %v, want %v", got, want)
+	}
+}
+
+// TestWithTx verifies WithTx commits on success and rolls back on error or
+// a cancelled context.
+func TestWithTx(t *testing.T) {
+	db := setupMigratedTestDB(t)
+	defer db.Close()
+	repo := NewRepository(db)
+
+	errAbort := errors.New("abort")
+	err := repo.WithTx(context.Background(), func(tx *sql.Tx) error {
+		if _, err := tx.Exec(`INSERT INTO app_settings (key, value, updated_at) VALUES ('a', '1', 1)`); err != nil {
+			return err
+		}
+		return errAbort
+	})
+	if !errors.Is(err, errAbort) {
+		t.Errorf("WithTx = %v, want errAbort", err)
+	}
+
+	err = repo.WithTx(context.Background(), func(tx *sql.Tx) error {
+		_, err := tx.Exec(`INSERT INTO app_settings (key, value, updated_at) VALUES ('b', '1', 1)`)
+		return err
+	})
+	if err != nil {
+		t.Fatalf("WithTx failed: %v", err)
+	}
+
+	var keys int
+	db.QueryRow(`SELECT COUNT(*) FROM app_settings WHERE key IN ('a', 'b')`).Scan(&keys)
+	if keys != 1 {
+		t.Errorf("Committed %d settings, want only the successful one", keys)
+	}
+
+	// A cancelled context writes neither the item nor its change_log entry
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	item := &models.ContentItem{Title: "Cancelled", MediaType: "web"}
+	if err := repo.CreateContentItemContext(ctx, item); err == nil {
+		t.Error("CreateContentItemContext with cancelled context should fail")
+	}
+	var items, logs int
+	db.QueryRow(`SELECT COUNT(*) FROM content_items`).Scan(&items)
+	db.QueryRow(`SELECT COUNT(*) FROM change_log`).Scan(&logs)
+	if items != 0 || logs != 0 {
+		t.Errorf("Cancelled create left %d items and %d change_log entries", items, logs)
+	}
+}
+
 // =====================================================
 // ConflictLog Repository Tests
 // =====================================================
@@ -0,0 +1,221 @@
+// Package db provides revision history for content items.
+package db
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+
+	"github.com/kimhsiao/memonexus/backend/internal/models"
+	"github.com/kimhsiao/memonexus/backend/internal/uuid"
+)
+
+// maxRevisionsPerItem is how many revisions are kept per item; older
+// revisions are removed as new ones are recorded.
+const maxRevisionsPerItem = 50
+
+// maxRevisionAge is how long, in seconds, revisions are kept.
+const maxRevisionAge = 90 * 24 * 60 * 60
+
+// CurrentRevision names the item's current state in DiffRevisions.
+const CurrentRevision = "current"
+
+// revisionColumns lists the content_revisions columns read by scanRevision.
+const revisionColumns = `id, content_id, version, title, content_text, source_url, media_type,
+	tags, summary, content_hash, edited_at, created_at`
+
+// scanRevision scans one row selected with revisionColumns.
+func scanRevision(row rowScanner) (*models.ContentRevision, error) {
+	var rev models.ContentRevision
+	var sourceURL, summary, contentHash sql.NullString
+	err := row.Scan(&rev.ID, &rev.ContentID, &rev.Version, &rev.Title, &rev.ContentText,
+		&sourceURL, &rev.MediaType, &rev.Tags, &summary, &contentHash, &rev.EditedAt, &rev.CreatedAt)
+	if err != nil {
+		return nil, err
+	}
+	rev.SourceURL = sourceURL.String
+	rev.Summary = summary.String
+	rev.ContentHash = contentHash.String
+	return &rev, nil
+}
+
+// snapshotContentItem records the stored state of item as a revision if the
+// update to item changes it, then trims the item's revisions to the
+// retention limits.
+func snapshotContentItem(ctx context.Context, tx *sql.Tx, item *models.ContentItem) error {
+	_, err := tx.ExecContext(ctx, `
+	INSERT INTO content_revisions (id, content_id, version, title, content_text, source_url,
+		media_type, tags, summary, content_hash, edited_at, created_at)
+	SELECT ?, id, version, title, content_text, source_url, media_type, tags, summary,
+		content_hash, updated_at, ?
+	FROM content_items
+	WHERE id = ? AND is_deleted = 0
+	  AND (title != ? OR content_text != ? OR COALESCE(source_url, '') != ?
+		OR media_type != ? OR tags != ? OR COALESCE(summary, '') != ?
+		OR COALESCE(content_hash, '') != ?)
+	`, models.UUID(uuid.New()), item.UpdatedAt, item.ID,
+		item.Title, item.ContentText, item.SourceURL, item.MediaType, item.Tags,
+		item.Summary, item.ContentHash)
+	if err != nil {
+		return fmt.Errorf("failed to record revision: %w", err)
+	}
+
+	_, err = tx.ExecContext(ctx, `
+	DELETE FROM content_revisions
+	WHERE content_id = ? AND (created_at < ? OR id NOT IN (
+		SELECT id FROM content_revisions WHERE content_id = ?
+		ORDER BY created_at DESC, version DESC LIMIT ?
+	))
+	`, item.ID, item.UpdatedAt-maxRevisionAge, item.ID, maxRevisionsPerItem)
+	if err != nil {
+		return fmt.Errorf("failed to trim revisions: %w", err)
+	}
+	return nil
+}
+
+// ListRevisions returns the revisions of a content item, newest first.
+// Returns sql.ErrNoRows if the item does not exist.
+func (r *Repository) ListRevisions(contentID string) ([]*models.ContentRevision, error) {
+	var exists int
+	err := r.db.QueryRow(`SELECT 1 FROM content_items WHERE id = ? AND is_deleted = 0`, contentID).Scan(&exists)
+	if err != nil {
+		return nil, err
+	}
+
+	rows, err := r.db.Query(`
+	SELECT `+revisionColumns+`
+	FROM content_revisions WHERE content_id = ?
+	ORDER BY created_at DESC, version DESC
+	`, contentID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	revisions := make([]*models.ContentRevision, 0)
+	for rows.Next() {
+		rev, err := scanRevision(rows)
+		if err != nil {
+			return nil, err
+		}
+		revisions = append(revisions, rev)
+	}
+	return revisions, rows.Err()
+}
+
+// GetRevision returns a revision of a content item. Returns sql.ErrNoRows if
+// the item has no such revision.
+func (r *Repository) GetRevision(contentID, revisionID string) (*models.ContentRevision, error) {
+	return scanRevision(r.db.QueryRow(`
+	SELECT `+revisionColumns+`
+	FROM content_revisions WHERE id = ? AND content_id = ?
+	`, revisionID, contentID))
+}
+
+// DiffRevisions compares two revisions of a content item. Either revision
+// may be CurrentRevision for the item's current state. Returns sql.ErrNoRows
+// if the item or a revision does not exist.
+func (r *Repository) DiffRevisions(contentID, fromID, toID string) (*models.RevisionDiff, error) {
+	from, err := r.revisionState(contentID, fromID)
+	if err != nil {
+		return nil, err
+	}
+	to, err := r.revisionState(contentID, toID)
+	if err != nil {
+		return nil, err
+	}
+	return diffRevisions(from, to), nil
+}
+
+// revisionState returns a revision, or the current item state as a revision
+// for CurrentRevision.
+func (r *Repository) revisionState(contentID, revisionID string) (*models.ContentRevision, error) {
+	if revisionID != CurrentRevision {
+		return r.GetRevision(contentID, revisionID)
+	}
+	item, err := r.GetContentItem(contentID)
+	if err != nil {
+		return nil, err
+	}
+	return &models.ContentRevision{
+		ContentID:   item.ID,
+		Version:     item.Version,
+		Title:       item.Title,
+		ContentText: item.ContentText,
+		SourceURL:   item.SourceURL,
+		MediaType:   item.MediaType,
+		Tags:        item.Tags,
+		Summary:     item.Summary,
+		ContentHash: item.ContentHash,
+		EditedAt:    item.UpdatedAt,
+	}, nil
+}
+
+// RestoreRevision writes a revision's state as a new version of the item and
+// returns the item. The replaced state becomes a revision itself, and the
+// new version is logged for sync. Returns sql.ErrNoRows if the item or
+// revision does not exist.
+func (r *Repository) RestoreRevision(contentID, revisionID string) (*models.ContentItem, error) {
+	return r.RestoreRevisionContext(context.Background(), contentID, revisionID)
+}
+
+// RestoreRevisionContext is RestoreRevision with a context. The revision
+// and item are read and the item written in one transaction.
+func (r *Repository) RestoreRevisionContext(ctx context.Context, contentID, revisionID string) (*models.ContentItem, error) {
+	var item *models.ContentItem
+	err := r.WithTx(ctx, func(tx *sql.Tx) error {
+		rev, err := scanRevision(tx.QueryRowContext(ctx, `
+		SELECT `+revisionColumns+`
+		FROM content_revisions WHERE id = ? AND content_id = ?
+		`, revisionID, contentID))
+		if err != nil {
+			return err
+		}
+		item, err = scanContentItem(tx.QueryRowContext(ctx, `
+		SELECT `+contentItemColumns+`
+		FROM content_items WHERE id = ? AND is_deleted = 0
+		`, contentID))
+		if err != nil {
+			return err
+		}
+
+		item.Title = rev.Title
+		item.ContentText = rev.ContentText
+		item.SourceURL = rev.SourceURL
+		item.MediaType = rev.MediaType
+		item.Tags = rev.Tags
+		item.Summary = rev.Summary
+		item.ContentHash = rev.ContentHash
+		item.Touch()
+		return updateContentItem(ctx, tx, item)
+	})
+	if err != nil {
+		return nil, err
+	}
+	return item, nil
+}
+
+// diffRevisions compares the fields of two item states and diffs their
+// content_text by line.
+func diffRevisions(from, to *models.ContentRevision) *models.RevisionDiff {
+	diff := &models.RevisionDiff{
+		FromVersion: from.Version,
+		ToVersion:   to.Version,
+		Fields:      make([]models.FieldChange, 0),
+	}
+	for _, f := range []struct{ field, from, to string }{
+		{"title", from.Title, to.Title},
+		{"source_url", from.SourceURL, to.SourceURL},
+		{"media_type", from.MediaType, to.MediaType},
+		{"tags", from.Tags, to.Tags},
+		{"summary", from.Summary, to.Summary},
+		{"content_hash", from.ContentHash, to.ContentHash},
+	} {
+		if f.from != f.to {
+			diff.Fields = append(diff.Fields, models.FieldChange{Field: f.field, From: f.from, To: f.to})
+		}
+	}
+	diff.Lines = diffLines(from.ContentText, to.ContentText)
+	diff.Unchanged = len(diff.Fields) == 0 && from.ContentText == to.ContentText
+	return diff
+}
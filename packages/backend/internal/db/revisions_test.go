@@ -0,0 +1,153 @@
+// Package db tests for content revision history.
+package db
+
+import (
+	"context"
+	"database/sql"
+	"reflect"
+	"testing"
+
+	"github.com/kimhsiao/memonexus/backend/internal/models"
+)
+
+// TestRevisions verifies updates record the replaced state and a revision
+// can be diffed and restored as a new version.
+func TestRevisions(t *testing.T) {
+	db := setupMigratedTestDB(t)
+	defer db.Close()
+
+	repo := NewRepository(db)
+	item := createTaggedItems(t, repo, "go")[0]
+	item.ContentText = "one\ntwo\nthree"
+	if err := repo.UpdateContentItem(item); err != nil {
+		t.Fatalf("UpdateContentItem failed: %v", err)
+	}
+	item.Title = "Renamed"
+	item.ContentText = "one\n2\nthree\nfour"
+	if err := repo.UpdateContentItem(item); err != nil {
+		t.Fatalf("UpdateContentItem failed: %v", err)
+	}
+	// Saving an unchanged item records nothing
+	if err := repo.UpdateContentItem(item); err != nil {
+		t.Fatalf("UpdateContentItem failed: %v", err)
+	}
+
+	revisions, err := repo.ListRevisions(string(item.ID))
+	if err != nil {
+		t.Fatalf("ListRevisions failed: %v", err)
+	}
+	if len(revisions) != 2 || revisions[0].Version != 2 || revisions[1].Version != 1 {
+		t.Fatalf("ListRevisions = %+v, want versions 2 and 1", revisions)
+	}
+	if revisions[1].ContentText != "body" || revisions[0].ContentText != "one\ntwo\nthree" {
+		t.Errorf("Unexpected revision contents: %q, %q", revisions[1].ContentText, revisions[0].ContentText)
+	}
+
+	diff, err := repo.DiffRevisions(string(item.ID), string(revisions[0].ID), CurrentRevision)
+	if err != nil {
+		t.Fatalf("DiffRevisions failed: %v", err)
+	}
+	wantLines := []models.DiffLine{
+		{Op: models.DiffEqual, Text: "one"},
+		{Op: models.DiffDelete, Text: "two"},
+		{Op: models.DiffInsert, Text: "2"},
+		{Op: models.DiffEqual, Text: "three"},
+		{Op: models.DiffInsert, Text: "four"},
+	}
+	if diff.FromVersion != 2 || diff.ToVersion != 4 || !reflect.DeepEqual(diff.Lines, wantLines) {
+		t.Errorf("DiffRevisions = %+v", diff)
+	}
+	if len(diff.Fields) != 1 || diff.Fields[0] != (models.FieldChange{Field: "title", From: "Item", To: "Renamed"}) {
+		t.Errorf("Diff fields = %+v, want title change", diff.Fields)
+	}
+	if _, err := repo.DiffRevisions(string(item.ID), "missing", CurrentRevision); err != sql.ErrNoRows {
+		t.Errorf("Diff of unknown revision = %v, want sql.ErrNoRows", err)
+	}
+
+	// A cancelled restore writes nothing
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	if _, err := repo.RestoreRevisionContext(ctx, string(item.ID), string(revisions[1].ID)); err == nil {
+		t.Error("RestoreRevisionContext with a cancelled context succeeded")
+	}
+	if _, err := repo.RestoreRevision(string(item.ID), "missing"); err != sql.ErrNoRows {
+		t.Errorf("Restore of unknown revision = %v, want sql.ErrNoRows", err)
+	}
+
+	restored, err := repo.RestoreRevision(string(item.ID), string(revisions[1].ID))
+	if err != nil {
+		t.Fatalf("RestoreRevision failed: %v", err)
+	}
+	if restored.Title != "Item" || restored.ContentText != "body" || restored.Version != 5 {
+		t.Errorf("Restored item = %+v", restored)
+	}
+	var logged int
+	db.QueryRow(`SELECT COUNT(*) FROM change_log WHERE item_id = ? AND version = 5`, item.ID).Scan(&logged)
+	if logged != 1 {
+		t.Errorf("change_log entries for restore = %d, want 1", logged)
+	}
+	// The state replaced by the restore is itself a revision
+	if revisions, _ := repo.ListRevisions(string(item.ID)); len(revisions) != 3 || revisions[0].Title != "Renamed" {
+		t.Errorf("Revisions after restore = %+v", revisions)
+	}
+
+	if _, err := repo.ListRevisions("00000000-0000-4000-8000-000000000000"); err != sql.ErrNoRows {
+		t.Errorf("ListRevisions of unknown item = %v, want sql.ErrNoRows", err)
+	}
+}
+
+// TestRevisions_retention verifies revisions beyond the per-item cap and
+// older than the maximum age are removed.
+func TestRevisions_retention(t *testing.T) {
+	db := setupMigratedTestDB(t)
+	defer db.Close()
+
+	repo := NewRepository(db)
+	item := createTaggedItems(t, repo, "")[0]
+	for i := 0; i < maxRevisionsPerItem+5; i++ {
+		item.ContentText = string(rune('a'+i%26)) + item.ContentText
+		if err := repo.UpdateContentItem(item); err != nil {
+			t.Fatalf("UpdateContentItem failed: %v", err)
+		}
+	}
+	revisions, _ := repo.ListRevisions(string(item.ID))
+	if len(revisions) != maxRevisionsPerItem || revisions[len(revisions)-1].Version != 6 {
+		t.Fatalf("Kept %d revisions down to v%d, want %d down to v6",
+			len(revisions), revisions[len(revisions)-1].Version, maxRevisionsPerItem)
+	}
+
+	if _, err := db.Exec(`UPDATE content_revisions SET created_at = created_at - ? WHERE version < 50`,
+		maxRevisionAge+1); err != nil {
+		t.Fatalf("Failed to age revisions: %v", err)
+	}
+	item.ContentText = "final"
+	if err := repo.UpdateContentItem(item); err != nil {
+		t.Fatalf("UpdateContentItem failed: %v", err)
+	}
+	if revisions, _ := repo.ListRevisions(string(item.ID)); len(revisions) != 7 {
+		t.Errorf("Kept %d revisions after aging, want 7", len(revisions))
+	}
+}
+
+func TestDiffLines(t *testing.T) {
+	tests := []struct {
+		from, to string
+		want     []string
+	}{
+		{"", "", []string{}},
+		{"", "a\nb", []string{"+a", "+b"}},
+		{"a\nb\n", "", []string{"-a", "-b"}},
+		{"a\nb\nc", "a\nc", []string{" a", "-b", " c"}},
+		{"a\nx\nb\ny\nc", "a\nb\nz\nc", []string{" a", "-x", " b", "-y", "+z", " c"}},
+	}
+	ops := map[string]string{models.DiffEqual: " ", models.DiffInsert: "+", models.DiffDelete: "-"}
+	for _, tt := range tests {
+		got := []string{}
+		for _, line := range diffLines(tt.from, tt.to) {
+			got = append(got, ops[line.Op]+line.Text)
+		}
+		if !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("diffLines(%q, %q) = %v, want %v", tt.from, tt.to, got, tt.want)
+		}
+	}
+}
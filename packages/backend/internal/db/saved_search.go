@@ -0,0 +1,243 @@
+// Package db provides saved search (smart collection) persistence.
+package db
+
+import (
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/kimhsiao/memonexus/backend/internal/models"
+	"github.com/kimhsiao/memonexus/backend/internal/uuid"
+)
+
+// savedSearchColumns lists the saved_searches columns read by scanSavedSearch.
+const savedSearchColumns = `id, name, query, filters, is_pinned, position, is_deleted,
+	created_at, updated_at, version`
+
+// rowScanner is implemented by *sql.Row and *sql.Rows.
+type rowScanner interface {
+	Scan(dest ...interface{}) error
+}
+
+// scanSavedSearch scans one row selected with savedSearchColumns.
+func scanSavedSearch(row rowScanner) (*models.SavedSearch, error) {
+	var ss models.SavedSearch
+	var filters string
+	err := row.Scan(&ss.ID, &ss.Name, &ss.Query, &filters, &ss.IsPinned, &ss.Position,
+		&ss.IsDeleted, &ss.CreatedAt, &ss.UpdatedAt, &ss.Version)
+	if err != nil {
+		return nil, err
+	}
+	if err := json.Unmarshal([]byte(filters), &ss.Filters); err != nil {
+		return nil, fmt.Errorf("invalid filters for saved search %s: %w", ss.ID, err)
+	}
+	return &ss, nil
+}
+
+// ValidateSavedSearch checks that a saved search has a name and a runnable
+// query or at least one filter.
+func ValidateSavedSearch(ss *models.SavedSearch) error {
+	if ss.Name == "" {
+		return fmt.Errorf("name is required")
+	}
+	if len([]rune(ss.Name)) > 100 {
+		return fmt.Errorf("name must be 100 characters or less")
+	}
+	if ss.Query == "" && ss.Filters.IsEmpty() {
+		return fmt.Errorf("query or filters are required")
+	}
+	if ss.Filters.MediaType != "" {
+		if !(&MediaTypeFilter{MediaType: ss.Filters.MediaType}).Valid() {
+			return fmt.Errorf("invalid media_type: %s", ss.Filters.MediaType)
+		}
+	}
+	if ss.Filters.WithinDays < 0 {
+		return fmt.Errorf("within_days must not be negative")
+	}
+	if ss.Query != "" {
+		if _, err := ParseQuery(ss.Query); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// SavedSearchOptions compiles a saved search into SearchOptions. Relative
+// filters (WithinDays) are resolved against now. The caller sets Limit,
+// Cursor and other per-request options.
+func SavedSearchOptions(ss *models.SavedSearch, now time.Time) (*SearchOptions, error) {
+	opts := &SearchOptions{}
+	if ss.Query != "" {
+		parsed, err := ParseQuery(ss.Query)
+		if err != nil {
+			return nil, err
+		}
+		parsed.Apply(opts)
+	}
+
+	filters := opts.Filters
+	if filters == nil {
+		filters = NewFilterBuilder()
+	}
+	f := ss.Filters
+	filters.MediaType(f.MediaType)
+	filters.Tags(f.Tags...)
+	filters.ExcludeTags(f.ExcludeTags...)
+	filters.DateRange(f.DateFrom, f.DateTo)
+	filters.Collection(f.Collection)
+	if f.WithinDays > 0 {
+		filters.DateFrom(now.AddDate(0, 0, -f.WithinDays).Unix())
+	}
+	if filters.HasFilters() {
+		opts.Filters = filters
+	}
+	return opts, nil
+}
+
+// CreateSavedSearch creates a new saved search.
+func (r *Repository) CreateSavedSearch(ss *models.SavedSearch) error {
+	now := time.Now().Unix()
+	ss.ID = models.UUID(uuid.New())
+	ss.CreatedAt = now
+	ss.UpdatedAt = now
+	ss.Version = 1
+
+	filters, err := json.Marshal(ss.Filters)
+	if err != nil {
+		return fmt.Errorf("failed to encode filters: %w", err)
+	}
+
+	query := `
+	INSERT INTO saved_searches (id, name, query, filters, is_pinned, position, is_deleted,
+		created_at, updated_at, version)
+	VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?)
+	`
+	_, err = r.db.Exec(query, ss.ID, ss.Name, ss.Query, string(filters), ss.IsPinned, ss.Position,
+		ss.IsDeleted, ss.CreatedAt, ss.UpdatedAt, ss.Version)
+	return err
+}
+
+// GetSavedSearch retrieves a saved search by ID.
+// Returns sql.ErrNoRows if it does not exist or was deleted.
+func (r *Repository) GetSavedSearch(id string) (*models.SavedSearch, error) {
+	query := `SELECT ` + savedSearchColumns + ` FROM saved_searches WHERE id = ? AND is_deleted = 0`
+	return scanSavedSearch(r.db.QueryRow(query, id))
+}
+
+// ListSavedSearches returns saved searches, pinned smart collections first
+// in sidebar order, then the rest by name. If pinnedOnly is set, only smart
+// collections are returned.
+func (r *Repository) ListSavedSearches(pinnedOnly bool) ([]*models.SavedSearch, error) {
+	query := `SELECT ` + savedSearchColumns + ` FROM saved_searches WHERE is_deleted = 0`
+	if pinnedOnly {
+		query += ` AND is_pinned = 1`
+	}
+	query += ` ORDER BY is_pinned DESC, position, name`
+	return r.querySavedSearches(query)
+}
+
+// querySavedSearches runs a query selecting savedSearchColumns.
+func (r *Repository) querySavedSearches(query string, args ...interface{}) ([]*models.SavedSearch, error) {
+	rows, err := r.db.Query(query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	searches := make([]*models.SavedSearch, 0)
+	for rows.Next() {
+		ss, err := scanSavedSearch(rows)
+		if err != nil {
+			return nil, err
+		}
+		searches = append(searches, ss)
+	}
+	return searches, rows.Err()
+}
+
+// UpdateSavedSearch updates an existing saved search and bumps its version.
+// Returns sql.ErrNoRows if it does not exist or was deleted.
+func (r *Repository) UpdateSavedSearch(ss *models.SavedSearch) error {
+	ss.Touch()
+	filters, err := json.Marshal(ss.Filters)
+	if err != nil {
+		return fmt.Errorf("failed to encode filters: %w", err)
+	}
+
+	query := `
+	UPDATE saved_searches
+	SET name = ?, query = ?, filters = ?, is_pinned = ?, position = ?, updated_at = ?, version = ?
+	WHERE id = ? AND is_deleted = 0
+	`
+	return execAffectingRow(r.db.Exec(query, ss.Name, ss.Query, string(filters), ss.IsPinned, ss.Position,
+		ss.UpdatedAt, ss.Version, ss.ID))
+}
+
+// DeleteSavedSearch soft deletes a saved search. The version is bumped so
+// the deletion syncs to other devices.
+func (r *Repository) DeleteSavedSearch(id string) error {
+	query := `
+	UPDATE saved_searches SET is_deleted = 1, updated_at = ?, version = version + 1
+	WHERE id = ? AND is_deleted = 0
+	`
+	return execAffectingRow(r.db.Exec(query, time.Now().Unix(), id))
+}
+
+// CountSavedSearch returns the number of items a saved search currently matches.
+func (r *Repository) CountSavedSearch(ss *models.SavedSearch) (int, error) {
+	opts, err := SavedSearchOptions(ss, time.Now())
+	if err != nil {
+		return 0, err
+	}
+	opts.Limit = 1
+	opts.SkipSuggestions = true
+
+	response, err := r.Search(opts)
+	if err != nil {
+		return 0, err
+	}
+	return response.Total, nil
+}
+
+// =====================================================
+// Saved Search Sync
+// =====================================================
+
+// ListSavedSearchesForSync returns every saved search, including deleted
+// ones, so deletions propagate to other devices.
+func (r *Repository) ListSavedSearchesForSync() ([]*models.SavedSearch, error) {
+	query := `SELECT ` + savedSearchColumns + ` FROM saved_searches ORDER BY id`
+	return r.querySavedSearches(query)
+}
+
+// ApplyRemoteSavedSearch stores a saved search received from another device
+// if it is new or has a higher version than the local copy.
+// Returns true if the local copy changed.
+func (r *Repository) ApplyRemoteSavedSearch(ss *models.SavedSearch) (bool, error) {
+	filters, err := json.Marshal(ss.Filters)
+	if err != nil {
+		return false, fmt.Errorf("failed to encode filters: %w", err)
+	}
+
+	query := `
+	INSERT INTO saved_searches (id, name, query, filters, is_pinned, position, is_deleted,
+		created_at, updated_at, version)
+	VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?)
+	ON CONFLICT(id) DO UPDATE SET
+		name = excluded.name, query = excluded.query, filters = excluded.filters,
+		is_pinned = excluded.is_pinned, position = excluded.position,
+		is_deleted = excluded.is_deleted, updated_at = excluded.updated_at,
+		version = excluded.version
+	WHERE excluded.version > saved_searches.version
+	`
+	result, err := r.db.Exec(query, ss.ID, ss.Name, ss.Query, string(filters), ss.IsPinned, ss.Position,
+		ss.IsDeleted, ss.CreatedAt, ss.UpdatedAt, ss.Version)
+	if err != nil {
+		return false, err
+	}
+	rows, err := result.RowsAffected()
+	if err != nil {
+		return false, err
+	}
+	return rows > 0, nil
+}
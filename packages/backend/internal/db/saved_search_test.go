@@ -0,0 +1,187 @@
+// Package db tests for saved searches.
+package db
+
+import (
+	"database/sql"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/kimhsiao/memonexus/backend/internal/models"
+)
+
+// TestValidateSavedSearch verifies saved search validation.
+func TestValidateSavedSearch(t *testing.T) {
+	tests := []struct {
+		name    string
+		ss      models.SavedSearch
+		wantErr bool
+	}{
+		{"query only", models.SavedSearch{Name: "Go", Query: "golang"}, false},
+		{"filters only", models.SavedSearch{Name: "PDFs", Filters: models.SearchFilters{MediaType: "pdf"}}, false},
+		{"missing name", models.SavedSearch{Query: "golang"}, true},
+		{"no query or filters", models.SavedSearch{Name: "Empty"}, true},
+		{"invalid media type", models.SavedSearch{Name: "Bad", Filters: models.SearchFilters{MediaType: "book"}}, true},
+		{"negative days", models.SavedSearch{Name: "Bad", Filters: models.SearchFilters{WithinDays: -1}}, true},
+		{"unparseable query", models.SavedSearch{Name: "Bad", Query: `"unterminated`}, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := ValidateSavedSearch(&tt.ss)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("ValidateSavedSearch() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+// TestSavedSearchOptions verifies queries and stored filters are combined.
+func TestSavedSearchOptions(t *testing.T) {
+	now := time.Unix(1_700_000_000, 0)
+	ss := &models.SavedSearch{
+		Name:  "Recent Go",
+		Query: "golang tag:dev",
+		Filters: models.SearchFilters{
+			MediaType:  "web",
+			WithinDays: 7,
+		},
+	}
+
+	opts, err := SavedSearchOptions(ss, now)
+	if err != nil {
+		t.Fatalf("SavedSearchOptions failed: %v", err)
+	}
+	if !strings.Contains(opts.Query, "golang") || strings.Contains(opts.Query, "tag:") {
+		t.Errorf("Query = %q, want the free text only", opts.Query)
+	}
+	if opts.Filters == nil {
+		t.Fatal("Expected filters")
+	}
+	where, args := opts.Filters.Build()
+	if where == "" || len(args) != 3 {
+		t.Errorf("Build() = %q, %v; want tag, media type and date conditions", where, args)
+	}
+	if want := now.AddDate(0, 0, -7).Unix(); !containsArg(args, want) {
+		t.Errorf("Expected date_from %d in args %v", want, args)
+	}
+}
+
+// containsArg reports whether args contains v.
+func containsArg(args []interface{}, v interface{}) bool {
+	for _, a := range args {
+		if a == v {
+			return true
+		}
+	}
+	return false
+}
+
+// TestSavedSearchCRUD verifies create, list, update and delete.
+func TestSavedSearchCRUD(t *testing.T) {
+	db := setupMigratedTestDB(t)
+	defer db.Close()
+
+	repo := NewRepository(db)
+	now := time.Now().Unix()
+	insertTestContentItem(t, db, "Go Concurrency", "goroutines", "web", "golang", now)
+	insertTestContentItem(t, db, "Go Generics", "type parameters", "pdf", "golang", now)
+	insertTestContentItem(t, db, "Rust Ownership", "borrowing", "web", "rust", now)
+
+	golang := &models.SavedSearch{Name: "Go", Filters: models.SearchFilters{Tags: []string{"golang"}}}
+	webOnly := &models.SavedSearch{Name: "Web", Filters: models.SearchFilters{MediaType: "web"}, IsPinned: true}
+	for _, ss := range []*models.SavedSearch{golang, webOnly} {
+		if err := repo.CreateSavedSearch(ss); err != nil {
+			t.Fatalf("CreateSavedSearch failed: %v", err)
+		}
+	}
+
+	got, err := repo.GetSavedSearch(string(golang.ID))
+	if err != nil {
+		t.Fatalf("GetSavedSearch failed: %v", err)
+	}
+	if got.Name != "Go" || len(got.Filters.Tags) != 1 || got.Version != 1 {
+		t.Errorf("GetSavedSearch = %+v", got)
+	}
+
+	count, err := repo.CountSavedSearch(got)
+	if err != nil || count != 2 {
+		t.Errorf("CountSavedSearch = %d, %v; want 2", count, err)
+	}
+
+	// Pinned smart collections come first
+	all, err := repo.ListSavedSearches(false)
+	if err != nil || len(all) != 2 || all[0].Name != "Web" {
+		t.Fatalf("ListSavedSearches(false) = %v, %v", all, err)
+	}
+	pinned, err := repo.ListSavedSearches(true)
+	if err != nil || len(pinned) != 1 || pinned[0].Name != "Web" {
+		t.Errorf("ListSavedSearches(true) = %v, %v", pinned, err)
+	}
+
+	got.Query = "generics"
+	if err := repo.UpdateSavedSearch(got); err != nil {
+		t.Fatalf("UpdateSavedSearch failed: %v", err)
+	}
+	if got.Version != 2 {
+		t.Errorf("Version = %d after update, want 2", got.Version)
+	}
+	if count, err := repo.CountSavedSearch(got); err != nil || count != 1 {
+		t.Errorf("CountSavedSearch after update = %d, %v; want 1", count, err)
+	}
+
+	if err := repo.DeleteSavedSearch(string(golang.ID)); err != nil {
+		t.Fatalf("DeleteSavedSearch failed: %v", err)
+	}
+	if _, err := repo.GetSavedSearch(string(golang.ID)); err != sql.ErrNoRows {
+		t.Errorf("GetSavedSearch after delete = %v, want sql.ErrNoRows", err)
+	}
+	if err := repo.DeleteSavedSearch(string(golang.ID)); err != sql.ErrNoRows {
+		t.Errorf("Second delete = %v, want sql.ErrNoRows", err)
+	}
+	if err := repo.UpdateSavedSearch(got); err != sql.ErrNoRows {
+		t.Errorf("Update after delete = %v, want sql.ErrNoRows", err)
+	}
+
+	// Deleted saved searches still sync
+	synced, err := repo.ListSavedSearchesForSync()
+	if err != nil || len(synced) != 2 {
+		t.Fatalf("ListSavedSearchesForSync = %v, %v", synced, err)
+	}
+}
+
+// TestApplyRemoteSavedSearch verifies the higher version wins.
+func TestApplyRemoteSavedSearch(t *testing.T) {
+	db := setupMigratedTestDB(t)
+	defer db.Close()
+
+	repo := NewRepository(db)
+	ss := &models.SavedSearch{Name: "Local", Query: "golang"}
+	if err := repo.CreateSavedSearch(ss); err != nil {
+		t.Fatalf("CreateSavedSearch failed: %v", err)
+	}
+
+	remote := *ss
+	remote.Name = "Remote"
+	if applied, err := repo.ApplyRemoteSavedSearch(&remote); err != nil || applied {
+		t.Errorf("Same version applied = %v, %v; want false", applied, err)
+	}
+
+	remote.Version = 3
+	if applied, err := repo.ApplyRemoteSavedSearch(&remote); err != nil || !applied {
+		t.Errorf("Newer version applied = %v, %v; want true", applied, err)
+	}
+	got, err := repo.GetSavedSearch(string(ss.ID))
+	if err != nil || got.Name != "Remote" || got.Version != 3 {
+		t.Errorf("GetSavedSearch = %+v, %v", got, err)
+	}
+
+	// Unknown saved searches are inserted
+	other := &models.SavedSearch{
+		ID: "00000000-0000-4000-8000-000000000001", Name: "New", Query: "rust",
+		CreatedAt: ss.CreatedAt, UpdatedAt: ss.UpdatedAt, Version: 1,
+	}
+	if applied, err := repo.ApplyRemoteSavedSearch(other); err != nil || !applied {
+		t.Errorf("New saved search applied = %v, %v; want true", applied, err)
+	}
+}
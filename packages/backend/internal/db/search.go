@@ -2,6 +2,7 @@
 package db
 
 import (
+	"context"
 	"database/sql"
 	"fmt"
 	"strings"
@@ -13,7 +14,7 @@ import (
 
 // SearchOptions contains parameters for search queries.
 type SearchOptions struct {
-	// Query is the FTS5 search query (required)
+	// Query is the FTS5 search query (required unless Filters is set)
 	Query string
 
 	// Limit is the maximum number of results (default: 20, max: 100)
@@ -30,13 +31,93 @@ type SearchOptions struct {
 
 	// DateTo filters results created before this Unix timestamp
 	DateTo int64
+
+	// Weights sets the per-column BM25 weights (default: DefaultColumnWeights)
+	Weights *ColumnWeights
+
+	// Filters adds clauses compiled from a structured query (see ParseQuery)
+	Filters *FilterBuilder
+
+	// RawQuery is the query as typed by the user, used for spelling
+	// suggestions (default: Query)
+	RawQuery string
+
+	// Facets requests facet counts over all matching items
+	Facets bool
+
+	// Columns limits matching to these content_fts columns (default: all).
+	// See SearchColumns for valid names.
+	Columns []string
+
+	// Cursor continues a previous search from its NextCursor
+	Cursor string
+
+	// Highlight requests FTS5 snippets and match offsets for each result
+	// (default: nil, no highlighting)
+	Highlight *HighlightOptions
+
+	// SkipSuggestions disables "did you mean" suggestions when nothing
+	// matches, for callers that only need counts
+	SkipSuggestions bool
+
+	// Ranking names the ranking profile (default: RankingRelevance).
+	// See LookupRankingProfile.
+	Ranking string
+
+	// Now is the Unix time recency boosts are measured from
+	// (default: the current time)
+	Now int64
+}
+
+// SearchColumns lists the content_fts columns in index order.
+var SearchColumns = []string{"title", "content_text", "tags", "summary", "source_domain"}
+
+// ColumnWeights holds the BM25 weight applied to each content_fts column.
+// A hit in a column with weight 10 counts ten times as much as a hit in a
+// column with weight 1.
+type ColumnWeights struct {
+	Title        float64
+	ContentText  float64
+	Tags         float64
+	Summary      float64
+	SourceDomain float64
+}
+
+// DefaultColumnWeights returns the weights used when SearchOptions.Weights is nil.
+// Title and tag hits outrank summary and source hits, which outrank body hits.
+func DefaultColumnWeights() *ColumnWeights {
+	return &ColumnWeights{
+		Title:        10.0,
+		ContentText:  1.0,
+		Tags:         5.0,
+		Summary:      3.0,
+		SourceDomain: 3.0,
+	}
+}
+
+// args returns the weights in content_fts column order for bm25().
+func (w *ColumnWeights) args() []interface{} {
+	return []interface{}{w.Title, w.ContentText, w.Tags, w.Summary, w.SourceDomain}
 }
 
 // SearchResult represents a single search result with relevance score.
 type SearchResult struct {
-	Item         *models.ContentItem
-	Relevance    float64
+	Item *models.ContentItem
+
+	// Relevance is the BM25 score normalized to [0, 1); higher is better.
+	Relevance float64
+
+	// Score is the raw weighted bm25() value, or the blended score for a
+	// blended ranking profile; lower (more negative) is better.
+	Score float64
+
+	// MatchedTerms lists the distinct words FTS5 matched, as they appear in
+	// the item. Set only when SearchOptions.Highlight is set.
 	MatchedTerms []string
+
+	// Highlights maps each matched content_fts column to its snippet and
+	// match offsets. Set only when SearchOptions.Highlight is set.
+	Highlights map[string]*ColumnHighlight
 }
 
 // SearchResponse contains search results and metadata.
@@ -44,13 +125,25 @@ type SearchResponse struct {
 	Results []*SearchResult
 	Total   int
 	Query   string
+
+	// Suggestions holds "did you mean" queries when nothing matched
+	Suggestions []*Suggestion
+
+	// Facets holds facet counts when SearchOptions.Facets is set
+	Facets *SearchFacets
+
+	// NextCursor continues the search after Results; empty on the last page
+	NextCursor string
 }
 
 // Search performs FTS5 full-text search on content items.
 // Implements T115: FTS5 search service with BM25 ranking and Unicode support.
 // Constitution requirement SC-002: <100ms for 10,000 items
+// Queries containing CJK text are also run against the trigram index
+// (content_fts_trigram) and the two rankings are merged.
 func (r *Repository) Search(opts *SearchOptions) (*SearchResponse, error) {
-	if opts == nil || opts.Query == "" {
+	hasFilters := opts != nil && opts.Filters != nil && opts.Filters.HasFilters()
+	if opts == nil || (opts.Query == "" && !hasFilters) {
 		return nil, fmt.Errorf("search query is required")
 	}
 
@@ -61,18 +154,117 @@ func (r *Repository) Search(opts *SearchOptions) (*SearchResponse, error) {
 	if opts.Limit > 100 {
 		opts.Limit = 100
 	}
+	weights := opts.Weights
+	if weights == nil {
+		weights = DefaultColumnWeights()
+	}
+	columns, err := searchColumns(opts.Columns)
+	if err != nil {
+		return nil, err
+	}
+	match := restrictColumns(opts.Query, opts.Columns)
+	profile, err := LookupRankingProfile(opts.Ranking)
+	if err != nil {
+		return nil, err
+	}
+	var after *cursorPayload
+	if opts.Cursor != "" {
+		after, err = decodeCursor(opts.Cursor, cursorKindSearch)
+		if err != nil {
+			return nil, err
+		}
+	}
+	now := opts.Now
+	if after != nil && after.Now != 0 {
+		now = after.Now
+	}
+	if now == 0 {
+		now = time.Now().Unix()
+	}
 
-	// Build the search query with filters
+	// Build the search query with filters. A filter-only structured query
+	// (e.g. "tag:research type:pdf") has no MATCH expression, so it skips the
+	// FTS join and lists the newest matching items instead.
+	// Results are ordered by rank, then rowid, which is also the keyset
+	// used by search cursors. Blended ranking profiles wrap the BM25 score
+	// (see RankingProfile.scoreSQL).
 	baseQuery := `
-		SELECT ci.id, ci.title, ci.content_text, ci.source_url, ci.media_type, ci.tags,
-			   ci.summary, ci.is_deleted, ci.created_at, ci.updated_at, ci.version, ci.content_hash
-		FROM content_items ci
+		SELECT ` + prefixedContentItemColumns + `,
+			   ci.rowid, `
+	var fromClause, score, join string
+	rank := "score"
+	var selectArgs, sourceArgs []interface{}
+	if profile.blended() {
+		join = rankingJoin
+	}
+	plan, err := planTrigramSearch(opts.Query)
+	if err != nil {
+		return nil, err
+	}
+	switch {
+	case opts.Query == "":
+		score = "0.0"
+		fromClause = `
+		FROM content_items ci` + join + `
+		WHERE ci.is_deleted = 0
+	`
+		if !profile.blended() {
+			rank = "-ci.created_at"
+		}
+	case plan != nil:
+		// CJK query: merge hits from the unicode61 and trigram indexes,
+		// keeping each item's best score
+		score = "hits.score"
+		var trigramSource string
+		sourceArgs = append(weights.args(), match)
+		if plan.Match != "" {
+			trigramSource = `
+				SELECT rowid, bm25(content_fts_trigram, ?, ?, ?, ?, ?) AS score
+				FROM content_fts_trigram WHERE content_fts_trigram MATCH ?`
+			sourceArgs = append(sourceArgs, weights.args()...)
+			sourceArgs = append(sourceArgs, restrictColumns(plan.Match, opts.Columns))
+		} else {
+			// Terms shorter than a trigram can only be found by substring scan
+			likeSQL, likeArgs := plan.Like.sql(columns)
+			sourceArgs = append(sourceArgs, likeArgs...)
+			trigramSource = `
+				SELECT rowid, 0.0 AS score
+				FROM content_items WHERE ` + likeSQL
+		}
+		fromClause = `
+		FROM content_items ci` + join + `
+		INNER JOIN (
+			SELECT rowid, MIN(score) AS score FROM (
+				SELECT rowid, bm25(content_fts, ?, ?, ?, ?, ?) AS score
+				FROM content_fts WHERE content_fts MATCH ?
+				UNION ALL` + trigramSource + `
+			) GROUP BY rowid
+		) hits ON ci.rowid = hits.rowid
+		WHERE ci.is_deleted = 0
+	`
+	default:
+		score = "bm25(content_fts, ?, ?, ?, ?, ?)"
+		selectArgs = weights.args()
+		fromClause = `
+		FROM content_items ci` + join + `
 		INNER JOIN content_fts fts ON ci.rowid = fts.rowid
 		WHERE content_fts MATCH ? AND ci.is_deleted = 0
 	`
+		sourceArgs = []interface{}{match}
+	}
+	// The raw BM25 score is selected on its own for Relevance, which stays
+	// comparable across profiles
+	relevance, relevanceArgs := score, selectArgs
+	if profile.blended() {
+		var profileArgs []interface{}
+		score, profileArgs = profile.scoreSQL(score, now)
+		selectArgs = append(append([]interface{}{}, selectArgs...), profileArgs...)
+	}
+	baseQuery += relevance + " AS bm25, " + score + " AS score" + fromClause
+	args := append(append(relevanceArgs, selectArgs...), sourceArgs...)
 
 	whereClauses := []string{}
-	args := []interface{}{opts.Query}
+	filterArgsStart := len(args)
 
 	// Add media type filter
 	if opts.MediaType != "" {
@@ -90,67 +282,84 @@ func (r *Repository) Search(opts *SearchOptions) (*SearchResponse, error) {
 		args = append(args, opts.DateTo)
 	}
 
-	// Add tag filter (simple LIKE matching - can be enhanced later)
+	// Add tag filter (exact names, any of them)
 	if opts.Tags != "" {
-		// Split tags and build OR conditions
-		tagList := strings.Split(opts.Tags, ",")
-		tagConditions := make([]string, 0, len(tagList))
-		for _, tag := range tagList {
-			tag = strings.TrimSpace(tag)
-			if tag != "" {
-				tagConditions = append(tagConditions, "ci.tags LIKE ?")
-				args = append(args, "%"+tag+"%")
-			}
-		}
-		if len(tagConditions) > 0 {
-			whereClauses = append(whereClauses, "("+strings.Join(tagConditions, " OR ")+")")
+		if tags := (&TagsFilter{Tags: TagsFromCommaString(opts.Tags)}); tags.Valid() {
+			whereClauses = append(whereClauses, tags.SQL())
+			args = append(args, tags.Args()...)
 		}
 	}
 
+	// Add structured query filters
+	if hasFilters {
+		filterSQL, filterArgs := opts.Filters.BuildForSearch()
+		whereClauses = append(whereClauses, filterSQL)
+		args = append(args, filterArgs...)
+	}
+
 	// Combine WHERE clauses
 	if len(whereClauses) > 0 {
 		baseQuery += " AND " + strings.Join(whereClauses, " AND ")
 	}
 
-	// Add ordering and limit
-	baseQuery += " ORDER BY rank LIMIT ?"
-	args = append(args, opts.Limit)
+	// Resume after the cursor position. This only narrows the page, so it
+	// is kept out of the count and facet queries.
+	filterArgsEnd := len(args)
+	if after != nil {
+		baseQuery += fmt.Sprintf(" AND (%[1]s > ? OR (%[1]s = ? AND ci.rowid > ?))", rank)
+		args = append(args, after.Rank, after.Rank, after.RowID)
+	}
+
+	// Add ordering and limit (weighted BM25, most relevant first). One
+	// extra row is fetched to learn whether another page follows.
+	baseQuery += " ORDER BY " + rank + ", ci.rowid LIMIT ?"
+	args = append(args, opts.Limit+1)
+
+	// Run the result, count and facet queries in one transaction so they
+	// all read the same snapshot and facet counts agree with Total
+	tx, err := r.db.Begin()
+	if err != nil {
+		return nil, fmt.Errorf("failed to begin search transaction: %w", err)
+	}
+	defer tx.Rollback()
 
 	// Execute the search query
-	rows, err := r.db.Query(baseQuery, args...)
+	rows, err := tx.Query(baseQuery, args...)
 	if err != nil {
 		return nil, fmt.Errorf("search query failed: %w", err)
 	}
 	defer rows.Close()
 
 	var results []*SearchResult
+	var targets []highlightTarget
+	var nextCursor string
+	var lastRank float64
+	var lastRowID int64
 	for rows.Next() {
-		var item models.ContentItem
-		var sourceURL, summary, contentHash sql.NullString
-		err := rows.Scan(
-			&item.ID, &item.Title, &item.ContentText, &sourceURL, &item.MediaType,
-			&item.Tags, &summary, &item.IsDeleted, &item.CreatedAt, &item.UpdatedAt,
-			&item.Version, &contentHash,
-		)
+		var rowid int64
+		var bm25, score float64
+		item, err := scanContentItem(rows, &rowid, &bm25, &score)
 		if err != nil {
 			return nil, fmt.Errorf("failed to scan search result: %w", err)
 		}
 
-		if sourceURL.Valid {
-			item.SourceURL = sourceURL.String
-		}
-		if summary.Valid {
-			item.Summary = summary.String
-		}
-		if contentHash.Valid {
-			item.ContentHash = contentHash.String
+		if len(results) == opts.Limit {
+			nextCursor = searchCursor(lastRank, lastRowID, now)
+			break
 		}
 
 		result := &SearchResult{
-			Item:      &item,
-			Relevance: 0, // BM25 rank is implicit in ORDER BY, we can add rank column if needed
+			Item:      item,
+			Relevance: NormalizeBM25(bm25),
+			Score:     score,
 		}
 		results = append(results, result)
+		targets = append(targets, highlightTarget{rowid: rowid, result: result})
+
+		lastRank, lastRowID = score, rowid
+		if rank != "score" {
+			lastRank = -float64(item.CreatedAt)
+		}
 	}
 
 	if err := rows.Err(); err != nil {
@@ -158,32 +367,116 @@ func (r *Repository) Search(opts *SearchOptions) (*SearchResponse, error) {
 	}
 
 	// Get total count (without limit)
-	countQuery := `
-		SELECT COUNT(*)
-		FROM content_items ci
-		INNER JOIN content_fts fts ON ci.rowid = fts.rowid
-		WHERE content_fts MATCH ? AND ci.is_deleted = 0
-	`
-	countArgs := []interface{}{opts.Query}
+	countQuery := "SELECT COUNT(*)" + fromClause
+	countArgs := append([]interface{}{}, sourceArgs...)
 	if len(whereClauses) > 0 {
 		countQuery += " AND " + strings.Join(whereClauses, " AND ")
-		// Rebuild count args (excluding LIMIT)
-		for i := 1; i < len(args)-1; i++ { // Skip first (query) and last (limit)
-			countArgs = append(countArgs, args[i])
-		}
+		// Rebuild count args (excluding score weights, cursor and LIMIT)
+		countArgs = append(countArgs, args[filterArgsStart:filterArgsEnd]...)
 	}
 
 	var total int
-	err = r.db.QueryRow(countQuery, countArgs...).Scan(&total)
+	err = tx.QueryRow(countQuery, countArgs...).Scan(&total)
 	if err != nil {
 		return nil, fmt.Errorf("failed to count search results: %w", err)
 	}
 
-	return &SearchResponse{
-		Results: results,
-		Total:   total,
-		Query:   opts.Query,
-	}, nil
+	response := &SearchResponse{
+		Results:    results,
+		Total:      total,
+		Query:      opts.Query,
+		NextCursor: nextCursor,
+	}
+
+	if opts.Facets {
+		facetFrom := fromClause
+		if len(whereClauses) > 0 {
+			facetFrom += " AND " + strings.Join(whereClauses, " AND ")
+		}
+		response.Facets, err = searchFacets(tx, facetFrom, countArgs)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	if opts.Highlight != nil && opts.Query != "" {
+		// Highlight from the index whose tokenization found the results:
+		// trigram first for CJK queries, and substring hits by regex
+		var sources []highlightSource
+		switch {
+		case plan == nil:
+			sources = []highlightSource{{"content_fts", match}}
+		case plan.Match != "":
+			sources = []highlightSource{
+				{"content_fts_trigram", restrictColumns(plan.Match, opts.Columns)},
+				{"content_fts", match},
+			}
+		}
+		if err := attachHighlights(tx, targets, sources, opts.Query, columns, opts.Highlight); err != nil {
+			return nil, err
+		}
+	}
+
+	if err := tx.Commit(); err != nil {
+		return nil, fmt.Errorf("failed to commit search transaction: %w", err)
+	}
+
+	// Offer spelling corrections when nothing matched. Suggestions are
+	// best-effort and never fail the search itself.
+	if total == 0 && opts.Query != "" && !opts.SkipSuggestions {
+		raw := opts.RawQuery
+		if raw == "" {
+			raw = opts.Query
+		}
+		if suggestions, err := r.Suggest(raw, 3); err == nil {
+			response.Suggestions = suggestions
+		}
+	}
+
+	return response, nil
+}
+
+// NormalizeBM25 maps a raw bm25() score onto [0, 1).
+// FTS5 returns more negative scores for better matches, so the magnitude is
+// squashed with s/(1+s). The mapping is monotonic and independent of the
+// result set, which keeps relevance values comparable across queries.
+func NormalizeBM25(score float64) float64 {
+	s := -score
+	if s <= 0 {
+		return 0
+	}
+	return s / (1 + s)
+}
+
+// searchColumns validates a column restriction and returns the columns to
+// search (all of SearchColumns if none are given).
+func searchColumns(requested []string) ([]string, error) {
+	if len(requested) == 0 {
+		return SearchColumns, nil
+	}
+	for _, column := range requested {
+		valid := false
+		for _, c := range SearchColumns {
+			if column == c {
+				valid = true
+				break
+			}
+		}
+		if !valid {
+			return nil, fmt.Errorf("invalid search column: %s (must be one of: %s)",
+				column, strings.Join(SearchColumns, ", "))
+		}
+	}
+	return requested, nil
+}
+
+// restrictColumns wraps a MATCH expression in an FTS5 column filter.
+// Column filters inside the expression still apply within the restriction.
+func restrictColumns(match string, columns []string) string {
+	if match == "" || len(columns) == 0 {
+		return match
+	}
+	return "{" + strings.Join(columns, " ") + "} : (" + match + ")"
 }
 
 // SearchSimple performs a simple search query with just the search string.
@@ -210,6 +503,12 @@ func (r *Repository) OptimizeFTSIndex() error {
 	if err != nil {
 		return fmt.Errorf("failed to optimize FTS index: %w", err)
 	}
+
+	query = `INSERT INTO content_fts_trigram(content_fts_trigram) VALUES('optimize')`
+	_, err = r.db.Exec(query)
+	if err != nil {
+		return fmt.Errorf("failed to optimize trigram FTS index: %w", err)
+	}
 	return nil
 }
 
@@ -224,7 +523,13 @@ func (r *Repository) RebuildFTSIndex() error {
 	}
 	defer tx.Rollback()
 
-	// Drop existing FTS table
+	// Drop existing FTS triggers and table
+	for _, trigger := range []string{"content_items_ai", "content_items_ad", "content_items_au"} {
+		_, err = tx.Exec(`DROP TRIGGER IF EXISTS ` + trigger)
+		if err != nil {
+			return fmt.Errorf("failed to drop FTS trigger: %w", err)
+		}
+	}
 	_, err = tx.Exec(`DROP TABLE IF EXISTS content_fts`)
 	if err != nil {
 		return fmt.Errorf("failed to drop FTS table: %w", err)
@@ -236,6 +541,8 @@ func (r *Repository) RebuildFTSIndex() error {
 		title,
 		content_text,
 		tags,
+		summary,
+		source_domain,
 		content=content_items,
 		content_rowid=rowid,
 		tokenize='unicode61 remove_diacritics 1'
@@ -247,8 +554,8 @@ func (r *Repository) RebuildFTSIndex() error {
 
 	// Populate FTS index with existing content
 	populateFTS := `
-	INSERT INTO content_fts(rowid, title, content_text, tags)
-	SELECT rowid, title, content_text, tags FROM content_items
+	INSERT INTO content_fts(rowid, title, content_text, tags, summary, source_domain)
+	SELECT rowid, title, content_text, tags, summary, source_domain FROM content_items
 	`
 	_, err = tx.Exec(populateFTS)
 	if err != nil {
@@ -258,18 +565,18 @@ func (r *Repository) RebuildFTSIndex() error {
 	// Recreate triggers
 	triggers := []string{
 		`CREATE TRIGGER content_items_ai AFTER INSERT ON content_items BEGIN
-			INSERT INTO content_fts(rowid, title, content_text, tags)
-			VALUES (new.rowid, new.title, new.content_text, new.tags);
+			INSERT INTO content_fts(rowid, title, content_text, tags, summary, source_domain)
+			VALUES (new.rowid, new.title, new.content_text, new.tags, new.summary, new.source_domain);
 		END`,
 		`CREATE TRIGGER content_items_ad AFTER DELETE ON content_items BEGIN
-			INSERT INTO content_fts(content_fts, rowid, title, content_text, tags)
-			VALUES ('delete', old.rowid, old.title, old.content_text, old.tags);
+			INSERT INTO content_fts(content_fts, rowid, title, content_text, tags, summary, source_domain)
+			VALUES ('delete', old.rowid, old.title, old.content_text, old.tags, old.summary, old.source_domain);
 		END`,
 		`CREATE TRIGGER content_items_au AFTER UPDATE ON content_items BEGIN
-			INSERT INTO content_fts(content_fts, rowid, title, content_text, tags)
-			VALUES ('delete', old.rowid, old.title, old.content_text, old.tags);
-			INSERT INTO content_fts(rowid, title, content_text, tags)
-			VALUES (new.rowid, new.title, new.content_text, new.tags);
+			INSERT INTO content_fts(content_fts, rowid, title, content_text, tags, summary, source_domain)
+			VALUES ('delete', old.rowid, old.title, old.content_text, old.tags, old.summary, old.source_domain);
+			INSERT INTO content_fts(rowid, title, content_text, tags, summary, source_domain)
+			VALUES (new.rowid, new.title, new.content_text, new.tags, new.summary, new.source_domain);
 		END`,
 	}
 
@@ -280,6 +587,12 @@ func (r *Repository) RebuildFTSIndex() error {
 		}
 	}
 
+	// Rebuild the trigram index in place (its triggers are left untouched)
+	_, err = tx.Exec(`INSERT INTO content_fts_trigram(content_fts_trigram) VALUES('rebuild')`)
+	if err != nil {
+		return fmt.Errorf("failed to rebuild trigram FTS index: %w", err)
+	}
+
 	return tx.Commit()
 }
 
@@ -288,11 +601,13 @@ func (r *Repository) RebuildFTSIndex() error {
 // For large datasets, this can take significant time.
 func (r *Repository) FTSIntegrityCheck() (bool, error) {
 	// FTS5 integrity check command
-	query := `INSERT INTO content_fts(content_fts, rank) VALUES('integrity-check', 0)`
-	_, err := r.db.Exec(query)
-	if err != nil {
-		// Integrity check failed - index is corrupted
-		return false, nil
+	for _, table := range []string{"content_fts", "content_fts_trigram"} {
+		query := fmt.Sprintf(`INSERT INTO %[1]s(%[1]s, rank) VALUES('integrity-check', 0)`, table)
+		_, err := r.db.Exec(query)
+		if err != nil {
+			// Integrity check failed - index is corrupted
+			return false, nil
+		}
 	}
 	return true, nil
 }
@@ -330,6 +645,7 @@ func (r *Repository) BatchImportContent(items []*models.ContentItem) (int, error
 	if len(items) == 0 {
 		return 0, nil
 	}
+	ctx := context.Background()
 
 	// Start transaction
 	tx, err := r.db.Begin()
@@ -351,8 +667,10 @@ func (r *Repository) BatchImportContent(items []*models.ContentItem) (int, error
 	// Prepare insert statement
 	stmt, err := tx.Prepare(`
 	INSERT INTO content_items (id, title, content_text, source_url, media_type, tags, summary,
-		is_deleted, created_at, updated_at, version, content_hash)
-	VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)
+		is_deleted, created_at, updated_at, version, content_hash,
+		is_favorite, is_pinned, read_status, read_progress, is_archived,
+		favorite_changed_at, pinned_changed_at, read_changed_at, archived_changed_at)
+	VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)
 	`)
 	if err != nil {
 		return 0, fmt.Errorf("failed to prepare insert statement: %w", err)
@@ -369,29 +687,52 @@ func (r *Repository) BatchImportContent(items []*models.ContentItem) (int, error
 			item.UpdatedAt = now
 			item.Version = 1
 		}
+		if item.ReadStatus == "" {
+			item.ReadStatus = models.ReadStatusUnread
+		}
+		tags, err := resolveItemTags(ctx, tx, item, now)
+		if err != nil {
+			return 0, fmt.Errorf("failed to resolve tags of item %s: %w", item.ID, err)
+		}
 
-		_, err := stmt.Exec(
+		_, err = stmt.Exec(
 			item.ID, item.Title, item.ContentText, item.SourceURL,
 			item.MediaType, item.Tags, item.Summary, item.IsDeleted,
 			item.CreatedAt, item.UpdatedAt, item.Version, item.ContentHash,
+			item.IsFavorite, item.IsPinned, item.ReadStatus, item.ReadProgress, item.IsArchived,
+			item.FavoriteChangedAt, item.PinnedChangedAt, item.ReadChangedAt, item.ArchivedChangedAt,
 		)
 		if err != nil {
 			return 0, fmt.Errorf("failed to insert item %s: %w", item.ID, err)
 		}
+		if len(tags) > 0 {
+			if err := linkContentTags(ctx, tx, string(item.ID), tags, now); err != nil {
+				return 0, fmt.Errorf("failed to tag item %s: %w", item.ID, err)
+			}
+		}
+		if err := replaceContentLinks(ctx, tx, item, now); err != nil {
+			return 0, fmt.Errorf("failed to link item %s: %w", item.ID, err)
+		}
+		if err := resolveWikiLinks(ctx, tx, string(item.ID), item.Title); err != nil {
+			return 0, fmt.Errorf("failed to link item %s: %w", item.ID, err)
+		}
+		if err := insertChangeLog(ctx, tx, string(item.ID), "create", item.Version, now); err != nil {
+			return 0, fmt.Errorf("failed to log item %s: %w", item.ID, err)
+		}
 		count++
 	}
 
 	// Recreate FTS triggers
 	triggers := []string{
 		`CREATE TRIGGER content_items_ai AFTER INSERT ON content_items BEGIN
-			INSERT INTO content_fts(rowid, title, content_text, tags)
-			VALUES (new.rowid, new.title, new.content_text, new.tags);
+			INSERT INTO content_fts(rowid, title, content_text, tags, summary, source_domain)
+			VALUES (new.rowid, new.title, new.content_text, new.tags, new.summary, new.source_domain);
 		END`,
 		`CREATE TRIGGER content_items_au AFTER UPDATE ON content_items BEGIN
-			INSERT INTO content_fts(content_fts, rowid, title, content_text, tags)
-			VALUES ('delete', old.rowid, old.title, old.content_text, old.tags);
-			INSERT INTO content_fts(rowid, title, content_text, tags)
-			VALUES (new.rowid, new.title, new.content_text, new.tags);
+			INSERT INTO content_fts(content_fts, rowid, title, content_text, tags, summary, source_domain)
+			VALUES ('delete', old.rowid, old.title, old.content_text, old.tags, old.summary, old.source_domain);
+			INSERT INTO content_fts(rowid, title, content_text, tags, summary, source_domain)
+			VALUES (new.rowid, new.title, new.content_text, new.tags, new.summary, new.source_domain);
 		END`,
 	}
 
@@ -407,8 +748,8 @@ func (r *Repository) BatchImportContent(items []*models.ContentItem) (int, error
 	// than WHERE rowid > (SELECT MAX(rowid) FROM content_fts) because FTS5 virtual tables
 	// don't support rowid subqueries the same way
 	_, err = tx.Exec(`
-	INSERT INTO content_fts(rowid, title, content_text, tags)
-	SELECT rowid, title, content_text, tags FROM content_items
+	INSERT INTO content_fts(rowid, title, content_text, tags, summary, source_domain)
+	SELECT rowid, title, content_text, tags, summary, source_domain FROM content_items
 	`)
 	if err != nil {
 		return 0, fmt.Errorf("failed to populate FTS index: %w", err)
@@ -44,7 +44,16 @@ func setupBenchmarkDB(b *testing.B) *sql.DB {
 			created_at INTEGER NOT NULL,
 			updated_at INTEGER NOT NULL,
 			version INTEGER NOT NULL DEFAULT 1,
-			content_hash TEXT
+			content_hash TEXT,
+			is_pinned INTEGER NOT NULL DEFAULT 0 CHECK(is_pinned IN (0, 1)),
+			is_favorite INTEGER NOT NULL DEFAULT 0 CHECK(is_favorite IN (0, 1)),
+			read_status TEXT NOT NULL DEFAULT 'unread' CHECK(read_status IN ('unread', 'in_progress', 'read')),
+			read_progress REAL NOT NULL DEFAULT 0 CHECK(read_progress >= 0 AND read_progress <= 1),
+			is_archived INTEGER NOT NULL DEFAULT 0 CHECK(is_archived IN (0, 1)),
+			favorite_changed_at INTEGER NOT NULL DEFAULT 0,
+			pinned_changed_at INTEGER NOT NULL DEFAULT 0,
+			read_changed_at INTEGER NOT NULL DEFAULT 0,
+			archived_changed_at INTEGER NOT NULL DEFAULT 0
 		)
 	`); err != nil {
 		b.Fatalf("Failed to create content_items table: %v", err)
@@ -0,0 +1,414 @@
+// Package db provides CJK query routing for FTS5 search.
+package db
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+)
+
+// trigramMinLength is the shortest term the trigram tokenizer can match.
+// Shorter terms (most two-character CJK words) match nothing via MATCH.
+const trigramMinLength = 3
+
+// trigramPlan describes how a CJK query is run against content_fts_trigram.
+// Exactly one of Match or Like is set.
+type trigramPlan struct {
+	// Match is the MATCH expression for content_fts_trigram
+	Match string
+
+	// Like is the query as substring conditions, used when a term is too
+	// short for the trigram index
+	Like *likeNode
+}
+
+// planTrigramSearch decides whether a MATCH expression should also be run
+// against the trigram index. Returns nil for non-CJK queries. A query with
+// a term too short for the trigram index is planned as substring
+// conditions, keeping its column filters, OR and NOT; if it uses syntax
+// those cannot express (NEAR, ^, +), a QueryError is returned rather than
+// silently matching nothing.
+func planTrigramSearch(query string) (*trigramPlan, error) {
+	if !HasCJKText(query) {
+		return nil, nil
+	}
+
+	terms, _ := scanFTSTerms(query)
+	if len(terms) == 0 {
+		return nil, nil
+	}
+
+	for _, term := range terms {
+		if utf8.RuneCountInString(term) < trigramMinLength {
+			node, err := parseLikeExpression(query)
+			if err != nil {
+				return nil, err
+			}
+			return &trigramPlan{Like: node}, nil
+		}
+	}
+	return &trigramPlan{Match: query}, nil
+}
+
+// likeNode is an FTS5 expression rewritten as substring conditions: a
+// term, or an AND, OR or NOT of two or more nodes. NOT matches its first
+// child and none of the others.
+type likeNode struct {
+	// Term fields
+	term    string
+	columns []string // Restricts the term to these columns; empty for all
+
+	// Group fields
+	op       string
+	children []*likeNode
+}
+
+// sql renders the node as a WHERE condition over content_items, searching
+// columns wherever the term does not restrict them.
+func (n *likeNode) sql(columns []string) (string, []interface{}) {
+	if n.op == "" {
+		var clauses []string
+		var args []interface{}
+		pattern := "%" + escapeLike(n.term) + "%"
+		for _, column := range columns {
+			if len(n.columns) > 0 && !slices.Contains(n.columns, column) {
+				continue
+			}
+			clauses = append(clauses, column+` LIKE ? ESCAPE '\'`)
+			args = append(args, pattern)
+		}
+		if len(clauses) == 0 {
+			// Restricted to columns the search does not cover
+			return "0", nil
+		}
+		return "(" + strings.Join(clauses, " OR ") + ")", args
+	}
+
+	parts := make([]string, len(n.children))
+	var args []interface{}
+	for i, child := range n.children {
+		expr, childArgs := child.sql(columns)
+		parts[i] = expr
+		args = append(args, childArgs...)
+	}
+	if n.op == "NOT" {
+		return "(" + parts[0] + " AND NOT (" + strings.Join(parts[1:], " OR ") + "))", args
+	}
+	return "(" + strings.Join(parts, " "+n.op+" ") + ")", args
+}
+
+// likeParser parses the subset of FTS5 syntax that substring conditions
+// can express: terms, phrases, prefixes, column filters, AND, OR, NOT and
+// parentheses. As in FTS5, NOT binds tightest and OR loosest.
+type likeParser struct {
+	runes []rune
+	pos   int
+}
+
+// parseLikeExpression parses an FTS5 MATCH expression into a likeNode.
+func parseLikeExpression(query string) (*likeNode, error) {
+	p := &likeParser{runes: []rune(query)}
+	node, err := p.parseOr()
+	if err != nil {
+		return nil, err
+	}
+	if p.skipSpace(); p.pos < len(p.runes) {
+		return nil, p.errorf("unexpected %q", string(p.runes[p.pos]))
+	}
+	return node, nil
+}
+
+func (p *likeParser) errorf(format string, args ...interface{}) error {
+	return &QueryError{Pos: p.pos + 1, Msg: fmt.Sprintf(format, args...) + " in a query with short CJK terms"}
+}
+
+func (p *likeParser) skipSpace() {
+	for p.pos < len(p.runes) && unicode.IsSpace(p.runes[p.pos]) {
+		p.pos++
+	}
+}
+
+// keyword consumes word if it comes next as a whole word.
+func (p *likeParser) keyword(word string) bool {
+	p.skipSpace()
+	end := p.pos + len(word)
+	if end > len(p.runes) || string(p.runes[p.pos:end]) != word {
+		return false
+	}
+	if end < len(p.runes) && !unicode.IsSpace(p.runes[end]) && p.runes[end] != '(' && p.runes[end] != '"' {
+		return false
+	}
+	p.pos = end
+	return true
+}
+
+func (p *likeParser) parseOr() (*likeNode, error) {
+	return p.parseGroup("OR", p.parseAnd)
+}
+
+func (p *likeParser) parseAnd() (*likeNode, error) {
+	return p.parseGroup("AND", p.parseNot)
+}
+
+// parseGroup parses operands joined by op; AND may also be implicit.
+func (p *likeParser) parseGroup(op string, operand func() (*likeNode, error)) (*likeNode, error) {
+	first, err := operand()
+	if err != nil {
+		return nil, err
+	}
+	node := &likeNode{op: op, children: []*likeNode{first}}
+	for {
+		if !p.keyword(op) {
+			if op != "AND" || !p.startsOperand() {
+				break
+			}
+		}
+		next, err := operand()
+		if err != nil {
+			return nil, err
+		}
+		node.children = append(node.children, next)
+	}
+	if len(node.children) == 1 {
+		return first, nil
+	}
+	return node, nil
+}
+
+// startsOperand reports whether an operand, not an operator or the end of
+// a group, comes next.
+func (p *likeParser) startsOperand() bool {
+	p.skipSpace()
+	if p.pos >= len(p.runes) || p.runes[p.pos] == ')' {
+		return false
+	}
+	save := p.pos
+	defer func() { p.pos = save }()
+	return !p.keyword("OR") && !p.keyword("NOT")
+}
+
+func (p *likeParser) parseNot() (*likeNode, error) {
+	first, err := p.parsePrimary()
+	if err != nil {
+		return nil, err
+	}
+	node := &likeNode{op: "NOT", children: []*likeNode{first}}
+	for p.keyword("NOT") {
+		next, err := p.parsePrimary()
+		if err != nil {
+			return nil, err
+		}
+		node.children = append(node.children, next)
+	}
+	if len(node.children) == 1 {
+		return first, nil
+	}
+	return node, nil
+}
+
+func (p *likeParser) parsePrimary() (*likeNode, error) {
+	p.skipSpace()
+	if p.pos >= len(p.runes) {
+		return nil, p.errorf("missing term")
+	}
+
+	if p.runes[p.pos] == '(' {
+		p.pos++
+		node, err := p.parseOr()
+		if err != nil {
+			return nil, err
+		}
+		if p.skipSpace(); p.pos >= len(p.runes) || p.runes[p.pos] != ')' {
+			return nil, p.errorf("missing )")
+		}
+		p.pos++
+		return node, nil
+	}
+
+	// Column filter: "title : term" or "{title summary} : (expr)"
+	var columns []string
+	if p.runes[p.pos] == '{' {
+		end := p.pos
+		for end < len(p.runes) && p.runes[end] != '}' {
+			end++
+		}
+		if end == len(p.runes) {
+			return nil, p.errorf("missing }")
+		}
+		columns = strings.Fields(string(p.runes[p.pos+1 : end]))
+		p.pos = end + 1
+		if !p.consumeColon() {
+			return nil, p.errorf("missing : after column list")
+		}
+	} else if column, ok := p.columnPrefix(); ok {
+		columns = []string{column}
+	}
+	if columns != nil {
+		node, err := p.parsePrimary()
+		if err != nil {
+			return nil, err
+		}
+		restrictLikeColumns(node, columns)
+		return node, nil
+	}
+
+	term, err := p.parseTerm()
+	if err != nil {
+		return nil, err
+	}
+	return &likeNode{term: term}, nil
+}
+
+// columnPrefix consumes "column :" if it comes next.
+func (p *likeParser) columnPrefix() (string, bool) {
+	save := p.pos
+	start := p.pos
+	for p.pos < len(p.runes) && (unicode.IsLetter(p.runes[p.pos]) || p.runes[p.pos] == '_') {
+		p.pos++
+	}
+	column := string(p.runes[start:p.pos])
+	if column != "" && slices.Contains(SearchColumns, column) && p.consumeColon() {
+		return column, true
+	}
+	p.pos = save
+	return "", false
+}
+
+func (p *likeParser) consumeColon() bool {
+	p.skipSpace()
+	if p.pos < len(p.runes) && p.runes[p.pos] == ':' {
+		p.pos++
+		return true
+	}
+	return false
+}
+
+// parseTerm parses a phrase or bare word with an optional trailing *.
+func (p *likeParser) parseTerm() (string, error) {
+	var sb strings.Builder
+	if p.runes[p.pos] == '"' {
+		p.pos++
+		closed := false
+		for p.pos < len(p.runes) {
+			if p.runes[p.pos] == '"' {
+				// "" is an escaped quote inside a phrase
+				if p.pos+1 < len(p.runes) && p.runes[p.pos+1] == '"' {
+					sb.WriteRune('"')
+					p.pos += 2
+					continue
+				}
+				p.pos++
+				closed = true
+				break
+			}
+			sb.WriteRune(p.runes[p.pos])
+			p.pos++
+		}
+		if !closed {
+			return "", p.errorf("unterminated phrase")
+		}
+	} else {
+		for p.pos < len(p.runes) && !unicode.IsSpace(p.runes[p.pos]) && !strings.ContainsRune(`"(){}*:`, p.runes[p.pos]) {
+			if strings.ContainsRune(`^+-`, p.runes[p.pos]) {
+				return "", p.errorf("unsupported operator %q", string(p.runes[p.pos]))
+			}
+			sb.WriteRune(p.runes[p.pos])
+			p.pos++
+		}
+		if word := sb.String(); word == "NEAR" {
+			return "", p.errorf("unsupported operator NEAR")
+		}
+	}
+	if p.pos < len(p.runes) && p.runes[p.pos] == '*' {
+		p.pos++
+	}
+	term := strings.TrimSpace(sb.String())
+	if term == "" {
+		return "", p.errorf("missing term")
+	}
+	return term, nil
+}
+
+// restrictLikeColumns limits the terms of node to columns, keeping any
+// narrower restriction a term already has.
+func restrictLikeColumns(node *likeNode, columns []string) {
+	if node.op == "" {
+		if node.columns == nil {
+			node.columns = columns
+			return
+		}
+		var both []string
+		for _, column := range node.columns {
+			if slices.Contains(columns, column) {
+				both = append(both, column)
+			}
+		}
+		node.columns = append([]string{}, both...)
+		return
+	}
+	for _, child := range node.children {
+		restrictLikeColumns(child, columns)
+	}
+}
+
+// scanFTSTerms extracts the terms of an FTS5 MATCH expression.
+// conjunctive is false if the expression uses OR, NOT, grouping or column
+// filters, i.e. anything beyond "all of these terms".
+func scanFTSTerms(query string) (terms []string, conjunctive bool) {
+	conjunctive = true
+	runes := []rune(query)
+
+	for i := 0; i < len(runes); {
+		r := runes[i]
+		switch {
+		case unicode.IsSpace(r) || r == '*':
+			i++
+		case r == '"':
+			var sb strings.Builder
+			i++
+			for i < len(runes) {
+				if runes[i] == '"' {
+					// "" is an escaped quote inside a phrase
+					if i+1 < len(runes) && runes[i+1] == '"' {
+						sb.WriteRune('"')
+						i += 2
+						continue
+					}
+					i++
+					break
+				}
+				sb.WriteRune(runes[i])
+				i++
+			}
+			if term := strings.TrimSpace(sb.String()); term != "" {
+				terms = append(terms, term)
+			}
+		case r == '(' || r == ')' || r == ':' || r == '-' || r == '^' || r == '+':
+			conjunctive = false
+			i++
+		default:
+			start := i
+			for i < len(runes) && !unicode.IsSpace(runes[i]) && !strings.ContainsRune(`"()*:^+`, runes[i]) {
+				i++
+			}
+			word := string(runes[start:i])
+			switch word {
+			case "AND":
+			case "OR", "NOT", "NEAR":
+				conjunctive = false
+			default:
+				terms = append(terms, word)
+			}
+		}
+	}
+
+	return terms, conjunctive
+}
+
+// escapeLike escapes LIKE wildcards so a term matches literally.
+// Use with ESCAPE '\'.
+func escapeLike(s string) string {
+	return strings.NewReplacer(`\`, `\\`, `%`, `\%`, `_`, `\_`).Replace(s)
+}
@@ -0,0 +1,134 @@
+// Package db tests for CJK query routing.
+package db
+
+import (
+	"errors"
+	"reflect"
+	"testing"
+	"time"
+)
+
+// TestPlanTrigramSearch verifies which queries are routed to the trigram index.
+func TestPlanTrigramSearch(t *testing.T) {
+	term := func(text string, columns ...string) *likeNode {
+		return &likeNode{term: text, columns: columns}
+	}
+	tests := []struct {
+		name  string
+		query string
+		want  *trigramPlan
+	}{
+		{"latin query", `"golang"`, nil},
+		{"long CJK term", `"知識庫"`, &trigramPlan{Match: `"知識庫"`}},
+		{"long CJK with column", `title : "知識庫"`, &trigramPlan{Match: `title : "知識庫"`}},
+		{"long CJK with OR", `"知識庫" OR "筆記本"`, &trigramPlan{Match: `"知識庫" OR "筆記本"`}},
+		{"short CJK term", `"知識"`, &trigramPlan{Like: term("知識")}},
+		{"short CJK conjunction", `"知識" AND "個人"`,
+			&trigramPlan{Like: &likeNode{op: "AND", children: []*likeNode{term("知識"), term("個人")}}}},
+		{"unquoted short CJK", `知識 golang*`,
+			&trigramPlan{Like: &likeNode{op: "AND", children: []*likeNode{term("知識"), term("golang")}}}},
+		{"short CJK with OR", `"知識" OR "筆記"`,
+			&trigramPlan{Like: &likeNode{op: "OR", children: []*likeNode{term("知識"), term("筆記")}}}},
+		{"short CJK with NOT", `"知識庫" NOT ("草稿")`,
+			&trigramPlan{Like: &likeNode{op: "NOT", children: []*likeNode{term("知識庫"), term("草稿")}}}},
+		{"short CJK with column", `title : "知識"`, &trigramPlan{Like: term("知識", "title")}},
+		{"column set", `{title summary} : ("知識" OR summary : "筆記")`,
+			&trigramPlan{Like: &likeNode{op: "OR", children: []*likeNode{term("知識", "title", "summary"), term("筆記", "summary")}}}},
+		{"escaped quote", `"知""識"`, &trigramPlan{Match: `"知""識"`}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := planTrigramSearch(tt.query)
+			if err != nil {
+				t.Fatalf("planTrigramSearch(%q) error: %v", tt.query, err)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("planTrigramSearch(%q) = %+v, want %+v", tt.query, got, tt.want)
+			}
+		})
+	}
+
+	// Syntax substring conditions cannot express is an error, not zero results
+	for _, query := range []string{`"知識" NEAR "筆記"`, `^知識`, `("知識"`} {
+		var queryErr *QueryError
+		if _, err := planTrigramSearch(query); !errors.As(err, &queryErr) {
+			t.Errorf("planTrigramSearch(%q) error = %v, want a QueryError", query, err)
+		}
+	}
+}
+
+// TestSearch_CJKTrigram verifies CJK words inside longer runs are found.
+func TestSearch_CJKTrigram(t *testing.T) {
+	db := setupSearchTestDB(t)
+	defer db.Close()
+
+	repo := NewRepository(db)
+
+	now := time.Now().Unix()
+	insertTestContentItem(t, db, "我的個人知識庫", "整理筆記的方法", "web", "筆記", now)
+	insertTestContentItem(t, db, "知識管理入門", "個人知識管理的基本概念", "pdf", "知識", now-10)
+	insertTestContentItem(t, db, "Golang Guide", "Notes about 100% coverage_reports", "web", "golang", now-20)
+	insertTestContentItem(t, db, "プログラミング入門ガイド", "初心者向け", "web", "入門", now-30)
+
+	tests := []struct {
+		query string
+		want  int
+	}{
+		{"知識", 2},    // two characters: substring scan
+		{"知識庫", 1},   // trigram MATCH
+		{"個人知識", 2},  // trigram MATCH inside longer runs
+		{"知識 管理", 1}, // all short terms must match
+		{"title:知識庫", 1},
+		{"グラミング", 1},
+		{"知識 type:pdf", 1},
+		{"知識 -tag:知識", 1},
+		{"知識庫 OR 知識管理", 2},
+		{"title:知識", 2},
+		{"body:知識", 1},
+		{"知識 OR 入門", 3},
+		{"知識 -管理", 1},
+		{"(知識 OR 入門) 管理", 1},
+		{"不存在的詞", 0},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.query, func(t *testing.T) {
+			pq, err := ParseQuery(tt.query)
+			if err != nil {
+				t.Fatalf("ParseQuery error: %v", err)
+			}
+			opts := &SearchOptions{Limit: 10}
+			pq.Apply(opts)
+
+			resp, err := repo.Search(opts)
+			if err != nil {
+				t.Fatalf("Search failed: %v", err)
+			}
+			if resp.Total != tt.want || len(resp.Results) != tt.want {
+				t.Errorf("Expected %d results, got %d (total %d)", tt.want, len(resp.Results), resp.Total)
+			}
+		})
+	}
+
+	// Trigram hits are ranked, with the title hit first
+	resp, err := repo.SearchSimple(`"個人知識"`, 10)
+	if err != nil {
+		t.Fatalf("Search failed: %v", err)
+	}
+	if len(resp.Results) != 2 || resp.Results[0].Item.Title != "我的個人知識庫" {
+		t.Errorf("Expected title hit ranked first, got %+v", resp.Results)
+	}
+	for _, r := range resp.Results {
+		if r.Relevance <= 0 {
+			t.Errorf("Expected positive relevance for trigram hit %q, got %f", r.Item.Title, r.Relevance)
+		}
+	}
+}
+
+// TestEscapeLike verifies LIKE wildcards are escaped.
+func TestEscapeLike(t *testing.T) {
+	if got, want := escapeLike(`100%_a\b`), `100\%\_a\\b`; got != want {
+		t.Errorf("escapeLike() = %q, want %q", got, want)
+	}
+}
@@ -0,0 +1,97 @@
+// Package db provides facet counts for search results.
+package db
+
+import (
+	"database/sql"
+	"fmt"
+)
+
+// maxFacetValues caps the number of values returned for open-ended facets
+// (tags and source domains).
+const maxFacetValues = 20
+
+// FacetCount is the number of matching items sharing one facet value.
+type FacetCount struct {
+	Value string
+	Count int
+}
+
+// SearchFacets holds facet counts over every item matching a search,
+// not just the returned page. Values are sorted by descending count.
+type SearchFacets struct {
+	// MediaTypes counts items per media type
+	MediaTypes []FacetCount
+
+	// Tags counts items per tag (top maxFacetValues)
+	Tags []FacetCount
+
+	// Months counts items per creation month (YYYY-MM, UTC), newest first
+	Months []FacetCount
+
+	// Domains counts items per source URL host (top maxFacetValues)
+	Domains []FacetCount
+}
+
+// searchFacets computes facet counts for the items selected by fromWhere,
+// a "FROM ... WHERE ..." clause using the ci alias for content_items.
+// Runs inside the search transaction so counts match the result total.
+func searchFacets(tx *sql.Tx, fromWhere string, args []interface{}) (*SearchFacets, error) {
+	facets := &SearchFacets{}
+	var err error
+
+	facets.MediaTypes, err = groupFacet(tx,
+		"SELECT ci.media_type, COUNT(*)"+fromWhere+" GROUP BY ci.media_type ORDER BY COUNT(*) DESC, ci.media_type",
+		args)
+	if err != nil {
+		return nil, fmt.Errorf("failed to count media type facets: %w", err)
+	}
+
+	facets.Months, err = groupFacet(tx,
+		"SELECT strftime('%Y-%m', ci.created_at, 'unixepoch') AS month, COUNT(*)"+fromWhere+
+			" GROUP BY month ORDER BY month DESC",
+		args)
+	if err != nil {
+		return nil, fmt.Errorf("failed to count month facets: %w", err)
+	}
+
+	facets.Tags, err = groupFacet(tx, `
+		SELECT t.name, COUNT(*) FROM content_tags ct
+		INNER JOIN tags t ON t.id = ct.tag_id
+		WHERE t.is_deleted = 0 AND ct.content_id IN (SELECT ci.id`+fromWhere+`)
+		GROUP BY t.id ORDER BY COUNT(*) DESC, t.name LIMIT ?`,
+		append(append([]interface{}{}, args...), maxFacetValues))
+	if err != nil {
+		return nil, fmt.Errorf("failed to count tag facets: %w", err)
+	}
+
+	facets.Domains, err = groupFacet(tx,
+		"SELECT ci.source_domain, COUNT(*)"+fromWhere+
+			" AND ci.source_domain IS NOT NULL AND ci.source_domain != ''"+
+			" GROUP BY ci.source_domain ORDER BY COUNT(*) DESC, ci.source_domain LIMIT ?",
+		append(append([]interface{}{}, args...), maxFacetValues))
+	if err != nil {
+		return nil, fmt.Errorf("failed to count domain facets: %w", err)
+	}
+	return facets, nil
+}
+
+// groupFacet runs a "SELECT value, COUNT(*) ... GROUP BY" query.
+func groupFacet(tx *sql.Tx, query string, args []interface{}) ([]FacetCount, error) {
+	rows, err := tx.Query(query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	counts := make([]FacetCount, 0)
+	for rows.Next() {
+		var value sql.NullString
+		var fc FacetCount
+		if err := rows.Scan(&value, &fc.Count); err != nil {
+			return nil, err
+		}
+		fc.Value = value.String
+		counts = append(counts, fc)
+	}
+	return counts, rows.Err()
+}
@@ -0,0 +1,77 @@
+// Package db tests for search facets.
+package db
+
+import (
+	"reflect"
+	"testing"
+	"time"
+)
+
+// TestSearch_facets verifies facet counts cover all matches, not just the page.
+func TestSearch_facets(t *testing.T) {
+	db := setupSearchTestDB(t)
+	defer db.Close()
+
+	repo := NewRepository(db)
+
+	jan := time.Date(2025, 1, 15, 0, 0, 0, 0, time.UTC).Unix()
+	feb := time.Date(2025, 2, 15, 0, 0, 0, 0, time.UTC).Unix()
+	id1 := insertTestContentItem(t, db, "Golang Concurrency", "golang notes", "web", "golang,concurrency", jan)
+	id2 := insertTestContentItem(t, db, "Golang Generics", "golang notes", "web", "golang", feb)
+	insertTestContentItem(t, db, "Golang Paper", "golang notes", "pdf", "golang,research,golang", feb)
+	insertTestContentItem(t, db, "Rust Notes", "rust notes", "web", "rust", feb)
+
+	for id, u := range map[string]string{
+		id1: "https://www.Example.com/posts/1",
+		id2: "https://go.dev/blog/generics",
+	} {
+		if _, err := db.Exec(`UPDATE content_items SET source_url = ? WHERE id = ?`, u, id); err != nil {
+			t.Fatalf("Failed to set source_url: %v", err)
+		}
+	}
+
+	resp, err := repo.Search(&SearchOptions{Query: "golang", Limit: 1, Facets: true})
+	if err != nil {
+		t.Fatalf("Search failed: %v", err)
+	}
+	if resp.Total != 3 || len(resp.Results) != 1 {
+		t.Fatalf("Expected 1 of 3 results, got %d of %d", len(resp.Results), resp.Total)
+	}
+	if resp.Facets == nil {
+		t.Fatal("Expected facets")
+	}
+
+	checks := []struct {
+		name string
+		got  []FacetCount
+		want []FacetCount
+	}{
+		{"media types", resp.Facets.MediaTypes, []FacetCount{{"web", 2}, {"pdf", 1}}},
+		{"tags", resp.Facets.Tags, []FacetCount{{"golang", 3}, {"concurrency", 1}, {"research", 1}}},
+		{"months", resp.Facets.Months, []FacetCount{{"2025-02", 2}, {"2025-01", 1}}},
+		{"domains", resp.Facets.Domains, []FacetCount{{"example.com", 1}, {"go.dev", 1}}},
+	}
+	for _, c := range checks {
+		if !reflect.DeepEqual(c.got, c.want) {
+			t.Errorf("%s facet = %v, want %v", c.name, c.got, c.want)
+		}
+	}
+
+	// Facets follow the filters applied to the search
+	resp, err = repo.Search(&SearchOptions{Query: "golang", MediaType: "pdf", Facets: true})
+	if err != nil {
+		t.Fatalf("Search failed: %v", err)
+	}
+	if want := []FacetCount{{"pdf", 1}}; !reflect.DeepEqual(resp.Facets.MediaTypes, want) {
+		t.Errorf("Filtered media type facet = %v, want %v", resp.Facets.MediaTypes, want)
+	}
+
+	// Facets are omitted unless requested
+	resp, err = repo.SearchSimple("golang", 10)
+	if err != nil {
+		t.Fatalf("Search failed: %v", err)
+	}
+	if resp.Facets != nil {
+		t.Error("Expected no facets by default")
+	}
+}
@@ -0,0 +1,252 @@
+// Package db provides search history persistence.
+package db
+
+import (
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"time"
+
+	"github.com/kimhsiao/memonexus/backend/internal/models"
+	"github.com/kimhsiao/memonexus/backend/internal/uuid"
+)
+
+// maxSearchHistory is how many history entries are kept; older entries are
+// removed as new searches are recorded.
+const maxSearchHistory = 1000
+
+// settingSearchHistorySync is the app_settings key for the history sync opt-in.
+const settingSearchHistorySync = "search_history.sync"
+
+// searchHistoryColumns lists the search_history columns read by scanSearchHistory.
+const searchHistoryColumns = `id, query, filters, result_count, clicked_content_id, is_deleted,
+	created_at, updated_at, version`
+
+// scanSearchHistory scans one row selected with searchHistoryColumns.
+func scanSearchHistory(row rowScanner) (*models.SearchHistoryEntry, error) {
+	var e models.SearchHistoryEntry
+	var filters string
+	var clicked sql.NullString
+	err := row.Scan(&e.ID, &e.Query, &filters, &e.ResultCount, &clicked, &e.IsDeleted,
+		&e.CreatedAt, &e.UpdatedAt, &e.Version)
+	if err != nil {
+		return nil, err
+	}
+	e.ClickedContentID = clicked.String
+	if err := json.Unmarshal([]byte(filters), &e.Filters); err != nil {
+		return nil, fmt.Errorf("invalid filters for search history %s: %w", e.ID, err)
+	}
+	return &e, nil
+}
+
+// RecordSearch adds a search to the history and trims the history to
+// maxSearchHistory entries.
+func (r *Repository) RecordSearch(entry *models.SearchHistoryEntry) error {
+	now := time.Now().Unix()
+	entry.ID = models.UUID(uuid.New())
+	entry.CreatedAt = now
+	entry.UpdatedAt = now
+	entry.Version = 1
+
+	filters, err := json.Marshal(entry.Filters)
+	if err != nil {
+		return fmt.Errorf("failed to encode filters: %w", err)
+	}
+
+	query := `
+	INSERT INTO search_history (id, query, filters, result_count, clicked_content_id, is_deleted,
+		created_at, updated_at, version)
+	VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?)
+	`
+	_, err = r.db.Exec(query, entry.ID, entry.Query, string(filters), entry.ResultCount,
+		sql.NullString{String: entry.ClickedContentID, Valid: entry.ClickedContentID != ""},
+		entry.IsDeleted, entry.CreatedAt, entry.UpdatedAt, entry.Version)
+	if err != nil {
+		return err
+	}
+
+	// Drop the oldest entries beyond the cap, in insertion order
+	_, err = r.db.Exec(`
+	DELETE FROM search_history WHERE rowid <= (
+		SELECT rowid FROM search_history ORDER BY rowid DESC LIMIT 1 OFFSET ?
+	)
+	`, maxSearchHistory)
+	return err
+}
+
+// ListSearchHistory returns history entries, most recent first.
+func (r *Repository) ListSearchHistory(limit int) ([]*models.SearchHistoryEntry, error) {
+	if limit <= 0 {
+		limit = 20
+	}
+	query := `SELECT ` + searchHistoryColumns + ` FROM search_history
+	WHERE is_deleted = 0 ORDER BY created_at DESC, rowid DESC LIMIT ?`
+	return r.querySearchHistory(query, limit)
+}
+
+// querySearchHistory runs a query selecting searchHistoryColumns.
+func (r *Repository) querySearchHistory(query string, args ...interface{}) ([]*models.SearchHistoryEntry, error) {
+	rows, err := r.db.Query(query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	entries := make([]*models.SearchHistoryEntry, 0)
+	for rows.Next() {
+		e, err := scanSearchHistory(rows)
+		if err != nil {
+			return nil, err
+		}
+		entries = append(entries, e)
+	}
+	return entries, rows.Err()
+}
+
+// RecentSearchQueries returns distinct queries from the history starting
+// with prefix (case-insensitive), most recently used first.
+func (r *Repository) RecentSearchQueries(prefix string, limit int) ([]string, error) {
+	query := `
+	SELECT query FROM search_history
+	WHERE is_deleted = 0 AND query LIKE ? ESCAPE '\'
+	GROUP BY lower(query)
+	ORDER BY MAX(created_at) DESC, MAX(rowid) DESC
+	LIMIT ?
+	`
+	rows, err := r.db.Query(query, escapeLike(prefix)+"%", limit)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var queries []string
+	for rows.Next() {
+		var q string
+		if err := rows.Scan(&q); err != nil {
+			return nil, err
+		}
+		queries = append(queries, q)
+	}
+	return queries, rows.Err()
+}
+
+// RecordSearchClick records the result opened from a history entry.
+// Returns sql.ErrNoRows if the entry does not exist or was deleted.
+func (r *Repository) RecordSearchClick(id, contentID string) error {
+	query := `
+	UPDATE search_history SET clicked_content_id = ?, updated_at = ?, version = version + 1
+	WHERE id = ? AND is_deleted = 0
+	`
+	return execAffectingRow(r.db.Exec(query, contentID, time.Now().Unix(), id))
+}
+
+// DeleteSearchHistoryEntry soft deletes a history entry.
+// Returns sql.ErrNoRows if it does not exist or was already deleted.
+func (r *Repository) DeleteSearchHistoryEntry(id string) error {
+	query := `
+	UPDATE search_history SET is_deleted = 1, updated_at = ?, version = version + 1
+	WHERE id = ? AND is_deleted = 0
+	`
+	return execAffectingRow(r.db.Exec(query, time.Now().Unix(), id))
+}
+
+// ClearSearchHistory soft deletes every history entry and returns how many
+// were removed. Entries are kept as tombstones so the clear syncs when
+// history sync is enabled.
+func (r *Repository) ClearSearchHistory() (int, error) {
+	query := `
+	UPDATE search_history SET is_deleted = 1, updated_at = ?, version = version + 1
+	WHERE is_deleted = 0
+	`
+	result, err := r.db.Exec(query, time.Now().Unix())
+	if err != nil {
+		return 0, err
+	}
+	rows, err := result.RowsAffected()
+	return int(rows), err
+}
+
+// execAffectingRow checks that an Exec result changed at least one row,
+// returning sql.ErrNoRows if it did not.
+func execAffectingRow(result sql.Result, err error) error {
+	if err != nil {
+		return err
+	}
+	rows, err := result.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if rows == 0 {
+		return sql.ErrNoRows
+	}
+	return nil
+}
+
+// =====================================================
+// Search History Sync
+// =====================================================
+
+// SearchHistorySyncEnabled reports whether the user opted in to syncing
+// search history. Defaults to false.
+func (r *Repository) SearchHistorySyncEnabled() (bool, error) {
+	var value string
+	err := r.db.QueryRow(`SELECT value FROM app_settings WHERE key = ?`, settingSearchHistorySync).Scan(&value)
+	if err == sql.ErrNoRows {
+		return false, nil
+	}
+	if err != nil {
+		return false, err
+	}
+	return strconv.ParseBool(value)
+}
+
+// SetSearchHistorySyncEnabled stores the search history sync opt-in.
+func (r *Repository) SetSearchHistorySyncEnabled(enabled bool) error {
+	query := `
+	INSERT INTO app_settings (key, value, updated_at) VALUES (?, ?, ?)
+	ON CONFLICT(key) DO UPDATE SET value = excluded.value, updated_at = excluded.updated_at
+	`
+	_, err := r.db.Exec(query, settingSearchHistorySync, strconv.FormatBool(enabled), time.Now().Unix())
+	return err
+}
+
+// ListSearchHistoryForSync returns every history entry, including deleted
+// ones, so deletions propagate to other devices.
+func (r *Repository) ListSearchHistoryForSync() ([]*models.SearchHistoryEntry, error) {
+	query := `SELECT ` + searchHistoryColumns + ` FROM search_history ORDER BY id`
+	return r.querySearchHistory(query)
+}
+
+// ApplyRemoteSearchHistory stores a history entry received from another
+// device if it is new or has a higher version than the local copy.
+// Returns true if the local copy changed.
+func (r *Repository) ApplyRemoteSearchHistory(entry *models.SearchHistoryEntry) (bool, error) {
+	filters, err := json.Marshal(entry.Filters)
+	if err != nil {
+		return false, fmt.Errorf("failed to encode filters: %w", err)
+	}
+
+	query := `
+	INSERT INTO search_history (id, query, filters, result_count, clicked_content_id, is_deleted,
+		created_at, updated_at, version)
+	VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?)
+	ON CONFLICT(id) DO UPDATE SET
+		query = excluded.query, filters = excluded.filters,
+		result_count = excluded.result_count, clicked_content_id = excluded.clicked_content_id,
+		is_deleted = excluded.is_deleted, updated_at = excluded.updated_at,
+		version = excluded.version
+	WHERE excluded.version > search_history.version
+	`
+	result, err := r.db.Exec(query, entry.ID, entry.Query, string(filters), entry.ResultCount,
+		sql.NullString{String: entry.ClickedContentID, Valid: entry.ClickedContentID != ""},
+		entry.IsDeleted, entry.CreatedAt, entry.UpdatedAt, entry.Version)
+	if err != nil {
+		return false, err
+	}
+	rows, err := result.RowsAffected()
+	if err != nil {
+		return false, err
+	}
+	return rows > 0, nil
+}
@@ -0,0 +1,162 @@
+// Package db tests for search history.
+package db
+
+import (
+	"database/sql"
+	"reflect"
+	"testing"
+
+	"github.com/kimhsiao/memonexus/backend/internal/models"
+	"github.com/kimhsiao/memonexus/backend/internal/uuid"
+)
+
+// TestSearchHistory verifies recording, listing, clicks and deletion.
+func TestSearchHistory(t *testing.T) {
+	db := setupMigratedTestDB(t)
+	defer db.Close()
+
+	repo := NewRepository(db)
+	entries := []*models.SearchHistoryEntry{
+		{Query: "golang", ResultCount: 3, Filters: models.SearchFilters{MediaType: "web"}},
+		{Query: "rust", ResultCount: 1},
+		{Query: "GoLang", ResultCount: 2},
+	}
+	for _, e := range entries {
+		if err := repo.RecordSearch(e); err != nil {
+			t.Fatalf("RecordSearch failed: %v", err)
+		}
+	}
+
+	list, err := repo.ListSearchHistory(10)
+	if err != nil || len(list) != 3 {
+		t.Fatalf("ListSearchHistory = %v, %v", list, err)
+	}
+	if list[0].Query != "GoLang" || list[2].Filters.MediaType != "web" || list[2].ResultCount != 3 {
+		t.Errorf("Unexpected history order or fields: %+v", list)
+	}
+
+	// Queries are distinct case-insensitively, most recent first
+	recent, err := repo.RecentSearchQueries("go", 10)
+	if err != nil {
+		t.Fatalf("RecentSearchQueries failed: %v", err)
+	}
+	if want := []string{"GoLang"}; !reflect.DeepEqual(recent, want) {
+		t.Errorf("RecentSearchQueries = %v, want %v", recent, want)
+	}
+
+	if err := repo.RecordSearchClick(string(entries[1].ID), "00000000-0000-4000-8000-000000000001"); err != nil {
+		t.Fatalf("RecordSearchClick failed: %v", err)
+	}
+	list, _ = repo.ListSearchHistory(10)
+	if list[1].ClickedContentID == "" || list[1].Version != 2 {
+		t.Errorf("Click not recorded: %+v", list[1])
+	}
+
+	if err := repo.DeleteSearchHistoryEntry(string(entries[0].ID)); err != nil {
+		t.Fatalf("DeleteSearchHistoryEntry failed: %v", err)
+	}
+	if err := repo.DeleteSearchHistoryEntry(string(entries[0].ID)); err != sql.ErrNoRows {
+		t.Errorf("Second delete = %v, want sql.ErrNoRows", err)
+	}
+	if err := repo.RecordSearchClick(string(entries[0].ID), "x"); err != sql.ErrNoRows {
+		t.Errorf("Click on deleted entry = %v, want sql.ErrNoRows", err)
+	}
+
+	cleared, err := repo.ClearSearchHistory()
+	if err != nil || cleared != 2 {
+		t.Errorf("ClearSearchHistory = %d, %v; want 2", cleared, err)
+	}
+	if list, _ := repo.ListSearchHistory(10); len(list) != 0 {
+		t.Errorf("Expected empty history after clear, got %d entries", len(list))
+	}
+
+	// Tombstones are kept for sync
+	synced, err := repo.ListSearchHistoryForSync()
+	if err != nil || len(synced) != 3 {
+		t.Errorf("ListSearchHistoryForSync = %d entries, %v; want 3", len(synced), err)
+	}
+}
+
+// TestSearchHistory_trim verifies the history is capped at maxSearchHistory.
+func TestSearchHistory_trim(t *testing.T) {
+	db := setupMigratedTestDB(t)
+	defer db.Close()
+
+	// Fill the history, then record past the cap
+	tx, err := db.Begin()
+	if err != nil {
+		t.Fatalf("Begin failed: %v", err)
+	}
+	for i := 0; i < maxSearchHistory; i++ {
+		_, err := tx.Exec(`INSERT INTO search_history (id, query, created_at, updated_at)
+			VALUES (?, 'old', 1, 1)`, string(models.UUID(uuid.New())))
+		if err != nil {
+			t.Fatalf("insert failed: %v", err)
+		}
+	}
+	if err := tx.Commit(); err != nil {
+		t.Fatalf("Commit failed: %v", err)
+	}
+
+	repo := NewRepository(db)
+	for i := 0; i < 5; i++ {
+		if err := repo.RecordSearch(&models.SearchHistoryEntry{Query: "new"}); err != nil {
+			t.Fatalf("RecordSearch failed: %v", err)
+		}
+	}
+
+	var count int
+	if err := db.QueryRow(`SELECT COUNT(*) FROM search_history`).Scan(&count); err != nil {
+		t.Fatalf("count failed: %v", err)
+	}
+	if count != maxSearchHistory {
+		t.Errorf("History has %d entries, want %d", count, maxSearchHistory)
+	}
+	if recent, _ := repo.ListSearchHistory(5); len(recent) != 5 || recent[4].Query != "new" {
+		t.Error("Expected the oldest entries to be dropped")
+	}
+}
+
+// TestSearchHistorySyncEnabled verifies the opt-in defaults to off.
+func TestSearchHistorySyncEnabled(t *testing.T) {
+	db := setupMigratedTestDB(t)
+	defer db.Close()
+
+	repo := NewRepository(db)
+	if enabled, err := repo.SearchHistorySyncEnabled(); err != nil || enabled {
+		t.Errorf("Default = %v, %v; want false", enabled, err)
+	}
+	for _, want := range []bool{true, false} {
+		if err := repo.SetSearchHistorySyncEnabled(want); err != nil {
+			t.Fatalf("SetSearchHistorySyncEnabled failed: %v", err)
+		}
+		if enabled, err := repo.SearchHistorySyncEnabled(); err != nil || enabled != want {
+			t.Errorf("SearchHistorySyncEnabled = %v, %v; want %v", enabled, err, want)
+		}
+	}
+}
+
+// TestApplyRemoteSearchHistory verifies the higher version wins.
+func TestApplyRemoteSearchHistory(t *testing.T) {
+	db := setupMigratedTestDB(t)
+	defer db.Close()
+
+	repo := NewRepository(db)
+	entry := &models.SearchHistoryEntry{Query: "golang"}
+	if err := repo.RecordSearch(entry); err != nil {
+		t.Fatalf("RecordSearch failed: %v", err)
+	}
+
+	remote := *entry
+	remote.IsDeleted = true
+	if applied, err := repo.ApplyRemoteSearchHistory(&remote); err != nil || applied {
+		t.Errorf("Same version applied = %v, %v; want false", applied, err)
+	}
+	remote.Version = 2
+	if applied, err := repo.ApplyRemoteSearchHistory(&remote); err != nil || !applied {
+		t.Errorf("Newer version applied = %v, %v; want true", applied, err)
+	}
+	if list, _ := repo.ListSearchHistory(10); len(list) != 0 {
+		t.Errorf("Remote deletion not applied: %+v", list)
+	}
+}
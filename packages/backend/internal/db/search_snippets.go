@@ -0,0 +1,306 @@
+// Package db provides FTS5-native snippets and highlight offsets for search results.
+package db
+
+import (
+	"database/sql"
+	"fmt"
+	"html"
+	"strings"
+	"unicode/utf8"
+)
+
+// Private markers passed to snippet() and highlight(). They are control
+// characters, so they never collide with HTML escaping and are swapped for
+// the caller's markers afterwards.
+const (
+	markOpen     = "\x01"
+	markClose    = "\x02"
+	markEllipsis = "\x03"
+)
+
+// maxSnippetTokens is the largest window FTS5 snippet() accepts.
+const maxSnippetTokens = 64
+
+// HighlightSpan is one match inside a column, as rune offsets into the full
+// column text: Start is inclusive, End is exclusive.
+type HighlightSpan struct {
+	Start int
+	End   int
+}
+
+// ColumnHighlight holds the highlighting for one matched column.
+type ColumnHighlight struct {
+	// Snippet is a window of the column around the best match, HTML-escaped,
+	// with matches wrapped in HighlightOptions.TagOpen/TagClose
+	Snippet string
+
+	// Spans locates every match in the full column text
+	Spans []HighlightSpan
+}
+
+// highlightTarget is a search result awaiting highlights, keyed by rowid.
+type highlightTarget struct {
+	rowid  int64
+	result *SearchResult
+}
+
+// highlightSource is an FTS table and the MATCH expression that found results in it.
+type highlightSource struct {
+	table string
+	match string
+}
+
+// attachHighlights fills in Highlights and MatchedTerms for a page of
+// results. Highlights come from FTS5 highlight()/snippet() over each source
+// in turn, so prefix and phrase queries mark exactly the tokens FTS5
+// matched. Results no source matched (substring hits for short CJK terms)
+// fall back to regex highlighting of the terms in query within columns.
+func attachHighlights(tx *sql.Tx, targets []highlightTarget, sources []highlightSource, query string, columns []string, opts *HighlightOptions) error {
+	opts = opts.withDefaults()
+
+	pending := targets
+	for _, source := range sources {
+		if len(pending) == 0 {
+			break
+		}
+		var err error
+		pending, err = ftsHighlights(tx, source.table, source.match, pending, opts)
+		if err != nil {
+			return err
+		}
+	}
+
+	if len(pending) == 0 {
+		return nil
+	}
+	domains, err := sourceDomains(tx, pending)
+	if err != nil {
+		return err
+	}
+	terms, _ := scanFTSTerms(query)
+	for _, t := range pending {
+		regexHighlights(t.result, domains[t.rowid], terms, columns, opts)
+	}
+	return nil
+}
+
+// sourceDomains returns the source_domain column of the target rows, which
+// the content item model does not carry.
+func sourceDomains(tx *sql.Tx, targets []highlightTarget) (map[int64]string, error) {
+	placeholders := make([]string, len(targets))
+	args := make([]interface{}, len(targets))
+	for i, t := range targets {
+		placeholders[i] = "?"
+		args[i] = t.rowid
+	}
+
+	rows, err := tx.Query(`SELECT rowid, source_domain FROM content_items WHERE rowid IN (`+
+		strings.Join(placeholders, ", ")+`)`, args...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load source domains: %w", err)
+	}
+	defer rows.Close()
+
+	domains := make(map[int64]string, len(targets))
+	for rows.Next() {
+		var rowid int64
+		var domain sql.NullString
+		if err := rows.Scan(&rowid, &domain); err != nil {
+			return nil, fmt.Errorf("failed to scan source domain: %w", err)
+		}
+		domains[rowid] = domain.String
+	}
+	return domains, rows.Err()
+}
+
+// ftsHighlights runs highlight() and snippet() over table for the target
+// rows and returns the targets the table did not match.
+func ftsHighlights(tx *sql.Tx, table, match string, targets []highlightTarget, opts *HighlightOptions) ([]highlightTarget, error) {
+	tokens := min(opts.ContextTokens, maxSnippetTokens)
+
+	selects := make([]string, 0, 2*len(SearchColumns))
+	for i := range SearchColumns {
+		selects = append(selects,
+			fmt.Sprintf("highlight(%s, %d, ?, ?)", table, i),
+			fmt.Sprintf("snippet(%s, %d, ?, ?, ?, ?)", table, i))
+	}
+	var args []interface{}
+	for range SearchColumns {
+		args = append(args, markOpen, markClose, markOpen, markClose, markEllipsis, tokens)
+	}
+
+	byRowID := make(map[int64]*SearchResult, len(targets))
+	placeholders := make([]string, len(targets))
+	args = append(args, match)
+	for i, t := range targets {
+		byRowID[t.rowid] = t.result
+		placeholders[i] = "?"
+		args = append(args, t.rowid)
+	}
+
+	query := fmt.Sprintf(`SELECT rowid, %[2]s FROM %[1]s WHERE %[1]s MATCH ? AND rowid IN (%[3]s)`,
+		table, strings.Join(selects, ", "), strings.Join(placeholders, ", "))
+	rows, err := tx.Query(query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to highlight search results: %w", err)
+	}
+	defer rows.Close()
+
+	done := make(map[int64]bool, len(targets))
+	for rows.Next() {
+		var rowid int64
+		values := make([]sql.NullString, 2*len(SearchColumns))
+		dest := []interface{}{&rowid}
+		for i := range values {
+			dest = append(dest, &values[i])
+		}
+		if err := rows.Scan(dest...); err != nil {
+			return nil, fmt.Errorf("failed to scan highlights: %w", err)
+		}
+
+		result := byRowID[rowid]
+		for i, column := range SearchColumns {
+			spans := parseHighlight(values[2*i].String)
+			if len(spans) == 0 {
+				continue
+			}
+			addHighlight(result, column, &ColumnHighlight{
+				Snippet: renderSnippet(values[2*i+1].String, opts),
+				Spans:   spans,
+			}, values[2*i].String)
+		}
+		done[rowid] = len(result.Highlights) > 0
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating highlights: %w", err)
+	}
+
+	var pending []highlightTarget
+	for _, t := range targets {
+		if !done[t.rowid] {
+			pending = append(pending, t)
+		}
+	}
+	return pending, nil
+}
+
+// regexHighlights highlights terms in the given columns with HighlightInText.
+// domain is the item's source_domain column.
+func regexHighlights(result *SearchResult, domain string, terms, columns []string, opts *HighlightOptions) {
+	if len(terms) == 0 {
+		return
+	}
+	pattern, err := buildHighlightPattern(terms)
+	if err != nil {
+		return
+	}
+	query := strings.Join(terms, " ")
+
+	item := result.Item
+	texts := map[string]string{
+		"title":         item.Title,
+		"content_text":  item.ContentText,
+		"tags":          item.Tags,
+		"summary":       item.Summary,
+		"source_domain": domain,
+	}
+	for _, column := range columns {
+		text := texts[column]
+		locs := pattern.FindAllStringIndex(text, -1)
+		if len(locs) == 0 {
+			continue
+		}
+
+		var marked strings.Builder
+		spans := make([]HighlightSpan, len(locs))
+		prev := 0
+		for j, loc := range locs {
+			spans[j] = HighlightSpan{
+				Start: utf8.RuneCountInString(text[:loc[0]]),
+				End:   utf8.RuneCountInString(text[:loc[1]]),
+			}
+			marked.WriteString(text[prev:loc[0]] + markOpen + text[loc[0]:loc[1]] + markClose)
+			prev = loc[1]
+		}
+		marked.WriteString(text[prev:])
+
+		snippet, err := HighlightInText(text, query, opts)
+		if err != nil {
+			continue
+		}
+		addHighlight(result, column, &ColumnHighlight{Snippet: snippet.Text, Spans: spans}, marked.String())
+	}
+}
+
+// addHighlight records a column highlight and the distinct terms it matched.
+// marked is the full column text with matches wrapped in private markers.
+func addHighlight(result *SearchResult, column string, h *ColumnHighlight, marked string) {
+	if result.Highlights == nil {
+		result.Highlights = make(map[string]*ColumnHighlight)
+	}
+	result.Highlights[column] = h
+
+	seen := make(map[string]bool, len(result.MatchedTerms))
+	for _, term := range result.MatchedTerms {
+		seen[strings.ToLower(term)] = true
+	}
+	for _, part := range strings.Split(marked, markOpen)[1:] {
+		term, _, _ := strings.Cut(part, markClose)
+		if key := strings.ToLower(term); term != "" && !seen[key] {
+			seen[key] = true
+			result.MatchedTerms = append(result.MatchedTerms, term)
+		}
+	}
+}
+
+// parseHighlight returns the rune offsets of each match marked in
+// highlight() output, measured in the text without the markers.
+func parseHighlight(marked string) []HighlightSpan {
+	var spans []HighlightSpan
+	pos := 0
+	for _, r := range marked {
+		switch string(r) {
+		case markOpen:
+			spans = append(spans, HighlightSpan{Start: pos})
+		case markClose:
+			if n := len(spans); n > 0 {
+				spans[n-1].End = pos
+			}
+		default:
+			pos++
+		}
+	}
+	return spans
+}
+
+// renderSnippet HTML-escapes snippet() output and swaps the private markers
+// for the configured tags and ellipsis.
+func renderSnippet(raw string, opts *HighlightOptions) string {
+	return strings.NewReplacer(
+		markOpen, opts.TagOpen,
+		markClose, opts.TagClose,
+		markEllipsis, opts.Ellipsis,
+	).Replace(html.EscapeString(raw))
+}
+
+// withDefaults returns a copy of the options with unset fields defaulted.
+func (o *HighlightOptions) withDefaults() *HighlightOptions {
+	d := DefaultHighlightOptions()
+	if o == nil {
+		return d
+	}
+	opts := *o
+	if opts.MaxChars <= 0 {
+		opts.MaxChars = d.MaxChars
+	}
+	if opts.TagOpen == "" && opts.TagClose == "" {
+		opts.TagOpen, opts.TagClose = d.TagOpen, d.TagClose
+	}
+	if opts.Ellipsis == "" {
+		opts.Ellipsis = d.Ellipsis
+	}
+	if opts.ContextTokens <= 0 {
+		opts.ContextTokens = d.ContextTokens
+	}
+	return &opts
+}
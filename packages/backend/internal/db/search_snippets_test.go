@@ -0,0 +1,143 @@
+// Package db tests for FTS5 snippets and highlight offsets.
+package db
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+)
+
+// TestParseHighlight verifies marker offsets are measured in runes.
+func TestParseHighlight(t *testing.T) {
+	marked := "我的" + markOpen + "個人知識" + markClose + "庫 and " + markOpen + "go" + markClose
+	want := []HighlightSpan{{Start: 2, End: 6}, {Start: 12, End: 14}}
+	if got := parseHighlight(marked); !reflect.DeepEqual(got, want) {
+		t.Errorf("parseHighlight() = %v, want %v", got, want)
+	}
+	if got := parseHighlight("no matches"); got != nil {
+		t.Errorf("parseHighlight() = %v, want nil", got)
+	}
+}
+
+// TestSearch_highlights verifies snippets follow FTS5 tokenization.
+func TestSearch_highlights(t *testing.T) {
+	db := setupSearchTestDB(t)
+	defer db.Close()
+
+	repo := NewRepository(db)
+
+	now := time.Now().Unix()
+	insertTestContentItem(t, db, "Programming in Go", "Writing programs <b>safely</b>. Progress is slow.", "web", "golang", now)
+	insertTestContentItem(t, db, "Phrase Test", "an exact match and an exact phrase here", "web", "misc", now-1)
+	long := strings.Repeat("filler ", 50) + "needle" + strings.Repeat(" filler", 50)
+	insertTestContentItem(t, db, "Long", long, "web", "misc", now-2)
+	insertTestContentItem(t, db, "我的個人知識庫", "整理筆記的方法", "web", "筆記", now-3)
+	insertTestContentItem(t, db, "知識管理", "個人知識管理的基本概念", "web", "知識", now-4)
+
+	search := func(t *testing.T, query string, hl *HighlightOptions) *SearchResponse {
+		t.Helper()
+		pq, err := ParseQuery(query)
+		if err != nil {
+			t.Fatalf("ParseQuery error: %v", err)
+		}
+		opts := &SearchOptions{Limit: 10, Highlight: hl}
+		pq.Apply(opts)
+		resp, err := repo.Search(opts)
+		if err != nil {
+			t.Fatalf("Search failed: %v", err)
+		}
+		return resp
+	}
+
+	t.Run("Prefix", func(t *testing.T) {
+		resp := search(t, "prog*", DefaultHighlightOptions())
+		if len(resp.Results) != 1 {
+			t.Fatalf("Expected 1 result, got %d", len(resp.Results))
+		}
+		r := resp.Results[0]
+
+		title := r.Highlights["title"]
+		if title == nil || title.Snippet != "<mark>Programming</mark> in Go" {
+			t.Fatalf("Unexpected title highlight: %+v", title)
+		}
+		if want := []HighlightSpan{{0, 11}}; !reflect.DeepEqual(title.Spans, want) {
+			t.Errorf("Title spans = %v, want %v", title.Spans, want)
+		}
+
+		// Matches are marked as tokenized, and the text is HTML-escaped
+		body := r.Highlights["content_text"]
+		want := "Writing <mark>programs</mark> &lt;b&gt;safely&lt;/b&gt;. <mark>Progress</mark> is slow."
+		if body == nil || body.Snippet != want {
+			t.Fatalf("Body snippet = %+v, want %q", body, want)
+		}
+		if _, ok := r.Highlights["tags"]; ok {
+			t.Error("Unmatched column should have no highlight")
+		}
+		if want := []string{"Programming", "programs", "Progress"}; !reflect.DeepEqual(r.MatchedTerms, want) {
+			t.Errorf("MatchedTerms = %v, want %v", r.MatchedTerms, want)
+		}
+	})
+
+	t.Run("Phrase", func(t *testing.T) {
+		resp := search(t, `"exact phrase"`, DefaultHighlightOptions())
+		if len(resp.Results) != 1 {
+			t.Fatalf("Expected 1 result, got %d", len(resp.Results))
+		}
+		body := resp.Results[0].Highlights["content_text"]
+		if want := "an exact match and an <mark>exact phrase</mark> here"; body.Snippet != want {
+			t.Errorf("Snippet = %q, want %q", body.Snippet, want)
+		}
+		if want := []HighlightSpan{{22, 34}}; !reflect.DeepEqual(body.Spans, want) {
+			t.Errorf("Spans = %v, want %v", body.Spans, want)
+		}
+	})
+
+	t.Run("MarkersAndWindow", func(t *testing.T) {
+		resp := search(t, "needle", &HighlightOptions{TagOpen: "[", TagClose: "]", Ellipsis: "…", ContextTokens: 5})
+		if len(resp.Results) != 1 {
+			t.Fatalf("Expected 1 result, got %d", len(resp.Results))
+		}
+		body := resp.Results[0].Highlights["content_text"]
+		if want := "…filler filler [needle] filler filler…"; body.Snippet != want {
+			t.Errorf("Snippet = %q, want %q", body.Snippet, want)
+		}
+		if want := []HighlightSpan{{350, 356}}; !reflect.DeepEqual(body.Spans, want) {
+			t.Errorf("Spans = %v, want %v", body.Spans, want)
+		}
+	})
+
+	t.Run("Trigram", func(t *testing.T) {
+		resp := search(t, "個人知識", DefaultHighlightOptions())
+		if len(resp.Results) != 2 {
+			t.Fatalf("Expected 2 results, got %d", len(resp.Results))
+		}
+		title := resp.Results[0].Highlights["title"]
+		if title == nil || title.Snippet != "我的<mark>個人知識</mark>庫" {
+			t.Fatalf("Unexpected title highlight: %+v", title)
+		}
+		if want := []HighlightSpan{{2, 6}}; !reflect.DeepEqual(title.Spans, want) {
+			t.Errorf("Spans = %v, want %v", title.Spans, want)
+		}
+	})
+
+	t.Run("SubstringFallback", func(t *testing.T) {
+		resp := search(t, "知識", DefaultHighlightOptions())
+		if len(resp.Results) != 2 {
+			t.Fatalf("Expected 2 results, got %d", len(resp.Results))
+		}
+		for _, r := range resp.Results {
+			title := r.Highlights["title"]
+			if title == nil || !strings.Contains(title.Snippet, "<mark>知識</mark>") {
+				t.Errorf("Expected regex highlight in %q, got %+v", r.Item.Title, title)
+			}
+		}
+	})
+
+	t.Run("Disabled", func(t *testing.T) {
+		resp := search(t, "prog*", nil)
+		if len(resp.Results) != 1 || resp.Results[0].Highlights != nil {
+			t.Errorf("Expected no highlights by default, got %+v", resp.Results)
+		}
+	})
+}
@@ -2,7 +2,9 @@
 package db
 
 import (
+	"context"
 	"database/sql"
+	"errors"
 	"fmt"
 	"os"
 	"strings"
@@ -37,11 +39,83 @@ func setupSearchTestDB(t *testing.T) *sql.DB {
 			created_at INTEGER NOT NULL,
 			updated_at INTEGER NOT NULL CHECK(updated_at > 0 AND updated_at >= created_at),
 			version INTEGER NOT NULL DEFAULT 1 CHECK(version > 0),
-			content_hash TEXT
+			content_hash TEXT,
+			is_pinned INTEGER NOT NULL DEFAULT 0 CHECK(is_pinned IN (0, 1)),
+			is_favorite INTEGER NOT NULL DEFAULT 0 CHECK(is_favorite IN (0, 1)),
+			read_status TEXT NOT NULL DEFAULT 'unread' CHECK(read_status IN ('unread', 'in_progress', 'read')),
+			read_progress REAL NOT NULL DEFAULT 0 CHECK(read_progress >= 0 AND read_progress <= 1),
+			is_archived INTEGER NOT NULL DEFAULT 0 CHECK(is_archived IN (0, 1)),
+			favorite_changed_at INTEGER NOT NULL DEFAULT 0,
+			pinned_changed_at INTEGER NOT NULL DEFAULT 0,
+			read_changed_at INTEGER NOT NULL DEFAULT 0,
+			archived_changed_at INTEGER NOT NULL DEFAULT 0,
+			source_domain TEXT GENERATED ALWAYS AS (
+				CASE WHEN instr(source_url, '://') > 0 THEN
+					substr(
+						lower(substr(
+							substr(source_url, instr(source_url, '://') + 3),
+							1,
+							instr(replace(replace(replace(substr(source_url, instr(source_url, '://') + 3),
+								'?', '/'), '#', '/'), ':', '/') || '/', '/') - 1
+						)),
+						1 + 4 * (substr(source_url, instr(source_url, '://') + 3) LIKE 'www.%')
+					)
+				END
+			) VIRTUAL
 		);
 
 		CREATE INDEX idx_content_items_created_at ON content_items(created_at DESC);
 		CREATE INDEX idx_content_items_media_type ON content_items(media_type);
+
+		CREATE TABLE tags (
+			id TEXT PRIMARY KEY,
+			name TEXT NOT NULL UNIQUE,
+			color TEXT DEFAULT '#3B82F6',
+			created_at INTEGER NOT NULL,
+			updated_at INTEGER NOT NULL,
+			is_deleted INTEGER NOT NULL DEFAULT 0,
+			parent_id TEXT
+		);
+
+		CREATE TABLE content_tags (
+			content_id TEXT NOT NULL REFERENCES content_items(id) ON DELETE CASCADE,
+			tag_id TEXT NOT NULL REFERENCES tags(id) ON DELETE CASCADE,
+			assigned_at INTEGER NOT NULL,
+			PRIMARY KEY (content_id, tag_id)
+		);
+
+		CREATE TABLE content_revisions (
+			id TEXT PRIMARY KEY,
+			content_id TEXT NOT NULL,
+			version INTEGER NOT NULL,
+			title TEXT NOT NULL,
+			content_text TEXT NOT NULL,
+			source_url TEXT,
+			media_type TEXT NOT NULL,
+			tags TEXT NOT NULL DEFAULT '',
+			summary TEXT,
+			content_hash TEXT,
+			edited_at INTEGER NOT NULL,
+			created_at INTEGER NOT NULL
+		);
+
+		CREATE TABLE content_links (
+			source_id TEXT NOT NULL,
+			kind TEXT NOT NULL,
+			target TEXT NOT NULL,
+			target_id TEXT,
+			position INTEGER NOT NULL DEFAULT 0,
+			created_at INTEGER NOT NULL,
+			PRIMARY KEY (source_id, kind, target)
+		);
+
+		CREATE TABLE change_log (
+			id TEXT PRIMARY KEY,
+			item_id TEXT NOT NULL,
+			operation TEXT NOT NULL,
+			version INTEGER NOT NULL,
+			timestamp INTEGER NOT NULL
+		);
 	`)
 	if err != nil {
 		db.Close()
@@ -54,31 +128,63 @@ func setupSearchTestDB(t *testing.T) *sql.DB {
 			title,
 			content_text,
 			tags,
+			summary,
+			source_domain,
 			content=content_items,
 			content_rowid=rowid,
 			tokenize='porter unicode61'
 		);
 
 		-- Triggers to keep FTS5 table in sync with content_items
-		INSERT INTO content_fts(rowid, title, content_text, tags)
-		SELECT rowid, title, content_text, tags FROM content_items;
+		INSERT INTO content_fts(rowid, title, content_text, tags, summary, source_domain)
+		SELECT rowid, title, content_text, tags, summary, source_domain FROM content_items;
 
 		CREATE TRIGGER content_items_ai AFTER INSERT ON content_items BEGIN
-			INSERT INTO content_fts(rowid, title, content_text, tags)
-			VALUES (new.rowid, new.title, new.content_text, new.tags);
+			INSERT INTO content_fts(rowid, title, content_text, tags, summary, source_domain)
+			VALUES (new.rowid, new.title, new.content_text, new.tags, new.summary, new.source_domain);
 		END;
 
 		CREATE TRIGGER content_items_ad AFTER DELETE ON content_items BEGIN
-			INSERT INTO content_fts(content_fts, rowid, title, content_text, tags)
-			VALUES ('delete', old.rowid, old.title, old.content_text, old.tags);
+			INSERT INTO content_fts(content_fts, rowid, title, content_text, tags, summary, source_domain)
+			VALUES ('delete', old.rowid, old.title, old.content_text, old.tags, old.summary, old.source_domain);
 		END;
 
 		CREATE TRIGGER content_items_au AFTER UPDATE ON content_items BEGIN
-			INSERT INTO content_fts(content_fts, rowid, title, content_text, tags)
-			VALUES ('delete', old.rowid, old.title, old.content_text, old.tags);
-			INSERT INTO content_fts(rowid, title, content_text, tags)
-			VALUES (new.rowid, new.title, new.content_text, new.tags);
+			INSERT INTO content_fts(content_fts, rowid, title, content_text, tags, summary, source_domain)
+			VALUES ('delete', old.rowid, old.title, old.content_text, old.tags, old.summary, old.source_domain);
+			INSERT INTO content_fts(rowid, title, content_text, tags, summary, source_domain)
+			VALUES (new.rowid, new.title, new.content_text, new.tags, new.summary, new.source_domain);
 		END;
+
+		CREATE VIRTUAL TABLE content_fts_trigram USING fts5(
+			title,
+			content_text,
+			tags,
+			summary,
+			source_domain,
+			content=content_items,
+			content_rowid=rowid,
+			tokenize='trigram'
+		);
+
+		CREATE TRIGGER content_items_trigram_ai AFTER INSERT ON content_items BEGIN
+			INSERT INTO content_fts_trigram(rowid, title, content_text, tags, summary, source_domain)
+			VALUES (new.rowid, new.title, new.content_text, new.tags, new.summary, new.source_domain);
+		END;
+
+		CREATE TRIGGER content_items_trigram_ad AFTER DELETE ON content_items BEGIN
+			INSERT INTO content_fts_trigram(content_fts_trigram, rowid, title, content_text, tags, summary, source_domain)
+			VALUES ('delete', old.rowid, old.title, old.content_text, old.tags, old.summary, old.source_domain);
+		END;
+
+		CREATE TRIGGER content_items_trigram_au AFTER UPDATE ON content_items BEGIN
+			INSERT INTO content_fts_trigram(content_fts_trigram, rowid, title, content_text, tags, summary, source_domain)
+			VALUES ('delete', old.rowid, old.title, old.content_text, old.tags, old.summary, old.source_domain);
+			INSERT INTO content_fts_trigram(rowid, title, content_text, tags, summary, source_domain)
+			VALUES (new.rowid, new.title, new.content_text, new.tags, new.summary, new.source_domain);
+		END;
+
+		CREATE VIRTUAL TABLE content_fts_vocab USING fts5vocab(content_fts, 'row');
 	`)
 	if err != nil {
 		db.Close()
@@ -100,6 +206,19 @@ func insertTestContentItem(t *testing.T, db *sql.DB, title, content, mediaType,
 	if err != nil {
 		t.Fatalf("Failed to insert test content: %v", err)
 	}
+
+	// Link the tags as the repository does
+	for _, tag := range TagsFromCommaString(tags) {
+		_, err := db.Exec(`INSERT OR IGNORE INTO tags (id, name, created_at, updated_at) VALUES (?, ?, ?, ?)`,
+			string(models.UUID(uuid.New())), tag, createdAt, createdAt)
+		if err == nil {
+			_, err = db.Exec(`INSERT OR IGNORE INTO content_tags (content_id, tag_id, assigned_at)
+				SELECT ?, id, ? FROM tags WHERE name = ?`, string(id), createdAt, tag)
+		}
+		if err != nil {
+			t.Fatalf("Failed to link test tag: %v", err)
+		}
+	}
 	return string(id)
 }
 
@@ -589,9 +708,16 @@ func toLower(s string) string {
 // Repository Close Tests
 // =====================================================
 
-// TestHighlightInText is skipped due to a known bug in buildHighlightPattern
-// that generates invalid regex patterns with extra closing parentheses.
-// The existing tests in highlighting_test.go cover the highlighting utilities.
+// TestHighlightInText verifies regex highlighting escapes HTML around the marks.
+func TestHighlightInText(t *testing.T) {
+	got, err := HighlightInText("Go <b>tips</b> for go", "go", nil)
+	if err != nil {
+		t.Fatalf("HighlightInText() error = %v", err)
+	}
+	if want := "<mark>Go</mark> &lt;b&gt;tips&lt;/b&gt; for <mark>go</mark>"; got.Text != want {
+		t.Errorf("HighlightInText() = %q, want %q", got.Text, want)
+	}
+}
 
 func TestRepositoryClose(t *testing.T) {
 	tmpDir, err := os.MkdirTemp("", "search_test_*")
@@ -651,6 +777,79 @@ func TestSearchSimple(t *testing.T) {
 	}
 }
 
+// TestSearch_cursor verifies cursor pages cover the ranking without gaps or repeats.
+func TestSearch_cursor(t *testing.T) {
+	db := setupSearchTestDB(t)
+	defer db.Close()
+
+	repo := NewRepository(db)
+
+	// Identical rows tie on rank and are ordered by rowid
+	now := time.Now().Unix()
+	insertTestContentItem(t, db, "Golang Golang Golang", "golang", "web", "golang", now)
+	for i := 0; i < 4; i++ {
+		insertTestContentItem(t, db, "Golang Notes", "notes", "web", "notes", now-int64(i))
+	}
+	insertTestContentItem(t, db, "Misc", "golang mentioned once in a longer body", "pdf", "misc", now)
+	insertTestContentItem(t, db, "Rust Notes", "rust", "web", "rust", now)
+
+	tests := []struct {
+		name string
+		opts SearchOptions
+		want int
+	}{
+		{"ranked", SearchOptions{Query: "golang"}, 6},
+		{"filter only", SearchOptions{Filters: NewFilterBuilder().MediaType("web")}, 6},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			all := tt.opts
+			all.Limit = 100
+			full, err := repo.Search(&all)
+			if err != nil {
+				t.Fatalf("Search failed: %v", err)
+			}
+			if len(full.Results) != tt.want || full.NextCursor != "" {
+				t.Fatalf("Expected %d results and no cursor, got %d (cursor %q)", tt.want, len(full.Results), full.NextCursor)
+			}
+
+			var paged []string
+			opts := tt.opts
+			opts.Limit = 4
+			for {
+				resp, err := repo.Search(&opts)
+				if err != nil {
+					t.Fatalf("Search failed: %v", err)
+				}
+				if resp.Total != tt.want {
+					t.Errorf("Expected total %d on every page, got %d", tt.want, resp.Total)
+				}
+				for _, r := range resp.Results {
+					paged = append(paged, string(r.Item.ID))
+				}
+				if resp.NextCursor == "" {
+					break
+				}
+				opts.Cursor = resp.NextCursor
+			}
+
+			if len(paged) != len(full.Results) {
+				t.Fatalf("Expected %d paged results, got %d", len(full.Results), len(paged))
+			}
+			for i, r := range full.Results {
+				if paged[i] != string(r.Item.ID) {
+					t.Errorf("Result %d differs between paged and full search", i)
+				}
+			}
+		})
+	}
+
+	if _, err := repo.Search(&SearchOptions{Query: "golang", Cursor: "%%%"}); !errors.Is(err, ErrInvalidCursor) {
+		t.Errorf("Expected ErrInvalidCursor, got %v", err)
+	}
+}
+
 // =====================================================
 // FTS Index Management Tests (T223)
 // =====================================================
@@ -830,6 +1029,37 @@ func TestBatchImportContent_withExistingIDs(t *testing.T) {
 	}
 }
 
+func TestBatchImportContent_linksTags(t *testing.T) {
+	db := setupMigratedTestDB(t)
+	defer db.Close()
+
+	repo := NewRepository(db)
+
+	items := []*models.ContentItem{
+		{Title: "Go tour", ContentText: "Content", MediaType: "web", Tags: "golang, research/ml"},
+		{Title: "Untagged", ContentText: "Content", MediaType: "web"},
+	}
+	if _, err := repo.BatchImportContent(items); err != nil {
+		t.Fatalf("BatchImportContent failed: %v", err)
+	}
+
+	tagged, err := repo.ListContentItemsFiltered(context.Background(), 20, 0, NewFilterBuilder().Tags("golang"))
+	if err != nil {
+		t.Fatalf("ListContentItemsFiltered failed: %v", err)
+	}
+	if len(tagged) != 1 || tagged[0].ID != items[0].ID {
+		t.Errorf("Expected the imported item tagged golang, got %d items", len(tagged))
+	}
+
+	tags, err := repo.ListTags()
+	if err != nil {
+		t.Fatalf("ListTags failed: %v", err)
+	}
+	if names := tagNames(tags); len(names) != 3 {
+		t.Errorf("Expected golang, research and research/ml tags, got %v", names)
+	}
+}
+
 // TestSearch_withDateRange tests search with date filters.
 func TestSearch_withDateRange(t *testing.T) {
 	db := setupSearchTestDB(t)
@@ -998,3 +1228,188 @@ func TestSearch_limitBounds(t *testing.T) {
 		t.Errorf("With limit=200, should be capped at 100, got %d", len(results.Results))
 	}
 }
+
+// TestSearch_relevanceScores verifies BM25 scores are returned and normalized.
+func TestSearch_relevanceScores(t *testing.T) {
+	db := setupSearchTestDB(t)
+	defer db.Close()
+
+	repo := NewRepository(db)
+
+	now := time.Now().Unix()
+	insertTestContentItem(t, db, "Golang Concurrency", "Goroutines and channels in golang", "web", "golang", now)
+	insertTestContentItem(t, db, "Rust Ownership", "Borrowing rules, briefly compared with golang", "web", "rust", now)
+
+	results, err := repo.Search(&SearchOptions{Query: "golang", Limit: 10})
+	if err != nil {
+		t.Fatalf("Search failed: %v", err)
+	}
+	if len(results.Results) != 2 {
+		t.Fatalf("Expected 2 results, got %d", len(results.Results))
+	}
+
+	for i, r := range results.Results {
+		if r.Score >= 0 {
+			t.Errorf("Result %d: expected negative bm25 score, got %f", i, r.Score)
+		}
+		if r.Relevance <= 0 || r.Relevance >= 1 {
+			t.Errorf("Result %d: relevance %f out of range (0, 1)", i, r.Relevance)
+		}
+	}
+
+	// Results are ordered by descending relevance
+	if results.Results[0].Relevance < results.Results[1].Relevance {
+		t.Errorf("Expected results sorted by relevance, got %f before %f",
+			results.Results[0].Relevance, results.Results[1].Relevance)
+	}
+}
+
+// TestSearch_columnWeights verifies title hits outrank body hits and weights are configurable.
+func TestSearch_columnWeights(t *testing.T) {
+	db := setupSearchTestDB(t)
+	defer db.Close()
+
+	repo := NewRepository(db)
+
+	now := time.Now().Unix()
+	insertTestContentItem(t, db, "Kubernetes Handbook", "Cluster operations guide", "web", "ops", now)
+	insertTestContentItem(t, db, "Operations Guide", "Running kubernetes kubernetes clusters with kubernetes", "web", "ops", now)
+
+	// Default weights: the title hit wins
+	results, err := repo.Search(&SearchOptions{Query: "kubernetes", Limit: 10})
+	if err != nil {
+		t.Fatalf("Search failed: %v", err)
+	}
+	if len(results.Results) != 2 {
+		t.Fatalf("Expected 2 results, got %d", len(results.Results))
+	}
+	if results.Results[0].Item.Title != "Kubernetes Handbook" {
+		t.Errorf("Expected title hit first with default weights, got %q", results.Results[0].Item.Title)
+	}
+
+	// Body-heavy weights: the body hit wins
+	results, err = repo.Search(&SearchOptions{
+		Query:   "kubernetes",
+		Limit:   10,
+		Weights: &ColumnWeights{Title: 1, ContentText: 10, Tags: 1},
+	})
+	if err != nil {
+		t.Fatalf("Search with custom weights failed: %v", err)
+	}
+	if results.Results[0].Item.Title != "Operations Guide" {
+		t.Errorf("Expected body hit first with body-heavy weights, got %q", results.Results[0].Item.Title)
+	}
+}
+
+func TestNormalizeBM25(t *testing.T) {
+	tests := []struct {
+		score float64
+		want  float64
+	}{
+		{0, 0},
+		{1.5, 0},
+		{-1, 0.5},
+		{-3, 0.75},
+	}
+
+	for _, tt := range tests {
+		if got := NormalizeBM25(tt.score); got != tt.want {
+			t.Errorf("NormalizeBM25(%f) = %f, want %f", tt.score, got, tt.want)
+		}
+	}
+}
+
+// TestSearch_summaryAndSourceDomain verifies summaries and source domains are indexed.
+func TestSearch_summaryAndSourceDomain(t *testing.T) {
+	db := setupSearchTestDB(t)
+	defer db.Close()
+
+	repo := NewRepository(db)
+
+	now := time.Now().Unix()
+	nyt := insertTestContentItem(t, db, "Election Coverage", "Polling numbers", "web", "politics", now)
+	blog := insertTestContentItem(t, db, "Weekend Notes", "Gardening and more", "web", "garden", now)
+	insertTestContentItem(t, db, "Nytimes Style Guide", "Writing advice", "web", "writing", now)
+
+	_, err := db.Exec(`UPDATE content_items SET source_url = ? WHERE id = ?`,
+		"https://www.NYTimes.com/2024/11/05/us/elections.html?smid=share", nyt)
+	if err != nil {
+		t.Fatalf("Failed to set source_url: %v", err)
+	}
+	_, err = db.Exec(`UPDATE content_items SET summary = ?, source_url = ? WHERE id = ?`,
+		"Compost heaps need turning weekly", "http://blog.example.org:8080/posts/1", blog)
+	if err != nil {
+		t.Fatalf("Failed to set summary: %v", err)
+	}
+
+	var domain string
+	if err := db.QueryRow(`SELECT source_domain FROM content_items WHERE id = ?`, nyt).Scan(&domain); err != nil {
+		t.Fatalf("Failed to read source_domain: %v", err)
+	}
+	if domain != "nytimes.com" {
+		t.Errorf("source_domain = %q, want %q", domain, "nytimes.com")
+	}
+
+	tests := []struct {
+		name    string
+		query   string
+		columns []string
+		want    int
+	}{
+		{"source domain", `"nytimes"`, nil, 2},
+		{"summary phrase", `"compost heaps"`, nil, 1},
+		{"site operator", `site:nytimes.com`, nil, 1},
+		{"summary operator", `summary:compost`, nil, 1},
+		{"restricted to title", `"nytimes"`, []string{"title"}, 1},
+		{"restricted to source", `"nytimes"`, []string{"source_domain"}, 1},
+		{"restricted to summary", `"gardening"`, []string{"summary"}, 0},
+		{"restricted with column filter", `title:weekend`, []string{"title", "summary"}, 1},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			pq, err := ParseQuery(tt.query)
+			if err != nil {
+				t.Fatalf("ParseQuery error: %v", err)
+			}
+			opts := &SearchOptions{Limit: 10, Columns: tt.columns}
+			pq.Apply(opts)
+
+			resp, err := repo.Search(opts)
+			if err != nil {
+				t.Fatalf("Search failed: %v", err)
+			}
+			if resp.Total != tt.want {
+				t.Errorf("Expected %d results, got %d", tt.want, resp.Total)
+			}
+		})
+	}
+
+	// Unknown columns are rejected
+	_, err = repo.Search(&SearchOptions{Query: "nytimes", Columns: []string{"source_url"}})
+	if err == nil || !strings.Contains(err.Error(), "invalid search column") {
+		t.Errorf("Expected invalid column error, got %v", err)
+	}
+}
+
+// TestSearch_columnsCJK verifies column restrictions apply to CJK routing.
+func TestSearch_columnsCJK(t *testing.T) {
+	db := setupSearchTestDB(t)
+	defer db.Close()
+
+	repo := NewRepository(db)
+
+	now := time.Now().Unix()
+	insertTestContentItem(t, db, "個人知識庫", "內容", "web", "", now)
+	insertTestContentItem(t, db, "筆記", "個人知識庫的整理", "web", "", now)
+
+	for _, query := range []string{`"知識"`, `"知識庫"`} {
+		resp, err := repo.Search(&SearchOptions{Query: query, Columns: []string{"title"}})
+		if err != nil {
+			t.Fatalf("Search(%s) failed: %v", query, err)
+		}
+		if resp.Total != 1 || resp.Results[0].Item.Title != "個人知識庫" {
+			t.Errorf("Search(%s) restricted to title: expected only the title hit, got %d", query, resp.Total)
+		}
+	}
+}
@@ -0,0 +1,189 @@
+// Package db provides triage state persistence for content items.
+package db
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/kimhsiao/memonexus/backend/internal/models"
+)
+
+// maxStateUpdateItems is the most items one state update may change.
+const maxStateUpdateItems = 500
+
+// ErrInvalidState is returned when a triage state update does not validate.
+var ErrInvalidState = errors.New("invalid state")
+
+// ContentStateUpdate changes the triage states of content items. Nil fields
+// are left as they are.
+//
+// ReadProgress also sets the read status it implies (0 unread, 1 read,
+// in progress otherwise). ReadStatus wins over that status, setting the
+// progress to 0 for unread and 1 for read.
+type ContentStateUpdate struct {
+	Favorite     *bool    `json:"favorite,omitempty"`
+	Pinned       *bool    `json:"pinned,omitempty"`
+	ReadStatus   *string  `json:"read_status,omitempty"`
+	ReadProgress *float64 `json:"read_progress,omitempty"`
+	Archived     *bool    `json:"archived,omitempty"`
+}
+
+// Validate checks that the update changes something and that its read
+// status and progress are valid.
+func (u *ContentStateUpdate) Validate() error {
+	if u.Favorite == nil && u.Pinned == nil && u.ReadStatus == nil && u.ReadProgress == nil && u.Archived == nil {
+		return fmt.Errorf("%w: no state to change", ErrInvalidState)
+	}
+	if u.ReadStatus != nil && !models.IsReadStatus(*u.ReadStatus) {
+		return fmt.Errorf("%w: read_status must be one of unread, in_progress, read", ErrInvalidState)
+	}
+	if u.ReadProgress != nil && !(*u.ReadProgress >= 0 && *u.ReadProgress <= 1) {
+		return fmt.Errorf("%w: read_progress must be between 0 and 1", ErrInvalidState)
+	}
+	return nil
+}
+
+// apply changes the states of item, stamping each changed state with now.
+// Returns true if anything changed.
+func (u *ContentStateUpdate) apply(item *models.ContentItem, now int64) bool {
+	changed := false
+	setFlag := func(flag *bool, value *bool, changedAt *int64) {
+		if value != nil && *flag != *value {
+			*flag, *changedAt = *value, now
+			changed = true
+		}
+	}
+	setFlag(&item.IsFavorite, u.Favorite, &item.FavoriteChangedAt)
+	setFlag(&item.IsPinned, u.Pinned, &item.PinnedChangedAt)
+	setFlag(&item.IsArchived, u.Archived, &item.ArchivedChangedAt)
+
+	status, progress := item.ReadStatus, item.ReadProgress
+	if u.ReadProgress != nil {
+		progress = *u.ReadProgress
+		status = models.ReadStatusForProgress(progress)
+	}
+	if u.ReadStatus != nil {
+		status = *u.ReadStatus
+		switch status {
+		case models.ReadStatusUnread:
+			progress = 0
+		case models.ReadStatusRead:
+			progress = 1
+		}
+	}
+	if status != item.ReadStatus || progress != item.ReadProgress {
+		item.ReadStatus, item.ReadProgress, item.ReadChangedAt = status, progress, now
+		changed = true
+	}
+	return changed
+}
+
+// UpdateContentStates applies a state update to live content items in one
+// transaction and returns the number of items that changed. State changes
+// do not bump item versions or create revisions; each state records when it
+// changed instead, which sync uses to merge it (see ApplyRemoteContentState).
+// Each changed item is logged to change_log at its current version.
+// Returns sql.ErrNoRows if any item does not exist, changing none, and
+// ErrInvalidState if the update does not validate.
+func (r *Repository) UpdateContentStates(ids []string, update *ContentStateUpdate) (int, error) {
+	if len(ids) == 0 {
+		return 0, fmt.Errorf("%w: no items given", ErrInvalidState)
+	}
+	if len(ids) > maxStateUpdateItems {
+		return 0, fmt.Errorf("%w: at most %d items can be updated at once", ErrInvalidState, maxStateUpdateItems)
+	}
+	if err := update.Validate(); err != nil {
+		return 0, err
+	}
+
+	tx, err := r.db.Begin()
+	if err != nil {
+		return 0, fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	now := time.Now().Unix()
+	seen := make(map[string]bool, len(ids))
+	updated := 0
+	for _, id := range ids {
+		if seen[id] {
+			continue
+		}
+		seen[id] = true
+
+		item, err := scanContentItem(tx.QueryRow(`SELECT `+contentItemColumns+` FROM content_items WHERE id = ? AND is_deleted = 0`, id))
+		if err != nil {
+			return 0, err
+		}
+		if !update.apply(item, now) {
+			continue
+		}
+		if err := writeContentStates(tx, item); err != nil {
+			return 0, fmt.Errorf("failed to update item %s: %w", id, err)
+		}
+		if err := insertChangeLog(context.Background(), tx, id, "update", item.Version, now); err != nil {
+			return 0, fmt.Errorf("failed to log state change of %s: %w", id, err)
+		}
+		updated++
+	}
+
+	if err := tx.Commit(); err != nil {
+		return 0, fmt.Errorf("failed to commit transaction: %w", err)
+	}
+	return updated, nil
+}
+
+// writeContentStates stores the triage states of item.
+func writeContentStates(tx *sql.Tx, item *models.ContentItem) error {
+	return execAffectingRow(tx.Exec(`
+	UPDATE content_items
+	SET is_favorite = ?, is_pinned = ?, read_status = ?, read_progress = ?, is_archived = ?,
+		favorite_changed_at = ?, pinned_changed_at = ?, read_changed_at = ?, archived_changed_at = ?
+	WHERE id = ?
+	`, item.IsFavorite, item.IsPinned, item.ReadStatus, item.ReadProgress, item.IsArchived,
+		item.FavoriteChangedAt, item.PinnedChangedAt, item.ReadChangedAt, item.ArchivedChangedAt, item.ID))
+}
+
+// =====================================================
+// Content State Sync
+// =====================================================
+
+// ApplyRemoteContentState merges the triage states of a content item
+// received from another device into the local copy, field by field: each
+// state takes whichever side changed it last, so a favorite set on one
+// device and an archive on another both survive. A merge that changes the
+// item is logged to change_log. Returns the states taken from the remote
+// item, and sql.ErrNoRows if the item does not exist.
+func (r *Repository) ApplyRemoteContentState(remote *models.ContentItem) ([]string, error) {
+	if remote.ReadStatus != "" && !models.IsReadStatus(remote.ReadStatus) {
+		return nil, fmt.Errorf("%w: unknown read status %q", ErrInvalidState, remote.ReadStatus)
+	}
+
+	tx, err := r.db.Begin()
+	if err != nil {
+		return nil, fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	local, err := scanContentItem(tx.QueryRow(`SELECT `+contentItemColumns+` FROM content_items WHERE id = ?`, remote.ID))
+	if err != nil {
+		return nil, err
+	}
+	merged := local.MergeStates(remote)
+	if len(merged) == 0 {
+		return nil, nil
+	}
+	if err := writeContentStates(tx, local); err != nil {
+		return nil, err
+	}
+	if err := insertChangeLog(context.Background(), tx, string(local.ID), "update", local.Version, time.Now().Unix()); err != nil {
+		return nil, fmt.Errorf("failed to log state merge: %w", err)
+	}
+	if err := tx.Commit(); err != nil {
+		return nil, fmt.Errorf("failed to commit transaction: %w", err)
+	}
+	return merged, nil
+}
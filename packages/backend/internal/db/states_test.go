@@ -0,0 +1,199 @@
+// Package db tests for content item triage states.
+package db
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/kimhsiao/memonexus/backend/internal/models"
+)
+
+// TestUpdateContentStates verifies states change in bulk without bumping
+// versions, and that read status and progress follow each other.
+func TestUpdateContentStates(t *testing.T) {
+	db := setupMigratedTestDB(t)
+	defer db.Close()
+
+	repo := NewRepository(db)
+	items := createTaggedItems(t, repo, "", "")
+	ids := []string{string(items[0].ID), string(items[1].ID)}
+	yes, progress, read := true, 0.4, models.ReadStatusRead
+
+	updated, err := repo.UpdateContentStates(ids, &ContentStateUpdate{Favorite: &yes, ReadProgress: &progress})
+	if err != nil || updated != 2 {
+		t.Fatalf("UpdateContentStates = %d, %v; want 2", updated, err)
+	}
+	item, _ := repo.GetContentItem(ids[0])
+	if !item.IsFavorite || item.ReadStatus != models.ReadStatusInProgress || item.ReadProgress != 0.4 {
+		t.Errorf("Unexpected states: %+v", item)
+	}
+	if item.Version != 1 || item.FavoriteChangedAt == 0 || item.ReadChangedAt == 0 || item.PinnedChangedAt != 0 {
+		t.Errorf("Unexpected version or change times: %+v", item)
+	}
+
+	// Marking read completes the progress; setting a state again is a no-op
+	updated, err = repo.UpdateContentStates(ids[:1], &ContentStateUpdate{Favorite: &yes, ReadStatus: &read})
+	if err != nil || updated != 1 {
+		t.Fatalf("UpdateContentStates = %d, %v; want 1", updated, err)
+	}
+	if item, _ = repo.GetContentItem(ids[0]); item.ReadStatus != read || item.ReadProgress != 1 {
+		t.Errorf("Unexpected read state: %s %v", item.ReadStatus, item.ReadProgress)
+	}
+	if updated, _ = repo.UpdateContentStates(ids[:1], &ContentStateUpdate{Favorite: &yes}); updated != 0 {
+		t.Errorf("Repeated update changed %d items, want 0", updated)
+	}
+
+	// Each change is logged at the unchanged version; the no-op is not
+	var logged int
+	db.QueryRow(`SELECT COUNT(*) FROM change_log WHERE item_id = ? AND operation = 'update'`, ids[0]).Scan(&logged)
+	if logged != 2 {
+		t.Errorf("change_log entries for state changes = %d, want 2", logged)
+	}
+
+	// A missing item fails the whole update
+	_, err = repo.UpdateContentStates([]string{ids[1], "00000000-0000-0000-0000-000000000000"}, &ContentStateUpdate{Pinned: &yes})
+	if err != sql.ErrNoRows {
+		t.Errorf("Missing item: expected sql.ErrNoRows, got %v", err)
+	}
+	if item, _ = repo.GetContentItem(ids[1]); item.IsPinned {
+		t.Error("Failed update pinned an item")
+	}
+
+	invalid := 1.5
+	for _, update := range []*ContentStateUpdate{{}, {ReadProgress: &invalid}, {ReadStatus: new(string)}} {
+		if _, err := repo.UpdateContentStates(ids, update); !errors.Is(err, ErrInvalidState) {
+			t.Errorf("Update %+v: expected ErrInvalidState, got %v", update, err)
+		}
+	}
+}
+
+// TestListContentItemsFiltered verifies pinned items list first, across
+// cursor pages, and that state filters apply.
+func TestListContentItemsFiltered(t *testing.T) {
+	db := setupMigratedTestDB(t)
+	defer db.Close()
+
+	repo := NewRepository(db)
+	items := createTaggedItems(t, repo, "", "", "")
+	for i, item := range items {
+		db.Exec(`UPDATE content_items SET created_at = ? WHERE id = ?`, 1700000000+i, item.ID)
+	}
+	yes := true
+	repo.UpdateContentStates([]string{string(items[0].ID)}, &ContentStateUpdate{Pinned: &yes})
+	repo.UpdateContentStates([]string{string(items[2].ID)}, &ContentStateUpdate{Archived: &yes})
+
+	var got []models.UUID
+	cursor := ""
+	for {
+		page, err := repo.ListContentItemsPageFiltered(cursor, 1, NewFilterBuilder().Archived(false))
+		if err != nil {
+			t.Fatalf("ListContentItemsPageFiltered failed: %v", err)
+		}
+		for _, item := range page.Items {
+			got = append(got, item.ID)
+		}
+		if cursor = page.NextCursor; cursor == "" {
+			break
+		}
+	}
+	if len(got) != 2 || got[0] != items[0].ID || got[1] != items[1].ID {
+		t.Errorf("Listed %v, want pinned %s then %s", got, items[0].ID, items[1].ID)
+	}
+
+	listed, err := repo.ListContentItemsFiltered(context.Background(), 10, 0, NewFilterBuilder().Archived(true))
+	if err != nil || len(listed) != 1 || listed[0].ID != items[2].ID {
+		t.Errorf("Archived listing = %v, %v", listed, err)
+	}
+
+	// The unfiltered listing still includes archived items
+	if all, _ := repo.ListContentItems(10, 0, ""); len(all) != 3 {
+		t.Errorf("ListContentItems returned %d items, want 3", len(all))
+	}
+
+	// Filtered listings are not kept in the statement cache
+	repo.stmtCache.Range(func(query, _ interface{}) bool {
+		if strings.Contains(query.(string), "ci.is_archived = ?") {
+			t.Errorf("Filtered listing cached: %s", query)
+		}
+		return true
+	})
+}
+
+// TestStateQuery verifies is: conditions in structured queries.
+func TestStateQuery(t *testing.T) {
+	db := setupMigratedTestDB(t)
+	defer db.Close()
+
+	repo := NewRepository(db)
+	items := createTaggedItems(t, repo, "", "", "")
+	yes, progress := true, 0.5
+	repo.UpdateContentStates([]string{string(items[0].ID)}, &ContentStateUpdate{Favorite: &yes, Archived: &yes})
+	repo.UpdateContentStates([]string{string(items[1].ID)}, &ContentStateUpdate{ReadProgress: &progress})
+
+	tests := []struct {
+		query string
+		want  int
+	}{
+		{"body", 3},
+		{"is:favorite", 1},
+		{"body -is:archived", 2},
+		{"is:in_progress", 1},
+		{"is:unread", 2},
+		{"body -is:unread", 1},
+		{"is:FAVORITE is:archived", 1},
+		{"is:pinned", 0},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.query, func(t *testing.T) {
+			pq, err := ParseQuery(tt.query)
+			if err != nil {
+				t.Fatalf("ParseQuery error: %v", err)
+			}
+			opts := &SearchOptions{Limit: 10}
+			pq.Apply(opts)
+
+			resp, err := repo.Search(opts)
+			if err != nil {
+				t.Fatalf("Search failed: %v", err)
+			}
+			if resp.Total != tt.want {
+				t.Errorf("Expected %d results, got %d", tt.want, resp.Total)
+			}
+		})
+	}
+}
+
+// TestApplyRemoteContentState verifies remote states merge field by field
+// on their change times.
+func TestApplyRemoteContentState(t *testing.T) {
+	db := setupMigratedTestDB(t)
+	defer db.Close()
+
+	repo := NewRepository(db)
+	items := createTaggedItems(t, repo, "")
+	yes := true
+	repo.UpdateContentStates([]string{string(items[0].ID)}, &ContentStateUpdate{Favorite: &yes})
+	local, _ := repo.GetContentItem(string(items[0].ID))
+
+	remote := *local
+	remote.IsFavorite, remote.FavoriteChangedAt = false, local.FavoriteChangedAt-10
+	remote.ReadStatus, remote.ReadProgress, remote.ReadChangedAt = models.ReadStatusRead, 1, local.FavoriteChangedAt+10
+
+	merged, err := repo.ApplyRemoteContentState(&remote)
+	if err != nil || len(merged) != 1 || merged[0] != models.StateRead {
+		t.Fatalf("ApplyRemoteContentState = %v, %v; want [read]", merged, err)
+	}
+	item, _ := repo.GetContentItem(string(items[0].ID))
+	if !item.IsFavorite || item.ReadStatus != models.ReadStatusRead || item.Version != 1 {
+		t.Errorf("Unexpected item after merge: %+v", item)
+	}
+	var logged int
+	db.QueryRow(`SELECT COUNT(*) FROM change_log WHERE item_id = ? AND operation = 'update'`, items[0].ID).Scan(&logged)
+	if logged != 2 {
+		t.Errorf("change_log entries after merge = %d, want 2 (update and merge)", logged)
+	}
+}
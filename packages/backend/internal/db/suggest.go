@@ -0,0 +1,275 @@
+// Package db provides "did you mean" spelling suggestions for search.
+package db
+
+import (
+	"fmt"
+	"math"
+	"sort"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+)
+
+const (
+	// maxSuggestions caps the number of suggestions returned
+	maxSuggestions = 10
+
+	// candidatesPerTerm is how many corrections are kept for each unknown term
+	candidatesPerTerm = 3
+
+	// maxCorrectedTerms caps how many unknown terms are corrected in one query
+	maxCorrectedTerms = 4
+)
+
+// Suggestion is a corrected query proposed when a search finds nothing.
+type Suggestion struct {
+	// Query is the corrected query text
+	Query string
+
+	// Score ranks suggestions; higher is better
+	Score float64
+}
+
+// termCorrection is a vocabulary term close to an unknown query term.
+type termCorrection struct {
+	term     string
+	distance int
+	docs     int64
+	score    float64
+}
+
+// suggestToken is a query word that may be corrected.
+type suggestToken struct {
+	start, end int // rune offsets into the query
+	word       string
+}
+
+// Suggest proposes corrected queries using the content_fts vocabulary.
+// Words not present in the index are replaced by vocabulary terms within a
+// small edit distance, weighted by how many documents contain them.
+// Field operators, phrases, prefix terms and boolean keywords are left as-is.
+// Returns an empty slice if every word is already indexed.
+func (r *Repository) Suggest(query string, limit int) ([]*Suggestion, error) {
+	if limit <= 0 {
+		limit = 5
+	}
+	if limit > maxSuggestions {
+		limit = maxSuggestions
+	}
+
+	tokens := suggestTokens(query)
+	suggestions := make([]*Suggestion, 0)
+	if len(tokens) == 0 {
+		return suggestions, nil
+	}
+
+	// Find the words missing from the index and their candidate corrections
+	type correctable struct {
+		token       suggestToken
+		corrections []termCorrection
+	}
+	var unknown []correctable
+	for _, tok := range tokens {
+		var docs int64
+		err := r.db.QueryRow(`SELECT doc FROM content_fts_vocab WHERE term = ?`, tok.word).Scan(&docs)
+		if err == nil && docs > 0 {
+			continue
+		}
+
+		corrections, err := r.termCorrections(tok.word)
+		if err != nil {
+			return nil, err
+		}
+		if len(corrections) > 0 {
+			unknown = append(unknown, correctable{token: tok, corrections: corrections})
+		}
+		if len(unknown) == maxCorrectedTerms {
+			break
+		}
+	}
+	if len(unknown) == 0 {
+		return suggestions, nil
+	}
+
+	// Combine per-term corrections, best combinations first
+	type combo struct {
+		choice []int
+		score  float64
+	}
+	combos := []combo{{choice: []int{}, score: 1}}
+	for _, u := range unknown {
+		var next []combo
+		for _, c := range combos {
+			for i, corr := range u.corrections {
+				choice := append(append([]int{}, c.choice...), i)
+				next = append(next, combo{choice: choice, score: c.score * corr.score})
+			}
+		}
+		sort.SliceStable(next, func(i, j int) bool { return next[i].score > next[j].score })
+		if len(next) > limit {
+			next = next[:limit]
+		}
+		combos = next
+	}
+
+	runes := []rune(query)
+	for _, c := range combos {
+		var sb strings.Builder
+		pos := 0
+		for i, u := range unknown {
+			sb.WriteString(string(runes[pos:u.token.start]))
+			sb.WriteString(u.corrections[c.choice[i]].term)
+			pos = u.token.end
+		}
+		sb.WriteString(string(runes[pos:]))
+		suggestions = append(suggestions, &Suggestion{Query: sb.String(), Score: c.score})
+	}
+
+	return suggestions, nil
+}
+
+// termCorrections returns the best vocabulary terms within edit distance of word.
+func (r *Repository) termCorrections(word string) ([]termCorrection, error) {
+	n := utf8.RuneCountInString(word)
+	maxDist := maxEditDistance(n)
+
+	rows, err := r.db.Query(`
+		SELECT term, doc FROM content_fts_vocab
+		WHERE length(term) BETWEEN ? AND ?
+	`, n-maxDist, n+maxDist)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query FTS vocabulary: %w", err)
+	}
+	defer rows.Close()
+
+	var corrections []termCorrection
+	for rows.Next() {
+		var c termCorrection
+		if err := rows.Scan(&c.term, &c.docs); err != nil {
+			return nil, fmt.Errorf("failed to scan vocabulary term: %w", err)
+		}
+		c.distance = editDistance(word, c.term)
+		if c.distance == 0 || c.distance > maxDist {
+			continue
+		}
+		c.score = correctionScore(c.distance, c.docs)
+		corrections = append(corrections, c)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating vocabulary: %w", err)
+	}
+
+	sort.Slice(corrections, func(i, j int) bool {
+		if corrections[i].score != corrections[j].score {
+			return corrections[i].score > corrections[j].score
+		}
+		return corrections[i].term < corrections[j].term
+	})
+	if len(corrections) > candidatesPerTerm {
+		corrections = corrections[:candidatesPerTerm]
+	}
+	return corrections, nil
+}
+
+// suggestTokens returns the plain words of a query that may be corrected,
+// lowercased to match the case-folded FTS vocabulary.
+func suggestTokens(query string) []suggestToken {
+	tokens, err := lexQuery(query)
+	if err != nil {
+		return nil
+	}
+
+	var result []suggestToken
+	for _, tok := range tokens {
+		if tok.kind != tokWord {
+			continue
+		}
+		word, start := tok.text, tok.pos-1
+
+		// Correct the value of text fields, skip filter fields
+		if idx := strings.Index(word, ":"); idx > 0 {
+			if _, ok := queryColumns[strings.ToLower(word[:idx])]; !ok {
+				continue
+			}
+			start += utf8.RuneCountInString(word[:idx+1])
+			word = word[idx+1:]
+		}
+
+		if !isCorrectableWord(word) {
+			continue
+		}
+		result = append(result, suggestToken{
+			start: start,
+			end:   start + utf8.RuneCountInString(word),
+			word:  strings.ToLower(word),
+		})
+	}
+	return result
+}
+
+// isCorrectableWord reports whether a word is a single index token worth
+// correcting: letters and digits only, not a keyword, not CJK, long enough.
+func isCorrectableWord(word string) bool {
+	if utf8.RuneCountInString(word) < minTermLength {
+		return false
+	}
+	switch word {
+	case "AND", "OR", "NOT":
+		return false
+	}
+	for _, r := range word {
+		if !unicode.IsLetter(r) && !unicode.IsDigit(r) {
+			return false
+		}
+		if IsCJKCharacter(r) {
+			return false
+		}
+	}
+	return true
+}
+
+// maxEditDistance is the largest edit distance tolerated for a word length.
+func maxEditDistance(n int) int {
+	switch {
+	case n <= 4:
+		return 1
+	default:
+		return 2
+	}
+}
+
+// correctionScore weights a candidate by edit distance and document
+// frequency: closer terms win, and among equally close terms the more
+// common one wins. A distance-2 term needs to be much more common than a
+// distance-1 term to outrank it.
+func correctionScore(distance int, docs int64) float64 {
+	return math.Log1p(float64(docs)) / float64(1+distance*distance)
+}
+
+// editDistance returns the optimal string alignment distance between a and
+// b: insertions, deletions, substitutions and adjacent transpositions.
+func editDistance(a, b string) int {
+	ra, rb := []rune(a), []rune(b)
+	prev2 := make([]int, len(rb)+1)
+	prev := make([]int, len(rb)+1)
+	curr := make([]int, len(rb)+1)
+	for j := range prev {
+		prev[j] = j
+	}
+
+	for i := 1; i <= len(ra); i++ {
+		curr[0] = i
+		for j := 1; j <= len(rb); j++ {
+			cost := 1
+			if ra[i-1] == rb[j-1] {
+				cost = 0
+			}
+			curr[j] = min(prev[j]+1, curr[j-1]+1, prev[j-1]+cost)
+			if i > 1 && j > 1 && ra[i-1] == rb[j-2] && ra[i-2] == rb[j-1] {
+				curr[j] = min(curr[j], prev2[j-2]+1)
+			}
+		}
+		prev2, prev, curr = prev, curr, prev2
+	}
+	return prev[len(rb)]
+}
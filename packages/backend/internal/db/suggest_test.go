@@ -0,0 +1,147 @@
+// Package db tests for "did you mean" spelling suggestions.
+package db
+
+import (
+	"testing"
+	"time"
+)
+
+// TestEditDistance verifies optimal string alignment distances.
+func TestEditDistance(t *testing.T) {
+	tests := []struct {
+		a, b string
+		want int
+	}{
+		{"golang", "golang", 0},
+		{"golang", "golan", 1},
+		{"golang", "golangs", 1},
+		{"golang", "gylang", 1},
+		{"golnag", "golang", 1}, // adjacent transposition
+		{"flutter", "fluter", 1},
+		{"kitten", "sitting", 3},
+		{"", "abc", 3},
+		{"café", "cafe", 1},
+	}
+
+	for _, tt := range tests {
+		if got := editDistance(tt.a, tt.b); got != tt.want {
+			t.Errorf("editDistance(%q, %q) = %d, want %d", tt.a, tt.b, got, tt.want)
+		}
+	}
+}
+
+// TestSuggestTokens verifies which query words are considered for correction.
+func TestSuggestTokens(t *testing.T) {
+	tokens := suggestTokens(`Golnag AND "exact phrase" tag:reserch title:fluter prog* -rsut x 知識`)
+
+	want := []suggestToken{
+		{start: 0, end: 6, word: "golnag"},
+		{start: 44, end: 50, word: "fluter"},
+		{start: 58, end: 62, word: "rsut"},
+	}
+	if len(tokens) != len(want) {
+		t.Fatalf("Expected %d tokens, got %+v", len(want), tokens)
+	}
+	for i := range want {
+		if tokens[i] != want[i] {
+			t.Errorf("Token %d = %+v, want %+v", i, tokens[i], want[i])
+		}
+	}
+}
+
+// TestSuggest verifies corrections come from the index vocabulary.
+func TestSuggest(t *testing.T) {
+	db := setupSearchTestDB(t)
+	defer db.Close()
+
+	repo := NewRepository(db)
+
+	now := time.Now().Unix()
+	insertTestContentItem(t, db, "Golang Concurrency", "Goroutines in golang", "web", "golang", now)
+	insertTestContentItem(t, db, "Golang Generics", "Type parameters", "web", "golang", now)
+	insertTestContentItem(t, db, "Golan Heights", "A region", "web", "travel", now)
+	insertTestContentItem(t, db, "Rust Ownership", "Borrowing rules", "web", "rust", now)
+
+	t.Run("SingleTerm", func(t *testing.T) {
+		suggestions, err := repo.Suggest("golnag", 5)
+		if err != nil {
+			t.Fatalf("Suggest failed: %v", err)
+		}
+		if len(suggestions) == 0 || suggestions[0].Query != "golang" {
+			t.Fatalf("Expected 'golang' first, got %+v", suggestions)
+		}
+	})
+
+	t.Run("DocumentFrequencyBreaksTies", func(t *testing.T) {
+		// "golang" and "golan" are both one edit from "golanx"
+		suggestions, err := repo.Suggest("golanx", 5)
+		if err != nil {
+			t.Fatalf("Suggest failed: %v", err)
+		}
+		if len(suggestions) < 2 || suggestions[0].Query != "golang" || suggestions[1].Query != "golan" {
+			t.Fatalf("Expected [golang golan], got %+v", suggestions)
+		}
+		if suggestions[0].Score <= suggestions[1].Score {
+			t.Errorf("Expected more common term to score higher: %+v", suggestions)
+		}
+	})
+
+	t.Run("PreservesQueryStructure", func(t *testing.T) {
+		suggestions, err := repo.Suggest(`type:web Golnag -rsut "exact phrase"`, 5)
+		if err != nil {
+			t.Fatalf("Suggest failed: %v", err)
+		}
+		if len(suggestions) == 0 || suggestions[0].Query != `type:web golang -rust "exact phrase"` {
+			t.Fatalf("Unexpected suggestions: %+v", suggestions)
+		}
+	})
+
+	t.Run("KnownTerms", func(t *testing.T) {
+		suggestions, err := repo.Suggest("golang rust", 5)
+		if err != nil {
+			t.Fatalf("Suggest failed: %v", err)
+		}
+		if len(suggestions) != 0 {
+			t.Errorf("Expected no suggestions for indexed terms, got %+v", suggestions)
+		}
+	})
+
+	t.Run("NoCloseTerm", func(t *testing.T) {
+		suggestions, err := repo.Suggest("kubernetes", 5)
+		if err != nil {
+			t.Fatalf("Suggest failed: %v", err)
+		}
+		if len(suggestions) != 0 {
+			t.Errorf("Expected no suggestions, got %+v", suggestions)
+		}
+	})
+
+	t.Run("SearchWithNoResults", func(t *testing.T) {
+		pq, err := ParseQuery("golnag")
+		if err != nil {
+			t.Fatalf("ParseQuery error: %v", err)
+		}
+		opts := &SearchOptions{Limit: 10}
+		pq.Apply(opts)
+
+		resp, err := repo.Search(opts)
+		if err != nil {
+			t.Fatalf("Search failed: %v", err)
+		}
+		if resp.Total != 0 {
+			t.Fatalf("Expected no results, got %d", resp.Total)
+		}
+		if len(resp.Suggestions) == 0 || resp.Suggestions[0].Query != "golang" {
+			t.Errorf("Expected 'golang' suggestion, got %+v", resp.Suggestions)
+		}
+
+		// Searches with results carry no suggestions
+		resp, err = repo.SearchSimple("golang", 10)
+		if err != nil {
+			t.Fatalf("Search failed: %v", err)
+		}
+		if len(resp.Suggestions) != 0 {
+			t.Errorf("Expected no suggestions, got %+v", resp.Suggestions)
+		}
+	})
+}
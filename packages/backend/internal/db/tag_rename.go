@@ -0,0 +1,159 @@
+// Package db provides tag rename and merge operations.
+package db
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/kimhsiao/memonexus/backend/internal/models"
+)
+
+// ErrInvalidTagMerge is returned when a tag is merged into itself or one of
+// its descendants.
+var ErrInvalidTagMerge = errors.New("cannot merge a tag into itself or its descendants")
+
+// Tag names are stored in content_items.tags (and so the FTS index) as well
+// as in tags, so renames and merges rewrite every affected item in the same
+// transaction and log the new item versions to change_log for sync.
+
+// renameTag gives tag the full path name, creating missing ancestors of the
+// new path, and renames its descendants with it. If parentID differs from
+// the tag's parent, the tag instead moves under parentID (or to the top
+// level if it is empty) with the last segment of name. Both happen in one
+// subtree rewrite. tag is updated in place.
+func renameTag(tx *sql.Tx, tag *models.Tag, name string, parentID models.UUID, now int64) error {
+	if name == "" {
+		return fmt.Errorf("%w: name is required", ErrInvalidTagName)
+	}
+	if !validTagName(name) {
+		return fmt.Errorf("%w: %q", ErrInvalidTagName, name)
+	}
+
+	if parentID != tag.ParentID {
+		name, err := childTagName(tx, tag, tagLeaf(name), parentID)
+		if err != nil {
+			return err
+		}
+		return moveTagSubtree(tx, tag, name, parentID, now)
+	}
+
+	var pathParentID models.UUID
+	if parentPath := parentTagPath(name); parentPath != "" {
+		if strings.HasPrefix(strings.ToLower(name), strings.ToLower(tag.Name)+tagPathSeparator) {
+			return fmt.Errorf("%w: cannot move %q under itself", ErrInvalidTagParent, tag.Name)
+		}
+		parent, err := resolveTag(context.Background(), tx, parentPath, now)
+		if err != nil {
+			return err
+		}
+		pathParentID = parent.ID
+		name = parent.Name + tagPathSeparator + tagLeaf(name)
+	}
+	return moveTagSubtree(tx, tag, name, pathParentID, now)
+}
+
+// MergeTags merges the tag sourceID into targetID ("merge js into
+// javascript"): items tagged with the source are tagged with the target,
+// the source's children move under the target (merging with same-named
+// children) and the source is deleted. Returns the target tag, or
+// sql.ErrNoRows if either tag does not exist.
+func (r *Repository) MergeTags(sourceID, targetID string) (*models.Tag, error) {
+	tx, err := r.db.Begin()
+	if err != nil {
+		return nil, fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	source, err := scanTag(tx.QueryRow(`SELECT `+tagColumns+` FROM tags WHERE id = ? AND is_deleted = 0`, sourceID))
+	if err != nil {
+		return nil, err
+	}
+	target, err := scanTag(tx.QueryRow(`SELECT `+tagColumns+` FROM tags WHERE id = ? AND is_deleted = 0`, targetID))
+	if err != nil {
+		return nil, err
+	}
+
+	var inSubtree int
+	err = tx.QueryRow(tagSubtreeCTE+` SELECT COUNT(*) FROM subtree WHERE id = ?`, sourceID, targetID).Scan(&inSubtree)
+	if err != nil {
+		return nil, err
+	}
+	if inSubtree > 0 {
+		return nil, fmt.Errorf("%w: %q into %q", ErrInvalidTagMerge, source.Name, target.Name)
+	}
+
+	if err := mergeTag(tx, source, target, time.Now().Unix()); err != nil {
+		return nil, err
+	}
+
+	if err := tx.Commit(); err != nil {
+		return nil, fmt.Errorf("failed to commit transaction: %w", err)
+	}
+	return r.GetTag(targetID)
+}
+
+// mergeTag moves the assignments and live children of source to target and
+// deletes source.
+func mergeTag(tx *sql.Tx, source, target *models.Tag, now int64) error {
+	rows, err := tx.Query(`SELECT `+tagColumns+` FROM tags WHERE parent_id = ? AND is_deleted = 0 ORDER BY name`, source.ID)
+	if err != nil {
+		return err
+	}
+	var children []*models.Tag
+	for rows.Next() {
+		child, err := scanTag(rows)
+		if err != nil {
+			rows.Close()
+			return err
+		}
+		children = append(children, child)
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return err
+	}
+
+	for _, child := range children {
+		name := target.Name + tagPathSeparator + tagLeaf(child.Name)
+		var taken int
+		if err := tx.QueryRow(`SELECT COUNT(*) FROM tags WHERE name = ? COLLATE NOCASE`, name).Scan(&taken); err != nil {
+			return err
+		}
+		if taken == 0 {
+			if err := moveTagSubtree(tx, child, name, target.ID, now); err != nil {
+				return err
+			}
+			continue
+		}
+
+		// The target already has a child of that name (possibly deleted)
+		existing, err := resolveTag(context.Background(), tx, name, now)
+		if err != nil {
+			return err
+		}
+		if err := mergeTag(tx, child, existing, now); err != nil {
+			return err
+		}
+	}
+
+	_, err = tx.Exec(`
+	INSERT OR IGNORE INTO content_tags (content_id, tag_id, assigned_at)
+	SELECT content_id, ?, assigned_at FROM content_tags WHERE tag_id = ?
+	`, target.ID, source.ID)
+	if err != nil {
+		return fmt.Errorf("failed to reassign %q: %w", source.Name, err)
+	}
+	if _, err := tx.Exec(`DELETE FROM content_tags WHERE tag_id = ?`, source.ID); err != nil {
+		return fmt.Errorf("failed to unlink %q: %w", source.Name, err)
+	}
+	if _, err := tx.Exec(`UPDATE tags SET is_deleted = 1, updated_at = ? WHERE id = ?`, now, source.ID); err != nil {
+		return fmt.Errorf("failed to delete %q: %w", source.Name, err)
+	}
+
+	return refreshContentTagStrings(tx, `SELECT content_id FROM content_tags WHERE tag_id = ?`,
+		[]interface{}{target.ID}, now)
+}
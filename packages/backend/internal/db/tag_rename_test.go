@@ -0,0 +1,175 @@
+// Package db tests for tag rename and merge.
+package db
+
+import (
+	"database/sql"
+	"errors"
+	"testing"
+
+	"github.com/kimhsiao/memonexus/backend/internal/models"
+)
+
+// createTaggedItems creates one item per tag string and returns them in order.
+func createTaggedItems(t *testing.T, repo *Repository, tagStrings ...string) []*models.ContentItem {
+	t.Helper()
+	items := make([]*models.ContentItem, len(tagStrings))
+	for i, tags := range tagStrings {
+		items[i] = &models.ContentItem{Title: "Item", ContentText: "body", MediaType: "web", Tags: tags}
+		if err := repo.CreateContentItem(items[i]); err != nil {
+			t.Fatalf("CreateContentItem failed: %v", err)
+		}
+	}
+	return items
+}
+
+// assertItemTags checks an item's tag string, version and change_log entry.
+func assertItemTags(t *testing.T, db *sql.DB, repo *Repository, id models.UUID, tags string, version int) {
+	t.Helper()
+	item, err := repo.GetContentItem(string(id))
+	if err != nil {
+		t.Fatalf("GetContentItem failed: %v", err)
+	}
+	if item.Tags != tags || item.Version != version {
+		t.Errorf("Item tags %q version %d, want %q version %d", item.Tags, item.Version, tags, version)
+	}
+	if version == 1 {
+		return
+	}
+	var logged int
+	err = db.QueryRow(`SELECT COUNT(*) FROM change_log WHERE item_id = ? AND operation = 'update' AND version = ?`,
+		id, version).Scan(&logged)
+	if err != nil || logged != 1 {
+		t.Errorf("change_log entries for %s v%d = %d (%v), want 1", id, version, logged, err)
+	}
+}
+
+// TestUpdateTag_rename verifies renaming a tag rewrites descendants, item
+// strings and FTS, and logs the changed items.
+func TestUpdateTag_rename(t *testing.T) {
+	db := setupMigratedTestDB(t)
+	defer db.Close()
+
+	repo := NewRepository(db)
+	items := createTaggedItems(t, repo, "js", "web,js/react", "web")
+	js := tagByName(t, repo, "js")
+
+	js.Name = "javascript"
+	if err := repo.UpdateTag(js); err != nil {
+		t.Fatalf("UpdateTag failed: %v", err)
+	}
+	if js.Name != "javascript" || tagByName(t, repo, "javascript/react").ParentID != js.ID {
+		t.Errorf("Unexpected tags after rename: %v", js)
+	}
+	assertItemTags(t, db, repo, items[0].ID, "javascript", 2)
+	assertItemTags(t, db, repo, items[1].ID, "web,javascript/react", 2)
+	assertItemTags(t, db, repo, items[2].ID, "web", 1)
+
+	for query, want := range map[string]int{"javascript": 2, "js": 0} {
+		resp, err := repo.Search(&SearchOptions{Query: query})
+		if err != nil || resp.Total != want {
+			t.Errorf("Search(%q) = %d results (%v), want %d", query, resp.Total, err, want)
+		}
+	}
+
+	// Renaming to a path moves the tag, creating the new parent
+	react := tagByName(t, repo, "javascript/react")
+	react.Name = "frontend/react"
+	if err := repo.UpdateTag(react); err != nil {
+		t.Fatalf("UpdateTag failed: %v", err)
+	}
+	if frontend := tagByName(t, repo, "frontend"); react.ParentID != frontend.ID {
+		t.Errorf("react parent = %q, want %q", react.ParentID, frontend.ID)
+	}
+	assertItemTags(t, db, repo, items[1].ID, "web,frontend/react", 3)
+
+	// Invalid renames leave everything unchanged
+	js.Name = "web"
+	if err := repo.UpdateTag(js); !errors.Is(err, ErrTagExists) {
+		t.Errorf("Rename onto existing tag = %v, want ErrTagExists", err)
+	}
+	js.Name = "javascript/core"
+	if err := repo.UpdateTag(js); !errors.Is(err, ErrInvalidTagParent) {
+		t.Errorf("Rename under itself = %v, want ErrInvalidTagParent", err)
+	}
+	if tags, _ := repo.ListTags(); len(tags) != 4 {
+		t.Errorf("ListTags after failed renames = %v", tagNames(tags))
+	}
+}
+
+// TestUpdateTag_renameOntoDeleted verifies a deleted tag does not block
+// renaming another tag to its name.
+func TestUpdateTag_renameOntoDeleted(t *testing.T) {
+	db := setupMigratedTestDB(t)
+	defer db.Close()
+
+	repo := NewRepository(db)
+	items := createTaggedItems(t, repo, "js", "javascript")
+	deleted := tagByName(t, repo, "javascript")
+	if err := repo.DeleteTag(string(deleted.ID)); err != nil {
+		t.Fatalf("DeleteTag failed: %v", err)
+	}
+
+	js := tagByName(t, repo, "js")
+	js.Name = "JavaScript"
+	if err := repo.UpdateTag(js); err != nil {
+		t.Fatalf("Rename onto deleted tag = %v, want nil", err)
+	}
+	if got := tagByName(t, repo, "JavaScript"); got.ID != js.ID {
+		t.Errorf("JavaScript tag = %s, want %s", got.ID, js.ID)
+	}
+	assertItemTags(t, db, repo, items[0].ID, "JavaScript", 2)
+	assertItemTags(t, db, repo, items[1].ID, "", 2)
+}
+
+// TestMergeTags verifies merging moves assignments and children to the
+// target and deletes the source.
+func TestMergeTags(t *testing.T) {
+	db := setupMigratedTestDB(t)
+	defer db.Close()
+
+	repo := NewRepository(db)
+	items := createTaggedItems(t, repo, "js", "javascript,js", "js/react", "js/tools", "javascript/react")
+	js := tagByName(t, repo, "js")
+	javascript := tagByName(t, repo, "javascript")
+	tools := tagByName(t, repo, "js/tools")
+	jsReact := tagByName(t, repo, "js/react")
+
+	if _, err := repo.MergeTags(string(js.ID), string(jsReact.ID)); !errors.Is(err, ErrInvalidTagMerge) {
+		t.Errorf("Merge into own child = %v, want ErrInvalidTagMerge", err)
+	}
+	if _, err := repo.MergeTags(string(js.ID), string(js.ID)); !errors.Is(err, ErrInvalidTagMerge) {
+		t.Errorf("Merge into itself = %v, want ErrInvalidTagMerge", err)
+	}
+
+	target, err := repo.MergeTags(string(js.ID), string(javascript.ID))
+	if err != nil {
+		t.Fatalf("MergeTags failed: %v", err)
+	}
+	if target.ID != javascript.ID {
+		t.Errorf("MergeTags returned %q, want %q", target.ID, javascript.ID)
+	}
+
+	assertItemTags(t, db, repo, items[0].ID, "javascript", 2)
+	assertItemTags(t, db, repo, items[1].ID, "javascript", 2)
+	assertItemTags(t, db, repo, items[2].ID, "javascript/react", 2)
+	assertItemTags(t, db, repo, items[3].ID, "javascript/tools", 2)
+	assertItemTags(t, db, repo, items[4].ID, "javascript/react", 1)
+
+	// Children without a counterpart keep their identity
+	if moved := tagByName(t, repo, "javascript/tools"); moved.ID != tools.ID || moved.ParentID != javascript.ID {
+		t.Errorf("js/tools moved to %+v", moved)
+	}
+	for _, id := range []models.UUID{js.ID, jsReact.ID} {
+		if tag, err := repo.GetTag(string(id)); err != nil || !tag.IsDeleted {
+			t.Errorf("Merged tag %s = %+v (%v), want deleted", id, tag, err)
+		}
+	}
+
+	resp, err := repo.Search(&SearchOptions{Query: "body", Tags: "javascript"})
+	if err != nil || resp.Total != 5 {
+		t.Errorf("Search under merged tag = %d results (%v), want 5", resp.Total, err)
+	}
+	if _, err := repo.MergeTags(string(js.ID), string(javascript.ID)); err != sql.ErrNoRows {
+		t.Errorf("Merge of deleted tag = %v, want sql.ErrNoRows", err)
+	}
+}
@@ -0,0 +1,290 @@
+// Package db provides hierarchical tag operations.
+package db
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"github.com/kimhsiao/memonexus/backend/internal/models"
+)
+
+// tagPathSeparator separates the segments of a hierarchical tag name.
+const tagPathSeparator = "/"
+
+// tagColumns lists the tags columns read by scanTag.
+const tagColumns = `id, name, color, is_deleted, created_at, updated_at, parent_id`
+
+// tagSubtreeCTE selects the ids of a tag (the single argument) and all of
+// its descendants, deleted or not, as the CTE "subtree".
+const tagSubtreeCTE = `WITH RECURSIVE subtree(id) AS (
+	SELECT ?
+	UNION
+	SELECT t.id FROM tags t INNER JOIN subtree s ON t.parent_id = s.id
+)`
+
+var (
+	// ErrTagExists is returned when a tag would take a name already in use.
+	ErrTagExists = errors.New("tag already exists")
+
+	// ErrInvalidTagParent is returned when a tag is moved under a missing
+	// tag or under itself or one of its descendants.
+	ErrInvalidTagParent = errors.New("invalid parent tag")
+)
+
+// scanTag scans one row selected with tagColumns.
+func scanTag(row rowScanner) (*models.Tag, error) {
+	var tag models.Tag
+	var parentID sql.NullString
+	err := row.Scan(&tag.ID, &tag.Name, &tag.Color, &tag.IsDeleted, &tag.CreatedAt,
+		&tag.UpdatedAt, &parentID)
+	if err != nil {
+		return nil, err
+	}
+	tag.ParentID = models.UUID(parentID.String)
+	return &tag, nil
+}
+
+// nullTagID stores an empty tag id as NULL.
+func nullTagID(id models.UUID) sql.NullString {
+	return sql.NullString{String: string(id), Valid: id != ""}
+}
+
+// normalizeTagPath trims each segment of a slash-separated tag name and
+// drops empty segments, so " research / ml/ " becomes "research/ml".
+func normalizeTagPath(name string) string {
+	segments := strings.Split(name, tagPathSeparator)
+	kept := segments[:0]
+	for _, segment := range segments {
+		if segment = strings.TrimSpace(segment); segment != "" {
+			kept = append(kept, segment)
+		}
+	}
+	return strings.Join(kept, tagPathSeparator)
+}
+
+// parentTagPath returns the name of a tag's parent, or "" for a top-level tag.
+func parentTagPath(name string) string {
+	if i := strings.LastIndex(name, tagPathSeparator); i >= 0 {
+		return name[:i]
+	}
+	return ""
+}
+
+// tagLeaf returns the last segment of a tag name.
+func tagLeaf(name string) string {
+	return name[strings.LastIndex(name, tagPathSeparator)+1:]
+}
+
+// TagNode is a tag with its child tags, for tree listings.
+type TagNode struct {
+	*models.Tag
+	Children []*TagNode `json:"children"`
+}
+
+// ListTagTree returns live tags as a forest ordered by name. Tags whose
+// parent was deleted are listed at the top level.
+func (r *Repository) ListTagTree() ([]*TagNode, error) {
+	tags, err := r.ListTags()
+	if err != nil {
+		return nil, err
+	}
+
+	nodes := make(map[models.UUID]*TagNode, len(tags))
+	for _, tag := range tags {
+		nodes[tag.ID] = &TagNode{Tag: tag, Children: make([]*TagNode, 0)}
+	}
+	roots := make([]*TagNode, 0)
+	for _, tag := range tags {
+		if parent, ok := nodes[tag.ParentID]; ok {
+			parent.Children = append(parent.Children, nodes[tag.ID])
+		} else {
+			roots = append(roots, nodes[tag.ID])
+		}
+	}
+	return roots, nil
+}
+
+// MoveTag moves a tag and its descendants under parentID, or to the top
+// level if parentID is empty. The tag keeps its last name segment; every
+// name in the subtree is rewritten and content assignments are unchanged.
+// Returns sql.ErrNoRows if the tag does not exist.
+func (r *Repository) MoveTag(id, parentID string) (*models.Tag, error) {
+	tx, err := r.db.Begin()
+	if err != nil {
+		return nil, fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	tag, err := scanTag(tx.QueryRow(`SELECT `+tagColumns+` FROM tags WHERE id = ? AND is_deleted = 0`, id))
+	if err != nil {
+		return nil, err
+	}
+
+	name, err := childTagName(tx, tag, tagLeaf(tag.Name), models.UUID(parentID))
+	if err != nil {
+		return nil, err
+	}
+
+	if name != tag.Name || string(tag.ParentID) != parentID {
+		if err := moveTagSubtree(tx, tag, name, models.UUID(parentID), time.Now().Unix()); err != nil {
+			return nil, err
+		}
+	}
+
+	if err := tx.Commit(); err != nil {
+		return nil, fmt.Errorf("failed to commit transaction: %w", err)
+	}
+	return r.GetTag(id)
+}
+
+// childTagName returns the full name of a child of parentID whose last
+// segment is leaf, or leaf itself if parentID is empty. Fails with
+// ErrInvalidTagParent if parentID is not a live tag or is tag or one of its
+// descendants.
+func childTagName(tx *sql.Tx, tag *models.Tag, leaf string, parentID models.UUID) (string, error) {
+	if parentID == "" {
+		return leaf, nil
+	}
+
+	parent, err := scanTag(tx.QueryRow(`SELECT `+tagColumns+` FROM tags WHERE id = ? AND is_deleted = 0`, parentID))
+	if err == sql.ErrNoRows {
+		return "", fmt.Errorf("%w: tag %s not found", ErrInvalidTagParent, parentID)
+	}
+	if err != nil {
+		return "", err
+	}
+
+	var inSubtree int
+	err = tx.QueryRow(tagSubtreeCTE+` SELECT COUNT(*) FROM subtree WHERE id = ?`, tag.ID, parentID).Scan(&inSubtree)
+	if err != nil {
+		return "", err
+	}
+	if inSubtree > 0 {
+		return "", fmt.Errorf("%w: cannot move %q under itself", ErrInvalidTagParent, tag.Name)
+	}
+	return parent.Name + tagPathSeparator + leaf, nil
+}
+
+// moveTagSubtree gives tag a new name and parent, rewrites the names of its
+// descendants to the new prefix and refreshes the tag strings of every item
+// carrying one of the renamed tags. tag is updated in place.
+func moveTagSubtree(tx *sql.Tx, tag *models.Tag, name string, parentID models.UUID, now int64) error {
+	oldLength := utf8.RuneCountInString(tag.Name)
+
+	var longest int
+	err := tx.QueryRow(tagSubtreeCTE+`
+	SELECT COALESCE(MAX(length(name)), 0) FROM tags WHERE id IN (SELECT id FROM subtree)
+	`, tag.ID).Scan(&longest)
+	if err != nil {
+		return err
+	}
+	if longest-oldLength+utf8.RuneCountInString(name) > maxTagNameLength {
+		return fmt.Errorf("%w: moving %q to %q makes a descendant name too long", ErrInvalidTagName, tag.Name, name)
+	}
+
+	var conflict sql.NullString
+	err = tx.QueryRow(tagSubtreeCTE+`
+	SELECT name FROM tags
+	WHERE is_deleted = 0
+	  AND id NOT IN (SELECT id FROM subtree)
+	  AND lower(name) IN (
+	    SELECT lower(? || substr(name, ?)) FROM tags WHERE id IN (SELECT id FROM subtree)
+	  )
+	LIMIT 1
+	`, tag.ID, name, oldLength+1).Scan(&conflict)
+	if err != nil && err != sql.ErrNoRows {
+		return err
+	}
+	if conflict.Valid {
+		return fmt.Errorf("%w: %q", ErrTagExists, conflict.String)
+	}
+
+	// Deleted tags keep their names but no longer carry any items (DeleteTag
+	// unassigned them), so drop those holding one of the new names to free
+	// it for the unique name index
+	_, err = tx.Exec(tagSubtreeCTE+`
+	DELETE FROM tags
+	WHERE is_deleted = 1
+	  AND id NOT IN (SELECT id FROM subtree)
+	  AND lower(name) IN (
+	    SELECT lower(? || substr(name, ?)) FROM tags WHERE id IN (SELECT id FROM subtree)
+	  )
+	`, tag.ID, name, oldLength+1)
+	if err != nil {
+		return fmt.Errorf("failed to reclaim deleted tag names: %w", err)
+	}
+
+	_, err = tx.Exec(tagSubtreeCTE+`
+	UPDATE tags
+	SET name = ? || substr(name, ?),
+	    parent_id = CASE WHEN id = ? THEN ? ELSE parent_id END,
+	    updated_at = ?
+	WHERE id IN (SELECT id FROM subtree)
+	`, tag.ID, name, oldLength+1, tag.ID, nullTagID(parentID), now)
+	if err != nil {
+		return fmt.Errorf("failed to rename tags: %w", err)
+	}
+	tag.Name = name
+	tag.ParentID = parentID
+	tag.UpdatedAt = now
+
+	return refreshContentTagStrings(tx, tagSubtreeCTE+`
+	SELECT DISTINCT content_id FROM content_tags WHERE tag_id IN (SELECT id FROM subtree)
+	`, []interface{}{tag.ID}, now)
+}
+
+// refreshContentTagStrings rewrites the denormalized tags string (and so
+// the FTS index) of each item selected by query from its content_tags
+// links. Items whose string changed get a new version and a change_log
+// entry so the change syncs.
+func refreshContentTagStrings(tx *sql.Tx, query string, args []interface{}, now int64) error {
+	rows, err := tx.Query(query, args...)
+	if err != nil {
+		return fmt.Errorf("failed to find tagged items: %w", err)
+	}
+	var ids []string
+	for rows.Next() {
+		var id string
+		if err := rows.Scan(&id); err != nil {
+			rows.Close()
+			return err
+		}
+		ids = append(ids, id)
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return err
+	}
+
+	for _, id := range ids {
+		tags, err := contentTags(tx, id)
+		if err != nil {
+			return err
+		}
+		value := tagString(tags)
+		result, err := tx.Exec(`
+		UPDATE content_items SET tags = ?, updated_at = ?, version = version + 1
+		WHERE id = ? AND tags IS NOT ?
+		`, value, now, id, value)
+		if err != nil {
+			return fmt.Errorf("failed to update tags of %s: %w", id, err)
+		}
+		if changed, _ := result.RowsAffected(); changed == 0 {
+			continue
+		}
+
+		var version int
+		if err := tx.QueryRow(`SELECT version FROM content_items WHERE id = ?`, id).Scan(&version); err != nil {
+			return err
+		}
+		if err := insertChangeLog(context.Background(), tx, id, "update", version, now); err != nil {
+			return fmt.Errorf("failed to log change of %s: %w", id, err)
+		}
+	}
+	return nil
+}
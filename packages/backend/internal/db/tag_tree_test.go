@@ -0,0 +1,270 @@
+// Package db tests for hierarchical tags.
+package db
+
+import (
+	"database/sql"
+	"errors"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/kimhsiao/memonexus/backend/internal/models"
+)
+
+// tagByName returns the live tag with the given name.
+func tagByName(t *testing.T, repo *Repository, name string) *models.Tag {
+	t.Helper()
+	tags, err := repo.ListTags()
+	if err != nil {
+		t.Fatalf("ListTags failed: %v", err)
+	}
+	for _, tag := range tags {
+		if tag.Name == name {
+			return tag
+		}
+	}
+	t.Fatalf("Tag %q not found in %v", name, tagNames(tags))
+	return nil
+}
+
+// TestTagHierarchy verifies slash paths create their ancestors and tree
+// listing nests tags under their parents.
+func TestTagHierarchy(t *testing.T) {
+	db := setupMigratedTestDB(t)
+	defer db.Close()
+
+	repo := NewRepository(db)
+	if err := repo.CreateTag(&models.Tag{Name: "Research", Color: "#FF0000"}); err != nil {
+		t.Fatalf("CreateTag failed: %v", err)
+	}
+	tag := &models.Tag{Name: " research / ml /transformers", Color: "#00FF00"}
+	if err := repo.CreateTag(tag); err != nil {
+		t.Fatalf("CreateTag failed: %v", err)
+	}
+	if tag.Name != "Research/ml/transformers" {
+		t.Errorf("tag.Name = %q, want the parent's spelling Research/ml/transformers", tag.Name)
+	}
+
+	research := tagByName(t, repo, "Research")
+	ml := tagByName(t, repo, "Research/ml")
+	if ml.ParentID != research.ID || tag.ParentID != ml.ID || research.ParentID != "" {
+		t.Errorf("Unexpected parents: research %q, ml %q, transformers %q", research.ParentID, ml.ParentID, tag.ParentID)
+	}
+
+	// Assignment creates missing ancestors too
+	item := &models.ContentItem{Title: "Diffusion", MediaType: "web", Tags: "research/vision/diffusion"}
+	if err := repo.CreateContentItem(item); err != nil {
+		t.Fatalf("CreateContentItem failed: %v", err)
+	}
+	if item.Tags != "Research/vision/diffusion" {
+		t.Errorf("item.Tags = %q", item.Tags)
+	}
+
+	tree, err := repo.ListTagTree()
+	if err != nil {
+		t.Fatalf("ListTagTree failed: %v", err)
+	}
+	if len(tree) != 1 || tree[0].Name != "Research" || len(tree[0].Children) != 2 {
+		t.Fatalf("Unexpected tree: %+v", tree)
+	}
+	if got := tree[0].Children[0].Children[0].Name; got != "Research/ml/transformers" {
+		t.Errorf("Grandchild = %q, want Research/ml/transformers", got)
+	}
+
+	// A child of a deleted tag is listed at the top level
+	if err := repo.DeleteTag(string(research.ID)); err != nil {
+		t.Fatalf("DeleteTag failed: %v", err)
+	}
+	if tree, _ = repo.ListTagTree(); len(tree) != 2 {
+		t.Errorf("Tree after deleting parent has %d roots, want 2", len(tree))
+	}
+}
+
+// TestSearch_parentTagFilter verifies filtering by a parent tag includes
+// items tagged with its descendants.
+func TestSearch_parentTagFilter(t *testing.T) {
+	db := setupMigratedTestDB(t)
+	defer db.Close()
+
+	repo := NewRepository(db)
+	for _, tags := range []string{"research", "research/ml", "research/ml/transformers", "researchers"} {
+		item := &models.ContentItem{Title: "Paper " + tags, ContentText: "paper", MediaType: "pdf", Tags: tags}
+		if err := repo.CreateContentItem(item); err != nil {
+			t.Fatalf("CreateContentItem failed: %v", err)
+		}
+	}
+
+	tests := []struct {
+		tags string
+		want int
+	}{
+		{"research", 3},
+		{"Research/ML", 2},
+		{"research/ml/transformers", 1},
+		{"ml", 0},
+	}
+	for _, tt := range tests {
+		resp, err := repo.Search(&SearchOptions{Query: "paper", Tags: tt.tags})
+		if err != nil {
+			t.Fatalf("Search(tags %q) failed: %v", tt.tags, err)
+		}
+		if resp.Total != tt.want {
+			t.Errorf("Search(tags %q) = %d results, want %d", tt.tags, resp.Total, tt.want)
+		}
+	}
+}
+
+// TestTagPathLength verifies the length limit applies to each path segment
+// rather than the whole path.
+func TestTagPathLength(t *testing.T) {
+	db := setupMigratedTestDB(t)
+	defer db.Close()
+
+	repo := NewRepository(db)
+	segment := strings.Repeat("x", 40)
+	deep := strings.Join([]string{"research", "ml", segment, segment, "attention"}, "/")
+	item := &models.ContentItem{Title: "Paper", MediaType: "web", Tags: deep}
+	if err := repo.CreateContentItem(item); err != nil {
+		t.Fatalf("CreateContentItem with a %d character path failed: %v", len(deep), err)
+	}
+	attention := tagByName(t, repo, deep)
+
+	tooLong := &models.Tag{Name: "research/" + strings.Repeat("y", 51), Color: "#3B82F6"}
+	if err := repo.CreateTag(tooLong); !errors.Is(err, ErrInvalidTagName) {
+		t.Errorf("CreateTag with a long segment = %v, want ErrInvalidTagName", err)
+	}
+
+	// Moving a subtree under a deep parent is limited by the full path
+	parent := &models.Tag{Name: strings.Repeat(segment+"/", 5) + segment, Color: "#3B82F6"}
+	if err := repo.CreateTag(parent); err != nil {
+		t.Fatalf("CreateTag failed: %v", err)
+	}
+	if _, err := repo.MoveTag(string(tagByName(t, repo, "research").ID), string(parent.ID)); !errors.Is(err, ErrInvalidTagName) {
+		t.Errorf("MoveTag past the path limit = %v, want ErrInvalidTagName", err)
+	}
+	if _, err := repo.MoveTag(string(attention.ID), string(parent.ID)); err != nil {
+		t.Errorf("MoveTag under a deep parent failed: %v", err)
+	}
+}
+
+// TestMoveTag verifies moving a subtree rewrites names and item tag strings
+// without touching assignments.
+func TestMoveTag(t *testing.T) {
+	db := setupMigratedTestDB(t)
+	defer db.Close()
+
+	repo := NewRepository(db)
+	item := &models.ContentItem{Title: "Attention", ContentText: "attention", MediaType: "pdf",
+		Tags: "reading,research/ml/transformers"}
+	if err := repo.CreateContentItem(item); err != nil {
+		t.Fatalf("CreateContentItem failed: %v", err)
+	}
+	if err := repo.CreateTag(&models.Tag{Name: "ai", Color: "#3B82F6"}); err != nil {
+		t.Fatalf("CreateTag failed: %v", err)
+	}
+	ml := tagByName(t, repo, "research/ml")
+	ai := tagByName(t, repo, "ai")
+	before, _ := repo.ListContentTags(string(item.ID))
+
+	moved, err := repo.MoveTag(string(ml.ID), string(ai.ID))
+	if err != nil {
+		t.Fatalf("MoveTag failed: %v", err)
+	}
+	if moved.Name != "ai/ml" || moved.ParentID != ai.ID {
+		t.Errorf("Moved tag = %q under %q", moved.Name, moved.ParentID)
+	}
+	child := tagByName(t, repo, "ai/ml/transformers")
+	if child.ParentID != ml.ID {
+		t.Errorf("Child parent = %q, want %q", child.ParentID, ml.ID)
+	}
+
+	got, err := repo.GetContentItem(string(item.ID))
+	if err != nil {
+		t.Fatalf("GetContentItem failed: %v", err)
+	}
+	if got.Tags != "reading,ai/ml/transformers" || got.Version != 2 {
+		t.Errorf("Item after move: tags %q, version %d", got.Tags, got.Version)
+	}
+	after, _ := repo.ListContentTags(string(item.ID))
+	if before[1].ID != after[1].ID {
+		t.Errorf("Assignment changed from %s to %s", before[1].ID, after[1].ID)
+	}
+	resp, err := repo.Search(&SearchOptions{Query: "attention", Tags: "ai"})
+	if err != nil || resp.Total != 1 {
+		t.Errorf("Search under new parent = %d results (%v), want 1", resp.Total, err)
+	}
+
+	// Back to the top level
+	if moved, err = repo.MoveTag(string(ml.ID), ""); err != nil || moved.Name != "ml" || moved.ParentID != "" {
+		t.Errorf("MoveTag to top level = %+v, %v", moved, err)
+	}
+
+	// Invalid moves
+	if _, err := repo.MoveTag(string(ml.ID), string(child.ID)); !errors.Is(err, ErrInvalidTagParent) {
+		t.Errorf("Move under own child = %v, want ErrInvalidTagParent", err)
+	}
+	if _, err := repo.MoveTag(string(ml.ID), "00000000-0000-4000-8000-000000000000"); !errors.Is(err, ErrInvalidTagParent) {
+		t.Errorf("Move under missing tag = %v, want ErrInvalidTagParent", err)
+	}
+	if err := repo.CreateTag(&models.Tag{Name: "research/ML", Color: "#3B82F6"}); err != nil {
+		t.Fatalf("CreateTag failed: %v", err)
+	}
+	research := tagByName(t, repo, "research")
+	if _, err := repo.MoveTag(string(ml.ID), string(research.ID)); !errors.Is(err, ErrTagExists) {
+		t.Errorf("Move onto existing name = %v, want ErrTagExists", err)
+	}
+	if _, err := repo.MoveTag("00000000-0000-4000-8000-000000000000", ""); err != sql.ErrNoRows {
+		t.Errorf("Move of missing tag = %v, want sql.ErrNoRows", err)
+	}
+}
+
+// TestMigrations_tagHierarchy verifies V10 links existing slash-path tags
+// to their parents, creating missing ancestors.
+func TestMigrations_tagHierarchy(t *testing.T) {
+	db, err := sql.Open("sqlite", filepath.Join(t.TempDir(), "test.db"))
+	if err != nil {
+		t.Fatalf("Failed to open database: %v", err)
+	}
+	defer db.Close()
+
+	m := NewMigrator(db, "migrations")
+	if err := m.Initialize(); err != nil {
+		t.Fatalf("Initialize() failed: %v", err)
+	}
+	if err := m.Up(); err != nil {
+		t.Fatalf("Up() failed: %v", err)
+	}
+	if err := m.MigrateTo(9); err != nil {
+		t.Fatalf("MigrateTo(9) failed: %v", err)
+	}
+
+	_, err = db.Exec(`
+	INSERT INTO tags (id, name, created_at, updated_at, is_deleted)
+	VALUES ('00000000-0000-4000-8000-0000000000a1', 'Research', 100, 100, 1),
+	       ('00000000-0000-4000-8000-0000000000a2', 'research/ml/transformers', 100, 100, 0),
+	       ('00000000-0000-4000-8000-0000000000a3', 'plain', 100, 100, 0);
+	`)
+	if err != nil {
+		t.Fatalf("Failed to seed pre-V10 tags: %v", err)
+	}
+	if err := m.Up(); err != nil {
+		t.Fatalf("Up() failed: %v", err)
+	}
+
+	repo := NewRepository(db)
+	all, _ := repo.ListTags()
+	want := []string{"Research", "plain", "research/ml", "research/ml/transformers"}
+	if !reflect.DeepEqual(tagNames(all), want) {
+		t.Fatalf("Tags after V10 = %v, want %v", tagNames(all), want)
+	}
+	parents := map[string]models.UUID{}
+	for _, tag := range all {
+		parents[tag.Name] = tag.ParentID
+	}
+	ml := tagByName(t, repo, "research/ml")
+	if parents["research/ml"] != "00000000-0000-4000-8000-0000000000a1" ||
+		parents["research/ml/transformers"] != ml.ID || parents["plain"] != "" || len(ml.ID) != 36 {
+		t.Errorf("Unexpected parents after V10: %v", parents)
+	}
+}
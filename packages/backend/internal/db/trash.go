@@ -0,0 +1,172 @@
+// Package db provides the trash bin for deleted content items.
+package db
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"time"
+
+	"github.com/kimhsiao/memonexus/backend/internal/models"
+)
+
+// Items deleted with DeleteContentItem (is_deleted = 1) stay in the trash,
+// restorable, until they are purged explicitly or by the retention job once
+// they have been deleted longer than the retention period. While an item is
+// in the trash its updated_at is the time it was deleted.
+
+// contentItemDependents lists tables whose rows are removed with a purged
+// item. Their foreign keys cascade, but only when foreign_keys is enabled on
+// the connection, so purges delete them explicitly.
+var contentItemDependents = []string{
+	"DELETE FROM content_tags WHERE content_id IN (%s)",
+	"DELETE FROM content_engagement WHERE content_id IN (%s)",
+	"DELETE FROM change_log WHERE item_id IN (%s)",
+	"DELETE FROM conflict_log WHERE item_id IN (%s)",
+	"DELETE FROM content_revisions WHERE content_id IN (%s)",
+	"DELETE FROM collection_items WHERE content_id IN (%s)",
+	"DELETE FROM content_links WHERE source_id IN (%s)",
+	"DELETE FROM annotations WHERE content_id IN (%s)",
+	"DELETE FROM content_properties WHERE content_id IN (%s)",
+}
+
+// ListTrash returns deleted content items, most recently deleted first.
+func (r *Repository) ListTrash(limit, offset int) ([]*models.ContentItem, error) {
+	rows, err := r.db.Query(`
+	SELECT `+contentItemColumns+`
+	FROM content_items WHERE is_deleted = 1
+	ORDER BY updated_at DESC, id DESC LIMIT ? OFFSET ?
+	`, limit, offset)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	items := make([]*models.ContentItem, 0)
+	for rows.Next() {
+		item, err := scanContentItem(rows)
+		if err != nil {
+			return nil, err
+		}
+		items = append(items, item)
+	}
+	return items, rows.Err()
+}
+
+// RestoreContentItem moves an item out of the trash and returns it. The
+// version is bumped and logged so the restore syncs. Returns sql.ErrNoRows
+// if the item is not in the trash.
+func (r *Repository) RestoreContentItem(id string) (*models.ContentItem, error) {
+	tx, err := r.db.Begin()
+	if err != nil {
+		return nil, fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	now := time.Now().Unix()
+	result, err := tx.Exec(`
+	UPDATE content_items SET is_deleted = 0, updated_at = ?, version = version + 1
+	WHERE id = ? AND is_deleted = 1
+	`, now, id)
+	if err != nil {
+		return nil, err
+	}
+	if rows, _ := result.RowsAffected(); rows == 0 {
+		return nil, sql.ErrNoRows
+	}
+
+	var version int
+	var title string
+	if err := tx.QueryRow(`SELECT version, title FROM content_items WHERE id = ?`, id).Scan(&version, &title); err != nil {
+		return nil, err
+	}
+	if err := resolveWikiLinks(context.Background(), tx, id, title); err != nil {
+		return nil, err
+	}
+	if err := insertChangeLog(context.Background(), tx, id, "update", version, now); err != nil {
+		return nil, fmt.Errorf("failed to log restore: %w", err)
+	}
+
+	if err := tx.Commit(); err != nil {
+		return nil, fmt.Errorf("failed to commit transaction: %w", err)
+	}
+	return r.GetContentItem(id)
+}
+
+// PurgeContentItem permanently deletes an item in the trash. Returns
+// sql.ErrNoRows if the item is not in the trash.
+func (r *Repository) PurgeContentItem(id string) error {
+	purged, err := r.purgeTrash(`id = ?`, id)
+	if err != nil {
+		return err
+	}
+	if purged == 0 {
+		return sql.ErrNoRows
+	}
+	return nil
+}
+
+// EmptyTrash permanently deletes every item in the trash and returns the
+// number of items deleted.
+func (r *Repository) EmptyTrash() (int, error) {
+	return r.purgeTrash(`1 = 1`)
+}
+
+// PurgeTrash permanently deletes items that were moved to the trash before
+// the Unix time before and returns the number of items deleted.
+func (r *Repository) PurgeTrash(before int64) (int, error) {
+	return r.purgeTrash(`updated_at < ?`, before)
+}
+
+// purgeTrash permanently deletes the trashed items matching where.
+func (r *Repository) purgeTrash(where string, args ...interface{}) (int, error) {
+	tx, err := r.db.Begin()
+	if err != nil {
+		return 0, fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	purged := `SELECT id FROM content_items WHERE is_deleted = 1 AND ` + where
+	for _, query := range contentItemDependents {
+		if _, err := tx.Exec(fmt.Sprintf(query, purged), args...); err != nil {
+			return 0, fmt.Errorf("failed to purge item data: %w", err)
+		}
+	}
+	result, err := tx.Exec(`DELETE FROM content_items WHERE id IN (`+purged+`)`, args...)
+	if err != nil {
+		return 0, fmt.Errorf("failed to purge items: %w", err)
+	}
+	if err := tx.Commit(); err != nil {
+		return 0, fmt.Errorf("failed to commit transaction: %w", err)
+	}
+
+	count, _ := result.RowsAffected()
+	return int(count), nil
+}
+
+// ContentHashes returns the content hashes of all items, including those
+// in the trash, and of their revisions, which restoring brings back, for
+// cleaning up unreferenced media files.
+func (r *Repository) ContentHashes() (map[string]bool, error) {
+	rows, err := r.db.Query(`
+	SELECT content_hash FROM content_items
+	WHERE content_hash IS NOT NULL AND content_hash != ''
+	UNION
+	SELECT content_hash FROM content_revisions
+	WHERE content_hash IS NOT NULL AND content_hash != ''
+	`)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	hashes := make(map[string]bool)
+	for rows.Next() {
+		var hash string
+		if err := rows.Scan(&hash); err != nil {
+			return nil, err
+		}
+		hashes[hash] = true
+	}
+	return hashes, rows.Err()
+}
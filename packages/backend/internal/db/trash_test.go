@@ -0,0 +1,113 @@
+// Package db tests for the trash bin.
+package db
+
+import (
+	"database/sql"
+	"testing"
+)
+
+// TestTrash verifies deleted items can be listed, restored and purged.
+func TestTrash(t *testing.T) {
+	db := setupMigratedTestDB(t)
+	defer db.Close()
+
+	repo := NewRepository(db)
+	items := createTaggedItems(t, repo, "go", "go", "rust")
+	for _, item := range items[:2] {
+		if err := repo.DeleteContentItem(string(item.ID)); err != nil {
+			t.Fatalf("DeleteContentItem failed: %v", err)
+		}
+	}
+	// Deletion order decides trash order
+	if _, err := db.Exec(`UPDATE content_items SET created_at = created_at - 10, updated_at = updated_at - 10 WHERE id = ?`, items[0].ID); err != nil {
+		t.Fatalf("Failed to age item: %v", err)
+	}
+
+	trash, err := repo.ListTrash(10, 0)
+	if err != nil {
+		t.Fatalf("ListTrash failed: %v", err)
+	}
+	if len(trash) != 2 || trash[0].ID != items[1].ID || !trash[0].IsDeleted {
+		t.Fatalf("ListTrash = %+v, want items 1 and 0", trash)
+	}
+
+	restored, err := repo.RestoreContentItem(string(items[1].ID))
+	if err != nil {
+		t.Fatalf("RestoreContentItem failed: %v", err)
+	}
+	if restored.IsDeleted || restored.Version != 3 || restored.Tags != "go" {
+		t.Errorf("Restored item = %+v", restored)
+	}
+	var logged int
+	db.QueryRow(`SELECT COUNT(*) FROM change_log WHERE item_id = ? AND version = 3`, items[1].ID).Scan(&logged)
+	if logged != 1 {
+		t.Errorf("change_log entries for restore = %d, want 1", logged)
+	}
+	if _, err := repo.RestoreContentItem(string(items[1].ID)); err != sql.ErrNoRows {
+		t.Errorf("Restore of live item = %v, want sql.ErrNoRows", err)
+	}
+
+	// Only trashed items can be purged
+	if err := repo.PurgeContentItem(string(items[2].ID)); err != sql.ErrNoRows {
+		t.Errorf("Purge of live item = %v, want sql.ErrNoRows", err)
+	}
+	if err := repo.PurgeContentItem(string(items[0].ID)); err != nil {
+		t.Fatalf("PurgeContentItem failed: %v", err)
+	}
+	var remaining int
+	db.QueryRow(`SELECT COUNT(*) FROM content_tags WHERE content_id = ?`, items[0].ID).Scan(&remaining)
+	if remaining != 0 {
+		t.Errorf("Purged item still has %d tag links", remaining)
+	}
+	if trash, _ := repo.ListTrash(10, 0); len(trash) != 0 {
+		t.Errorf("Trash after purge = %d items, want 0", len(trash))
+	}
+	if resp, err := repo.Search(&SearchOptions{Query: "body"}); err != nil || resp.Total != 2 {
+		t.Errorf("Search after purge = %d results (%v), want 2", resp.Total, err)
+	}
+}
+
+// TestPurgeTrash verifies only items trashed before the cutoff are purged
+// and EmptyTrash removes the rest.
+func TestPurgeTrash(t *testing.T) {
+	db := setupMigratedTestDB(t)
+	defer db.Close()
+
+	repo := NewRepository(db)
+	items := createTaggedItems(t, repo, "", "", "")
+	items[0].ContentHash = "hash-a"
+	items[1].ContentHash = "hash-b"
+	for i, item := range items {
+		if err := repo.UpdateContentItem(item); err != nil {
+			t.Fatalf("UpdateContentItem failed: %v", err)
+		}
+		if i < 2 {
+			if err := repo.DeleteContentItem(string(item.ID)); err != nil {
+				t.Fatalf("DeleteContentItem failed: %v", err)
+			}
+		}
+	}
+	var deletedAt int64
+	db.QueryRow(`SELECT updated_at FROM content_items WHERE id = ?`, items[1].ID).Scan(&deletedAt)
+	if _, err := db.Exec(`UPDATE content_items SET created_at = ?, updated_at = ? WHERE id = ?`,
+		deletedAt-100, deletedAt-100, items[0].ID); err != nil {
+		t.Fatalf("Failed to age item: %v", err)
+	}
+
+	purged, err := repo.PurgeTrash(deletedAt - 50)
+	if err != nil || purged != 1 {
+		t.Fatalf("PurgeTrash = %d, %v; want 1", purged, err)
+	}
+	hashes, err := repo.ContentHashes()
+	if err != nil || len(hashes) != 1 || !hashes["hash-b"] {
+		t.Errorf("ContentHashes = %v, %v; want trashed item's hash-b only", hashes, err)
+	}
+
+	purged, err = repo.EmptyTrash()
+	if err != nil || purged != 1 {
+		t.Errorf("EmptyTrash = %d, %v; want 1", purged, err)
+	}
+	if _, err := repo.GetContentItem(string(items[2].ID)); err != nil {
+		t.Errorf("Live item was purged: %v", err)
+	}
+}
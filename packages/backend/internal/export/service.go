@@ -59,6 +59,10 @@ type ExportManifest struct {
 	Checksum     string    `json:"checksum"`
 	Encrypted    bool      `json:"encrypted"`
 	IncludeMedia bool      `json:"include_media"`
+
+	// AnnotationCount is the number of annotations in annotations.json,
+	// which archives made before annotations existed do not have
+	AnnotationCount int `json:"annotation_count,omitempty"`
 }
 
 // ExportResult represents the result of an export operation.
@@ -69,6 +73,8 @@ type ExportResult struct {
 	Checksum  string
 	Encrypted bool
 	Duration  time.Duration
+
+	AnnotationCount int
 }
 
 // ImportResult represents the result of an import operation.
@@ -76,6 +82,8 @@ type ImportResult struct {
 	ImportedCount int
 	SkippedCount  int
 	Duration      time.Duration
+
+	ImportedAnnotations int
 }
 
 // Export creates an encrypted export archive of all data.
@@ -128,6 +136,18 @@ func (s *ExportService) Export(config *ExportConfig) (*ExportResult, error) {
 	manifest.ItemCount = itemCount
 	manifest.Checksum = checksum
 
+	// Create annotations file
+	annotationCount, err := s.createAnnotationsFile(filepath.Join(tempDir, "annotations.json"))
+	if err != nil {
+		logging.ErrorWithCode("Export failed: annotations file creation",
+			string(errors.ErrInternal), err,
+			map[string]interface{}{
+				"correlation_id": correlationID,
+			})
+		return nil, fmt.Errorf("failed to create annotations file: %w", err)
+	}
+	manifest.AnnotationCount = annotationCount
+
 	// Create manifest file
 	manifestFile := filepath.Join(tempDir, "manifest.json")
 	if err := s.writeManifest(manifestFile, &manifest); err != nil {
@@ -215,6 +235,8 @@ func (s *ExportService) Export(config *ExportConfig) (*ExportResult, error) {
 		Checksum:  checksum,
 		Encrypted: config.Password != "",
 		Duration:  time.Since(startTime),
+
+		AnnotationCount: annotationCount,
 	}
 
 	// Log successful completion
@@ -317,7 +339,8 @@ func (s *ExportService) Import(config *ImportConfig) (*ImportResult, error) {
 	}
 
 	// Import data
-	importedCount, skippedCount, err := s.importDataFile(dataFilePath)
+	ids := make(map[models.UUID]models.UUID)
+	importedCount, skippedCount, err := s.importDataFile(dataFilePath, ids)
 	if err != nil {
 		logging.ErrorWithCode("Import failed: data import",
 			string(errors.ErrInternal), err,
@@ -327,10 +350,23 @@ func (s *ExportService) Import(config *ImportConfig) (*ImportResult, error) {
 		return nil, fmt.Errorf("failed to import data: %w", err)
 	}
 
+	// Import annotations onto the imported items
+	importedAnnotations, err := s.importAnnotationsFile(filepath.Join(tempDir, "annotations.json"), ids)
+	if err != nil {
+		logging.ErrorWithCode("Import failed: annotations import",
+			string(errors.ErrInternal), err,
+			map[string]interface{}{
+				"correlation_id": correlationID,
+			})
+		return nil, fmt.Errorf("failed to import annotations: %w", err)
+	}
+
 	result := &ImportResult{
 		ImportedCount: importedCount,
 		SkippedCount:  skippedCount,
 		Duration:      time.Since(startTime),
+
+		ImportedAnnotations: importedAnnotations,
 	}
 
 	// Log successful completion
@@ -396,8 +432,10 @@ func (s *ExportService) readManifest(path string) (*ExportManifest, error) {
 	return &manifest, nil
 }
 
-// importDataFile imports items from the data JSON file.
-func (s *ExportService) importDataFile(path string) (int, int, error) {
+// importDataFile imports items from the data JSON file. If ids is not nil,
+// it maps the archived ID of each imported or existing item to its local
+// ID, which differs for imported items.
+func (s *ExportService) importDataFile(path string, ids map[models.UUID]models.UUID) (int, int, error) {
 	// Read file
 	data, err := os.ReadFile(path)
 	if err != nil {
@@ -421,15 +459,22 @@ func (s *ExportService) importDataFile(path string) (int, int, error) {
 			continue
 		}
 		if exists {
+			if ids != nil {
+				ids[item.ID] = item.ID
+			}
 			skippedCount++
 			continue
 		}
 
+		archivedID := item.ID
 		if err := s.createItem(item); err != nil {
 			// Log and continue on error
 			skippedCount++
 			continue
 		}
+		if ids != nil {
+			ids[archivedID] = item.ID
+		}
 
 		importedCount++
 	}
@@ -437,6 +482,78 @@ func (s *ExportService) importDataFile(path string) (int, int, error) {
 	return importedCount, skippedCount, nil
 }
 
+// createAnnotationsFile writes the live annotations to a JSON file when
+// the repository supports them, returning how many were written.
+func (s *ExportService) createAnnotationsFile(path string) (int, error) {
+	repo, ok := s.repo.(db.AnnotationSyncRepository)
+	if !ok {
+		return 0, nil
+	}
+	all, err := repo.ListAnnotationsForSync()
+	if err != nil {
+		return 0, err
+	}
+	annotations := make([]*models.Annotation, 0, len(all))
+	for _, a := range all {
+		if !a.IsDeleted {
+			annotations = append(annotations, a)
+		}
+	}
+
+	data, err := json.MarshalIndent(annotations, "", "  ")
+	if err != nil {
+		return 0, err
+	}
+	if err := os.WriteFile(path, data, 0644); err != nil {
+		return 0, err
+	}
+	return len(annotations), nil
+}
+
+// importAnnotationsFile imports annotations from the annotations JSON file
+// onto the items mapped by ids, returning how many were stored. Archives
+// without the file import no annotations. Annotations on items imported
+// under a new ID get a new ID too.
+func (s *ExportService) importAnnotationsFile(path string, ids map[models.UUID]models.UUID) (int, error) {
+	repo, ok := s.repo.(db.AnnotationSyncRepository)
+	if !ok {
+		return 0, nil
+	}
+	data, err := os.ReadFile(path)
+	if stderrors.Is(err, os.ErrNotExist) {
+		return 0, nil
+	}
+	if err != nil {
+		return 0, err
+	}
+
+	var annotations []*models.Annotation
+	if err := json.Unmarshal(data, &annotations); err != nil {
+		return 0, err
+	}
+
+	imported := 0
+	for _, a := range annotations {
+		contentID, ok := ids[a.ContentID]
+		if !ok {
+			continue
+		}
+		if contentID != a.ContentID {
+			a.ID = models.UUID(uuid.New().String())
+			a.ContentID = contentID
+		}
+		applied, err := repo.ApplyRemoteAnnotation(a)
+		if err != nil {
+			// Log and continue on error
+			continue
+		}
+		if applied {
+			imported++
+		}
+	}
+	return imported, nil
+}
+
 // itemExists checks if an item already exists.
 func (s *ExportService) itemExists(id models.UUID) (bool, error) {
 	_, err := s.repo.GetContentItem(string(id))
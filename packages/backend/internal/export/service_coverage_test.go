@@ -1057,7 +1057,7 @@ func TestImportDataFile_validItem(t *testing.T) {
 		t.Fatalf("Failed to write data file: %v", err)
 	}
 
-	imported, skipped, err := service.importDataFile(dataPath)
+	imported, skipped, err := service.importDataFile(dataPath, nil)
 	if err != nil {
 		// This might fail due to UUID format or other validation
 		// Just verify it doesn't panic
@@ -1160,7 +1160,7 @@ func TestImportDataFile_repoError(t *testing.T) {
 		t.Fatalf("Failed to write data file: %v", err)
 	}
 
-	imported, skipped, err := service.importDataFile(dataPath)
+	imported, skipped, err := service.importDataFile(dataPath, nil)
 	// Should handle the error gracefully
 	if err == nil {
 		// If it didn't error, check that it skipped the invalid item
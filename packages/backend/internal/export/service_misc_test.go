@@ -460,7 +460,7 @@ func TestImportDataFile_emptyArray(t *testing.T) {
 		t.Fatalf("Failed to create data file: %v", err)
 	}
 
-	imported, skipped, err := service.importDataFile(dataPath)
+	imported, skipped, err := service.importDataFile(dataPath, nil)
 	if err != nil {
 		t.Fatalf("importDataFile() error = %v", err)
 	}
@@ -483,7 +483,7 @@ func TestImportDataFile_invalidJSON(t *testing.T) {
 		t.Fatalf("Failed to create data file: %v", err)
 	}
 
-	_, _, err := service.importDataFile(dataPath)
+	_, _, err := service.importDataFile(dataPath, nil)
 	if err == nil {
 		t.Error("importDataFile() with invalid JSON should return error")
 	}
@@ -493,7 +493,7 @@ func TestImportDataFile_invalidJSON(t *testing.T) {
 func TestImportDataFile_nonExistentFile(t *testing.T) {
 	service := &ExportService{}
 
-	_, _, err := service.importDataFile("/non/existent/data.json")
+	_, _, err := service.importDataFile("/non/existent/data.json", nil)
 	if err == nil {
 		t.Error("importDataFile() with non-existent file should return error")
 	}
@@ -740,7 +740,7 @@ func TestImportDataFile_withValidData(t *testing.T) {
 		}
 	}()
 
-	service.importDataFile(dataPath)
+	service.importDataFile(dataPath, nil)
 }
 
 // TestImportDataFile_malformedJSON verifies handling of malformed JSON.
@@ -754,7 +754,7 @@ func TestImportDataFile_malformedJSON(t *testing.T) {
 		t.Fatalf("Failed to create data file: %v", err)
 	}
 
-	_, _, err := service.importDataFile(dataPath)
+	_, _, err := service.importDataFile(dataPath, nil)
 	if err == nil {
 		t.Error("importDataFile() with malformed JSON should return error")
 	}
@@ -771,7 +771,7 @@ func TestImportDataFile_invalidItemType(t *testing.T) {
 		t.Fatalf("Failed to create data file: %v", err)
 	}
 
-	_, _, err := service.importDataFile(dataPath)
+	_, _, err := service.importDataFile(dataPath, nil)
 	if err == nil {
 		t.Error("importDataFile() with non-array JSON should return error")
 	}
@@ -927,7 +927,7 @@ func TestImportDataFile_itemExistsError(t *testing.T) {
 	os.WriteFile(dataFile, data, 0644)
 
 	// Import should skip items when itemExists fails
-	imported, skipped, err := service.importDataFile(dataFile)
+	imported, skipped, err := service.importDataFile(dataFile, nil)
 
 	if err != nil {
 		t.Errorf("importDataFile() should not return error, got: %v", err)
@@ -962,7 +962,7 @@ func TestImportDataFile_createItemError(t *testing.T) {
 	os.WriteFile(dataFile, data, 0644)
 
 	// Import should skip items when createItem fails
-	imported, skipped, err := service.importDataFile(dataFile)
+	imported, skipped, err := service.importDataFile(dataFile, nil)
 
 	if err != nil {
 		t.Errorf("importDataFile() should not return error, got: %v", err)
@@ -1000,7 +1000,7 @@ func TestImportDataFile_mixedSuccessAndFailure(t *testing.T) {
 	os.WriteFile(dataFile, data, 0644)
 
 	// Import should handle mixed scenarios
-	imported, skipped, err := service.importDataFile(dataFile)
+	imported, skipped, err := service.importDataFile(dataFile, nil)
 
 	if err != nil {
 		t.Errorf("importDataFile() should not return error, got: %v", err)
@@ -711,3 +711,49 @@ func TestCreateItem(t *testing.T) {
 		t.Errorf("Retrieved title = %q, want %q", retrieved.Title, item.Title)
 	}
 }
+
+// TestImport_annotations verifies annotations are exported and imported
+// onto the items they belong to.
+func TestImport_annotations(t *testing.T) {
+	database, repo := setupTestDB(t)
+	defer database.Close()
+
+	item := &models.ContentItem{Title: "Article", ContentText: "Some highlighted text", MediaType: "web"}
+	if err := repo.CreateContentItem(item); err != nil {
+		t.Fatalf("Failed to create test item: %v", err)
+	}
+	a := &models.Annotation{ContentID: item.ID, StartOffset: 5, EndOffset: 16, Note: "key point", Color: "yellow"}
+	if err := repo.CreateAnnotation(a); err != nil {
+		t.Fatalf("Failed to create annotation: %v", err)
+	}
+
+	service := NewExportService(repo)
+	exportResult, err := service.Export(&ExportConfig{OutputPath: filepath.Join(t.TempDir(), "annotated.tar.gz")})
+	if err != nil {
+		t.Fatalf("Export() failed: %v", err)
+	}
+	if exportResult.AnnotationCount != 1 {
+		t.Errorf("AnnotationCount = %d, want 1", exportResult.AnnotationCount)
+	}
+
+	if err := repo.DeleteContentItem(string(item.ID)); err != nil {
+		t.Fatalf("Failed to delete item: %v", err)
+	}
+	importResult, err := service.Import(&ImportConfig{ArchivePath: exportResult.FilePath})
+	if err != nil {
+		t.Fatalf("Import() failed: %v", err)
+	}
+	if importResult.ImportedAnnotations != 1 {
+		t.Errorf("ImportedAnnotations = %d, want 1", importResult.ImportedAnnotations)
+	}
+
+	// The item comes back under a new ID, with its annotation
+	items, _ := repo.ListContentItems(10, 0, "")
+	if len(items) != 1 {
+		t.Fatalf("Imported items = %d, want 1", len(items))
+	}
+	annotations, err := repo.ListAnnotations(string(items[0].ID))
+	if err != nil || len(annotations) != 1 || annotations[0].Quote != "highlighted" || annotations[0].Note != "key point" {
+		t.Errorf("Imported annotations = %+v, %v", annotations, err)
+	}
+}
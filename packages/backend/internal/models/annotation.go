@@ -0,0 +1,47 @@
+// Package models provides data model definitions for MemoNexus Core.
+package models
+
+import "time"
+
+// AnnotationColors lists the highlight colors an annotation may use.
+var AnnotationColors = []string{"yellow", "green", "blue", "pink", "purple"}
+
+// Annotation is a highlighted passage of a content item's text, with an
+// optional note. StartOffset and EndOffset count characters (Unicode code
+// points) of ContentText; Quote, Prefix and Suffix fingerprint the passage
+// so it can be found again after the text is edited.
+type Annotation struct {
+	ID          UUID   `db:"id" json:"id"`
+	ContentID   UUID   `db:"content_id" json:"content_id"`
+	StartOffset int    `db:"start_offset" json:"start_offset"`
+	EndOffset   int    `db:"end_offset" json:"end_offset"`
+	Quote       string `db:"quote" json:"quote"`   // Highlighted text
+	Prefix      string `db:"prefix" json:"prefix"` // Text just before the quote
+	Suffix      string `db:"suffix" json:"suffix"` // Text just after the quote
+	Note        string `db:"note" json:"note"`
+	Color       string `db:"color" json:"color"`
+	IsDeleted   bool   `db:"is_deleted" json:"is_deleted"`
+	CreatedAt   int64  `db:"created_at" json:"created_at"`
+	UpdatedAt   int64  `db:"updated_at" json:"updated_at"`
+	Version     int    `db:"version" json:"version"`
+
+	// Orphaned is set on reads when the quote can no longer be found in
+	// the item's text. The offsets are then those last saved.
+	Orphaned bool `db:"-" json:"orphaned,omitempty"`
+}
+
+// TableName returns the table name for Annotation.
+func (Annotation) TableName() string {
+	return "annotations"
+}
+
+// UpdatedAtTime returns the UpdatedAt as time.Time.
+func (a *Annotation) UpdatedAtTime() time.Time {
+	return time.Unix(a.UpdatedAt, 0)
+}
+
+// Touch updates the UpdatedAt timestamp and bumps the version.
+func (a *Annotation) Touch() {
+	a.UpdatedAt = time.Now().Unix()
+	a.Version++
+}
@@ -0,0 +1,39 @@
+// Package models provides data model definitions for MemoNexus Core.
+package models
+
+import "time"
+
+// Collection is a named, manually ordered group of content items, such as
+// a reading list or a course notebook. Collections nest: ParentID is the
+// enclosing collection, empty for top-level collections.
+type Collection struct {
+	ID          UUID   `db:"id" json:"id"`
+	Name        string `db:"name" json:"name"`
+	Description string `db:"description" json:"description"`
+	ParentID    UUID   `db:"parent_id" json:"parent_id,omitempty"`
+	Position    int    `db:"position" json:"position"` // Order among siblings
+	IsDeleted   bool   `db:"is_deleted" json:"is_deleted"`
+	CreatedAt   int64  `db:"created_at" json:"created_at"`
+	UpdatedAt   int64  `db:"updated_at" json:"updated_at"`
+	Version     int    `db:"version" json:"version"`
+
+	// ItemIDs are the member content items in order. Only filled in where
+	// noted, e.g. for sync, where it is the complete membership.
+	ItemIDs []UUID `db:"-" json:"item_ids,omitempty"`
+}
+
+// TableName returns the table name for Collection.
+func (Collection) TableName() string {
+	return "collections"
+}
+
+// UpdatedAtTime returns the UpdatedAt as time.Time.
+func (c *Collection) UpdatedAtTime() time.Time {
+	return time.Unix(c.UpdatedAt, 0)
+}
+
+// Touch updates the UpdatedAt timestamp and bumps the version.
+func (c *Collection) Touch() {
+	c.UpdatedAt = time.Now().Unix()
+	c.Version++
+}
@@ -60,6 +60,18 @@ type ContentItem struct {
 	UpdatedAt   int64   `db:"updated_at" json:"updated_at"`
 	Version     int     `db:"version" json:"version"`
 	ContentHash string  `db:"content_hash" json:"content_hash,omitempty"`
+
+	// Triage states. They change without bumping Version; each records
+	// when it last changed so sync can merge them field by field.
+	IsFavorite        bool    `db:"is_favorite" json:"is_favorite"`
+	IsPinned          bool    `db:"is_pinned" json:"is_pinned"`
+	ReadStatus        string  `db:"read_status" json:"read_status"`
+	ReadProgress      float64 `db:"read_progress" json:"read_progress"` // 0 to 1
+	IsArchived        bool    `db:"is_archived" json:"is_archived"`
+	FavoriteChangedAt int64   `db:"favorite_changed_at" json:"favorite_changed_at"`
+	PinnedChangedAt   int64   `db:"pinned_changed_at" json:"pinned_changed_at"`
+	ReadChangedAt     int64   `db:"read_changed_at" json:"read_changed_at"`
+	ArchivedChangedAt int64   `db:"archived_changed_at" json:"archived_changed_at"`
 }
 
 // TableName returns the table name for ContentItem.
@@ -0,0 +1,28 @@
+// Package models provides data model definitions for MemoNexus Core.
+package models
+
+// Link kinds.
+const (
+	LinkKindWiki = "wiki" // [[Title]]
+	LinkKindID   = "id"   // memonexus://item/<id>
+)
+
+// ContentLink is a link from one content item to another, parsed from the
+// source item's content when it is saved. Links are local and are not
+// synced; each device derives them from the synced content.
+type ContentLink struct {
+	SourceID    UUID   `db:"source_id" json:"source_id"`
+	SourceTitle string `db:"-" json:"source_title,omitempty"`
+	Kind        string `db:"kind" json:"kind"`
+	Target      string `db:"target" json:"target"` // Title or item ID as written
+	TargetID    UUID   `db:"target_id" json:"target_id,omitempty"`
+	TargetTitle string `db:"-" json:"target_title,omitempty"`
+	Position    int    `db:"position" json:"position"` // Order in the source
+	Resolved    bool   `db:"-" json:"resolved"`        // True when the target is a live item
+	CreatedAt   int64  `db:"created_at" json:"created_at"`
+}
+
+// TableName returns the table name for ContentLink.
+func (ContentLink) TableName() string {
+	return "content_links"
+}
@@ -154,6 +154,41 @@ func TestContentItem_Touch(t *testing.T) {
 	}
 }
 
+// TestContentItem_MergeStates verifies each triage state takes the newer
+// change.
+func TestContentItem_MergeStates(t *testing.T) {
+	local := ContentItem{
+		IsFavorite: true, FavoriteChangedAt: 200,
+		ReadStatus: ReadStatusUnread, ReadChangedAt: 100,
+	}
+	remote := ContentItem{
+		IsFavorite: false, FavoriteChangedAt: 150,
+		IsPinned: true, PinnedChangedAt: 300,
+		ReadStatus: ReadStatusInProgress, ReadProgress: 0.4, ReadChangedAt: 250,
+	}
+
+	merged := local.MergeStates(&remote)
+	if len(merged) != 2 || merged[0] != StatePinned || merged[1] != StateRead {
+		t.Errorf("MergeStates() = %v, want [pinned read]", merged)
+	}
+	if !local.IsFavorite || !local.IsPinned || local.ReadStatus != ReadStatusInProgress || local.ReadProgress != 0.4 {
+		t.Errorf("MergeStates() left %+v", local)
+	}
+	if merged := local.MergeStates(&remote); len(merged) != 0 {
+		t.Errorf("MergeStates() again = %v, want none", merged)
+	}
+}
+
+// TestReadStatusForProgress verifies progress maps to a read status.
+func TestReadStatusForProgress(t *testing.T) {
+	tests := map[float64]string{0: ReadStatusUnread, 0.5: ReadStatusInProgress, 1: ReadStatusRead}
+	for progress, want := range tests {
+		if got := ReadStatusForProgress(progress); got != want {
+			t.Errorf("ReadStatusForProgress(%v) = %q, want %q", progress, got, want)
+		}
+	}
+}
+
 // =====================================================
 // Tag Tests
 // =====================================================
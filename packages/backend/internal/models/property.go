@@ -0,0 +1,41 @@
+// Package models provides data model definitions for MemoNexus Core.
+package models
+
+import "time"
+
+// Property value types.
+const (
+	PropertyTypeString = "string"
+	PropertyTypeNumber = "number"
+	PropertyTypeDate   = "date" // RFC 3339, UTC
+	PropertyTypeURL    = "url"
+)
+
+// Property sources.
+const (
+	PropertySourceParser = "parser" // Extracted from the item's source
+	PropertySourceUser   = "user"   // Set by the user
+)
+
+// ContentProperty is a typed metadata value on a content item, such as
+// its author or publish date. Each item has at most one value per key.
+type ContentProperty struct {
+	ContentID UUID   `db:"content_id" json:"content_id"`
+	Key       string `db:"key" json:"key"`
+	Type      string `db:"type" json:"type"`
+	Value     string `db:"value" json:"value"` // Canonical text of the value
+	Source    string `db:"source" json:"source"`
+	IsDeleted bool   `db:"is_deleted" json:"is_deleted"`
+	UpdatedAt int64  `db:"updated_at" json:"updated_at"`
+	Version   int    `db:"version" json:"version"`
+}
+
+// TableName returns the table name for ContentProperty.
+func (ContentProperty) TableName() string {
+	return "content_properties"
+}
+
+// UpdatedAtTime returns the UpdatedAt as time.Time.
+func (p *ContentProperty) UpdatedAtTime() time.Time {
+	return time.Unix(p.UpdatedAt, 0)
+}
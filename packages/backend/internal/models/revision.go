@@ -0,0 +1,60 @@
+// Package models provides data model definitions for MemoNexus Core.
+package models
+
+import "time"
+
+// ContentRevision is a snapshot of a content item taken when an update
+// replaced it. Revisions are local and are not synced.
+type ContentRevision struct {
+	ID          UUID   `db:"id" json:"id"`
+	ContentID   UUID   `db:"content_id" json:"content_id"`
+	Version     int    `db:"version" json:"version"` // Item version captured
+	Title       string `db:"title" json:"title"`
+	ContentText string `db:"content_text" json:"content_text"`
+	SourceURL   string `db:"source_url" json:"source_url,omitempty"`
+	MediaType   string `db:"media_type" json:"media_type"`
+	Tags        string `db:"tags" json:"tags"` // Comma-separated
+	Summary     string `db:"summary" json:"summary,omitempty"`
+	ContentHash string `db:"content_hash" json:"content_hash,omitempty"`
+	EditedAt    int64  `db:"edited_at" json:"edited_at"`   // When the item was saved in this state
+	CreatedAt   int64  `db:"created_at" json:"created_at"` // When this state was replaced
+}
+
+// TableName returns the table name for ContentRevision.
+func (ContentRevision) TableName() string {
+	return "content_revisions"
+}
+
+// CreatedAtTime returns the CreatedAt as time.Time.
+func (r *ContentRevision) CreatedAtTime() time.Time {
+	return time.Unix(r.CreatedAt, 0)
+}
+
+// RevisionDiff describes the changes between two states of a content item.
+type RevisionDiff struct {
+	FromVersion int           `json:"from_version"`
+	ToVersion   int           `json:"to_version"`
+	Fields      []FieldChange `json:"fields"`    // Changed fields other than content_text
+	Lines       []DiffLine    `json:"lines"`     // Line diff of content_text
+	Unchanged   bool          `json:"unchanged"` // True when the states are identical
+}
+
+// FieldChange is a changed single-value field in a RevisionDiff.
+type FieldChange struct {
+	Field string `json:"field"`
+	From  string `json:"from"`
+	To    string `json:"to"`
+}
+
+// Diff line operations.
+const (
+	DiffEqual  = "equal"
+	DiffInsert = "insert"
+	DiffDelete = "delete"
+)
+
+// DiffLine is one line of a content_text diff.
+type DiffLine struct {
+	Op   string `json:"op"` // DiffEqual, DiffInsert or DiffDelete
+	Text string `json:"text"`
+}
@@ -0,0 +1,56 @@
+// Package models provides data model definitions for MemoNexus Core.
+package models
+
+import "time"
+
+// SavedSearch is a named search that is re-run on demand. Pinned saved
+// searches appear in the sidebar as smart collections.
+type SavedSearch struct {
+	ID        UUID          `db:"id" json:"id"`
+	Name      string        `db:"name" json:"name"`
+	Query     string        `db:"query" json:"query"` // Structured query, may be empty
+	Filters   SearchFilters `db:"filters" json:"filters"`
+	IsPinned  bool          `db:"is_pinned" json:"is_pinned"`
+	Position  int           `db:"position" json:"position"`
+	IsDeleted bool          `db:"is_deleted" json:"is_deleted"`
+	CreatedAt int64         `db:"created_at" json:"created_at"`
+	UpdatedAt int64         `db:"updated_at" json:"updated_at"`
+	Version   int           `db:"version" json:"version"`
+}
+
+// SearchFilters are the filters stored with a saved search, applied on top
+// of its query.
+type SearchFilters struct {
+	MediaType   string   `json:"media_type,omitempty"`
+	Tags        []string `json:"tags,omitempty"`         // Any of these tags
+	ExcludeTags []string `json:"exclude_tags,omitempty"` // None of these tags
+	DateFrom    int64    `json:"date_from,omitempty"`    // Unix timestamp, inclusive
+	DateTo      int64    `json:"date_to,omitempty"`      // Unix timestamp, inclusive
+	Collection  string   `json:"collection,omitempty"`   // Collection ID, including nested collections
+
+	// WithinDays limits results to items created in the last N days,
+	// evaluated each time the search runs
+	WithinDays int `json:"within_days,omitempty"`
+}
+
+// IsEmpty reports whether no filter is set.
+func (f SearchFilters) IsEmpty() bool {
+	return f.MediaType == "" && len(f.Tags) == 0 && len(f.ExcludeTags) == 0 &&
+		f.DateFrom == 0 && f.DateTo == 0 && f.Collection == "" && f.WithinDays == 0
+}
+
+// TableName returns the table name for SavedSearch.
+func (SavedSearch) TableName() string {
+	return "saved_searches"
+}
+
+// UpdatedAtTime returns the UpdatedAt as time.Time.
+func (s *SavedSearch) UpdatedAtTime() time.Time {
+	return time.Unix(s.UpdatedAt, 0)
+}
+
+// Touch updates the UpdatedAt timestamp and bumps the version.
+func (s *SavedSearch) Touch() {
+	s.UpdatedAt = time.Now().Unix()
+	s.Version++
+}
@@ -0,0 +1,28 @@
+// Package models provides data model definitions for MemoNexus Core.
+package models
+
+import "time"
+
+// SearchHistoryEntry records a search run by the user. History is local
+// unless the user opts in to syncing it.
+type SearchHistoryEntry struct {
+	ID               UUID          `db:"id" json:"id"`
+	Query            string        `db:"query" json:"query"`
+	Filters          SearchFilters `db:"filters" json:"filters"`
+	ResultCount      int           `db:"result_count" json:"result_count"`
+	ClickedContentID string        `db:"clicked_content_id" json:"clicked_content_id,omitempty"`
+	IsDeleted        bool          `db:"is_deleted" json:"is_deleted"`
+	CreatedAt        int64         `db:"created_at" json:"created_at"`
+	UpdatedAt        int64         `db:"updated_at" json:"updated_at"`
+	Version          int           `db:"version" json:"version"`
+}
+
+// TableName returns the table name for SearchHistoryEntry.
+func (SearchHistoryEntry) TableName() string {
+	return "search_history"
+}
+
+// CreatedAtTime returns the CreatedAt as time.Time.
+func (e *SearchHistoryEntry) CreatedAtTime() time.Time {
+	return time.Unix(e.CreatedAt, 0)
+}
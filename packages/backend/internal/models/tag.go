@@ -4,9 +4,13 @@ package models
 import "time"
 
 // Tag represents a user-defined label for organizing content.
+// Tags form a hierarchy: Name is the full slash-separated path
+// (e.g. "research/ml/transformers") and ParentID is the tag named by the
+// path without its last segment, empty for top-level tags.
 type Tag struct {
 	ID        UUID  `db:"id" json:"id"`
 	Name      string `db:"name" json:"name"`
+	ParentID  UUID   `db:"parent_id" json:"parent_id,omitempty"`
 	Color     string `db:"color" json:"color"`
 	IsDeleted bool  `db:"is_deleted" json:"is_deleted"`
 	CreatedAt int64 `db:"created_at" json:"created_at"`
@@ -0,0 +1,64 @@
+// Package models provides data model definitions for MemoNexus Core.
+package models
+
+// Read statuses of a content item.
+const (
+	ReadStatusUnread     = "unread"
+	ReadStatusInProgress = "in_progress"
+	ReadStatusRead       = "read"
+)
+
+// Triage state fields, as reported by MergeStates.
+const (
+	StateFavorite = "favorite"
+	StatePinned   = "pinned"
+	StateRead     = "read"
+	StateArchived = "archived"
+)
+
+// IsReadStatus reports whether status is a known read status.
+func IsReadStatus(status string) bool {
+	switch status {
+	case ReadStatusUnread, ReadStatusInProgress, ReadStatusRead:
+		return true
+	}
+	return false
+}
+
+// ReadStatusForProgress returns the read status matching a reading
+// progress between 0 and 1.
+func ReadStatusForProgress(progress float64) string {
+	switch {
+	case progress <= 0:
+		return ReadStatusUnread
+	case progress >= 1:
+		return ReadStatusRead
+	}
+	return ReadStatusInProgress
+}
+
+// MergeStates takes each triage state of other that changed later than
+// the same state of c, so concurrent changes to different states both
+// survive. Read status and progress move together. Returns the states
+// taken from other.
+func (c *ContentItem) MergeStates(other *ContentItem) []string {
+	var merged []string
+	if other.FavoriteChangedAt > c.FavoriteChangedAt {
+		c.IsFavorite, c.FavoriteChangedAt = other.IsFavorite, other.FavoriteChangedAt
+		merged = append(merged, StateFavorite)
+	}
+	if other.PinnedChangedAt > c.PinnedChangedAt {
+		c.IsPinned, c.PinnedChangedAt = other.IsPinned, other.PinnedChangedAt
+		merged = append(merged, StatePinned)
+	}
+	if other.ReadChangedAt > c.ReadChangedAt {
+		c.ReadStatus, c.ReadProgress = other.ReadStatus, other.ReadProgress
+		c.ReadChangedAt = other.ReadChangedAt
+		merged = append(merged, StateRead)
+	}
+	if other.ArchivedChangedAt > c.ArchivedChangedAt {
+		c.IsArchived, c.ArchivedChangedAt = other.IsArchived, other.ArchivedChangedAt
+		merged = append(merged, StateArchived)
+	}
+	return merged
+}
@@ -8,6 +8,7 @@ import (
 	"io"
 	"os"
 	"path/filepath"
+	"time"
 )
 
 // StorageManager handles file storage with SHA-256 content addressing.
@@ -245,6 +246,13 @@ func (s *StorageManager) GetStats() (*StorageStats, error) {
 // Cleanup removes files that are not referenced in the provided set.
 // The safeHashes parameter contains all hashes that should be kept.
 func (s *StorageManager) Cleanup(safeHashes map[string]bool) (removed int, freedBytes int64, err error) {
+	return s.CleanupBefore(safeHashes, time.Time{})
+}
+
+// CleanupBefore is like Cleanup but keeps unreferenced files modified at or
+// after cutoff, so files stored for an item that is still being saved are
+// not removed. A zero cutoff removes every unreferenced file.
+func (s *StorageManager) CleanupBefore(safeHashes map[string]bool, cutoff time.Time) (removed int, freedBytes int64, err error) {
 	// Build a set of safe hashes for fast lookup
 	safeSet := make(map[string]bool)
 	for hash := range safeHashes {
@@ -292,6 +300,9 @@ func (s *StorageManager) Cleanup(safeHashes map[string]bool) (removed int, freed
 				var size int64
 				if err == nil {
 					size = info.Size()
+					if !cutoff.IsZero() && !info.ModTime().Before(cutoff) {
+						continue
+					}
 				}
 
 				// Remove file
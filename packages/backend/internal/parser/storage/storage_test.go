@@ -8,6 +8,7 @@ import (
 	"path/filepath"
 	"strings"
 	"testing"
+	"time"
 )
 
 // TestNewStorageManager verifies storage manager creation.
@@ -705,3 +706,36 @@ type errorReader struct {
 func (r *errorReader) Read(p []byte) (n int, err error) {
 	return 0, r.err
 }
+
+// TestCleanupBefore_keepsRecentFiles verifies unreferenced files modified
+// after the cutoff are kept.
+func TestCleanupBefore_keepsRecentFiles(t *testing.T) {
+	tempDir := t.TempDir()
+	manager, err := NewStorageManager(tempDir)
+	if err != nil {
+		t.Fatalf("NewStorageManager() error = %v", err)
+	}
+
+	oldHash, _, _ := manager.StoreFile(bytes.NewReader([]byte("old orphan - more than 16 bytes")))
+	newHash, _, _ := manager.StoreFile(bytes.NewReader([]byte("new orphan - more than 16 bytes")))
+
+	oldPath, _ := manager.GetFilePath(oldHash)
+	past := time.Now().Add(-48 * time.Hour)
+	if err := os.Chtimes(oldPath, past, past); err != nil {
+		t.Fatalf("Chtimes() error = %v", err)
+	}
+
+	removed, _, err := manager.CleanupBefore(map[string]bool{}, time.Now().Add(-time.Hour))
+	if err != nil {
+		t.Fatalf("CleanupBefore() error = %v", err)
+	}
+	if removed != 1 {
+		t.Errorf("Removed = %d, want 1", removed)
+	}
+	if exists, _ := manager.FileExists(oldHash); exists {
+		t.Error("Old unreferenced file should be deleted")
+	}
+	if exists, _ := manager.FileExists(newHash); !exists {
+		t.Error("Recent unreferenced file should be kept")
+	}
+}
@@ -2,10 +2,12 @@
 package services
 
 import (
+	"context"
 	"database/sql"
 	"fmt"
 	"io"
 	"net/url"
+	"strconv"
 	"strings"
 	"time"
 
@@ -49,6 +51,11 @@ func NewContentService(database *sql.DB, storageDir string) (*ContentService, er
 	}, nil
 }
 
+// Storage returns the media store the service writes ingested files to.
+func (s *ContentService) Storage() *storage.StorageManager {
+	return s.storage
+}
+
 // CreateFromURL creates a content item from a URL.
 // T211: Critical operations logging with correlation ID.
 func (s *ContentService) CreateFromURL(sourceURL string) (*models.ContentItem, error) {
@@ -140,7 +147,9 @@ func (s *ContentService) CreateFromURL(sourceURL string) (*models.ContentItem, e
 		ContentHash: contentHash,
 	}
 
-	if err := s.repo.CreateContentItem(item); err != nil {
+	// Keep the metadata found by the parser as item properties, written
+	// with the item
+	if err := s.repo.CreateParsedContentItem(context.Background(), item, parsedProperties(result)); err != nil {
 		logging.ErrorWithCode("Failed to create content item", string(errors.ErrDatabase), err,
 			map[string]interface{}{
 				"correlation_id": correlationID,
@@ -295,6 +304,29 @@ func (s *ContentService) findByContentHash(contentHash string) (*models.ContentI
 	return nil, sql.ErrNoRows
 }
 
+// parsedProperties converts the metadata of a parse result to content
+// properties: author, published, language, word_count and canonical_url.
+// Metadata the parser did not find is left out.
+func parsedProperties(result *parser.ParseResult) []*models.ContentProperty {
+	var properties []*models.ContentProperty
+	add := func(key, propertyType, value string) {
+		if strings.TrimSpace(value) != "" {
+			properties = append(properties, &models.ContentProperty{Key: key, Type: propertyType, Value: value})
+		}
+	}
+
+	add("author", models.PropertyTypeString, result.Author)
+	if result.PublishDate != nil && !result.PublishDate.IsZero() {
+		add("published", models.PropertyTypeDate, result.PublishDate.UTC().Format(time.RFC3339))
+	}
+	add("language", models.PropertyTypeString, result.Language)
+	if result.WordCount > 0 {
+		add("word_count", models.PropertyTypeNumber, strconv.Itoa(result.WordCount))
+	}
+	add("canonical_url", models.PropertyTypeURL, result.CanonicalURL)
+	return properties
+}
+
 // detectMediaTypeFromPath detects media type from file path extension.
 func (s *ContentService) detectMediaTypeFromPath(path string) parser.MediaType {
 	dotIdx := strings.LastIndex(path, ".")
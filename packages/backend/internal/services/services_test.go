@@ -5,13 +5,17 @@ import (
 	"context"
 	"database/sql"
 	"fmt"
+	"os"
 	"strings"
 	"sync"
 	"testing"
+	"time"
 
 	"github.com/kimhsiao/memonexus/backend/internal/analysis"
 	"github.com/kimhsiao/memonexus/backend/internal/db"
 	"github.com/kimhsiao/memonexus/backend/internal/models"
+	"github.com/kimhsiao/memonexus/backend/internal/parser"
+	"github.com/kimhsiao/memonexus/backend/internal/parser/storage"
 )
 
 // =====================================================
@@ -604,6 +608,38 @@ func TestDetectMediaTypeFromPath(t *testing.T) {
 	}
 }
 
+// TestParsedProperties verifies parser metadata becomes typed properties.
+func TestParsedProperties(t *testing.T) {
+	published := time.Date(2024, 3, 1, 9, 30, 0, 0, time.FixedZone("CET", 3600))
+	properties := parsedProperties(&parser.ParseResult{
+		Author:       "Jane Doe",
+		PublishDate:  &published,
+		Language:     "en",
+		WordCount:    1200,
+		CanonicalURL: "https://example.com/post",
+	})
+
+	want := map[string]string{
+		"author":        "string:Jane Doe",
+		"published":     "date:2024-03-01T08:30:00Z",
+		"language":      "string:en",
+		"word_count":    "number:1200",
+		"canonical_url": "url:https://example.com/post",
+	}
+	if len(properties) != len(want) {
+		t.Fatalf("parsedProperties() returned %d properties, want %d", len(properties), len(want))
+	}
+	for _, p := range properties {
+		if got := p.Type + ":" + p.Value; got != want[p.Key] {
+			t.Errorf("property %s = %s, want %s", p.Key, got, want[p.Key])
+		}
+	}
+
+	if properties := parsedProperties(&parser.ParseResult{Title: "No metadata"}); len(properties) != 0 {
+		t.Errorf("parsedProperties() without metadata returned %d properties", len(properties))
+	}
+}
+
 // TestAnalyzeContent_emptyText verifies empty text error handling.
 func TestAnalyzeContent_emptyText(t *testing.T) {
 	svc := NewAnalysisService(nil)
@@ -2202,3 +2238,137 @@ func TestContentService_CreateFromFile_unicodeFilename(t *testing.T) {
 		t.Errorf("Title = %s, want %s (Unicode preserved)", item.Title, filename)
 	}
 }
+
+// =====================================================
+// TrashRetention Tests
+// =====================================================
+
+// TestTrashRetention_RunOnce verifies expired trash and orphaned media are
+// removed while recent trash and referenced media are kept.
+func TestTrashRetention_RunOnce(t *testing.T) {
+	database := setupTestDB(t)
+	defer database.Close()
+
+	store, err := storage.NewStorageManager(t.TempDir())
+	if err != nil {
+		t.Fatalf("NewStorageManager() failed: %v", err)
+	}
+	repo := db.NewRepository(database)
+	now := time.Now()
+	old := now.Add(-2 * time.Hour)
+
+	// One expired and one recent trashed item, each with its own media file
+	var hashes []string
+	for i, deletedAt := range []time.Time{now.AddDate(0, 0, -40), now.AddDate(0, 0, -5)} {
+		hash, _, err := store.StoreFile(strings.NewReader(strings.Repeat(fmt.Sprintf("media %d ", i), 20)))
+		if err != nil {
+			t.Fatalf("StoreFile() failed: %v", err)
+		}
+		path, _ := store.GetFilePath(hash)
+		os.Chtimes(path, old, old)
+		hashes = append(hashes, hash)
+
+		item := &models.ContentItem{Title: "Item", ContentText: "body", MediaType: "image", ContentHash: hash}
+		if err := repo.CreateContentItem(item); err != nil {
+			t.Fatalf("CreateContentItem() failed: %v", err)
+		}
+		if err := repo.DeleteContentItem(string(item.ID)); err != nil {
+			t.Fatalf("DeleteContentItem() failed: %v", err)
+		}
+		if _, err := database.Exec(`UPDATE content_items SET created_at = ?, updated_at = ? WHERE id = ?`,
+			deletedAt.Unix(), deletedAt.Unix(), item.ID); err != nil {
+			t.Fatalf("Failed to age item: %v", err)
+		}
+	}
+
+	// A fresh orphan is within the grace period and must survive
+	fresh, _, err := store.StoreFile(strings.NewReader(strings.Repeat("fresh media ", 20)))
+	if err != nil {
+		t.Fatalf("StoreFile() failed: %v", err)
+	}
+
+	result, err := NewTrashRetention(repo, store, DefaultTrashRetentionDays).RunOnce(now)
+	if err != nil {
+		t.Fatalf("RunOnce() failed: %v", err)
+	}
+	if result.PurgedItems != 1 || result.RemovedFiles != 1 || result.FreedBytes == 0 {
+		t.Errorf("RunOnce() = %+v, want 1 purged item and 1 removed file", result)
+	}
+
+	for hash, want := range map[string]bool{hashes[0]: false, hashes[1]: true, fresh: true} {
+		if exists, _ := store.FileExists(hash); exists != want {
+			t.Errorf("FileExists(%s) = %v, want %v", hash, exists, want)
+		}
+	}
+	if trash, _ := repo.ListTrash(10, 0); len(trash) != 1 || trash[0].ContentHash != hashes[1] {
+		t.Errorf("Trash after retention = %+v, want the recent item", trash)
+	}
+}
+
+// TestTrashRetention_revisionMedia verifies media referenced only by an
+// item's earlier revision survives cleanup, so the revision can be restored.
+func TestTrashRetention_revisionMedia(t *testing.T) {
+	database := setupTestDB(t)
+	defer database.Close()
+
+	store, err := storage.NewStorageManager(t.TempDir())
+	if err != nil {
+		t.Fatalf("NewStorageManager() failed: %v", err)
+	}
+	repo := db.NewRepository(database)
+	old := time.Now().Add(-2 * time.Hour)
+
+	var hashes []string
+	for _, content := range []string{"first version ", "second version "} {
+		hash, _, err := store.StoreFile(strings.NewReader(strings.Repeat(content, 20)))
+		if err != nil {
+			t.Fatalf("StoreFile() failed: %v", err)
+		}
+		path, _ := store.GetFilePath(hash)
+		os.Chtimes(path, old, old)
+		hashes = append(hashes, hash)
+	}
+
+	item := &models.ContentItem{Title: "Item", ContentText: "body", MediaType: "image", ContentHash: hashes[0]}
+	if err := repo.CreateContentItem(item); err != nil {
+		t.Fatalf("CreateContentItem() failed: %v", err)
+	}
+	item.ContentHash = hashes[1]
+	if err := repo.UpdateContentItem(item); err != nil {
+		t.Fatalf("UpdateContentItem() failed: %v", err)
+	}
+
+	result, err := NewTrashRetention(repo, store, DefaultTrashRetentionDays).RunOnce(time.Now())
+	if err != nil {
+		t.Fatalf("RunOnce() failed: %v", err)
+	}
+	if result.RemovedFiles != 0 {
+		t.Errorf("RunOnce() = %+v, want no removed files", result)
+	}
+	for _, hash := range hashes {
+		if exists, _ := store.FileExists(hash); !exists {
+			t.Errorf("FileExists(%s) = false, want true", hash)
+		}
+	}
+}
+
+// TestTrashRetention_disabled verifies a non-positive retention keeps trash.
+func TestTrashRetention_disabled(t *testing.T) {
+	database := setupTestDB(t)
+	defer database.Close()
+
+	repo := db.NewRepository(database)
+	item := &models.ContentItem{Title: "Item", ContentText: "body", MediaType: "web"}
+	if err := repo.CreateContentItem(item); err != nil {
+		t.Fatalf("CreateContentItem() failed: %v", err)
+	}
+	repo.DeleteContentItem(string(item.ID))
+
+	result, err := NewTrashRetention(repo, nil, 0).RunOnce(time.Now().AddDate(1, 0, 0))
+	if err != nil {
+		t.Fatalf("RunOnce() failed: %v", err)
+	}
+	if result.PurgedItems != 0 || result.RemovedFiles != 0 {
+		t.Errorf("RunOnce() with retention disabled = %+v, want nothing removed", result)
+	}
+}
@@ -0,0 +1,112 @@
+// Package services provides the trash retention job.
+package services
+
+import (
+	"context"
+	"time"
+
+	"github.com/kimhsiao/memonexus/backend/internal/db"
+	"github.com/kimhsiao/memonexus/backend/internal/errors"
+	"github.com/kimhsiao/memonexus/backend/internal/logging"
+	"github.com/kimhsiao/memonexus/backend/internal/parser/storage"
+)
+
+// DefaultTrashRetentionDays is how long deleted items stay in the trash
+// before the retention job purges them.
+const DefaultTrashRetentionDays = 30
+
+// mediaGracePeriod keeps recently stored media files that no item
+// references yet, because the item referencing them may still be saving.
+const mediaGracePeriod = time.Hour
+
+// RetentionResult summarizes one run of the trash retention job.
+type RetentionResult struct {
+	PurgedItems  int
+	RemovedFiles int
+	FreedBytes   int64
+}
+
+// TrashRetention periodically purges items that have been in the trash
+// longer than the retention period and removes media files no item
+// references any more.
+type TrashRetention struct {
+	repo    *db.Repository
+	storage *storage.StorageManager // nil disables media cleanup
+	days    int                     // 0 or less keeps trashed items forever
+	stopCh  chan struct{}
+}
+
+// NewTrashRetention creates a retention job keeping trashed items for days.
+func NewTrashRetention(repo *db.Repository, store *storage.StorageManager, days int) *TrashRetention {
+	return &TrashRetention{
+		repo:    repo,
+		storage: store,
+		days:    days,
+		stopCh:  make(chan struct{}),
+	}
+}
+
+// RunOnce purges expired trash and orphaned media as of now.
+func (t *TrashRetention) RunOnce(now time.Time) (*RetentionResult, error) {
+	result := &RetentionResult{}
+
+	if t.days > 0 {
+		purged, err := t.repo.PurgeTrash(now.AddDate(0, 0, -t.days).Unix())
+		if err != nil {
+			return result, err
+		}
+		result.PurgedItems = purged
+	}
+
+	// Trashed items still reference their media, so only purged items'
+	// files become orphans
+	if t.storage != nil {
+		hashes, err := t.repo.ContentHashes()
+		if err != nil {
+			return result, err
+		}
+		removed, freed, err := t.storage.CleanupBefore(hashes, now.Add(-mediaGracePeriod))
+		if err != nil {
+			return result, err
+		}
+		result.RemovedFiles = removed
+		result.FreedBytes = freed
+	}
+
+	logging.Info("Trash retention completed",
+		map[string]interface{}{
+			"retention_days": t.days,
+			"purged_items":   result.PurgedItems,
+			"removed_files":  result.RemovedFiles,
+			"freed_bytes":    result.FreedBytes,
+		})
+	return result, nil
+}
+
+// Start runs the job now and then every interval until Stop is called or
+// ctx is cancelled.
+func (t *TrashRetention) Start(ctx context.Context, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	go func() {
+		defer ticker.Stop()
+		for {
+			if _, err := t.RunOnce(time.Now()); err != nil {
+				logging.ErrorWithCode("Trash retention failed", string(errors.ErrDatabase), err,
+					map[string]interface{}{"retention_days": t.days})
+			}
+
+			select {
+			case <-ticker.C:
+			case <-t.stopCh:
+				return
+			case <-ctx.Done():
+				return
+			}
+		}
+	}()
+}
+
+// Stop stops a started job.
+func (t *TrashRetention) Stop() {
+	close(t.stopCh)
+}
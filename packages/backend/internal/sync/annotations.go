@@ -0,0 +1,51 @@
+// Package sync provides annotation synchronization.
+package sync
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+
+	"github.com/kimhsiao/memonexus/backend/internal/db"
+	"github.com/kimhsiao/memonexus/backend/internal/models"
+)
+
+// annotationPrefix is the object store prefix for annotations.
+const annotationPrefix = "annotations/"
+
+// syncAnnotations exchanges annotations, with their saved anchors, with
+// the remote store when the repository supports them.
+func (e *SyncEngine) syncAnnotations(ctx context.Context, syncID string) (uploaded, downloaded int, err error) {
+	repo, ok := e.repo.(db.AnnotationSyncRepository)
+	if !ok {
+		return 0, 0, nil
+	}
+
+	return e.exchangeRecords(ctx, syncID, recordExchange{
+		kind:   "annotation",
+		prefix: annotationPrefix,
+		apply: func(data []byte) (string, bool, error) {
+			var a models.Annotation
+			if err := json.Unmarshal(data, &a); err != nil {
+				return "", false, fmt.Errorf("failed to deserialize annotation: %w", err)
+			}
+			applied, err := repo.ApplyRemoteAnnotation(&a)
+			return string(a.ID), applied, err
+		},
+		local: func() ([]localRecord, error) {
+			annotations, err := repo.ListAnnotationsForSync()
+			if err != nil {
+				return nil, err
+			}
+			records := make([]localRecord, 0, len(annotations))
+			for _, a := range annotations {
+				data, err := json.Marshal(a)
+				if err != nil {
+					return nil, err
+				}
+				records = append(records, localRecord{id: string(a.ID), data: data, stamp: recordStamp{a.Version, a.UpdatedAt}})
+			}
+			return records, nil
+		},
+	})
+}
@@ -0,0 +1,65 @@
+// Package sync tests for annotation synchronization.
+package sync
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+
+	"github.com/kimhsiao/memonexus/backend/internal/models"
+)
+
+// mockAnnotationRepository adds annotation sync to mockSyncRepository.
+type mockAnnotationRepository struct {
+	*mockSyncRepository
+	annotations map[string]*models.Annotation
+}
+
+func (m *mockAnnotationRepository) ListAnnotationsForSync() ([]*models.Annotation, error) {
+	result := make([]*models.Annotation, 0, len(m.annotations))
+	for _, a := range m.annotations {
+		result = append(result, a)
+	}
+	return result, nil
+}
+
+func (m *mockAnnotationRepository) ApplyRemoteAnnotation(a *models.Annotation) (bool, error) {
+	local, ok := m.annotations[string(a.ID)]
+	if ok && local.Version >= a.Version {
+		return false, nil
+	}
+	m.annotations[string(a.ID)] = a
+	return true, nil
+}
+
+// TestSyncAnnotations verifies annotations are exchanged and the higher
+// version wins, including deletions.
+func TestSyncAnnotations(t *testing.T) {
+	store := newMockObjectStore()
+	repo := &mockAnnotationRepository{
+		mockSyncRepository: newMockSyncRepository(),
+		annotations: map[string]*models.Annotation{
+			"highlight": {ID: "highlight", ContentID: "item", Quote: "text", Note: "old", Version: 2},
+			"local":     {ID: "local", ContentID: "item", Quote: "more", Version: 1},
+		},
+	}
+
+	data, _ := json.Marshal(models.Annotation{ID: "highlight", ContentID: "item", Quote: "text",
+		Note: "old", IsDeleted: true, Version: 3})
+	store.Upload(context.Background(), annotationPrefix+"highlight.json", data)
+
+	engine := NewSyncEngine(repo, store)
+	uploaded, downloaded, err := engine.syncAnnotations(context.Background(), "test")
+	if err != nil {
+		t.Fatalf("syncAnnotations failed: %v", err)
+	}
+	if uploaded != 1 || downloaded != 1 {
+		t.Errorf("uploaded, downloaded = %d, %d; want 1, 1", uploaded, downloaded)
+	}
+	if !repo.annotations["highlight"].IsDeleted {
+		t.Error("Remote deletion was not applied")
+	}
+	if _, err := store.Download(context.Background(), annotationPrefix+"local.json"); err != nil {
+		t.Errorf("Local annotation was not uploaded: %v", err)
+	}
+}
@@ -0,0 +1,51 @@
+// Package sync provides collection synchronization.
+package sync
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+
+	"github.com/kimhsiao/memonexus/backend/internal/db"
+	"github.com/kimhsiao/memonexus/backend/internal/models"
+)
+
+// collectionPrefix is the object store prefix for collections.
+const collectionPrefix = "collections/"
+
+// syncCollections exchanges collections, each with its ordered item IDs,
+// with the remote store when the repository supports them.
+func (e *SyncEngine) syncCollections(ctx context.Context, syncID string) (uploaded, downloaded int, err error) {
+	repo, ok := e.repo.(db.CollectionSyncRepository)
+	if !ok {
+		return 0, 0, nil
+	}
+
+	return e.exchangeRecords(ctx, syncID, recordExchange{
+		kind:   "collection",
+		prefix: collectionPrefix,
+		apply: func(data []byte) (string, bool, error) {
+			var c models.Collection
+			if err := json.Unmarshal(data, &c); err != nil {
+				return "", false, fmt.Errorf("failed to deserialize collection: %w", err)
+			}
+			applied, err := repo.ApplyRemoteCollection(&c)
+			return string(c.ID), applied, err
+		},
+		local: func() ([]localRecord, error) {
+			collections, err := repo.ListCollectionsForSync()
+			if err != nil {
+				return nil, err
+			}
+			records := make([]localRecord, 0, len(collections))
+			for _, c := range collections {
+				data, err := json.Marshal(c)
+				if err != nil {
+					return nil, err
+				}
+				records = append(records, localRecord{id: string(c.ID), data: data, stamp: recordStamp{c.Version, c.UpdatedAt}})
+			}
+			return records, nil
+		},
+	})
+}
@@ -0,0 +1,68 @@
+// Package sync tests for collection synchronization.
+package sync
+
+import (
+	"context"
+	"encoding/json"
+	"reflect"
+	"testing"
+
+	"github.com/kimhsiao/memonexus/backend/internal/models"
+)
+
+// mockCollectionRepository adds collection sync to mockSyncRepository.
+type mockCollectionRepository struct {
+	*mockSyncRepository
+	collections map[string]*models.Collection
+}
+
+func (m *mockCollectionRepository) ListCollectionsForSync() ([]*models.Collection, error) {
+	result := make([]*models.Collection, 0, len(m.collections))
+	for _, c := range m.collections {
+		result = append(result, c)
+	}
+	return result, nil
+}
+
+func (m *mockCollectionRepository) ApplyRemoteCollection(c *models.Collection) (bool, error) {
+	local, ok := m.collections[string(c.ID)]
+	if ok && local.Version >= c.Version {
+		return false, nil
+	}
+	m.collections[string(c.ID)] = c
+	return true, nil
+}
+
+// TestSyncCollections verifies collections are exchanged with their item
+// order and the higher version wins.
+func TestSyncCollections(t *testing.T) {
+	store := newMockObjectStore()
+	repo := &mockCollectionRepository{
+		mockSyncRepository: newMockSyncRepository(),
+		collections: map[string]*models.Collection{
+			"reading": {ID: "reading", Name: "Reading", Version: 1, ItemIDs: []models.UUID{"a", "b"}},
+		},
+	}
+
+	data, _ := json.Marshal(models.Collection{ID: "reading", Name: "Reading", Version: 3,
+		ItemIDs: []models.UUID{"b", "a", "c"}})
+	store.Upload(context.Background(), collectionPrefix+"reading.json", data)
+
+	engine := NewSyncEngine(repo, store)
+	uploaded, downloaded, err := engine.syncCollections(context.Background(), "test")
+	if err != nil {
+		t.Fatalf("syncCollections failed: %v", err)
+	}
+	if uploaded != 0 || downloaded != 1 {
+		t.Errorf("uploaded, downloaded = %d, %d; want 0, 1", uploaded, downloaded)
+	}
+	if got := repo.collections["reading"].ItemIDs; !reflect.DeepEqual(got, []models.UUID{"b", "a", "c"}) {
+		t.Errorf("ItemIDs = %v, want remote order", got)
+	}
+
+	// Unsupported repositories are skipped
+	engine = NewSyncEngine(newMockSyncRepository(), newMockObjectStore())
+	if uploaded, downloaded, err := engine.syncCollections(context.Background(), "test"); err != nil || uploaded+downloaded != 0 {
+		t.Errorf("syncCollections = %d, %d, %v; want no-op", uploaded, downloaded, err)
+	}
+}
@@ -235,12 +235,34 @@ func (r *Resolver) ShouldAutoResolve(conflict *Conflict) bool {
 	return diff > 1
 }
 
-// MergeItems attempts to merge local and remote changes.
-// This is a placeholder for future merge strategies.
+// MergeItems merges local and remote changes to an item field by field.
+// Content fields come from the item written last, as in last write wins,
+// while each triage state (favorite, pinned, read, archived) comes from
+// whichever side changed it last. Neither input is modified.
 func (r *Resolver) MergeItems(localItem, remoteItem *models.ContentItem) (*models.ContentItem, error) {
-	// For now, we don't support automatic merging
-	// This could be implemented in the future for specific field-level merges
-	return nil, ErrMergeNotSupported
+	if localItem == nil || remoteItem == nil {
+		return nil, ErrInvalidConflict
+	}
+	if localItem.ID != remoteItem.ID {
+		return nil, ErrItemIDMismatch
+	}
+
+	winner, other := localItem, remoteItem
+	if remoteItem.UpdatedAt > localItem.UpdatedAt {
+		winner, other = remoteItem, localItem
+	}
+	merged := *winner
+	fields := merged.MergeStates(other)
+
+	logging.Info("Merged conflicting item",
+		map[string]interface{}{
+			"item_id":           localItem.ID,
+			"local_timestamp":   localItem.UpdatedAt,
+			"remote_timestamp":  remoteItem.UpdatedAt,
+			"states_from_loser": fields,
+		})
+
+	return &merged, nil
 }
 
 // Errors
@@ -387,23 +387,41 @@ func TestMergeItems(t *testing.T) {
 	now := time.Now().Unix()
 
 	localItem := &models.ContentItem{
-		ID:        "item-1",
-		Title:     "Local Title",
-		UpdatedAt: now,
-		Version:   1,
+		ID:                "item-1",
+		Title:             "Local Title",
+		UpdatedAt:         now,
+		Version:           1,
+		IsFavorite:        true,
+		FavoriteChangedAt: now + 200,
 	}
 
 	remoteItem := &models.ContentItem{
-		ID:        "item-1",
-		Title:     "Remote Title",
-		UpdatedAt: now + 100,
-		Version:   2,
+		ID:                "item-1",
+		Title:             "Remote Title",
+		UpdatedAt:         now + 100,
+		Version:           2,
+		IsArchived:        true,
+		ArchivedChangedAt: now + 100,
 	}
 
-	_, err := resolver.MergeItems(localItem, remoteItem)
+	merged, err := resolver.MergeItems(localItem, remoteItem)
+	if err != nil {
+		t.Fatalf("MergeItems failed: %v", err)
+	}
+
+	// Content fields follow the newer write, states the newer change
+	if merged.Title != "Remote Title" || merged.Version != 2 {
+		t.Errorf("Expected remote content, got %q version %d", merged.Title, merged.Version)
+	}
+	if !merged.IsFavorite || !merged.IsArchived {
+		t.Errorf("Expected both states to survive, got favorite %v archived %v", merged.IsFavorite, merged.IsArchived)
+	}
+	if remoteItem.IsFavorite {
+		t.Error("MergeItems modified its input")
+	}
 
-	if err != ErrMergeNotSupported {
-		t.Errorf("Expected ErrMergeNotSupported, got %v", err)
+	if _, err := resolver.MergeItems(localItem, &models.ContentItem{ID: "item-2"}); err != ErrItemIDMismatch {
+		t.Errorf("Expected ErrItemIDMismatch, got %v", err)
 	}
 }
 
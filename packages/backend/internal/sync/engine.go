@@ -6,6 +6,7 @@ import (
 	"database/sql"
 	"encoding/json"
 	"fmt"
+	"maps"
 	"sync"
 	"time"
 
@@ -103,6 +104,12 @@ type SyncEngine struct {
 	eventHandler SyncEventHandler
 	errorHistory []SyncErrorEntry
 	mu           sync.RWMutex
+
+	// syncedRecords holds the stamp of each record object as of the last
+	// successful sync; pendingRecords collects the stamps of the current
+	// sync until it succeeds (see exchangeRecords)
+	syncedRecords  map[string]recordStamp
+	pendingRecords map[string]recordStamp
 }
 
 // ObjectStore defines the interface for cloud storage operations.
@@ -123,10 +130,12 @@ type ObjectStore interface {
 // NewSyncEngine creates a new SyncEngine.
 func NewSyncEngine(repo db.SyncRepository, storage ObjectStore) *SyncEngine {
 	return &SyncEngine{
-		repo:         repo,
-		storage:      storage,
-		status:       SyncStatusIdle,
-		errorHistory: make([]SyncErrorEntry, 0, maxErrorHistory),
+		repo:           repo,
+		storage:        storage,
+		status:         SyncStatusIdle,
+		errorHistory:   make([]SyncErrorEntry, 0, maxErrorHistory),
+		syncedRecords:  make(map[string]recordStamp),
+		pendingRecords: make(map[string]recordStamp),
 	}
 }
 
@@ -237,6 +246,7 @@ func (e *SyncEngine) Sync(ctx context.Context) (*SyncResult, error) {
 
 	e.status = SyncStatusSyncing
 	e.lastErr = nil
+	clear(e.pendingRecords)
 
 	result := &SyncResult{
 		StartTime: time.Now(),
@@ -291,6 +301,7 @@ func (e *SyncEngine) Sync(ctx context.Context) (*SyncResult, error) {
 			e.status = SyncStatusIdle
 			e.lastSync = &result.EndTime
 			e.pending = 0
+			maps.Copy(e.syncedRecords, e.pendingRecords)
 
 			// Log sync success
 			logging.Info("Sync operation completed successfully",
@@ -335,6 +346,51 @@ func (e *SyncEngine) Sync(ctx context.Context) (*SyncResult, error) {
 	}
 	result.Downloaded = downloaded
 
+	// Step 2b: Exchange saved searches
+	searchesUp, searchesDown, err := e.syncSavedSearches(ctx, syncID)
+	result.Uploaded += searchesUp
+	result.Downloaded += searchesDown
+	if err != nil {
+		e.lastErr = fmt.Errorf("saved search sync failed: %w", err)
+		return result, e.lastErr
+	}
+
+	// Step 2c: Exchange collections
+	collectionsUp, collectionsDown, err := e.syncCollections(ctx, syncID)
+	result.Uploaded += collectionsUp
+	result.Downloaded += collectionsDown
+	if err != nil {
+		e.lastErr = fmt.Errorf("collection sync failed: %w", err)
+		return result, e.lastErr
+	}
+
+	// Step 2d: Exchange annotations
+	annotationsUp, annotationsDown, err := e.syncAnnotations(ctx, syncID)
+	result.Uploaded += annotationsUp
+	result.Downloaded += annotationsDown
+	if err != nil {
+		e.lastErr = fmt.Errorf("annotation sync failed: %w", err)
+		return result, e.lastErr
+	}
+
+	// Step 2e: Exchange content properties
+	propertiesUp, propertiesDown, err := e.syncContentProperties(ctx, syncID)
+	result.Uploaded += propertiesUp
+	result.Downloaded += propertiesDown
+	if err != nil {
+		e.lastErr = fmt.Errorf("content property sync failed: %w", err)
+		return result, e.lastErr
+	}
+
+	// Step 2f: Exchange search history (opt-in only)
+	historyUp, historyDown, err := e.syncSearchHistory(ctx, syncID)
+	result.Uploaded += historyUp
+	result.Downloaded += historyDown
+	if err != nil {
+		e.lastErr = fmt.Errorf("search history sync failed: %w", err)
+		return result, e.lastErr
+	}
+
 	// Step 3: Resolve conflicts
 	conflicts := e.resolveConflicts(ctx)
 	result.Conflicts = len(conflicts)
@@ -586,6 +642,11 @@ func (e *SyncEngine) downloadChanges(ctx context.Context, syncID string) (int, e
 					},
 				})
 			}
+
+			// Triage states merge field by field whichever version won
+			if merged := e.mergeContentStates(syncID, item); len(merged) > 0 && localItem.Version >= item.Version {
+				downloaded++
+			}
 		}
 	}
 
@@ -0,0 +1,58 @@
+// Package sync provides content property synchronization.
+package sync
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+
+	"github.com/kimhsiao/memonexus/backend/internal/db"
+	"github.com/kimhsiao/memonexus/backend/internal/models"
+)
+
+// propertyPrefix is the object store prefix for content properties.
+const propertyPrefix = "properties/"
+
+// syncContentProperties exchanges content properties with the remote store
+// when the repository supports them. Each property is its own record,
+// stored as {content_id}.{key}, so edits to different keys of an item
+// merge.
+func (e *SyncEngine) syncContentProperties(ctx context.Context, syncID string) (uploaded, downloaded int, err error) {
+	repo, ok := e.repo.(db.ContentPropertySyncRepository)
+	if !ok {
+		return 0, 0, nil
+	}
+
+	return e.exchangeRecords(ctx, syncID, recordExchange{
+		kind:   "content property",
+		prefix: propertyPrefix,
+		apply: func(data []byte) (string, bool, error) {
+			var p models.ContentProperty
+			if err := json.Unmarshal(data, &p); err != nil {
+				return "", false, fmt.Errorf("failed to deserialize content property: %w", err)
+			}
+			applied, err := repo.ApplyRemoteContentProperty(&p)
+			return propertyRecordID(&p), applied, err
+		},
+		local: func() ([]localRecord, error) {
+			properties, err := repo.ListContentPropertiesForSync()
+			if err != nil {
+				return nil, err
+			}
+			records := make([]localRecord, 0, len(properties))
+			for _, p := range properties {
+				data, err := json.Marshal(p)
+				if err != nil {
+					return nil, err
+				}
+				records = append(records, localRecord{id: propertyRecordID(p), data: data, stamp: recordStamp{p.Version, p.UpdatedAt}})
+			}
+			return records, nil
+		},
+	})
+}
+
+// propertyRecordID returns the record ID of a content property.
+func propertyRecordID(p *models.ContentProperty) string {
+	return string(p.ContentID) + "." + p.Key
+}
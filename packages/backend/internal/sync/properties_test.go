@@ -0,0 +1,71 @@
+// Package sync tests for content property synchronization.
+package sync
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+
+	"github.com/kimhsiao/memonexus/backend/internal/models"
+)
+
+// mockPropertyRepository adds content property sync to mockSyncRepository.
+type mockPropertyRepository struct {
+	*mockSyncRepository
+	properties map[string]*models.ContentProperty
+}
+
+func (m *mockPropertyRepository) ListContentPropertiesForSync() ([]*models.ContentProperty, error) {
+	result := make([]*models.ContentProperty, 0, len(m.properties))
+	for _, p := range m.properties {
+		result = append(result, p)
+	}
+	return result, nil
+}
+
+func (m *mockPropertyRepository) ApplyRemoteContentProperty(p *models.ContentProperty) (bool, error) {
+	local, ok := m.properties[propertyRecordID(p)]
+	if ok && local.Version >= p.Version {
+		return false, nil
+	}
+	m.properties[propertyRecordID(p)] = p
+	return true, nil
+}
+
+// TestSyncContentProperties verifies each key of an item is exchanged on
+// its own, so a remote edit to one key keeps local edits to another.
+func TestSyncContentProperties(t *testing.T) {
+	store := newMockObjectStore()
+	repo := &mockPropertyRepository{
+		mockSyncRepository: newMockSyncRepository(),
+		properties: map[string]*models.ContentProperty{
+			"item.author": {ContentID: "item", Key: "author", Type: "string", Value: "Jane", Version: 1},
+			"item.rating": {ContentID: "item", Key: "rating", Type: "number", Value: "4", Version: 2},
+		},
+	}
+
+	data, _ := json.Marshal(models.ContentProperty{ContentID: "item", Key: "author", Type: "string",
+		Value: "Jane Doe", Version: 2})
+	store.Upload(context.Background(), propertyPrefix+"item.author.json", data)
+	data, _ = json.Marshal(models.ContentProperty{ContentID: "item", Key: "rating", Type: "number",
+		Value: "1", Version: 1})
+	store.Upload(context.Background(), propertyPrefix+"item.rating.json", data)
+
+	engine := NewSyncEngine(repo, store)
+	uploaded, downloaded, err := engine.syncContentProperties(context.Background(), "test")
+	if err != nil {
+		t.Fatalf("syncContentProperties failed: %v", err)
+	}
+	if uploaded != 1 || downloaded != 1 {
+		t.Errorf("uploaded, downloaded = %d, %d; want 1, 1", uploaded, downloaded)
+	}
+	if repo.properties["item.author"].Value != "Jane Doe" || repo.properties["item.rating"].Value != "4" {
+		t.Errorf("Unexpected properties after sync: %+v %+v", repo.properties["item.author"], repo.properties["item.rating"])
+	}
+
+	data, err = store.Download(context.Background(), propertyPrefix+"item.rating.json")
+	var uploadedRating models.ContentProperty
+	if err != nil || json.Unmarshal(data, &uploadedRating) != nil || uploadedRating.Version != 2 {
+		t.Errorf("Local rating was not uploaded: %+v, %v", uploadedRating, err)
+	}
+}
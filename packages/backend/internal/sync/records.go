@@ -0,0 +1,136 @@
+// Package sync provides the exchange of versioned records other than
+// content items, such as saved searches.
+package sync
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/kimhsiao/memonexus/backend/internal/logging"
+)
+
+// localRecord is a JSON-encoded local record ready for upload.
+type localRecord struct {
+	id    string
+	data  []byte
+	stamp recordStamp
+}
+
+// recordStamp identifies one state of a record. A record whose stamp
+// matches the last successful sync has not changed and is not uploaded again.
+type recordStamp struct {
+	version   int
+	updatedAt int64
+}
+
+// recordExchange describes one kind of versioned record synced alongside
+// content items. Each record is stored as {prefix}{id}.json.
+type recordExchange struct {
+	// kind names the records in events and logs, e.g. "saved search"
+	kind string
+
+	// prefix is the object store prefix
+	prefix string
+
+	// apply decodes and stores a remote record if it is newer than the local
+	// copy, reporting the record ID and whether the local copy changed
+	apply func(data []byte) (id string, applied bool, err error)
+
+	// local returns every local record, including deleted ones
+	local func() ([]localRecord, error)
+}
+
+// exchangeRecords applies remote records first (the higher version wins),
+// then uploads the local records that changed since the last successful
+// sync, so the store always ends up with the newest version of each record,
+// including deletions. Records whose remote copy failed to download or
+// apply are not uploaded, so a newer remote version is never overwritten.
+func (e *SyncEngine) exchangeRecords(ctx context.Context, syncID string, x recordExchange) (uploaded, downloaded int, err error) {
+	// Download and apply remote records
+	keys, err := e.storage.List(ctx, x.prefix)
+	if err != nil {
+		return 0, 0, err
+	}
+	remote := make(map[string]bool, len(keys))
+	failed := make(map[string]bool)
+	applied := make(map[string]bool)
+	for _, key := range keys {
+		remote[key] = true
+		if err := ctx.Err(); err != nil {
+			return uploaded, downloaded, err
+		}
+
+		data, err := e.storage.Download(ctx, key)
+		if err != nil {
+			// T175: Graceful degradation - record error but continue
+			e.recordError(key, "download", err)
+			failed[key] = true
+			continue
+		}
+
+		id, changed, err := x.apply(data)
+		if err != nil {
+			failed[key] = true
+			e.recordError(key, "apply", fmt.Errorf("failed to apply %s %s: %w", x.kind, id, err))
+			logging.Warn("Failed to apply "+x.kind,
+				map[string]interface{}{
+					"sync_id": syncID,
+					"key":     key,
+					"error":   err.Error(),
+				})
+			continue
+		}
+		if changed {
+			applied[key] = true
+			downloaded++
+		}
+	}
+
+	// Upload local records
+	records, err := x.local()
+	if err != nil {
+		return uploaded, downloaded, err
+	}
+	for _, rec := range records {
+		if err := ctx.Err(); err != nil {
+			return uploaded, downloaded, err
+		}
+
+		key := x.prefix + rec.id + ".json"
+		if failed[key] {
+			continue
+		}
+		// A record just taken from the store, or unchanged since the last
+		// sync, is already there
+		if applied[key] || (remote[key] && e.syncedRecords[key] == rec.stamp) {
+			e.pendingRecords[key] = rec.stamp
+			continue
+		}
+
+		if err := e.storage.Upload(ctx, key, rec.data); err != nil {
+			e.recordError(rec.id, "upload", err)
+			logging.Warn("Failed to upload "+x.kind,
+				map[string]interface{}{
+					"sync_id": syncID,
+					"id":      rec.id,
+					"error":   err.Error(),
+				})
+			continue
+		}
+		e.pendingRecords[key] = rec.stamp
+		uploaded++
+	}
+
+	e.emitEvent(SyncEvent{
+		Type:    SyncEventProgress,
+		Message: fmt.Sprintf("%s records synced: %d uploaded, %d downloaded", x.kind, uploaded, downloaded),
+		Data: map[string]interface{}{
+			"sync_id":    syncID,
+			"kind":       x.kind,
+			"uploaded":   uploaded,
+			"downloaded": downloaded,
+		},
+	})
+
+	return uploaded, downloaded, nil
+}
@@ -0,0 +1,51 @@
+// Package sync provides saved search synchronization.
+package sync
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+
+	"github.com/kimhsiao/memonexus/backend/internal/db"
+	"github.com/kimhsiao/memonexus/backend/internal/models"
+)
+
+// savedSearchPrefix is the object store prefix for saved searches.
+const savedSearchPrefix = "saved_searches/"
+
+// syncSavedSearches exchanges saved searches with the remote store when the
+// repository supports them.
+func (e *SyncEngine) syncSavedSearches(ctx context.Context, syncID string) (uploaded, downloaded int, err error) {
+	repo, ok := e.repo.(db.SavedSearchSyncRepository)
+	if !ok {
+		return 0, 0, nil
+	}
+
+	return e.exchangeRecords(ctx, syncID, recordExchange{
+		kind:   "saved search",
+		prefix: savedSearchPrefix,
+		apply: func(data []byte) (string, bool, error) {
+			var ss models.SavedSearch
+			if err := json.Unmarshal(data, &ss); err != nil {
+				return "", false, fmt.Errorf("failed to deserialize saved search: %w", err)
+			}
+			applied, err := repo.ApplyRemoteSavedSearch(&ss)
+			return string(ss.ID), applied, err
+		},
+		local: func() ([]localRecord, error) {
+			searches, err := repo.ListSavedSearchesForSync()
+			if err != nil {
+				return nil, err
+			}
+			records := make([]localRecord, 0, len(searches))
+			for _, ss := range searches {
+				data, err := json.Marshal(ss)
+				if err != nil {
+					return nil, err
+				}
+				records = append(records, localRecord{id: string(ss.ID), data: data, stamp: recordStamp{ss.Version, ss.UpdatedAt}})
+			}
+			return records, nil
+		},
+	})
+}
@@ -0,0 +1,127 @@
+// Package sync tests for saved search synchronization.
+package sync
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+
+	"github.com/kimhsiao/memonexus/backend/internal/models"
+)
+
+// mockSavedSearchRepository adds saved search sync to mockSyncRepository.
+type mockSavedSearchRepository struct {
+	*mockSyncRepository
+	searches map[string]*models.SavedSearch
+}
+
+func (m *mockSavedSearchRepository) ListSavedSearchesForSync() ([]*models.SavedSearch, error) {
+	result := make([]*models.SavedSearch, 0, len(m.searches))
+	for _, ss := range m.searches {
+		result = append(result, ss)
+	}
+	return result, nil
+}
+
+func (m *mockSavedSearchRepository) ApplyRemoteSavedSearch(ss *models.SavedSearch) (bool, error) {
+	local, ok := m.searches[string(ss.ID)]
+	if ok && local.Version >= ss.Version {
+		return false, nil
+	}
+	m.searches[string(ss.ID)] = ss
+	return true, nil
+}
+
+// TestSyncSavedSearches verifies saved searches are exchanged and the
+// higher version wins.
+func TestSyncSavedSearches(t *testing.T) {
+	store := newMockObjectStore()
+	repo := &mockSavedSearchRepository{
+		mockSyncRepository: newMockSyncRepository(),
+		searches: map[string]*models.SavedSearch{
+			"local-only": {ID: "local-only", Name: "Local", Version: 1},
+			"shared":     {ID: "shared", Name: "Old", Version: 1},
+		},
+	}
+
+	upload := func(ss models.SavedSearch) {
+		data, _ := json.Marshal(ss)
+		store.Upload(context.Background(), savedSearchPrefix+string(ss.ID)+".json", data)
+	}
+	upload(models.SavedSearch{ID: "shared", Name: "New", Version: 2})
+	upload(models.SavedSearch{ID: "remote-only", Name: "Remote", Version: 1})
+
+	engine := NewSyncEngine(repo, store)
+	uploaded, downloaded, err := engine.syncSavedSearches(context.Background(), "test")
+	if err != nil {
+		t.Fatalf("syncSavedSearches failed: %v", err)
+	}
+	if downloaded != 2 {
+		t.Errorf("downloaded = %d, want 2", downloaded)
+	}
+	// Records just applied from the store are not uploaded back
+	if uploaded != 1 {
+		t.Errorf("uploaded = %d, want 1", uploaded)
+	}
+	if got := repo.searches["shared"].Name; got != "New" {
+		t.Errorf("shared name = %q, want remote version", got)
+	}
+	if _, ok := store.data[savedSearchPrefix+"local-only.json"]; !ok {
+		t.Error("Expected local saved search to be uploaded")
+	}
+}
+
+// TestSyncSavedSearches_unsupported verifies repositories without saved
+// search support are skipped.
+func TestSyncSavedSearches_unsupported(t *testing.T) {
+	engine := NewSyncEngine(newMockSyncRepository(), newMockObjectStore())
+	uploaded, downloaded, err := engine.syncSavedSearches(context.Background(), "test")
+	if err != nil || uploaded != 0 || downloaded != 0 {
+		t.Errorf("syncSavedSearches = %d, %d, %v; want no-op", uploaded, downloaded, err)
+	}
+}
+
+// TestSyncSavedSearches_uploadsChanges verifies only records changed since
+// the last successful sync are uploaded, and never over a remote copy that
+// failed to apply.
+func TestSyncSavedSearches_uploadsChanges(t *testing.T) {
+	store := newMockObjectStore()
+	repo := &mockSavedSearchRepository{
+		mockSyncRepository: newMockSyncRepository(),
+		searches: map[string]*models.SavedSearch{
+			"a": {ID: "a", Name: "A", Version: 1},
+			"b": {ID: "b", Name: "B", Version: 1},
+		},
+	}
+	engine := NewSyncEngine(repo, store)
+
+	sync := func() int {
+		result, err := engine.Sync(context.Background())
+		if err != nil {
+			t.Fatalf("Sync failed: %v", err)
+		}
+		return result.Uploaded
+	}
+	if uploaded := sync(); uploaded != 2 {
+		t.Errorf("First sync uploaded %d, want 2", uploaded)
+	}
+	if uploaded := sync(); uploaded != 0 {
+		t.Errorf("Unchanged sync uploaded %d, want 0", uploaded)
+	}
+
+	repo.searches["a"] = &models.SavedSearch{ID: "a", Name: "A2", Version: 2}
+	repo.searches["b"] = &models.SavedSearch{ID: "b", Name: "B2", Version: 2}
+	store.Upload(context.Background(), savedSearchPrefix+"b.json", []byte("not json"))
+	if uploaded := sync(); uploaded != 1 {
+		t.Errorf("Sync after changes uploaded %d, want 1", uploaded)
+	}
+	if got := string(store.data[savedSearchPrefix+"b.json"]); got != "not json" {
+		t.Errorf("Record that failed to apply was overwritten with %s", got)
+	}
+
+	// A record missing from the store is uploaded again
+	store.Delete(context.Background(), savedSearchPrefix+"a.json")
+	if uploaded := sync(); uploaded != 1 {
+		t.Errorf("Sync after remote loss uploaded %d, want 1", uploaded)
+	}
+}
@@ -0,0 +1,56 @@
+// Package sync provides opt-in search history synchronization.
+package sync
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+
+	"github.com/kimhsiao/memonexus/backend/internal/db"
+	"github.com/kimhsiao/memonexus/backend/internal/models"
+)
+
+// searchHistoryPrefix is the object store prefix for search history entries.
+const searchHistoryPrefix = "search_history/"
+
+// syncSearchHistory exchanges search history with the remote store, but
+// only if the repository supports it and the user opted in. Otherwise the
+// history never leaves this device.
+func (e *SyncEngine) syncSearchHistory(ctx context.Context, syncID string) (uploaded, downloaded int, err error) {
+	repo, ok := e.repo.(db.SearchHistorySyncRepository)
+	if !ok {
+		return 0, 0, nil
+	}
+	enabled, err := repo.SearchHistorySyncEnabled()
+	if err != nil || !enabled {
+		return 0, 0, err
+	}
+
+	return e.exchangeRecords(ctx, syncID, recordExchange{
+		kind:   "search history",
+		prefix: searchHistoryPrefix,
+		apply: func(data []byte) (string, bool, error) {
+			var entry models.SearchHistoryEntry
+			if err := json.Unmarshal(data, &entry); err != nil {
+				return "", false, fmt.Errorf("failed to deserialize search history: %w", err)
+			}
+			applied, err := repo.ApplyRemoteSearchHistory(&entry)
+			return string(entry.ID), applied, err
+		},
+		local: func() ([]localRecord, error) {
+			entries, err := repo.ListSearchHistoryForSync()
+			if err != nil {
+				return nil, err
+			}
+			records := make([]localRecord, 0, len(entries))
+			for _, entry := range entries {
+				data, err := json.Marshal(entry)
+				if err != nil {
+					return nil, err
+				}
+				records = append(records, localRecord{id: string(entry.ID), data: data, stamp: recordStamp{entry.Version, entry.UpdatedAt}})
+			}
+			return records, nil
+		},
+	})
+}
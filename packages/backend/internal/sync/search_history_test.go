@@ -0,0 +1,75 @@
+// Package sync tests for opt-in search history synchronization.
+package sync
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+
+	"github.com/kimhsiao/memonexus/backend/internal/models"
+)
+
+// mockSearchHistoryRepository adds search history sync to mockSyncRepository.
+type mockSearchHistoryRepository struct {
+	*mockSyncRepository
+	enabled bool
+	entries map[string]*models.SearchHistoryEntry
+}
+
+func (m *mockSearchHistoryRepository) SearchHistorySyncEnabled() (bool, error) {
+	return m.enabled, nil
+}
+
+func (m *mockSearchHistoryRepository) ListSearchHistoryForSync() ([]*models.SearchHistoryEntry, error) {
+	result := make([]*models.SearchHistoryEntry, 0, len(m.entries))
+	for _, e := range m.entries {
+		result = append(result, e)
+	}
+	return result, nil
+}
+
+func (m *mockSearchHistoryRepository) ApplyRemoteSearchHistory(e *models.SearchHistoryEntry) (bool, error) {
+	local, ok := m.entries[string(e.ID)]
+	if ok && local.Version >= e.Version {
+		return false, nil
+	}
+	m.entries[string(e.ID)] = e
+	return true, nil
+}
+
+// TestSyncSearchHistory verifies history only leaves the device after opt-in.
+func TestSyncSearchHistory(t *testing.T) {
+	store := newMockObjectStore()
+	repo := &mockSearchHistoryRepository{
+		mockSyncRepository: newMockSyncRepository(),
+		entries: map[string]*models.SearchHistoryEntry{
+			"local": {ID: "local", Query: "golang", Version: 1},
+		},
+	}
+	data, _ := json.Marshal(models.SearchHistoryEntry{ID: "remote", Query: "rust", Version: 1})
+	store.Upload(context.Background(), searchHistoryPrefix+"remote.json", data)
+
+	engine := NewSyncEngine(repo, store)
+
+	// Opted out: nothing is exchanged
+	uploaded, downloaded, err := engine.syncSearchHistory(context.Background(), "test")
+	if err != nil || uploaded != 0 || downloaded != 0 {
+		t.Errorf("syncSearchHistory = %d, %d, %v; want no-op when disabled", uploaded, downloaded, err)
+	}
+	if _, ok := store.data[searchHistoryPrefix+"local.json"]; ok {
+		t.Error("History uploaded without opt-in")
+	}
+
+	// Opted in
+	repo.enabled = true
+	uploaded, downloaded, err = engine.syncSearchHistory(context.Background(), "test")
+	if err != nil {
+		t.Fatalf("syncSearchHistory failed: %v", err)
+	}
+	if uploaded != 1 || downloaded != 1 {
+		t.Errorf("syncSearchHistory = %d uploaded, %d downloaded; want 1, 1", uploaded, downloaded)
+	}
+	if _, ok := repo.entries["remote"]; !ok {
+		t.Error("Expected remote history entry to be applied")
+	}
+}
@@ -0,0 +1,48 @@
+// Package sync provides triage state synchronization.
+package sync
+
+import (
+	"fmt"
+
+	"github.com/kimhsiao/memonexus/backend/internal/db"
+	"github.com/kimhsiao/memonexus/backend/internal/logging"
+	"github.com/kimhsiao/memonexus/backend/internal/models"
+)
+
+// mergeContentStates merges the triage states of a downloaded item into
+// the local copy when the repository supports it. States change without
+// bumping item versions, so they merge field by field on their change
+// times whichever version wins. Returns the states taken from the remote
+// item.
+func (e *SyncEngine) mergeContentStates(syncID string, item *models.ContentItem) []string {
+	repo, ok := e.repo.(db.ContentStateSyncRepository)
+	if !ok {
+		return nil
+	}
+
+	merged, err := repo.ApplyRemoteContentState(item)
+	if err != nil {
+		e.recordError(string(item.ID), "merge_states", err)
+		logging.Warn("Failed to merge item states",
+			map[string]interface{}{
+				"sync_id": syncID,
+				"item_id": item.ID,
+				"error":   err.Error(),
+			})
+		return nil
+	}
+	if len(merged) == 0 {
+		return nil
+	}
+
+	e.emitEvent(SyncEvent{
+		Type:    SyncEventDownloadItem,
+		ItemID:  string(item.ID),
+		Message: fmt.Sprintf("Merged states of item %s", item.ID),
+		Data: map[string]interface{}{
+			"sync_id":       syncID,
+			"merged_fields": merged,
+		},
+	})
+	return merged
+}
@@ -0,0 +1,58 @@
+// Package sync tests for triage state synchronization.
+package sync
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+
+	"github.com/kimhsiao/memonexus/backend/internal/models"
+)
+
+// mockStateRepository adds triage state merging to mockSyncRepository.
+type mockStateRepository struct {
+	*mockSyncRepository
+}
+
+func (m *mockStateRepository) ApplyRemoteContentState(item *models.ContentItem) ([]string, error) {
+	local, err := m.GetContentItem(string(item.ID))
+	if err != nil {
+		return nil, err
+	}
+	return local.MergeStates(item), nil
+}
+
+// TestDownloadChanges_mergesStates verifies triage states merge field by
+// field even when the local item has the newer version.
+func TestDownloadChanges_mergesStates(t *testing.T) {
+	store := newMockObjectStore()
+	repo := &mockStateRepository{mockSyncRepository: newMockSyncRepository()}
+	repo.items["item"] = &models.ContentItem{
+		ID: "item", Title: "Local", Version: 3,
+		IsFavorite: true, FavoriteChangedAt: 300,
+	}
+
+	data, _ := json.Marshal(models.ContentItem{
+		ID: "item", Title: "Remote", Version: 2,
+		IsFavorite: false, FavoriteChangedAt: 100,
+		IsArchived: true, ArchivedChangedAt: 200,
+	})
+	store.Upload(context.Background(), "items/item.json", data)
+
+	engine := NewSyncEngine(repo, store)
+	downloaded, err := engine.downloadChanges(context.Background(), "test")
+	if err != nil {
+		t.Fatalf("downloadChanges failed: %v", err)
+	}
+	if downloaded != 1 {
+		t.Errorf("downloaded = %d, want 1", downloaded)
+	}
+
+	local := repo.items["item"]
+	if local.Title != "Local" || !local.IsFavorite || !local.IsArchived {
+		t.Errorf("Unexpected item after sync: %+v", local)
+	}
+	if len(repo.conflictLogs) != 1 {
+		t.Errorf("Expected the version conflict to be logged, got %d logs", len(repo.conflictLogs))
+	}
+}